@@ -2,11 +2,16 @@ package commands
 
 import (
 	"context" // 上下文控制
+	"fmt"     // 错误格式化
 	"net"     // 网络操作
+	"strings" // 路径解析
 	"time"    // 时间处理
 
-	samqp "github.com/rabbitmq/amqp091-go" // RabbitMQ客户端，别名为samqp
+	"github.com/libp2p/go-libp2p-core/peer" // peer ID 校验
+	samqp "github.com/rabbitmq/amqp091-go"  // RabbitMQ客户端，别名为samqp
 
+	"github.com/ipfs-search/ipfs-search/components/dnslink"    // 域名识别
+	"github.com/ipfs-search/ipfs-search/components/queue"      // 队列接口
 	"github.com/ipfs-search/ipfs-search/components/queue/amqp" // 队列组件
 	"github.com/ipfs-search/ipfs-search/config"                // 配置管理
 	"github.com/ipfs-search/ipfs-search/instr"                 // 监控工具 （tocheck: 具体实现？）
@@ -14,8 +19,59 @@ import (
 	"github.com/ipfs-search/ipfs-search/utils"                 // 工具函数
 )
 
-// AddHash 将单个IPFS哈希添加到索引队列中，接收上下文、配置对象和哈希字符串
-func AddHash(ctx context.Context, cfg *config.Config, hash string) error {
+// parseResource 解析 CID、域名、/ipfs/<cid> 或 /ipns/<name> 形式的路径；域名通过 DNSLink 解析
+func parseResource(path string) (*t.Resource, error) {
+	resource := &t.Resource{
+		Protocol: t.IPFSProtocol,
+		ID:       path,
+	}
+
+	if strings.HasPrefix(path, "/") {
+		parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid path: %s", path)
+		}
+
+		switch parts[0] {
+		case "ipfs":
+			resource.Protocol = t.IPFSProtocol
+		case "ipns":
+			resource.Protocol = t.IPNSProtocol
+			if dnslink.IsDomain(parts[1]) {
+				resource.Protocol = t.DNSLinkProtocol
+			}
+		default:
+			return nil, fmt.Errorf("unsupported protocol in path: %s", path)
+		}
+
+		resource.ID = parts[1]
+	} else if dnslink.IsDomain(path) {
+		// CID 不含点号
+		resource.Protocol = t.DNSLinkProtocol
+	}
+
+	if !resource.IsValid() {
+		return nil, fmt.Errorf("invalid path: %s", path)
+	}
+
+	return resource, nil
+}
+
+// AddHash 将单个IPFS哈希（或域名、/ipfs/、/ipns/ 路径）添加到索引队列中，接收上下文、配置对象、哈希字符串以及（可选的）提供者 peer ID
+func AddHash(ctx context.Context, cfg *config.Config, hash string, providers []string) error {
+	// 构建资源对象（IPFS 或 IPNS 协议 + 用户输入）
+	resource, err := parseResource(hash)
+	if err != nil {
+		return err
+	}
+
+	// 校验提供者 peer ID
+	for _, p := range providers {
+		if _, err := peer.Decode(p); err != nil {
+			return fmt.Errorf("invalid provider %s: %w", p, err)
+		}
+	}
+
 	// 初始化监控组件，命名空间为"ipfs-crawler add"
 	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler add")
 	if err != nil {
@@ -25,6 +81,25 @@ func AddHash(ctx context.Context, cfg *config.Config, hash string) error {
 
 	i := instr.New() // 创建监控实例（tocheck: 是否与Install的实例关联？）
 
+	// 创建队列发布者实例
+	queue, err := getHashesQueue(ctx, cfg, i)
+	if err != nil {
+		return err // 连接失败（如认证错误、网络不可达）
+	}
+
+	// 添加元数据（来源标记为手动）
+	r := t.AnnotatedResource{
+		Resource:  resource,
+		Source:    t.ManualSource, // 区分任务来源（如爬虫发现 vs 用户添加）
+		Providers: providers,      // 爬取前先连接这些提供者
+	}
+
+	// 发布消息到队列，优先级9（最高）
+	return queue.Publish(ctx, &r, 9) // tocheck: 队列是否启用优先级支持？
+}
+
+// getHashesQueue 连接 AMQP 并返回 hashes 队列的发布者
+func getHashesQueue(ctx context.Context, cfg *config.Config, i *instr.Instrumentation) (queue.Publisher, error) {
 	// 配置带重试的拨号器（TCP连接）
 	dialer := &utils.RetryingDialer{
 		Dialer: net.Dialer{
@@ -49,25 +124,5 @@ func AddHash(ctx context.Context, cfg *config.Config, hash string) error {
 	}
 
 	// 创建队列发布者实例
-	queue, err := f.NewPublisher(ctx)
-	if err != nil {
-		return err // 连接失败（如认证错误、网络不可达）
-	}
-
-	// 构建资源对象（IPFS协议 + 用户输入哈希）
-	resource := &t.Resource{
-		Protocol: t.IPFSProtocol, // 协议类型（tocheck: 是否支持IPNS？）
-		ID:       hash,           // 用户提供的CID
-	}
-
-	// 添加元数据（来源标记为手动）
-	r := t.AnnotatedResource{
-		Resource: resource,
-		Source:   t.ManualSource, // 区分任务来源（如爬虫发现 vs 用户添加）
-	}
-
-	// TODO: 使用provider字段（当前未实现，可能影响内容路由效率）
-
-	// 发布消息到队列，优先级9（最高）
-	return queue.Publish(ctx, &r, 9) // tocheck: 队列是否启用优先级支持？
+	return f.NewPublisher(ctx)
 }
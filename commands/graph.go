@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ipfs-search/ipfs-search/components/graph"
+	"github.com/ipfs-search/ipfs-search/components/graph/sqlite"
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// ErrNoGraph 表示未配置边存储。
+var ErrNoGraph = errors.New("edge store not configured, set graph.path or GRAPH_PATH")
+
+// openGraph 打开配置的边存储。
+func openGraph(ctx context.Context, cfg *config.Config) (*sqlite.Store, error) {
+	if cfg.Graph.Path == "" {
+		return nil, ErrNoGraph
+	}
+
+	return sqlite.New(ctx, cfg.GraphConfig(), instr.New())
+}
+
+// WalkGraph 将 id 的祖先或后代（最多 depth 层）写入 w，每行一条边，按层级缩进。
+func WalkGraph(ctx context.Context, cfg *config.Config, id string, dir graph.Direction, depth, limit int, w io.Writer) error {
+	store, err := openGraph(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	steps, err := graph.Walk(ctx, store, id, dir, depth, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range steps {
+		indent := strings.Repeat("  ", s.Depth-1)
+
+		if dir == graph.Ancestors {
+			fmt.Fprintf(w, "%s%s <- %s/%s\n", indent, s.ChildHash, s.ParentHash, s.Name)
+		} else {
+			fmt.Fprintf(w, "%s%s/%s -> %s\n", indent, s.ParentHash, s.Name, s.ChildHash)
+		}
+	}
+
+	return nil
+}
+
+// GraphPaths 将 id 的完整路径（最多 limit 条，向上最多 depth 层）写入 w，每行一条。
+func GraphPaths(ctx context.Context, cfg *config.Config, id string, depth, limit int, w io.Writer) error {
+	store, err := openGraph(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	paths, err := graph.Paths(ctx, store, id, depth, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		fmt.Fprintln(w, p.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-search/ipfs-search/components/car"           // CAR 文件解析
+	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs" // IPFS 节点
+	"github.com/ipfs-search/ipfs-search/components/worker/pool"   // 爬虫构建
+	"github.com/ipfs-search/ipfs-search/config"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// ImportCAR 离线读取 CARv1/v2 文件，解析 UnixFS 节点以发现其中的文件与目录，并将根节点（类型与大小已知）加入队列。
+// crawl 为真时直接爬取根节点而不经过队列；push 为真时先将所有区块写入每个 IPFS 节点，使尚未在网络上提供的内容可被爬取。
+func ImportCAR(ctx context.Context, cfg *config.Config, filename string, crawl, push bool, w io.Writer) error {
+	instFlusher, err := instr.Install(cfg.InstrConfig(), "ipfs-crawler import-car")
+	if err != nil {
+		return err
+	}
+	defer instFlusher(ctx)
+
+	i := instr.New()
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// 可选：边读取边写入区块
+	var onBlock func(*car.Block) error
+	if push {
+		p := ipfs.New(cfg.IPFSConfig(), &http.Client{}, i)
+
+		onBlock = func(b *car.Block) error {
+			return p.PutBlock(ctx, b.Cid, b.Data)
+		}
+	}
+
+	dag, err := car.Load(f, onBlock)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	fmt.Fprintf(w, "读取 %d 个区块，%d 个根节点\n", dag.Len(), len(dag.Roots()))
+
+	var resources []*t.AnnotatedResource
+
+	for _, root := range dag.Roots() {
+		if r := describeRoot(dag, root, w); r != nil {
+			resources = append(resources, r)
+		}
+	}
+
+	if crawl {
+		return crawlRoots(ctx, cfg, i, resources)
+	}
+
+	return queueRoots(ctx, cfg, i, resources)
+}
+
+// describeRoot 输出根节点下的文件与目录统计，并返回待爬取的资源；不支持的类型返回 nil
+func describeRoot(dag *car.DAG, root cid.Cid, w io.Writer) *t.AnnotatedResource {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       root.String(),
+		},
+		Source: t.ManualSource,
+	}
+
+	n := dag.Get(root)
+	if n == nil {
+		// 根节点不在文件中，由爬虫通过网络获取类型
+		fmt.Fprintf(w, "%s: 不在文件中\n", root)
+		return r
+	}
+
+	if n.Type == t.UnsupportedType {
+		fmt.Fprintf(w, "%s: 不支持的类型，跳过\n", root)
+		return nil
+	}
+
+	var files, directories, missing int
+
+	// WalkFunc 从不返回错误
+	_ = dag.Walk(root, func(_ string, _ cid.Cid, n *car.Node) error {
+		switch {
+		case n == nil:
+			missing++
+		case n.Type == t.FileType:
+			files++
+		case n.Type == t.DirectoryType:
+			directories++
+		}
+
+		return nil
+	})
+
+	fmt.Fprintf(w, "%s: %s，%d 字节，%d 个文件，%d 个目录，缺失 %d 个条目\n",
+		root, n.Type, n.Size, files, directories, missing)
+
+	r.Stat = t.Stat{
+		Type: n.Type,
+		Size: n.Size,
+	}
+
+	return r
+}
+
+// queueRoots 将根节点以最高优先级加入 hashes 队列
+func queueRoots(ctx context.Context, cfg *config.Config, i *instr.Instrumentation, resources []*t.AnnotatedResource) error {
+	queue, err := getHashesQueue(ctx, cfg, i)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range resources {
+		if err := queue.Publish(ctx, r, 9); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// crawlRoots 直接爬取根节点；目录中的条目仍通过队列由爬虫处理
+func crawlRoots(ctx context.Context, cfg *config.Config, i *instr.Instrumentation, resources []*t.AnnotatedResource) error {
+	c, closeCrawler, err := pool.NewCrawler(ctx, cfg, i)
+	if err != nil {
+		return err
+	}
+
+	// 刷新后台组件缓冲的更新，并等待其写入索引
+	defer func() {
+		log.Println("Waiting for indexes to flush.")
+		closeCrawler(context.Background())
+	}()
+
+	for _, r := range resources {
+		if err := c.Crawl(ctx, r); err != nil {
+			return fmt.Errorf("crawling %s: %w", r, err)
+		}
+	}
+
+	return nil
+}
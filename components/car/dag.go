@@ -0,0 +1,141 @@
+package car
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/ipfs/go-cid"
+)
+
+// DAG holds the decoded nodes of an archive. Only types, sizes and directory entries are kept; block data is not.
+type DAG struct {
+	roots []cid.Cid
+	nodes map[string]*Node // Keyed by multihash, as links may use either CID version.
+}
+
+// ErrMissing is returned when blocks required for an operation are missing from the archive.
+var ErrMissing = errors.New("block missing from archive")
+
+// WalkFunc is called by Walk for every entry, with its path relative to the root.
+// Node is nil for entries missing from the archive.
+type WalkFunc func(p string, c cid.Cid, n *Node) error
+
+// Load reads and decodes all blocks from an archive, calling onBlock for each block when not nil.
+func Load(r io.Reader, onBlock func(*Block) error) (*DAG, error) {
+	cr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DAG{
+		roots: cr.Roots(),
+		nodes: make(map[string]*Node),
+	}
+
+	for {
+		b, err := cr.Next()
+		if errors.Is(err, io.EOF) {
+			return d, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := Decode(b)
+		if err != nil {
+			return nil, err
+		}
+
+		d.nodes[string(b.Cid.Hash())] = n
+
+		if onBlock != nil {
+			if err := onBlock(b); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// Roots returns the roots listed in the header of the archive.
+func (d *DAG) Roots() []cid.Cid {
+	return d.roots
+}
+
+// Len returns the number of blocks in the archive.
+func (d *DAG) Len() int {
+	return len(d.nodes)
+}
+
+// Get returns the Node for c, or nil when missing from the archive.
+func (d *DAG) Get(c cid.Cid) *Node {
+	return d.nodes[string(c.Hash())]
+}
+
+// Walk calls fn for root and, recursively, for the entries of directories; sub-shards of sharded directories
+// are walked as part of their directory.
+func (d *DAG) Walk(root cid.Cid, fn WalkFunc) error {
+	return d.walk("", root, fn)
+}
+
+func (d *DAG) walk(p string, c cid.Cid, fn WalkFunc) error {
+	n := d.Get(c)
+
+	if err := fn(p, c, n); err != nil {
+		return err
+	}
+
+	if n == nil {
+		return nil
+	}
+
+	return d.walkLinks(p, n, fn)
+}
+
+func (d *DAG) walkLinks(p string, n *Node, fn WalkFunc) error {
+	for _, l := range n.Links {
+		if l.Name != "" {
+			if err := d.walk(path.Join(p, l.Name), l.Cid, fn); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		// Sub-shard; missing shards are skipped as their entries are unknown.
+		if shard := d.Get(l.Cid); shard != nil {
+			if err := d.walkLinks(p, shard, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Ls calls fn for the entries of directory c, including those in sub-shards of sharded directories.
+// ErrMissing is returned when c or any of its sub-shards are missing.
+func (d *DAG) Ls(c cid.Cid, fn func(Link) error) error {
+	n := d.Get(c)
+	if n == nil {
+		return fmt.Errorf("%w: %s", ErrMissing, c)
+	}
+
+	for _, l := range n.Links {
+		if l.Name != "" {
+			if err := fn(l); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := d.Ls(l.Cid, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package car
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	merkledag "github.com/ipfs/go-merkledag"
+	unixfs "github.com/ipfs/go-unixfs"
+	"github.com/stretchr/testify/suite"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type DAGTestSuite struct {
+	suite.Suite
+
+	buf *bytes.Buffer
+	w   *Writer
+}
+
+type entry struct {
+	Path string
+	Cid  cid.Cid
+	Node *Node
+}
+
+func (s *DAGTestSuite) archive(roots ...cid.Cid) {
+	var err error
+
+	s.buf = new(bytes.Buffer)
+	s.w, err = NewWriter(s.buf, roots)
+	s.Require().NoError(err)
+}
+
+func (s *DAGTestSuite) put(c cid.Cid, data []byte) {
+	s.Require().NoError(s.w.Put(c, data))
+}
+
+func (s *DAGTestSuite) load() *DAG {
+	d, err := Load(s.buf, nil)
+	s.Require().NoError(err)
+
+	return d
+}
+
+func (s *DAGTestSuite) walk(d *DAG, root cid.Cid) []entry {
+	var entries []entry
+
+	err := d.Walk(root, func(p string, c cid.Cid, n *Node) error {
+		entries = append(entries, entry{p, c, n})
+		return nil
+	})
+	s.Require().NoError(err)
+
+	return entries
+}
+
+func (s *DAGTestSuite) TestDirectory() {
+	leaf := merkledag.NewRawNode([]byte("hello"))
+	file := merkledag.NodeWithData(unixfs.FilePBData([]byte("hello world"), 11))
+	missing := merkledag.NewRawNode([]byte("missing"))
+
+	sub := merkledag.NodeWithData(unixfs.FolderPBData())
+	s.Require().NoError(sub.AddNodeLink("readme.txt", file))
+
+	root := merkledag.NodeWithData(unixfs.FolderPBData())
+	s.Require().NoError(root.AddNodeLink("a.txt", leaf))
+	s.Require().NoError(root.AddNodeLink("sub", sub))
+	s.Require().NoError(root.AddNodeLink("missing.txt", missing))
+
+	s.archive(root.Cid())
+	s.put(root.Cid(), root.RawData())
+	s.put(sub.Cid(), sub.RawData())
+	s.put(file.Cid(), file.RawData())
+	s.put(leaf.Cid(), leaf.RawData())
+
+	d := s.load()
+
+	s.Equal([]cid.Cid{root.Cid()}, d.Roots())
+	s.Equal(4, d.Len())
+
+	rootSize, err := root.Size()
+	s.Require().NoError(err)
+
+	s.Equal(t.DirectoryType, d.Get(root.Cid()).Type)
+	s.Equal(rootSize, d.Get(root.Cid()).Size)
+
+	entries := s.walk(d, root.Cid())
+	s.Len(entries, 5)
+
+	s.Equal("", entries[0].Path)
+	s.Equal(root.Cid(), entries[0].Cid)
+
+	s.Equal("a.txt", entries[1].Path)
+	s.Equal(&Node{Type: t.FileType, Size: 5}, entries[1].Node)
+
+	// Links are sorted by name.
+	s.Equal("missing.txt", entries[2].Path)
+	s.Equal(missing.Cid(), entries[2].Cid)
+	s.Nil(entries[2].Node)
+
+	s.Equal("sub", entries[3].Path)
+	s.Equal(t.DirectoryType, entries[3].Node.Type)
+
+	s.Equal("sub/readme.txt", entries[4].Path)
+	s.Equal(&Node{Type: t.FileType, Size: 11}, entries[4].Node)
+}
+
+func (s *DAGTestSuite) TestShardedDirectory() {
+	const fanout, murmur3 = 256, 0x22
+
+	b := merkledag.NewRawNode([]byte("b"))
+	c := merkledag.NewRawNode([]byte("c"))
+
+	shardData, err := unixfs.HAMTShardData([]byte{1}, fanout, murmur3)
+	s.Require().NoError(err)
+
+	subShard := merkledag.NodeWithData(shardData)
+	s.Require().NoError(subShard.AddNodeLink("00c.txt", c))
+
+	shard := merkledag.NodeWithData(shardData)
+	s.Require().NoError(shard.AddNodeLink("0Ab.txt", b))
+	s.Require().NoError(shard.AddNodeLink("1F", subShard))
+
+	s.archive(shard.Cid())
+	s.put(shard.Cid(), shard.RawData())
+	s.put(subShard.Cid(), subShard.RawData())
+	s.put(b.Cid(), b.RawData())
+	s.put(c.Cid(), c.RawData())
+
+	d := s.load()
+	entries := s.walk(d, shard.Cid())
+
+	s.Len(entries, 3)
+	s.Equal(t.DirectoryType, entries[0].Node.Type)
+	s.Equal("b.txt", entries[1].Path)
+	s.Equal(b.Cid(), entries[1].Cid)
+	s.Equal("c.txt", entries[2].Path)
+	s.Equal(c.Cid(), entries[2].Cid)
+
+	var links []Link
+	err = d.Ls(shard.Cid(), func(l Link) error {
+		links = append(links, l)
+		return nil
+	})
+
+	s.NoError(err)
+	s.Equal([]Link{{"b.txt", b.Cid()}, {"c.txt", c.Cid()}}, links)
+}
+
+func (s *DAGTestSuite) TestLsMissingShard() {
+	const fanout, murmur3 = 256, 0x22
+
+	shardData, err := unixfs.HAMTShardData([]byte{1}, fanout, murmur3)
+	s.Require().NoError(err)
+
+	subShard := merkledag.NodeWithData(shardData)
+
+	shard := merkledag.NodeWithData(shardData)
+	s.Require().NoError(shard.AddNodeLink("1F", subShard))
+
+	s.archive(shard.Cid())
+	s.put(shard.Cid(), shard.RawData())
+
+	err = s.load().Ls(shard.Cid(), func(Link) error { return nil })
+
+	s.ErrorIs(err, ErrMissing)
+}
+
+func (s *DAGTestSuite) TestUnsupported() {
+	symlinkData, err := unixfs.SymlinkData("a.txt")
+	s.Require().NoError(err)
+
+	symlink := merkledag.NodeWithData(symlinkData)
+	plain := merkledag.NodeWithData([]byte("not unixfs"))
+
+	s.archive()
+	s.put(symlink.Cid(), symlink.RawData())
+	s.put(plain.Cid(), plain.RawData())
+
+	d := s.load()
+
+	s.Equal(t.UnsupportedType, d.Get(symlink.Cid()).Type)
+	s.Equal(t.UnsupportedType, d.Get(plain.Cid()).Type)
+}
+
+func (s *DAGTestSuite) TestOnBlock() {
+	leaf := merkledag.NewRawNode([]byte("hello"))
+
+	s.archive(leaf.Cid())
+	s.put(leaf.Cid(), leaf.RawData())
+
+	var blocks []*Block
+
+	_, err := Load(s.buf, func(b *Block) error {
+		blocks = append(blocks, b)
+		return nil
+	})
+
+	s.NoError(err)
+	s.Equal([]*Block{{leaf.Cid(), leaf.RawData()}}, blocks)
+}
+
+func TestDAGTestSuite(t *testing.T) {
+	suite.Run(t, new(DAGTestSuite))
+}
@@ -0,0 +1,84 @@
+package car
+
+import (
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	merkledag "github.com/ipfs/go-merkledag"
+	unixfs "github.com/ipfs/go-unixfs"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Link is a named entry of a directory. Links to sub-shards of sharded directories have no name.
+type Link struct {
+	Name string
+	Cid  cid.Cid
+}
+
+// Node is the type and size of a block, with the entries for directories.
+// Sizes match those of the IPFS protocol: file size for files and cumulative size for directories.
+type Node struct {
+	Type  t.ResourceType
+	Size  uint64
+	Links []Link
+}
+
+// Decode returns the Node for a block. Blocks which are neither UnixFS nor IPLD documents are unsupported.
+func Decode(b *Block) (*Node, error) {
+	switch {
+	case b.Cid.Type() == cid.Raw:
+		return &Node{Type: t.FileType, Size: uint64(len(b.Data))}, nil
+	case t.IsIPLDDocument(b.Cid):
+		return &Node{Type: t.IPLDType, Size: uint64(len(b.Data))}, nil
+	case b.Cid.Type() == cid.DagProtobuf:
+		return decodeProtobuf(b)
+	default:
+		return &Node{Type: t.UnsupportedType}, nil
+	}
+}
+
+func decodeProtobuf(b *Block) (*Node, error) {
+	pn, err := merkledag.DecodeProtobuf(b.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSection, b.Cid, err)
+	}
+
+	fsn, err := unixfs.FSNodeFromBytes(pn.Data())
+	if err != nil {
+		// Valid dag-pb but not UnixFS.
+		return &Node{Type: t.UnsupportedType}, nil
+	}
+
+	switch fsn.Type() {
+	case unixfs.TFile, unixfs.TRaw:
+		return &Node{Type: t.FileType, Size: fsn.FileSize()}, nil
+	case unixfs.TDirectory, unixfs.THAMTShard:
+		n := &Node{
+			Type:  t.DirectoryType,
+			Size:  uint64(len(b.Data)),
+			Links: make([]Link, len(pn.Links())),
+		}
+
+		// Entries in shards are prefixed by their (uppercase hex) index in the shard; sub-shards are named by
+		// their index only.
+		var padLen int
+		if fsn.Type() == unixfs.THAMTShard {
+			padLen = len(fmt.Sprintf("%X", fsn.Fanout()-1))
+		}
+
+		for i, l := range pn.Links() {
+			n.Size += l.Size
+			n.Links[i] = Link{Cid: l.Cid}
+
+			if len(l.Name) > padLen {
+				n.Links[i].Name = l.Name[padLen:]
+			}
+		}
+
+		return n, nil
+	default:
+		// Symlinks and metadata.
+		return &Node{Type: t.UnsupportedType}, nil
+	}
+}
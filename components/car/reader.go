@@ -0,0 +1,249 @@
+// Package car reads and writes Content Addressable aRchives, the transport format for IPLD blocks.
+// Ref: https://ipld.io/specs/transport/car/
+package car
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	"github.com/ipfs/go-cid"
+)
+
+const (
+	// maxHeaderSize limits the allocation for headers, which only list roots.
+	maxHeaderSize = 1024 * 1024
+
+	// maxSectionSize limits the allocation for blocks, which are 2MiB at most on the network.
+	maxSectionSize = 8 * 1024 * 1024
+
+	// cidTag is the CBOR tag for CIDs in dag-cbor.
+	cidTag = 42
+
+	// v2HeaderSize is the size of the fixed CARv2 header, following the pragma.
+	v2HeaderSize = 40
+)
+
+var (
+	// ErrInvalidHeader is returned for archives without a valid header.
+	ErrInvalidHeader = errors.New("invalid CAR header")
+
+	// ErrUnsupportedVersion is returned for archives which are neither CARv1 nor CARv2.
+	ErrUnsupportedVersion = errors.New("unsupported CAR version")
+
+	// ErrInvalidSection is returned for blocks which cannot be read.
+	ErrInvalidSection = errors.New("invalid CAR section")
+
+	// ErrHashMismatch is returned for blocks of which the data doesn't match the CID.
+	ErrHashMismatch = errors.New("block does not match CID")
+)
+
+// Block is raw block data with its CID.
+type Block struct {
+	Cid  cid.Cid
+	Data []byte
+}
+
+type header struct {
+	Roots   []cbor.Tag `cbor:"roots"`
+	Version uint64     `cbor:"version"`
+}
+
+// Reader reads blocks from CARv1 and CARv2 archives, verifying them against their CID.
+type Reader struct {
+	r       *bufio.Reader
+	roots   []cid.Cid
+	version uint64
+
+	pos    int64 // Position in the archive.
+	offset int64 // Offset of the data of the last block.
+}
+
+// NewReader reads the header of an archive and returns a Reader for its blocks.
+// CARv2 archives are read through their CARv1 payload; their index is ignored.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+
+	h, pos, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	switch h.Version {
+	case 1:
+	case 2:
+		// The header was the CARv2 pragma; the payload follows a fixed header.
+		var dataOffset, headerLen int64
+
+		if br, dataOffset, err = openPayload(br); err != nil {
+			return nil, err
+		}
+
+		if h, headerLen, err = readHeader(br); err != nil {
+			return nil, err
+		}
+
+		pos = dataOffset + headerLen
+
+		if h.Version != 1 {
+			return nil, fmt.Errorf("%w: payload version %d", ErrUnsupportedVersion, h.Version)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, h.Version)
+	}
+
+	roots := make([]cid.Cid, 0, len(h.Roots))
+	for _, tag := range h.Roots {
+		c, err := decodeCIDTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		roots = append(roots, c)
+	}
+
+	return &Reader{
+		r:       br,
+		roots:   roots,
+		version: h.Version,
+		pos:     pos,
+	}, nil
+}
+
+// Roots returns the roots listed in the header.
+func (r *Reader) Roots() []cid.Cid {
+	return r.roots
+}
+
+// Offset returns the offset of the data of the last block returned by Next, relative to the start of the archive.
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+// Next returns the next block, or io.EOF after the last block.
+func (r *Reader) Next() (*Block, error) {
+	section, l, err := readSection(r.r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := r.pos + int64(l-len(section))
+	r.pos += int64(l)
+
+	n, c, err := cid.CidFromBytes(section)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSection, err)
+	}
+
+	data := section[n:]
+	r.offset = start + int64(n)
+
+	sum, err := c.Prefix().Sum(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSection, err)
+	}
+
+	if !bytes.Equal(sum.Hash(), c.Hash()) {
+		return nil, fmt.Errorf("%w: %s", ErrHashMismatch, c)
+	}
+
+	return &Block{c, data}, nil
+}
+
+// uvarintLen returns the length of the varint encoding of x.
+func uvarintLen(x uint64) int {
+	var buf [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(buf[:], x)
+}
+
+// readSection reads a varint length-prefixed section, returning it with the number of bytes read.
+// io.EOF is returned only before its first byte.
+func readSection(r *bufio.Reader) ([]byte, int, error) {
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, 0, io.EOF
+		}
+
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidSection, err)
+	}
+
+	if l == 0 || l > maxSectionSize {
+		return nil, 0, fmt.Errorf("%w: length %d", ErrInvalidSection, l)
+	}
+
+	section := make([]byte, l)
+	if _, err := io.ReadFull(r, section); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidSection, err)
+	}
+
+	return section, uvarintLen(l) + int(l), nil
+}
+
+// readHeader reads a header, returning it with the number of bytes read.
+func readHeader(r *bufio.Reader) (*header, int64, error) {
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	if l == 0 || l > maxHeaderSize {
+		return nil, 0, fmt.Errorf("%w: length %d", ErrInvalidHeader, l)
+	}
+
+	data := make([]byte, l)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	h := new(header)
+	if err := cbor.Unmarshal(data, h); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	return h, int64(uvarintLen(l)) + int64(l), nil
+}
+
+// openPayload reads the fixed CARv2 header after the pragma and returns a reader limited to the CARv1 payload,
+// with the offset of the payload.
+// Ref: https://ipld.io/specs/transport/car/carv2/#header
+func openPayload(r *bufio.Reader) (*bufio.Reader, int64, error) {
+	var h [v2HeaderSize]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	// Offsets are relative to the start of the archive, which includes the pragma.
+	const headerEnd = 11 + v2HeaderSize
+
+	dataOffset := binary.LittleEndian.Uint64(h[16:24])
+	dataSize := binary.LittleEndian.Uint64(h[24:32])
+
+	if dataOffset < headerEnd {
+		return nil, 0, fmt.Errorf("%w: data offset %d", ErrInvalidHeader, dataOffset)
+	}
+
+	if _, err := io.CopyN(io.Discard, r, int64(dataOffset-headerEnd)); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	return bufio.NewReader(io.LimitReader(r, int64(dataSize))), int64(dataOffset), nil
+}
+
+func decodeCIDTag(tag cbor.Tag) (cid.Cid, error) {
+	data, ok := tag.Content.([]byte)
+	if tag.Number != cidTag || !ok || len(data) < 1 || data[0] != 0 {
+		return cid.Undef, fmt.Errorf("%w: invalid root", ErrInvalidHeader)
+	}
+
+	c, err := cid.Cast(data[1:])
+	if err != nil {
+		return cid.Undef, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	return c, nil
+}
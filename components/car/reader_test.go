@@ -0,0 +1,165 @@
+package car
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReaderTestSuite struct {
+	suite.Suite
+
+	blocks []*Block
+}
+
+func rawBlock(data string) *Block {
+	c, err := cid.NewPrefixV1(cid.Raw, 0x12).Sum([]byte(data))
+	if err != nil {
+		panic(err)
+	}
+
+	return &Block{c, []byte(data)}
+}
+
+func (s *ReaderTestSuite) SetupTest() {
+	s.blocks = []*Block{
+		rawBlock("hello"),
+		rawBlock("world"),
+	}
+}
+
+func (s *ReaderTestSuite) writeV1(roots []cid.Cid, blocks []*Block) []byte {
+	buf := new(bytes.Buffer)
+
+	w, err := NewWriter(buf, roots)
+	s.Require().NoError(err)
+
+	for _, b := range blocks {
+		s.Require().NoError(w.Put(b.Cid, b.Data))
+	}
+
+	return buf.Bytes()
+}
+
+// writeV2 wraps a CARv1 payload, with padding between header and payload and a (fake) index after it.
+func (s *ReaderTestSuite) writeV2(payload []byte) []byte {
+	const padding = 7
+
+	pragma := []byte{0x0a, 0xa1, 0x67, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x02}
+
+	offset := uint64(len(pragma) + v2HeaderSize + padding)
+
+	var h [v2HeaderSize]byte
+	binary.LittleEndian.PutUint64(h[16:24], offset)
+	binary.LittleEndian.PutUint64(h[24:32], uint64(len(payload)))
+	binary.LittleEndian.PutUint64(h[32:40], offset+uint64(len(payload)))
+
+	buf := new(bytes.Buffer)
+	buf.Write(pragma)
+	buf.Write(h[:])
+	buf.Write(make([]byte, padding))
+	buf.Write(payload)
+	buf.Write([]byte("index"))
+
+	return buf.Bytes()
+}
+
+func (s *ReaderTestSuite) readAll(data []byte) (*Reader, []*Block, error) {
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var blocks []*Block
+
+	for {
+		b, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return r, blocks, nil
+		}
+
+		if err != nil {
+			return r, blocks, err
+		}
+
+		blocks = append(blocks, b)
+	}
+}
+
+func (s *ReaderTestSuite) TestV1() {
+	roots := []cid.Cid{s.blocks[0].Cid}
+
+	r, blocks, err := s.readAll(s.writeV1(roots, s.blocks))
+
+	s.NoError(err)
+	s.Equal(roots, r.Roots())
+	s.Equal(s.blocks, blocks)
+}
+
+func (s *ReaderTestSuite) TestV2() {
+	roots := []cid.Cid{s.blocks[1].Cid}
+
+	r, blocks, err := s.readAll(s.writeV2(s.writeV1(roots, s.blocks)))
+
+	s.NoError(err)
+	s.Equal(roots, r.Roots())
+	s.Equal(s.blocks, blocks)
+}
+
+func (s *ReaderTestSuite) TestOffset() {
+	v1 := s.writeV1(nil, s.blocks)
+
+	for _, data := range [][]byte{v1, s.writeV2(v1)} {
+		r, err := NewReader(bytes.NewReader(data))
+		s.Require().NoError(err)
+
+		for range s.blocks {
+			b, err := r.Next()
+			s.Require().NoError(err)
+
+			offset := r.Offset()
+			s.Equal(b.Data, data[offset:offset+int64(len(b.Data))])
+		}
+	}
+}
+
+func (s *ReaderTestSuite) TestHashMismatch() {
+	invalid := &Block{s.blocks[0].Cid, []byte("tampered")}
+
+	_, blocks, err := s.readAll(s.writeV1(nil, []*Block{s.blocks[1], invalid}))
+
+	s.ErrorIs(err, ErrHashMismatch)
+	s.Equal(s.blocks[1:], blocks)
+}
+
+func (s *ReaderTestSuite) TestTruncated() {
+	data := s.writeV1(nil, s.blocks)
+
+	_, _, err := s.readAll(data[:len(data)-1])
+
+	s.ErrorIs(err, ErrInvalidSection)
+}
+
+func (s *ReaderTestSuite) TestInvalidHeader() {
+	_, err := NewReader(bytes.NewReader([]byte("not a car")))
+
+	s.ErrorIs(err, ErrInvalidHeader)
+}
+
+func (s *ReaderTestSuite) TestUnsupportedVersion() {
+	// {"version": 3}
+	data := []byte{0x0a, 0xa1, 0x67, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x03}
+
+	_, err := NewReader(bytes.NewReader(data))
+
+	s.ErrorIs(err, ErrUnsupportedVersion)
+}
+
+func TestReaderTestSuite(t *testing.T) {
+	suite.Run(t, new(ReaderTestSuite))
+}
@@ -0,0 +1,69 @@
+package car
+
+import (
+	"encoding/binary"
+	"io"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	"github.com/ipfs/go-cid"
+)
+
+// Writer writes CARv1 archives.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter writes the header for an archive with the given roots and returns a Writer for its blocks.
+func NewWriter(w io.Writer, roots []cid.Cid) (*Writer, error) {
+	h := header{
+		Roots:   make([]cbor.Tag, len(roots)),
+		Version: 1,
+	}
+
+	for i, c := range roots {
+		// CIDs in dag-cbor are prefixed with the multibase identity prefix.
+		h.Roots[i] = cbor.Tag{
+			Number:  cidTag,
+			Content: append([]byte{0}, c.Bytes()...),
+		}
+	}
+
+	data, err := cbor.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeSection(w, data); err != nil {
+		return nil, err
+	}
+
+	return &Writer{w}, nil
+}
+
+// Put writes a block.
+func (w *Writer) Put(c cid.Cid, data []byte) error {
+	return writeSection(w.w, c.Bytes(), data)
+}
+
+// writeSection writes parts, prefixed by their total length as varint.
+func writeSection(w io.Writer, parts ...[]byte) error {
+	var l int
+	for _, p := range parts {
+		l += len(p)
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(l))
+
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+
+	for _, p := range parts {
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Package classifier detects the kind of content of directories, e.g. websites or datasets, from their links.
+package classifier
+
+import (
+	_ "embed" // For default rules.
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+//go:embed default_rules.yml
+var defaultRules []byte
+
+// Classifier classifies directories by rules.
+type Classifier struct {
+	rules []Rule
+}
+
+// New returns a Classifier using the rules file from the configuration or, when not set, the built-in rules.
+func New(cfg *Config) (*Classifier, error) {
+	var (
+		rules []Rule
+		err   error
+	)
+
+	if cfg.Rules == "" {
+		rules, err = ParseRules(defaultRules)
+	} else {
+		rules, err = LoadRules(cfg.Rules)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithRules(rules), nil
+}
+
+// NewWithRules returns a Classifier using the given, validated, rules.
+func NewWithRules(rules []Rule) *Classifier {
+	return &Classifier{rules}
+}
+
+// Classify returns the class of the matching rule with the highest confidence, the first one on ties,
+// or nil when no rule matches.
+func (c *Classifier) Classify(links indexTypes.Links) *indexTypes.DirectoryClass {
+	var match *Rule
+
+	for i := range c.rules {
+		r := &c.rules[i]
+
+		if (match == nil || r.Confidence > match.Confidence) && r.matches(links) {
+			match = r
+		}
+	}
+
+	if match == nil {
+		return nil
+	}
+
+	return &indexTypes.DirectoryClass{
+		Type:       match.Type,
+		Confidence: match.Confidence,
+	}
+}
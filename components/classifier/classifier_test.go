@@ -0,0 +1,134 @@
+package classifier
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+type ClassifierTestSuite struct {
+	suite.Suite
+
+	c *Classifier
+}
+
+func (s *ClassifierTestSuite) SetupTest() {
+	var err error
+
+	s.c, err = New(DefaultConfig())
+	s.Require().NoError(err)
+}
+
+func file(name string) indexTypes.Link {
+	return indexTypes.Link{Hash: "QmFile", Name: name, Size: 1, Type: indexTypes.FileLinkType}
+}
+
+func dir(name string) indexTypes.Link {
+	return indexTypes.Link{Hash: "QmDir", Name: name, Type: indexTypes.DirectoryLinkType}
+}
+
+func numbered(n int, format string) indexTypes.Links {
+	links := make(indexTypes.Links, n)
+	for i := range links {
+		links[i] = file(fmt.Sprintf(format, i))
+	}
+	return links
+}
+
+func (s *ClassifierTestSuite) assertClass(links indexTypes.Links, expected string) {
+	class := s.c.Classify(links)
+
+	if expected == "" {
+		s.Nil(class)
+		return
+	}
+
+	if s.NotNil(class) {
+		s.Equal(expected, class.Type)
+	}
+}
+
+func (s *ClassifierTestSuite) TestDefaultRules() {
+	s.assertClass(indexTypes.Links{file("INDEX.HTML"), file("style.css"), dir("img")}, "website")
+	s.assertClass(indexTypes.Links{file("go.mod"), file("main.go"), dir("cmd")}, "code")
+	s.assertClass(indexTypes.Links{file("package.json"), file("index.html")}, "website")
+	s.assertClass(append(numbered(20, "%d.json"), numbered(20, "%d.png")...), "nft-collection")
+	s.assertClass(numbered(20, "%d.json"), "nft-collection")
+	s.assertClass(indexTypes.Links{file("blocks.car"), file("README.md")}, "dataset")
+	s.assertClass(numbered(10, "IMG_%04d.jpg"), "photo-album")
+	s.assertClass(indexTypes.Links{file("README.md"), dir("stuff")}, "")
+	s.assertClass(nil, "")
+}
+
+func (s *ClassifierTestSuite) TestTypeMustMatch() {
+	// A directory named index.html is no website.
+	s.assertClass(indexTypes.Links{dir("index.html")}, "")
+}
+
+func (s *ClassifierTestSuite) TestHighestConfidence() {
+	rules, err := ParseRules([]byte(`
+rules:
+  - type: low
+    confidence: 0.2
+    any:
+      - names: ["*.txt"]
+  - type: high
+    confidence: 0.8
+    all:
+      - names: ["*.txt"]
+        min_count: 2
+  - type: tie
+    confidence: 0.8
+    all:
+      - names: ["*.txt"]
+        min_count: 2
+`))
+	s.Require().NoError(err)
+
+	c := NewWithRules(rules)
+
+	s.Equal(&indexTypes.DirectoryClass{Type: "low", Confidence: 0.2}, c.Classify(indexTypes.Links{file("a.txt")}))
+	s.Equal(&indexTypes.DirectoryClass{Type: "high", Confidence: 0.8}, c.Classify(indexTypes.Links{file("a.txt"), file("b.txt")}))
+}
+
+func (s *ClassifierTestSuite) TestMinFraction() {
+	rules, err := ParseRules([]byte(`
+rules:
+  - type: mostly-text
+    confidence: 1
+    all:
+      - names: ["*.txt"]
+        min_fraction: 0.5
+`))
+	s.Require().NoError(err)
+
+	c := NewWithRules(rules)
+
+	s.NotNil(c.Classify(indexTypes.Links{file("a.txt"), file("b.bin")}))
+	s.Nil(c.Classify(indexTypes.Links{file("a.txt"), file("b.bin"), file("c.bin")}))
+}
+
+func (s *ClassifierTestSuite) TestInvalidRules() {
+	for _, rules := range []string{
+		"rules: [{type: x, confidence: 0.5}]",
+		"rules: [{type: x, confidence: 0, any: [{names: ['*']}]}]",
+		"rules: [{confidence: 0.5, any: [{names: ['*']}]}]",
+		"rules: [{type: x, confidence: 0.5, any: [{names: ['[']}]}]",
+		"rules: [{type: x, confidence: 0.5, all: [{min_fraction: 2}]}]",
+	} {
+		_, err := ParseRules([]byte(rules))
+		s.ErrorIs(err, ErrInvalidRule, rules)
+	}
+}
+
+func (s *ClassifierTestSuite) TestLoadRulesMissing() {
+	_, err := New(&Config{Rules: "/nonexistent/rules.yml"})
+	s.Error(err)
+}
+
+func TestClassifierTestSuite(t *testing.T) {
+	suite.Run(t, new(ClassifierTestSuite))
+}
@@ -0,0 +1,11 @@
+package classifier
+
+// Config contains configuration for the directory classifier.
+type Config struct {
+	Rules string // Path of a YAML rules file. When empty, the built-in rules are used.
+}
+
+// DefaultConfig returns the default configuration for the directory classifier.
+func DefaultConfig() *Config {
+	return &Config{}
+}
@@ -0,0 +1,173 @@
+package classifier
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// ErrInvalidRule is returned for rules which can never match or cannot be evaluated.
+var ErrInvalidRule = errors.New("invalid rule")
+
+// Condition holds when enough links match it.
+type Condition struct {
+	// Names are glob patterns (as in path.Match) on lowercase link names; a link matches when any matches.
+	// When empty, all names match.
+	Names []string `yaml:"names,omitempty"`
+
+	// Type of matching links; any type when empty.
+	Type indexTypes.LinkType `yaml:"type,omitempty"`
+
+	MinCount    uint    `yaml:"min_count,omitempty"`    // Minimum number of matching links, at least 1.
+	MinFraction float64 `yaml:"min_fraction,omitempty"` // Minimum fraction of links matching.
+}
+
+// Rule assigns Type with Confidence to directories for which all conditions in All and,
+// when set, any condition in Any hold.
+type Rule struct {
+	Type       string      `yaml:"type"`
+	Confidence float64     `yaml:"confidence"`
+	All        []Condition `yaml:"all,omitempty"`
+	Any        []Condition `yaml:"any,omitempty"`
+}
+
+// Rules is the format of a rules file.
+type Rules struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+func (c *Condition) check() error {
+	for _, n := range c.Names {
+		if _, err := path.Match(n, ""); err != nil {
+			return fmt.Errorf("pattern %q: %w", n, err)
+		}
+	}
+
+	if c.MinFraction < 0 || c.MinFraction > 1 {
+		return fmt.Errorf("min_fraction %v not between 0 and 1", c.MinFraction)
+	}
+
+	return nil
+}
+
+func (r *Rule) check() error {
+	if r.Type == "" {
+		return fmt.Errorf("%w: empty type", ErrInvalidRule)
+	}
+
+	if r.Confidence <= 0 || r.Confidence > 1 {
+		return fmt.Errorf("%w %s: confidence %v not in (0, 1]", ErrInvalidRule, r.Type, r.Confidence)
+	}
+
+	if len(r.All) == 0 && len(r.Any) == 0 {
+		return fmt.Errorf("%w %s: no conditions", ErrInvalidRule, r.Type)
+	}
+
+	for _, cs := range [][]Condition{r.All, r.Any} {
+		for i := range cs {
+			if err := cs[i].check(); err != nil {
+				return fmt.Errorf("%w %s: %s", ErrInvalidRule, r.Type, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ParseRules parses and validates rules in YAML.
+func ParseRules(data []byte) ([]Rule, error) {
+	var rules Rules
+
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+
+	for i := range rules.Rules {
+		if err := rules.Rules[i].check(); err != nil {
+			return nil, err
+		}
+	}
+
+	return rules.Rules, nil
+}
+
+// LoadRules reads rules from a YAML file.
+func LoadRules(filename string) ([]Rule, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("rules file %s: %w", filename, err)
+	}
+
+	return rules, nil
+}
+
+func (c *Condition) matches(l *indexTypes.Link, name string) bool {
+	if c.Type != "" && c.Type != l.Type {
+		return false
+	}
+
+	if len(c.Names) == 0 {
+		return true
+	}
+
+	for _, n := range c.Names {
+		// Patterns have been checked, so errors can't occur.
+		if ok, _ := path.Match(n, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Condition) holds(links indexTypes.Links) bool {
+	if len(links) == 0 {
+		return false
+	}
+
+	var count uint
+
+	for i := range links {
+		if c.matches(&links[i], strings.ToLower(links[i].Name)) {
+			count++
+		}
+	}
+
+	minCount := c.MinCount
+	if minCount == 0 {
+		minCount = 1
+	}
+
+	return count >= minCount && float64(count)/float64(len(links)) >= c.MinFraction
+}
+
+func (r *Rule) matches(links indexTypes.Links) bool {
+	for i := range r.All {
+		if !r.All[i].holds(links) {
+			return false
+		}
+	}
+
+	if len(r.Any) == 0 {
+		return true
+	}
+
+	for i := range r.Any {
+		if r.Any[i].holds(links) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,174 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// checkpointSaveTimeout 为出错后保存检查点的超时时间。
+const checkpointSaveTimeout = 5 * time.Second
+
+// errCheckpointMismatch 表示目录列表的顺序与检查点不一致，已跳过的条目可能未入队。
+var errCheckpointMismatch = errors.New("directory listing does not match checkpoint")
+
+// dirCheckpointer 记录目录列表进度，使中断的列表在下次尝试时跳过已入队的条目。
+//
+// 协议的 Ls 无法从偏移量开始，因此列表仍从头开始并重新获取所有条目，检查点仅避免重复入队检查点之前的条目。
+// 列表顺序通过第一个和最后一个已处理条目的名称验证；第一个名称不一致时忽略检查点，
+// 最后一个名称不一致时返回 errCheckpointMismatch，调用方在同一次尝试中调用 reset 后从头重新列出。
+type dirCheckpointer struct {
+	index    index.Index
+	dir      string
+	interval uint64
+
+	resume  indexTypes.DirectoryCheckpoint // 上次保存的进度
+	current indexTypes.DirectoryCheckpoint
+	stored  bool // 索引中存在检查点
+}
+
+// newDirCheckpointer 加载目录 dir 的检查点；未配置 Checkpoints 索引时返回 nil。
+func (c *Crawler) newDirCheckpointer(ctx context.Context, dir string) (*dirCheckpointer, error) {
+	if c.indexes.Checkpoints == nil {
+		return nil, nil
+	}
+
+	cp := &dirCheckpointer{
+		index:    c.indexes.Checkpoints,
+		dir:      dir,
+		interval: uint64(c.config.DirCheckpointInterval),
+	}
+
+	found, err := cp.index.Get(ctx, dir, &cp.resume)
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		log.Printf("Listing %s without queueing the first %d entries, queued by an earlier attempt.", dir, cp.resume.Offset)
+		cp.stored = true
+	} else {
+		cp.resume = indexTypes.DirectoryCheckpoint{}
+	}
+
+	return cp, nil
+}
+
+// skip 返回第 n 个名为 name 的条目是否已在之前的尝试中入队。
+func (cp *dirCheckpointer) skip(n uint64, name string) (bool, error) {
+	if n >= cp.resume.Offset {
+		return false, nil
+	}
+
+	if n == 0 && name != cp.resume.FirstName {
+		log.Printf("Listing of %s does not match checkpoint, ignoring it.", cp.dir)
+		cp.resume = indexTypes.DirectoryCheckpoint{}
+
+		return false, nil
+	}
+
+	if n == cp.resume.Offset-1 && name != cp.resume.LastName {
+		return false, errCheckpointMismatch
+	}
+
+	return true, nil
+}
+
+// reset 忽略已加载的检查点和当前进度，以便从头重新列出；已保存的检查点在 finish 时覆盖或删除。
+func (cp *dirCheckpointer) reset() {
+	cp.resume = indexTypes.DirectoryCheckpoint{}
+	cp.current = indexTypes.DirectoryCheckpoint{}
+}
+
+// done 记录第 n 个名为 name 的条目已处理，每 interval 个条目保存一次。
+func (cp *dirCheckpointer) done(ctx context.Context, n uint64, name string) error {
+	if n == 0 {
+		cp.current.FirstName = name
+	}
+
+	cp.current.Offset = n + 1
+	cp.current.LastName = name
+
+	if cp.interval > 0 && cp.current.Offset%cp.interval == 0 {
+		return cp.save(ctx)
+	}
+
+	return nil
+}
+
+// save 保存当前进度，除非其落后于已保存的检查点。
+func (cp *dirCheckpointer) save(ctx context.Context) error {
+	if cp.current.Offset <= cp.resume.Offset {
+		return nil
+	}
+
+	if err := cp.index.Index(ctx, cp.dir, &cp.current); err != nil {
+		return err
+	}
+
+	cp.resume = cp.current
+	cp.stored = true
+
+	return nil
+}
+
+// errListingInterrupted 表示目录列表超时中断，但已保存检查点；该错误为临时错误，重试时从检查点继续。
+type errListingInterrupted struct {
+	err error
+}
+
+func (e errListingInterrupted) Error() string {
+	return "directory listing interrupted, resumable from checkpoint: " + e.err.Error()
+}
+
+func (e errListingInterrupted) Unwrap() error {
+	return e.err
+}
+
+// Is 使该错误同时匹配 index.ErrTemporary，从而重新投递。
+func (e errListingInterrupted) Is(target error) bool {
+	return target == index.ErrTemporary
+}
+
+// finish 在列表结束后处理检查点：完成时删除，出错时保存进度。
+// 返回是否存在可供下次尝试继续的检查点。
+func (cp *dirCheckpointer) finish(ctx context.Context, listErr error) bool {
+	var err error
+
+	switch {
+	case listErr == nil, errors.Is(listErr, ErrDirectoryTooLarge):
+		err = cp.clear(ctx)
+	default:
+		// 上下文可能已取消
+		ctx, cancel := context.WithTimeout(context.Background(), checkpointSaveTimeout)
+		defer cancel()
+
+		if cp.resume.Offset == 0 && cp.current.Offset == 0 {
+			// reset 后没有进度，已保存的检查点已过时
+			err = cp.clear(ctx)
+		} else {
+			err = cp.save(ctx)
+		}
+	}
+
+	if err != nil {
+		log.Printf("Error writing checkpoint for %s: %v", cp.dir, err)
+	}
+
+	return cp.stored && err == nil
+}
+
+// clear 删除检查点。
+func (cp *dirCheckpointer) clear(ctx context.Context) error {
+	if !cp.stored {
+		return nil
+	}
+
+	cp.stored = false
+
+	return cp.index.Delete(ctx, cp.dir)
+}
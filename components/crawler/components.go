@@ -0,0 +1,14 @@
+package crawler
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/classifier"
+	"github.com/ipfs-search/ipfs-search/components/names"
+	"github.com/ipfs-search/ipfs-search/components/site"
+)
+
+// Components are optional collaborators of the crawler; without them, their features are disabled.
+type Components struct {
+	Classifier *classifier.Classifier // Optional directory classifier; without it, directories are not classified.
+	Sites      *site.Indexer          // Optional indexer of pages of directories classified as websites.
+	Names      *names.Refresher       // Optional refresher scheduling IPNS names and DNSLink domains to be resolved again.
+}
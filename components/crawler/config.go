@@ -6,20 +6,34 @@ import (
 
 // Config contains configuration for a Crawler.
 type Config struct {
-	DirEntryBufferSize uint          // Size of buffer for processing directory entry channels.
-	MinUpdateAge       time.Duration // The minimum age for items to be updated.
-	StatTimeout        time.Duration // Timeout for Stat() calls.
-	DirEntryTimeout    time.Duration // Timeout *between* directory entries.
-	MaxDirSize         uint          // Maximum number of directory entries
+	DirEntryBufferSize    uint          // Size of buffer for processing directory entry channels.
+	MinUpdateAge          time.Duration // The minimum age for items to be updated.
+	StatTimeout           time.Duration // Timeout for Stat() calls.
+	DirEntryTimeout       time.Duration // Timeout *between* directory entries.
+	MaxDirSize            uint          // Maximum number of directory entries; larger directories are paged, when a pages index is available.
+	DirPageSize           uint          // Number of directory entries per page for large directories.
+	DirCheckpointInterval uint          // Save listing progress every this many directory entries, 0 to only save on errors.
+	MaxReferences         uint          // Maximum number of stored references, 0 for no limit; further references are counted, all are stored in the references index.
+	MaxReferenceNames     uint          // Maximum number of distinct names sampled for documents with more than MaxReferences references.
+	NameRefreshInterval   time.Duration // Resolve IPNS names again after this time.
+	MaxNameHistory        uint          // Maximum number of previous targets stored for IPNS names.
+	MaxDAGLinks           uint          // Maximum number of links indexed and queued per IPLD document, 0 for no limit.
 }
 
 // DefaultConfig generates a default configuration for a Crawler.
 func DefaultConfig() *Config {
 	return &Config{
-		DirEntryBufferSize: 8192,
-		MinUpdateAge:       time.Hour,
-		StatTimeout:        60 * time.Second,
-		DirEntryTimeout:    60 * time.Second,
-		MaxDirSize:         32768,
+		DirEntryBufferSize:    8192,
+		MinUpdateAge:          time.Hour,
+		StatTimeout:           60 * time.Second,
+		DirEntryTimeout:       60 * time.Second,
+		MaxDirSize:            32768,
+		DirPageSize:           4096,
+		DirCheckpointInterval: 1024,
+		MaxReferences:         1024,
+		MaxReferenceNames:     32,
+		NameRefreshInterval:   time.Hour,
+		MaxNameHistory:        32,
+		MaxDAGLinks:           4096,
 	}
 }
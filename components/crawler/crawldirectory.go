@@ -5,10 +5,12 @@ import (
 	"errors"
 	"log"
 	"math/rand"
+	"time"
 
 	"golang.org/x/sync/errgroup" // 用于并发控制
 
 	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/site"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
@@ -25,6 +27,36 @@ func (c *Crawler) crawlDir(ctx context.Context, r *t.AnnotatedResource, properti
 	ctx, span := c.Tracer.Start(ctx, "crawler.crawlDir") // 开始跟踪
 	defer span.End()
 
+	// 检查点，可能为 nil
+	cp, err := c.newDirCheckpointer(ctx, r.ID)
+	if err != nil {
+		return err
+	}
+
+	err = c.listDir(ctx, r, properties, cp)
+
+	if errors.Is(err, errCheckpointMismatch) {
+		// 已跳过的条目可能未入队：忽略检查点，在本次尝试中从头重新列出。
+		log.Printf("Listing of %s does not match checkpoint, listing again from the start.", r.ID)
+
+		cp.reset()
+		*properties = indexTypes.Directory{Document: properties.Document}
+
+		err = c.listDir(ctx, r, properties, cp)
+	}
+
+	if cp != nil && cp.finish(ctx, err) {
+		// 条目超时（而非整体取消）时，重试可从检查点继续。
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			err = errListingInterrupted{err}
+		}
+	}
+
+	return err
+}
+
+// listDir 列出目录并处理其条目。
+func (c *Crawler) listDir(ctx context.Context, r *t.AnnotatedResource, properties *indexTypes.Directory, cp *dirCheckpointer) error {
 	entries := make(chan *t.AnnotatedResource, c.config.DirEntryBufferSize) // 带缓冲的条目通道
 
 	wg, ctx := errgroup.WithContext(ctx) // 创建错误组
@@ -45,7 +77,7 @@ func (c *Crawler) crawlDir(ctx context.Context, r *t.AnnotatedResource, properti
 				panicVar = r
 			}
 		}()
-		return c.processDirEntries(ctx, entries, properties)
+		return c.processDirEntries(ctx, r, entries, properties, cp)
 	})
 
 	// 启动目录列表协程
@@ -78,26 +110,34 @@ func resourceToLinkType(r *t.AnnotatedResource) indexTypes.LinkType {
 	}
 }
 
-// 添加目录链接
-func addLink(e *t.AnnotatedResource, properties *indexTypes.Directory) {
-	properties.Links = append(properties.Links, indexTypes.Link{
+// 创建目录链接
+func newLink(e *t.AnnotatedResource) indexTypes.Link {
+	return indexTypes.Link{
 		Hash: e.ID,
 		Name: e.Reference.Name,
 		Size: e.Size,
 		Type: resourceToLinkType(e),
-	})
+	}
 }
 
 // 处理目录条目（核心逻辑）
-func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.AnnotatedResource, properties *indexTypes.Directory) error {
+// 超过 MaxDirSize 的目录在配置了 Pages 索引时分页写入，目录文档仅保留统计信息。
+// 配置了检查点 cp 时，跳过之前尝试中已入队的条目。
+func (c *Crawler) processDirEntries(ctx context.Context, r *t.AnnotatedResource, entries <-chan *t.AnnotatedResource, properties *indexTypes.Directory, cp *dirCheckpointer) error {
 	ctx, span := c.Tracer.Start(ctx, "crawler.processDirEntries")
 	defer span.End()
 
 	var (
 		dirCnt  uint = 0     // 条目计数器
 		isLarge bool = false // 大目录标记
+		edges   []indexTypes.ReferenceEdge
+		pager   = c.newDirPager(r.ID) // 大目录分页器，可能为 nil
+		stats   = newDirStats()       // 全部条目的统计信息，包括大目录
+		sample  indexTypes.Links      // 大目录用于分类的前 MaxDirSize 个链接
 	)
 
+	now := time.Now().Truncate(time.Second)
+
 	// Question: do we need a maximum entry cutoff point? E.g. 10^6 entries or something?
 	processNextDirEntry := func() error { // 闭包函数处理单个条目
 		// Create (and cancel!) a new timeout context for every entry.
@@ -121,15 +161,67 @@ func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.Annot
 			// 大目录处理逻辑
 			if dirCnt == c.config.MaxDirSize {
 				span.AddEvent("large-directory")
-				log.Printf("Directory %v is large, crawling entries but not directory itself.", entry.Parent)
 				isLarge = true // 标记但继续处理
+
+				if pager != nil {
+					log.Printf("Directory %v is large, indexing links in pages.", entry.Parent)
+
+					// 已收集的链接移入分页
+					sample = properties.Links
+					for _, l := range properties.Links {
+						if err := pager.add(ctx, l); err != nil {
+							return err
+						}
+					}
+					properties.Links = nil
+				} else {
+					log.Printf("Directory %v is large, crawling entries but not directory itself.", entry.Parent)
+				}
+			}
+
+			link := newLink(entry)
+
+			switch {
+			case !isLarge:
+				properties.Links = append(properties.Links, link) // 正常目录添加链接
+			case pager != nil:
+				if err := pager.add(ctx, link); err != nil {
+					return err
+				}
+			}
+
+			properties.LinkCount++
+			stats.add(link)
+
+			// 大目录的边同样写入边存储
+			if edge, ok := referenceEdge(entry, now); ok {
+				edges = append(edges, edge)
+			}
+
+			if len(edges) >= edgeBatchSize {
+				if err := c.addEdges(ctx, edges...); err != nil {
+					return err
+				}
+				edges = edges[:0]
+			}
+
+			if cp == nil {
+				return c.queueDirEntry(ctx, entry) // 条目入队
+			}
+
+			// 跳过之前尝试中已入队的条目
+			skip, err := cp.skip(uint64(dirCnt), entry.Reference.Name)
+			if err != nil {
+				return err
 			}
 
-			if !isLarge {
-				addLink(entry, properties) // 正常目录添加链接
+			if !skip {
+				if err := c.queueDirEntry(ctx, entry); err != nil {
+					return err
+				}
 			}
 
-			return c.queueDirEntry(ctx, entry) // 条目入队
+			return cp.done(ctx, uint64(dirCnt), entry.Reference.Name)
 		}
 	}
 
@@ -142,11 +234,35 @@ func (c *Crawler) processDirEntries(ctx context.Context, entries <-chan *t.Annot
 
 	// 错误后处理
 	if errors.Is(err, errEndOfLs) { // 正常结束
-		// Normal exit of loop, reset error condition
-		err = nil
+		// Normal exit of loop, reset error condition after writing remaining edges
+		err = c.addEdges(ctx, edges...)
+		properties.Stats = stats.result()
+
+		if err == nil {
+			properties.Stats.Depth, err = c.dirDepth(ctx, r.ID)
+		}
+
+		if c.classifier != nil {
+			links := properties.Links
+			if isLarge {
+				links = sample
+			}
 
-		if isLarge {
-			err = ErrDirectoryTooLarge // 大目录特殊错误
+			properties.Class = c.classifier.Classify(links)
+
+			// 网站的页面在后台索引
+			if c.sites != nil && properties.Class != nil && properties.Class.Type == site.ClassType {
+				c.sites.Add(r.ID, links)
+			}
+		}
+
+		if err == nil && isLarge {
+			if pager != nil {
+				err = pager.flush(ctx)
+				properties.Pages = pager.pages
+			} else {
+				err = ErrDirectoryTooLarge // 大目录特殊错误
+			}
 		}
 	} else {
 		// 异常错误
@@ -9,8 +9,12 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/ipfs-search/ipfs-search/components/classifier"
 	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/names"
 	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/components/site"
 
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
@@ -18,11 +22,14 @@ import (
 
 // Crawler 允许爬取资源
 type Crawler struct {
-	config     *Config               // 配置信息
-	indexes    *Indexes              // 索引管理
-	queues     *Queues               // 队列管理
-	protocol   protocol.Protocol     // 协议处理
-	extractors []extractor.Extractor // 提取器列表
+	config     *Config                // 配置信息
+	indexes    *Indexes               // 索引管理
+	queues     *Queues                // 队列管理
+	protocol   protocol.Protocol      // 协议处理
+	extractors []extractor.Extractor  // 提取器列表
+	classifier *classifier.Classifier // 目录分类器，可能为 nil
+	sites      *site.Indexer          // 静态网站索引器，可能为 nil
+	names      *names.Refresher       // IPNS 名称刷新器，可能为 nil
 
 	*instr.Instrumentation // 插桩工具
 }
@@ -30,7 +37,7 @@ type Crawler struct {
 // isSupportedType 检查资源类型是否支持
 func isSupportedType(rType t.ResourceType) bool {
 	switch rType {
-	case t.UndefinedType, t.FileType, t.DirectoryType:
+	case t.UndefinedType, t.FileType, t.DirectoryType, t.IPLDType:
 		return true
 	default:
 		return false
@@ -57,6 +64,13 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 		// Calling crawler with unsupported types is undefined behaviour.
 		panic("invalid type for crawler")
 	}
+	// IPNS 名称和 DNSLink 域名需要解析，而不是索引
+	if r.Protocol == t.IPNSProtocol || r.Protocol == t.DNSLinkProtocol {
+		if err = c.crawlName(ctx, r); err != nil {
+			span.RecordError(err)
+		}
+		return err
+	}
 	// 检查并更新可能存在的资源
 	exists, err := c.updateMaybeExisting(ctx, r)
 	if err != nil {
@@ -77,6 +91,9 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 			span.AddEvent("Indexing invalid resource")
 
 			err = c.indexInvalid(ctx, r, err)
+			if errors.Is(err, index.ErrDocumentExists) {
+				err = c.updateConcurrent(ctx, r)
+			}
 		}
 
 		// Errors from ensureType imply that no type could be found, hence we can't index.
@@ -88,20 +105,30 @@ func (c *Crawler) Crawl(ctx context.Context, r *t.AnnotatedResource) error {
 	// 索引新资源
 	log.Printf("Indexing new item %v", r)
 	err = c.index(ctx, r)
+	if errors.Is(err, index.ErrDocumentExists) {
+		err = c.updateConcurrent(ctx, r)
+	}
 	if err != nil {
 		span.RecordError(err)
 	}
 	return err
 }
 
-// New 创建一个新的 Crawler 实例
-func New(config *Config, indexes *Indexes, queues *Queues, protocol protocol.Protocol, extractors []extractor.Extractor, i *instr.Instrumentation) *Crawler {
+// New 创建一个新的 Crawler 实例；components 为可选组件，可能为 nil
+func New(config *Config, indexes *Indexes, queues *Queues, protocol protocol.Protocol, extractors []extractor.Extractor, components *Components, i *instr.Instrumentation) *Crawler {
+	if components == nil {
+		components = &Components{}
+	}
+
 	return &Crawler{
 		config,
 		indexes,
 		queues,
 		protocol,
 		extractors,
+		components.Classifier,
+		components.Sites,
+		components.Names,
 		i,
 	}
 }
@@ -4,17 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"mime"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/ipfs-search/ipfs-search/components/classifier"
 	"github.com/ipfs-search/ipfs-search/components/extractor"
+	"github.com/ipfs-search/ipfs-search/components/graph"
 	"github.com/ipfs-search/ipfs-search/components/index"
 	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/names"
 	"github.com/ipfs-search/ipfs-search/components/protocol"
 	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/components/site"
 
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
@@ -45,6 +50,12 @@ type CrawlerTestSuite struct {
 	hashQ *queue.Mock
 }
 
+// SetupSuite loads the system MIME table, which is loaded on first use; loading it while crawling directories
+// with a short DirEntryTimeout times out their entries.
+func (s *CrawlerTestSuite) SetupSuite() {
+	mime.TypeByExtension(".html")
+}
+
 func (s *CrawlerTestSuite) SetupTest() {
 	s.ctx = context.Background()
 
@@ -76,7 +87,7 @@ func (s *CrawlerTestSuite) SetupTest() {
 
 	s.cfg = DefaultConfig()
 
-	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, extractors, s.instr)
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, extractors, nil, s.instr)
 }
 
 func (s *CrawlerTestSuite) assertExpectations() {
@@ -95,22 +106,22 @@ func (s *CrawlerTestSuite) assertExpectations() {
 
 func (s *CrawlerTestSuite) assertNotExists(rID string) {
 	s.fileIdx.
-		On("Get", mock.Anything, rID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, rID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, rID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, rID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Once()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, rID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, rID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Once()
 
 	s.partialIdx.
-		On("Get", mock.Anything, rID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, rID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Once()
 }
@@ -342,22 +353,22 @@ func (s *CrawlerTestSuite) TestCrawlReferencedPartialType() {
 		Once()
 
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.partialIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(true, nil).
 		Once()
 
@@ -422,7 +433,7 @@ func (s *CrawlerTestSuite) TestCrawlFileType() {
 func (s *CrawlerTestSuite) TestCrawlMultiExtractor() {
 	extractors := []extractor.Extractor{s.extractor1, s.extractor2}
 
-	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, extractors, s.instr)
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, extractors, nil, s.instr)
 
 	// Prepare resource
 	r := &t.AnnotatedResource{
@@ -586,6 +597,76 @@ func (s *CrawlerTestSuite) TestCrawlReferencedFile() {
 	s.assertExpectations()
 }
 
+// TestCrawlIndexedConcurrently tests whether the reference is added when another worker indexed the file first.
+func (s *CrawlerTestSuite) TestCrawlIndexedConcurrently() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Source: t.DirectorySource,
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+		},
+	}
+
+	existing := indexTypes.Reference{
+		ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+		Name:       "ExistingReference.pdf",
+	}
+
+	s.assertNotExists(r.Resource.ID)
+
+	s.extractor1.
+		On("Extract", mock.Anything, r, mock.Anything).
+		Return(nil).
+		Once()
+
+	s.fileIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.Anything).
+		Return(index.ErrDocumentExists).
+		Once()
+
+	// Indexed by another worker in the meantime.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.References = indexTypes.References{existing}
+		}).
+		Return(true, nil).
+		Once()
+
+	for _, idx := range []*index.Mock{s.dirIdx, s.invalidIdx, s.partialIdx} {
+		idx.
+			On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+			Return(false, nil).
+			Maybe()
+	}
+
+	s.fileIdx.
+		On("Update", mock.Anything, r.Resource.ID, &indexTypes.Update{
+			References: indexTypes.References{
+				existing,
+				{ParentHash: r.Reference.Parent.ID, Name: r.Reference.Name},
+			},
+		}).
+		Return(nil).
+		Once()
+
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+}
+
 func (s *CrawlerTestSuite) TestCrawlReferencedDirectory() {
 	// Prepare resource
 	r := &t.AnnotatedResource{
@@ -835,7 +916,7 @@ func (s *CrawlerTestSuite) TestCrawlLargeDirectory() {
 	// Override MaxDirSize
 	s.cfg.MaxDirSize = 3
 
-	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, s.instr)
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
 
 	// Prepare resource
 	r := &t.AnnotatedResource{
@@ -912,7 +993,7 @@ func (s *CrawlerTestSuite) TestCrawlDirEntryTimeout() {
 	// Override dir entry timeout
 	s.cfg.DirEntryTimeout = 5 * time.Millisecond
 
-	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, s.instr)
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
 
 	entryDelay := 2 * s.cfg.DirEntryTimeout
 
@@ -1001,7 +1082,7 @@ func (s *CrawlerTestSuite) TestCrawlUpdateLastSeen() {
 
 	// File is found, last seen 1 hour
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			lastSeen := time.Now().Add(-2 * time.Hour)
@@ -1011,17 +1092,17 @@ func (s *CrawlerTestSuite) TestCrawlUpdateLastSeen() {
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.partialIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
@@ -1052,22 +1133,22 @@ func (s *CrawlerTestSuite) TestCrawlNotUpdateInvalid() {
 
 	// File is found, last seen 1 hour
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.partialIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(true, nil).
 		Maybe()
 
@@ -1098,7 +1179,7 @@ func (s *CrawlerTestSuite) TestCrawlAddReference() {
 
 	// File is found, very recently, but a new reference is found.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			lastSeen := time.Now()
@@ -1114,17 +1195,17 @@ func (s *CrawlerTestSuite) TestCrawlAddReference() {
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.partialIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
@@ -1152,6 +1233,77 @@ func (s *CrawlerTestSuite) TestCrawlAddReference() {
 	s.assertExpectations()
 }
 
+func (s *CrawlerTestSuite) TestCrawlAppendReference() {
+	// Use an index supporting atomic appends for files.
+	fileIdx := &index.AppenderMock{}
+	s.indexes.Files = fileIdx
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Source: t.DirectorySource,
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	// File is found, with another reference.
+	fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+					Name:       "ExistingReference.pdf",
+				},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	s.partialIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	// Only the new reference is appended, no full update.
+	fileIdx.
+		On("AppendReference", mock.Anything, r.Resource.ID, indexTypes.Reference{
+			ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			Name:       "NewReference.pdf",
+		}).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	fileIdx.AssertExpectations(s.T())
+	fileIdx.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func (s *CrawlerTestSuite) TestCrawlUpdateGetError() {
 	// Prepare resource
 	r := &t.AnnotatedResource{
@@ -1172,22 +1324,22 @@ func (s *CrawlerTestSuite) TestCrawlUpdateGetError() {
 	testErr := errors.New("test")
 
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, testErr).
 		Maybe()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.partialIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
@@ -1218,7 +1370,7 @@ func (s *CrawlerTestSuite) TestCrawlUpdateUpdateError() {
 
 	// File is found, very recently, but a new reference is found.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			lastSeen := time.Now()
@@ -1236,17 +1388,17 @@ func (s *CrawlerTestSuite) TestCrawlUpdateUpdateError() {
 	testErr := errors.New("test")
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.partialIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
@@ -1293,7 +1445,7 @@ func (s *CrawlerTestSuite) TestCrawlSameReference() {
 
 	// File is found, very recently, but a new reference is found.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			lastSeen := time.Now()
@@ -1309,17 +1461,17 @@ func (s *CrawlerTestSuite) TestCrawlSameReference() {
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.partialIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
@@ -1350,7 +1502,7 @@ func (s *CrawlerTestSuite) TestCrawlSameReferenceDifferentName() {
 
 	// File is found, very recently, but a new reference is found.
 	s.fileIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Run(func(args mock.Arguments) {
 			u := args.Get(2).(*indexTypes.Update)
 			lastSeen := time.Now()
@@ -1366,17 +1518,17 @@ func (s *CrawlerTestSuite) TestCrawlSameReferenceDifferentName() {
 		Once()
 
 	s.dirIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.partialIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
 	s.invalidIdx.
-		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen"}).
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
 		Return(false, nil).
 		Maybe()
 
@@ -1408,3 +1560,1509 @@ func (s *CrawlerTestSuite) TestCrawlSameReferenceDifferentName() {
 func TestCrawlerTestSuite(t *testing.T) {
 	suite.Run(t, new(CrawlerTestSuite))
 }
+
+func (s *CrawlerTestSuite) TestCrawlReferenceOverflow() {
+	// Store a single reference; further references go to the references index.
+	refIdx := &index.Mock{}
+	s.indexes.References = refIdx
+	s.cfg.MaxReferences = 1
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Source: t.DirectorySource,
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	// File is found, with the maximum number of references and a counter.
+	count := uint64(5)
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+					Name:       "ExistingReference.pdf",
+				},
+			}
+			u.ReferenceCount = &count
+			u.ReferenceNames = indexTypes.ReferenceNames{"ExistingReference.pdf"}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	s.partialIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	edgeID := referenceEdgeID(r.Reference.Parent.ID, r.Reference.Name, r.Resource.ID)
+
+	refIdx.
+		On("Get", mock.Anything, edgeID, mock.Anything, []string(nil)).
+		Return(false, nil).
+		Once()
+
+	refIdx.
+		On("Index", mock.Anything, edgeID, mock.MatchedBy(func(e *indexTypes.ReferenceEdge) bool {
+			return e.ParentHash == r.Reference.Parent.ID &&
+				e.ChildHash == r.Resource.ID &&
+				e.Name == r.Reference.Name &&
+				!e.FirstSeen.IsZero()
+		})).
+		Return(nil).
+		Once()
+
+	// Only the counter and sampled names are updated; stored references are untouched.
+	s.fileIdx.
+		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
+			return u.References == nil &&
+				u.ReferenceCount != nil && *u.ReferenceCount == 6 &&
+				u.ReferenceNames.Contains("ExistingReference.pdf") &&
+				u.ReferenceNames.Contains("NewReference.pdf")
+		})).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	refIdx.AssertExpectations(s.T())
+}
+
+// TestCrawlReferenceOverflowFirst tests whether stored references are written to the references index when a
+// document first exceeds the maximum number of references.
+func (s *CrawlerTestSuite) TestCrawlReferenceOverflowFirst() {
+	refIdx := &index.Mock{}
+	s.indexes.References = refIdx
+	s.cfg.MaxReferences = 1
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Source: t.DirectorySource,
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	existing := indexTypes.Reference{
+		ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+		Name:       "ExistingReference.pdf",
+	}
+
+	// File is found with the maximum number of references, without a counter.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.References = indexTypes.References{existing}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	s.partialIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	edgeID := referenceEdgeID(r.Reference.Parent.ID, r.Reference.Name, r.Resource.ID)
+
+	refIdx.
+		On("Get", mock.Anything, edgeID, mock.Anything, []string(nil)).
+		Return(false, nil).
+		Once()
+
+	// Both the stored and the new reference are written as edges; the stored one was already written by an
+	// earlier, partially failed attempt.
+	existingID := referenceEdgeID(existing.ParentHash, existing.Name, r.Resource.ID)
+	refIdx.
+		On("Index", mock.Anything, existingID, mock.MatchedBy(func(e *indexTypes.ReferenceEdge) bool {
+			return e.ParentHash == existing.ParentHash &&
+				e.ChildHash == r.Resource.ID &&
+				e.Name == existing.Name
+		})).
+		Return(fmt.Errorf("%w: %s", index.ErrDocumentExists, existingID)).
+		Once()
+
+	refIdx.
+		On("Index", mock.Anything, edgeID, mock.MatchedBy(func(e *indexTypes.ReferenceEdge) bool {
+			return e.ParentHash == r.Reference.Parent.ID && e.Name == r.Reference.Name
+		})).
+		Return(nil).
+		Once()
+
+	s.fileIdx.
+		On("Update", mock.Anything, r.Resource.ID, mock.MatchedBy(func(u *indexTypes.Update) bool {
+			return u.References == nil && u.ReferenceCount != nil && *u.ReferenceCount == 2
+		})).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	refIdx.AssertExpectations(s.T())
+}
+
+func (s *CrawlerTestSuite) TestCrawlReferenceOverflowExisting() {
+	refIdx := &index.Mock{}
+	s.indexes.References = refIdx
+	s.cfg.MaxReferences = 1
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Source: t.DirectorySource,
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "NewReference.pdf",
+		},
+	}
+
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "Qmc8mmzycvXnzgwBHokZQd97iWAmtdFMqX4FZUAQ5AQdQi",
+					Name:       "ExistingReference.pdf",
+				},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	s.partialIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	// Edge has been recorded before; not counted again.
+	refIdx.
+		On("Get", mock.Anything, referenceEdgeID(r.Reference.Parent.ID, r.Reference.Name, r.Resource.ID), mock.Anything, []string(nil)).
+		Return(true, nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	refIdx.AssertExpectations(s.T())
+	s.fileIdx.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryGraph() {
+	g := &graph.Mock{}
+	s.indexes.Graph = g
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+			Size: 23,
+		},
+	}
+
+	fileEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "fileName.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- &fileEntry
+		}).
+		Return(nil).
+		Once()
+
+	var dir *indexTypes.Directory
+
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.AnythingOfType("*types.Directory")).
+		Run(func(args mock.Arguments) {
+			dir = args.Get(2).(*indexTypes.Directory)
+		}).
+		Return(nil).
+		Once()
+
+	s.fileQ.
+		On("Publish", mock.Anything, &fileEntry, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	// The directory is found in a root, and at a deeper path.
+	g.
+		On("Parents", mock.Anything, r.Resource.ID, dirDepthPaths).
+		Return([]indexTypes.ReferenceEdge{
+			{ParentHash: "QmRoot", ChildHash: r.Resource.ID, Name: "dir"},
+			{ParentHash: "QmParent", ChildHash: r.Resource.ID, Name: "sub"},
+		}, nil).
+		Once()
+	g.
+		On("Parents", mock.Anything, "QmRoot", dirDepthPaths).
+		Return([]indexTypes.ReferenceEdge{}, nil).
+		Twice()
+	g.
+		On("Parents", mock.Anything, "QmParent", dirDepthPaths).
+		Return([]indexTypes.ReferenceEdge{
+			{ParentHash: "QmRoot", ChildHash: "QmParent", Name: "parent"},
+		}, nil).
+		Once()
+
+	g.
+		On("AddEdges", mock.Anything, mock.MatchedBy(func(edges []indexTypes.ReferenceEdge) bool {
+			return len(edges) == 1 &&
+				edges[0].ParentHash == r.Resource.ID &&
+				edges[0].ChildHash == fileEntry.ID &&
+				edges[0].Name == "fileName.pdf" &&
+				!edges[0].FirstSeen.IsZero()
+		})).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	g.AssertExpectations(s.T())
+
+	// Depth is that of the shortest path.
+	s.Require().NotNil(dir.Stats.Depth)
+	s.Equal(uint64(1), *dir.Stats.Depth)
+}
+
+func (s *CrawlerTestSuite) TestCrawlExistingGraph() {
+	g := &graph.Mock{}
+	s.indexes.Graph = g
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Source: t.DirectorySource,
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+			},
+			Name: "ExistingReference.pdf",
+		},
+	}
+
+	// File is found, with the same reference.
+	s.fileIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.References = indexTypes.References{
+				indexTypes.Reference{
+					ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8",
+					Name:       "ExistingReference.pdf",
+				},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.dirIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	s.invalidIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	s.partialIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Maybe()
+
+	// The edge is written regardless; the store ignores existing edges.
+	g.
+		On("AddEdges", mock.Anything, mock.MatchedBy(func(edges []indexTypes.ReferenceEdge) bool {
+			return len(edges) == 1 &&
+				edges[0].ParentHash == "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8" &&
+				edges[0].ChildHash == r.Resource.ID &&
+				edges[0].Name == "ExistingReference.pdf"
+		})).
+		Return(nil).
+		Once()
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	g.AssertExpectations(s.T())
+	s.fileIdx.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CrawlerTestSuite) TestCrawlLargeDirectoryPaged() {
+	pageIdx := &index.Mock{}
+	s.indexes.Pages = pageIdx
+
+	s.cfg.MaxDirSize = 2
+	s.cfg.DirPageSize = 2
+
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+			Size: 23,
+		},
+	}
+
+	fileEntry := t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "fileName.pdf",
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+
+	link := indexTypes.Link{
+		Hash: fileEntry.ID,
+		Name: fileEntry.Reference.Name,
+		Size: fileEntry.Size,
+		Type: indexTypes.FileLinkType,
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			for i := 0; i < 3; i++ {
+				entryChan <- &fileEntry
+			}
+		}).
+		Return(nil).
+		Once()
+
+	s.fileQ.
+		On("Publish", mock.Anything, &fileEntry, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Times(3)
+
+	// The first page was written by an earlier attempt.
+	pageIdx.
+		On("Index", mock.Anything, r.Resource.ID+"-0", &indexTypes.DirectoryPage{
+			Directory: r.Resource.ID,
+			Page:      0,
+			Offset:    0,
+			Links:     indexTypes.Links{link, link},
+		}).
+		Return(fmt.Errorf("%w: %s-0", index.ErrDocumentExists, r.Resource.ID)).
+		Once()
+
+	pageIdx.
+		On("Index", mock.Anything, r.Resource.ID+"-1", &indexTypes.DirectoryPage{
+			Directory: r.Resource.ID,
+			Page:      1,
+			Offset:    2,
+			Links:     indexTypes.Links{link},
+		}).
+		Return(nil).
+		Once()
+
+	// Directory is indexed with summary, without links.
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(d *indexTypes.Directory) bool {
+			return d.Links == nil && d.LinkCount == 3 && d.Pages == 2
+		})).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	pageIdx.AssertExpectations(s.T())
+}
+
+func (s *CrawlerTestSuite) namedFileEntry(parent *t.Resource, name string) *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmafrLBfzRLV4XSH1XcaMMeaXEUhDJjmtDfsYU95TrWG87",
+		},
+		Reference: t.Reference{
+			Parent: parent,
+			Name:   name,
+		},
+		Stat: t.Stat{
+			Type: t.FileType,
+			Size: 3431,
+		},
+	}
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryCheckpointResume() {
+	cpIdx := &index.Mock{}
+	s.indexes.Checkpoints = cpIdx
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	a, b, c := s.namedFileEntry(r.Resource, "a"), s.namedFileEntry(r.Resource, "b"), s.namedFileEntry(r.Resource, "c")
+
+	// Previous attempt queued a and b.
+	cpIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.AnythingOfType("*types.DirectoryCheckpoint"), []string(nil)).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*indexTypes.DirectoryCheckpoint) = indexTypes.DirectoryCheckpoint{
+				Offset: 2, FirstName: "a", LastName: "b",
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- a
+			entryChan <- b
+			entryChan <- c
+		}).
+		Return(nil).
+		Once()
+
+	// Only c is queued.
+	s.fileQ.
+		On("Publish", mock.Anything, c, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	// All links are indexed.
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(d *indexTypes.Directory) bool {
+			return len(d.Links) == 3
+		})).
+		Return(nil).
+		Once()
+
+	// Checkpoint is removed when done.
+	cpIdx.
+		On("Delete", mock.Anything, r.Resource.ID).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	cpIdx.AssertExpectations(s.T())
+}
+
+// TestCrawlDirectoryCheckpointMismatch tests whether a listing which does not match its checkpoint is listed again
+// from the start, within the same attempt.
+func (s *CrawlerTestSuite) TestCrawlDirectoryCheckpointMismatch() {
+	cpIdx := &index.Mock{}
+	s.indexes.Checkpoints = cpIdx
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	a, b, c := s.namedFileEntry(r.Resource, "a"), s.namedFileEntry(r.Resource, "b"), s.namedFileEntry(r.Resource, "c")
+
+	// Previous attempt listed a different second entry.
+	cpIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.AnythingOfType("*types.DirectoryCheckpoint"), []string(nil)).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*indexTypes.DirectoryCheckpoint) = indexTypes.DirectoryCheckpoint{
+				Offset: 2, FirstName: "a", LastName: "x",
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			for _, e := range []*t.AnnotatedResource{a, b, c} {
+				select {
+				case entryChan <- e:
+				case <-args.Get(0).(context.Context).Done():
+					return
+				}
+			}
+		}).
+		Return(nil).
+		Twice()
+
+	// All entries are queued when listing again.
+	s.fileQ.
+		On("Publish", mock.Anything, mock.Anything, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Times(3)
+
+	// Links of the mismatching listing are discarded.
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(d *indexTypes.Directory) bool {
+			return len(d.Links) == 3 && d.LinkCount == 3
+		})).
+		Return(nil).
+		Once()
+
+	// Checkpoint is removed when done.
+	cpIdx.
+		On("Delete", mock.Anything, r.Resource.ID).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+	cpIdx.AssertExpectations(s.T())
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryCheckpointSave() {
+	cpIdx := &index.Mock{}
+	s.indexes.Checkpoints = cpIdx
+	s.cfg.DirEntryTimeout = 5 * time.Millisecond
+	s.cfg.DirCheckpointInterval = 0
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, nil, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	a, b := s.namedFileEntry(r.Resource, "a"), s.namedFileEntry(r.Resource, "b")
+
+	cpIdx.
+		On("Get", mock.Anything, r.Resource.ID, mock.AnythingOfType("*types.DirectoryCheckpoint"), []string(nil)).
+		Return(false, nil).
+		Once()
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- a
+			entryChan <- b
+
+			// Hang until the entry timeout.
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return(nil).
+		Once()
+
+	s.fileQ.
+		On("Publish", mock.Anything, mock.Anything, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Twice()
+
+	// Progress is saved on timeout.
+	cpIdx.
+		On("Index", mock.Anything, r.Resource.ID, &indexTypes.DirectoryCheckpoint{
+			Offset: 2, FirstName: "a", LastName: "b",
+		}).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	s.ErrorIs(err, context.DeadlineExceeded)
+	// Temporary, so that the delivery is retried from the checkpoint.
+	s.ErrorIs(err, index.ErrTemporary)
+	s.assertExpectations()
+	cpIdx.AssertExpectations(s.T())
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryStats() {
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	index := s.namedFileEntry(r.Resource, "index.html")
+	index.Size = 100
+
+	photo := s.namedFileEntry(r.Resource, "Photo.JPG")
+	photo.Size = 300
+
+	other := s.namedFileEntry(r.Resource, "photo.jpg")
+	other.Size = 200
+
+	dir := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmPEw9P3eKG2A6L2dKGjCrGYo3RxCbg3N5MKKN3v9FWzv2",
+		},
+		Reference: t.Reference{
+			Parent: r.Resource,
+			Name:   "assets",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+			Size: 50,
+		},
+	}
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- index
+			entryChan <- photo
+			entryChan <- other
+			entryChan <- dir
+		}).
+		Return(nil).
+		Once()
+
+	s.fileQ.
+		On("Publish", mock.Anything, mock.Anything, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Times(3)
+
+	s.dirQ.
+		On("Publish", mock.Anything, dir, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	var stats *indexTypes.DirectoryStats
+
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.AnythingOfType("*types.Directory")).
+		Run(func(args mock.Arguments) {
+			stats = args.Get(2).(*indexTypes.Directory).Stats
+		}).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err := s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+
+	s.Equal(&indexTypes.DirectoryStats{
+		TotalSize: 650,
+		Types: []indexTypes.Count{
+			{Key: "File", Count: 3},
+			{Key: "Directory", Count: 1},
+		},
+		Extensions: []indexTypes.Count{
+			{Key: "jpg", Count: 2},
+			{Key: "html", Count: 1},
+		},
+		MimeTypes: []indexTypes.Count{
+			{Key: "image/jpeg", Count: 2},
+			{Key: "text/html", Count: 1},
+		},
+		Largest: indexTypes.Links{
+			{Hash: photo.ID, Name: "Photo.JPG", Size: 300, Type: indexTypes.FileLinkType},
+			{Hash: other.ID, Name: "photo.jpg", Size: 200, Type: indexTypes.FileLinkType},
+			{Hash: index.ID, Name: "index.html", Size: 100, Type: indexTypes.FileLinkType},
+			{Hash: dir.ID, Name: "assets", Size: 50, Type: indexTypes.DirectoryLinkType},
+		},
+	}, stats)
+}
+
+func (s *CrawlerTestSuite) TestCrawlDirectoryClass() {
+	cl, err := classifier.New(classifier.DefaultConfig())
+	s.Require().NoError(err)
+
+	sites := site.New(s.indexes.Files, nil, s.protocol, site.DefaultConfig(), s.instr)
+
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, []extractor.Extractor{s.extractor1}, &Components{Classifier: cl, Sites: sites}, s.instr)
+
+	// Prepare resource
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp",
+		},
+		Stat: t.Stat{
+			Type: t.DirectoryType,
+		},
+	}
+
+	entry := s.namedFileEntry(r.Resource, "index.html")
+
+	s.protocol.
+		On("Ls", mock.Anything, r, mock.AnythingOfType("chan<- *types.AnnotatedResource")).
+		Run(func(args mock.Arguments) {
+			entryChan := args.Get(2).(chan<- *t.AnnotatedResource)
+			entryChan <- entry
+		}).
+		Return(nil).
+		Once()
+
+	s.fileQ.
+		On("Publish", mock.Anything, entry, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.dirIdx.
+		On("Index", mock.Anything, r.Resource.ID, mock.MatchedBy(func(d *indexTypes.Directory) bool {
+			return s.Equal(&indexTypes.DirectoryClass{Type: "website", Confidence: 0.9}, d.Class)
+		})).
+		Return(nil).
+		Once()
+
+	s.assertNotExists(r.Resource.ID)
+
+	// Crawl
+	err = s.c.Crawl(s.ctx, r)
+
+	// Test result, side effects
+	s.NoError(err)
+	s.assertExpectations()
+
+	// Website is queued for site indexing.
+	s.Equal(int64(1), sites.Stats().Enqueued)
+}
+
+// nameResource returns an IPNS name resource, with a names index and refresher for the crawler.
+func (s *CrawlerTestSuite) nameResource() (*t.AnnotatedResource, *index.Mock, *names.Refresher) {
+	namesIdx := &index.Mock{}
+	s.indexes.Names = namesIdx
+
+	refresher := names.New(s.hashQ, names.DefaultConfig(), s.instr)
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, nil, &Components{Names: refresher}, s.instr)
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPNSProtocol,
+			ID:       "k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8",
+		},
+	}
+
+	return r, namesIdx, refresher
+}
+
+func (s *CrawlerTestSuite) expectName(namesIdx *index.Mock, id string, existing *indexTypes.Name) {
+	namesIdx.
+		On("Get", mock.Anything, id, mock.AnythingOfType("*types.Name"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			if existing != nil {
+				*args.Get(2).(*indexTypes.Name) = *existing
+			}
+		}).
+		Return(existing != nil, nil).
+		Once()
+}
+
+func (s *CrawlerTestSuite) expectTargetQueued(target *t.Resource) {
+	s.hashQ.
+		On("Publish", mock.Anything, &t.AnnotatedResource{Resource: target}, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+}
+
+func (s *CrawlerTestSuite) TestCrawlNameNew() {
+	r, namesIdx, refresher := s.nameResource()
+	target := &t.Resource{Protocol: t.IPFSProtocol, ID: "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}
+
+	s.expectName(namesIdx, r.ID, nil)
+
+	s.protocol.
+		On("Resolve", mock.Anything, r).
+		Return(target, nil).
+		Once()
+
+	var name *indexTypes.Name
+
+	namesIdx.
+		On("Index", mock.Anything, r.ID, mock.AnythingOfType("*types.Name")).
+		Run(func(args mock.Arguments) {
+			name = args.Get(2).(*indexTypes.Name)
+		}).
+		Return(nil).
+		Once()
+
+	s.expectTargetQueued(target)
+
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+	namesIdx.AssertExpectations(s.T())
+
+	s.Equal(r.ID, name.Name)
+	s.Equal(target.ID, name.Target)
+	s.Equal(name.FirstSeen, name.LastSeen)
+	s.Equal(uint64(3600), name.RefreshInterval)
+	s.Equal([]indexTypes.NameTarget{{Target: target.ID, FirstSeen: name.FirstSeen}}, name.History)
+
+	s.Equal(names.Stats{Scheduled: 1}, refresher.Stats())
+}
+
+func (s *CrawlerTestSuite) TestCrawlNameChanged() {
+	s.cfg.MaxNameHistory = 2
+
+	r, namesIdx, _ := s.nameResource()
+	target := &t.Resource{Protocol: t.IPFSProtocol, ID: "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}
+
+	firstSeen := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+
+	s.expectName(namesIdx, r.ID, &indexTypes.Name{
+		Name:      r.ID,
+		Target:    "QmOld",
+		FirstSeen: firstSeen,
+		LastSeen:  firstSeen.Add(24 * time.Hour),
+		History: []indexTypes.NameTarget{
+			{Target: "QmOlder", FirstSeen: firstSeen},
+			{Target: "QmOld", FirstSeen: firstSeen.Add(time.Hour)},
+		},
+	})
+
+	s.protocol.
+		On("Resolve", mock.Anything, r).
+		Return(target, nil).
+		Once()
+
+	var name *indexTypes.Name
+
+	namesIdx.
+		On("Update", mock.Anything, r.ID, mock.AnythingOfType("*types.Name")).
+		Run(func(args mock.Arguments) {
+			name = args.Get(2).(*indexTypes.Name)
+		}).
+		Return(nil).
+		Once()
+
+	s.expectTargetQueued(target)
+
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+	namesIdx.AssertExpectations(s.T())
+
+	s.Equal(target.ID, name.Target)
+	s.Equal(firstSeen, name.FirstSeen)
+	s.True(name.LastSeen.After(firstSeen.Add(24 * time.Hour)))
+
+	// History is capped at MaxNameHistory, dropping the oldest target.
+	s.Equal([]indexTypes.NameTarget{
+		{Target: "QmOld", FirstSeen: firstSeen.Add(time.Hour)},
+		{Target: target.ID, FirstSeen: name.LastSeen},
+	}, name.History)
+}
+
+func (s *CrawlerTestSuite) TestCrawlNameUnchanged() {
+	r, namesIdx, _ := s.nameResource()
+	target := &t.Resource{Protocol: t.IPFSProtocol, ID: "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}
+
+	lastSeen := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+
+	s.expectName(namesIdx, r.ID, &indexTypes.Name{
+		Name:      r.ID,
+		Target:    target.ID,
+		FirstSeen: lastSeen,
+		LastSeen:  lastSeen,
+		History:   []indexTypes.NameTarget{{Target: target.ID, FirstSeen: lastSeen}},
+	})
+
+	s.protocol.
+		On("Resolve", mock.Anything, r).
+		Return(target, nil).
+		Once()
+
+	namesIdx.
+		On("Update", mock.Anything, r.ID, mock.MatchedBy(func(n *indexTypes.Name) bool {
+			return n.LastSeen.After(lastSeen) && len(n.History) == 1
+		})).
+		Return(nil).
+		Once()
+
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+	namesIdx.AssertExpectations(s.T())
+
+	// Unchanged targets are not queued again.
+	s.hashQ.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CrawlerTestSuite) TestCrawlNameRecentlyResolved() {
+	r, namesIdx, _ := s.nameResource()
+
+	s.expectName(namesIdx, r.ID, &indexTypes.Name{
+		Name:     r.ID,
+		Target:   "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+		LastSeen: time.Now().Add(-time.Minute),
+	})
+
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+	namesIdx.AssertExpectations(s.T())
+
+	s.protocol.AssertNotCalled(s.T(), "Resolve", mock.Anything, mock.Anything)
+}
+
+func (s *CrawlerTestSuite) TestCrawlNameInvalid() {
+	r, namesIdx, _ := s.nameResource()
+
+	s.expectName(namesIdx, r.ID, nil)
+
+	s.protocol.
+		On("Resolve", mock.Anything, r).
+		Return((*t.Resource)(nil), t.ErrInvalidResource).
+		Once()
+
+	s.invalidIdx.
+		On("Index", mock.Anything, r.ID, &indexTypes.Invalid{
+			Error: t.ErrInvalidResource.Error(),
+		}).
+		Return(nil).
+		Once()
+
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+	namesIdx.AssertExpectations(s.T())
+}
+
+func (s *CrawlerTestSuite) TestCrawlDomain() {
+	domainsIdx := &index.Mock{}
+	s.indexes.Domains = domainsIdx
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.DNSLinkProtocol,
+			ID:       "example.com",
+		},
+		Source: t.ManualSource,
+	}
+	target := &t.Resource{Protocol: t.IPFSProtocol, ID: "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}
+
+	s.expectName(domainsIdx, r.ID, nil)
+
+	s.protocol.
+		On("Resolve", mock.Anything, r).
+		Return(target, nil).
+		Once()
+
+	s.assertNotExists(target.ID)
+
+	domainsIdx.
+		On("Index", mock.Anything, r.ID, mock.MatchedBy(func(n *indexTypes.Name) bool {
+			return n.Name == r.ID && n.Target == target.ID
+		})).
+		Return(nil).
+		Once()
+
+	// The root is queued with the domain as alias.
+	s.hashQ.
+		On("Publish", mock.Anything, &t.AnnotatedResource{
+			Resource: target,
+			Source:   t.ManualSource,
+			Reference: t.Reference{
+				Parent: r.Resource,
+				Name:   "example.com",
+			},
+		}, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+	domainsIdx.AssertExpectations(s.T())
+}
+
+// TestCrawlDomainIPNS tests whether the alias of a domain pointing to /ipns/ is passed to the root of the name.
+func (s *CrawlerTestSuite) TestCrawlDomainIPNS() {
+	domainsIdx := &index.Mock{}
+	s.indexes.Domains = domainsIdx
+
+	name, namesIdx, _ := s.nameResource()
+	root := &t.Resource{Protocol: t.IPFSProtocol, ID: "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.DNSLinkProtocol,
+			ID:       "example.com",
+		},
+		Source: t.ManualSource,
+	}
+	alias := t.Reference{
+		Parent: r.Resource,
+		Name:   "example.com",
+	}
+
+	lastSeen := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+
+	// The domain is unchanged.
+	s.expectName(domainsIdx, r.ID, &indexTypes.Name{
+		Name:     r.ID,
+		Target:   name.ID,
+		LastSeen: lastSeen,
+	})
+
+	s.protocol.
+		On("Resolve", mock.Anything, r).
+		Return(name.Resource, nil).
+		Once()
+
+	domainsIdx.
+		On("Update", mock.Anything, r.ID, mock.AnythingOfType("*types.Name")).
+		Return(nil).
+		Once()
+
+	// The name is queued with the domain as alias, nevertheless.
+	queued := &t.AnnotatedResource{
+		Resource:  name.Resource,
+		Source:    t.ManualSource,
+		Reference: alias,
+	}
+
+	s.hashQ.
+		On("Publish", mock.Anything, queued, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.NoError(s.c.Crawl(s.ctx, r))
+	s.assertExpectations()
+	domainsIdx.AssertExpectations(s.T())
+
+	// The root of the name is queued with the alias of the domain.
+	s.expectName(namesIdx, name.ID, nil)
+
+	s.protocol.
+		On("Resolve", mock.Anything, queued).
+		Return(root, nil).
+		Once()
+
+	s.assertNotExists(root.ID)
+
+	namesIdx.
+		On("Index", mock.Anything, name.ID, mock.AnythingOfType("*types.Name")).
+		Return(nil).
+		Once()
+
+	s.hashQ.
+		On("Publish", mock.Anything, &t.AnnotatedResource{
+			Resource:  root,
+			Source:    t.ManualSource,
+			Reference: alias,
+		}, mock.AnythingOfType("uint8")).
+		Return(nil).
+		Once()
+
+	s.NoError(s.c.Crawl(s.ctx, queued))
+	s.assertExpectations()
+	namesIdx.AssertExpectations(s.T())
+}
+
+// TestCrawlNameRecentlyResolvedAlias tests whether the alias carried by a recently resolved name is appended to
+// its current root.
+func (s *CrawlerTestSuite) TestCrawlNameRecentlyResolvedAlias() {
+	r, namesIdx, _ := s.nameResource()
+	root := "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"
+
+	dirIdx := &index.AppenderMock{}
+	s.indexes.Directories = dirIdx
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, nil, nil, s.instr)
+
+	r.Reference = t.Reference{
+		Parent: &t.Resource{Protocol: t.DNSLinkProtocol, ID: "example.com"},
+		Name:   "example.com",
+	}
+
+	s.expectName(namesIdx, r.ID, &indexTypes.Name{
+		Name:     r.ID,
+		Target:   root,
+		LastSeen: time.Now().Add(-time.Minute),
+	})
+
+	fields := []string{"references", "last-seen", "reference_count", "reference_names"}
+
+	s.fileIdx.On("Get", mock.Anything, root, mock.Anything, fields).Return(false, nil).Maybe()
+	s.invalidIdx.On("Get", mock.Anything, root, mock.Anything, fields).Return(false, nil).Maybe()
+	s.partialIdx.On("Get", mock.Anything, root, mock.Anything, fields).Return(false, nil).Maybe()
+
+	dirIdx.
+		On("Get", mock.Anything, root, mock.Anything, fields).
+		Return(true, nil).
+		Once()
+
+	dirIdx.
+		On("AppendReference", mock.Anything, root, indexTypes.Reference{
+			ParentHash: "example.com",
+			Name:       "example.com",
+		}).
+		Return(nil).
+		Once()
+
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	namesIdx.AssertExpectations(s.T())
+	dirIdx.AssertExpectations(s.T())
+
+	s.protocol.AssertNotCalled(s.T(), "Resolve", mock.Anything, mock.Anything)
+	s.hashQ.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestCrawlDomainExistingRoot tests whether the domain is appended as alias to an already indexed root.
+func (s *CrawlerTestSuite) TestCrawlDomainExistingRoot() {
+	domainsIdx := &index.Mock{}
+	s.indexes.Domains = domainsIdx
+
+	dirIdx := &index.AppenderMock{}
+	s.indexes.Directories = dirIdx
+	s.c = New(s.cfg, s.indexes, s.queues, s.protocol, nil, nil, s.instr)
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.DNSLinkProtocol,
+			ID:       "example.com",
+		},
+		Source: t.UnknownSource,
+	}
+	target := &t.Resource{Protocol: t.IPFSProtocol, ID: "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}
+
+	s.expectName(domainsIdx, r.ID, nil)
+
+	s.protocol.
+		On("Resolve", mock.Anything, r).
+		Return(target, nil).
+		Once()
+
+	fields := []string{"references", "last-seen", "reference_count", "reference_names"}
+
+	s.fileIdx.On("Get", mock.Anything, target.ID, mock.Anything, fields).Return(false, nil).Maybe()
+	s.invalidIdx.On("Get", mock.Anything, target.ID, mock.Anything, fields).Return(false, nil).Maybe()
+	s.partialIdx.On("Get", mock.Anything, target.ID, mock.Anything, fields).Return(false, nil).Maybe()
+
+	// The root is indexed, referenced from a directory.
+	dirIdx.
+		On("Get", mock.Anything, target.ID, mock.Anything, fields).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*indexTypes.Update).References = indexTypes.References{
+				{ParentHash: "QmYAqhbqNDpU7X9VW6FV5imtngQ3oBRY35zuDXduuZnyA8", Name: "site"},
+			}
+		}).
+		Return(true, nil).
+		Once()
+
+	dirIdx.
+		On("AppendReference", mock.Anything, target.ID, indexTypes.Reference{
+			ParentHash: "example.com",
+			Name:       "example.com",
+		}).
+		Return(nil).
+		Once()
+
+	domainsIdx.
+		On("Index", mock.Anything, r.ID, mock.AnythingOfType("*types.Name")).
+		Return(nil).
+		Once()
+
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+	domainsIdx.AssertExpectations(s.T())
+	dirIdx.AssertExpectations(s.T())
+
+	// The root is not queued.
+	s.hashQ.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+const testIPLD = `{
+	"name": "Token #1",
+	"image": {"/": "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"},
+	"attributes": [
+		{"trait": "color", "value": "red"},
+		{"file": {"/": "bafkreiblvqc3q73ygovlzaxz4iilm5fopppcdc3uzkrtepjsgkvyev3kgy"}}
+	],
+	"thumbnail": {"/": "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"},
+	"signature": {"/": {"bytes": "c2lnbmF0dXJl"}},
+	"broken": {"/": "notacid"},
+	"count": 3
+}`
+
+func (s *CrawlerTestSuite) TestParseIPLD() {
+	doc, err := parseIPLD([]byte(testIPLD), 0)
+	s.Require().NoError(err)
+
+	// Links are unique, in the order of sorted keys.
+	s.Equal([]ipldLink{
+		{Path: "attributes/1/file", CID: "bafkreiblvqc3q73ygovlzaxz4iilm5fopppcdc3uzkrtepjsgkvyev3kgy"},
+		{Path: "image", CID: "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"},
+	}, doc.links)
+	s.False(doc.truncated)
+
+	// Bytes and links are not content.
+	s.Equal([]string{"color", "red", "Token #1"}, doc.strings)
+
+	doc, err = parseIPLD([]byte(testIPLD), 1)
+	s.Require().NoError(err)
+
+	s.Len(doc.links, 1)
+	s.True(doc.truncated)
+
+	_, err = parseIPLD([]byte(`{"invalid"`), 0)
+	s.Error(err)
+}
+
+func (s *CrawlerTestSuite) TestCrawlIPLD() {
+	ipldIdx := &index.Mock{}
+	s.indexes.IPLD = ipldIdx
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "bafyreidmmwhoqp5x5ajeqjeu6psbnkdw6y7udcqlripv45wup3sbo4bvzm",
+		},
+	}
+
+	s.assertNotExists(r.ID)
+
+	ipldIdx.
+		On("Get", mock.Anything, r.ID, mock.Anything, []string{"references", "last-seen", "reference_count", "reference_names"}).
+		Return(false, nil).
+		Once()
+
+	s.protocol.
+		On("Stat", mock.Anything, r).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*t.AnnotatedResource).Stat = t.Stat{
+				Type: t.IPLDType,
+				Size: uint64(len(testIPLD)),
+			}
+		}).
+		Return(nil).
+		Once()
+
+	s.protocol.
+		On("GetDAG", mock.Anything, r).
+		Return([]byte(testIPLD), nil).
+		Once()
+
+	for _, l := range []ipldLink{
+		{Path: "attributes/1/file", CID: "bafkreiblvqc3q73ygovlzaxz4iilm5fopppcdc3uzkrtepjsgkvyev3kgy"},
+		{Path: "image", CID: "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"},
+	} {
+		s.hashQ.
+			On("Publish", mock.Anything, &t.AnnotatedResource{
+				Resource: &t.Resource{
+					Protocol: t.IPFSProtocol,
+					ID:       l.CID,
+				},
+				Source: t.DirectorySource,
+				Reference: t.Reference{
+					Parent: r.Resource,
+					Name:   l.Path,
+				},
+			}, mock.AnythingOfType("uint8")).
+			Return(nil).
+			Once()
+	}
+
+	var doc *indexTypes.IPLD
+
+	ipldIdx.
+		On("Index", mock.Anything, r.ID, mock.AnythingOfType("*types.IPLD")).
+		Run(func(args mock.Arguments) {
+			doc = args.Get(2).(*indexTypes.IPLD)
+		}).
+		Return(nil).
+		Once()
+
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+	ipldIdx.AssertExpectations(s.T())
+
+	s.Equal("dag-cbor", doc.Codec)
+	s.Equal(uint64(len(testIPLD)), doc.Size)
+	s.JSONEq(testIPLD, string(doc.Data))
+	s.Equal("color\nred\nToken #1", doc.Content)
+	s.Equal([]string{"bafkreiblvqc3q73ygovlzaxz4iilm5fopppcdc3uzkrtepjsgkvyev3kgy", "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}, doc.Links)
+}
+
+func (s *CrawlerTestSuite) TestCrawlIPLDWithoutIndex() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "bafyreidmmwhoqp5x5ajeqjeu6psbnkdw6y7udcqlripv45wup3sbo4bvzm",
+		},
+		Stat: t.Stat{
+			Type: t.IPLDType,
+		},
+	}
+
+	s.assertNotExists(r.ID)
+
+	s.invalidIdx.
+		On("Index", mock.Anything, r.ID, &indexTypes.Invalid{
+			Error: t.ErrUnsupportedType.Error(),
+		}).
+		Return(nil).
+		Once()
+
+	err := s.c.Crawl(s.ctx, r)
+
+	s.NoError(err)
+	s.assertExpectations()
+}
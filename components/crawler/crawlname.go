@@ -0,0 +1,206 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// nameIndex 返回名称所属的索引：IPNS 名称存入 Names，DNSLink 域名存入 Domains。
+func (c *Crawler) nameIndex(r *t.AnnotatedResource) index.Index {
+	if r.Protocol == t.DNSLinkProtocol {
+		return c.indexes.Domains
+	}
+
+	return c.indexes.Names
+}
+
+// nameTarget 返回待爬取的目标；域名作为别名引用附加到目标上，使搜索域名时能找到网站。
+// 指向 /ipns/ 的域名的别名随 IPNS 名称传递，从而附加到名称解析到的根 CID 上。
+func nameTarget(r *t.AnnotatedResource, target *t.Resource) *t.AnnotatedResource {
+	a := &t.AnnotatedResource{
+		Resource: target,
+		Source:   r.Source,
+	}
+
+	switch {
+	case r.Protocol == t.DNSLinkProtocol:
+		a.Reference = t.Reference{
+			Parent: r.Resource,
+			Name:   r.ID,
+		}
+	case isAlias(r):
+		a.Reference = r.Reference
+	}
+
+	return a
+}
+
+// isAlias 返回 r 是否携带域名的别名引用。
+func isAlias(r *t.AnnotatedResource) bool {
+	return r.Reference.Parent != nil && r.Reference.Parent.Protocol == t.DNSLinkProtocol
+}
+
+// crawlName 解析 IPNS 名称或 DNSLink 域名，记录其当前目标和历史，将新的或变化的目标加入队列，并安排下次解析。
+func (c *Crawler) crawlName(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := c.Tracer.Start(ctx, "crawler.crawlName")
+	defer span.End()
+
+	resolver, ok := c.protocol.(protocol.Resolver)
+	if !ok {
+		return protocol.ErrNotResolver
+	}
+
+	var (
+		name  indexTypes.Name
+		found bool
+		err   error
+		now   = time.Now().UTC().Truncate(time.Second) // 截断到秒级兼容ES格式
+	)
+
+	idx := c.nameIndex(r)
+
+	if idx != nil {
+		if found, err = idx.Get(ctx, r.ID, &name); err != nil {
+			return err
+		}
+
+		// 最近解析过的名称已安排刷新；携带的别名附加到名称当前的目标上
+		if found && now.Sub(name.LastSeen) < c.nameMinAge() {
+			log.Printf("Skipping recently resolved name %v", r)
+			span.AddEvent("recently resolved")
+
+			if isAlias(r) {
+				_, err = c.addAlias(ctx, nameTarget(r, &t.Resource{Protocol: t.IPFSProtocol, ID: name.Target}))
+			}
+
+			return err
+		}
+	}
+
+	target, err := c.resolveName(ctx, resolver, r)
+	if err != nil {
+		if errors.Is(err, t.ErrInvalidResource) {
+			log.Printf("Indexing invalid name %v", r)
+			span.AddEvent("Indexing invalid name")
+
+			return c.indexInvalid(ctx, r, err)
+		}
+
+		return err
+	}
+
+	changed := !found || name.Target != target.ID
+	next := nameTarget(r, target)
+
+	// 已索引的网站不会因入队而更新引用，因此直接附加别名；在记录名称之前附加，使失败时可重试。
+	var aliased bool
+	if isAlias(next) && target.Protocol == t.IPFSProtocol {
+		if aliased, err = c.addAlias(ctx, next); err != nil {
+			return err
+		}
+	}
+
+	if idx != nil {
+		if err := indexName(ctx, idx, r.ID, &name, found, target.ID, c.config, now); err != nil {
+			return err
+		}
+	}
+
+	if c.names != nil {
+		c.names.Add(r.Resource, now.Add(c.config.NameRefreshInterval))
+	}
+
+	// 指向 IPNS 名称的别名每次都随名称入队，使其附加到名称最新的目标上
+	forward := isAlias(next) && target.Protocol == t.IPNSProtocol
+
+	if aliased || (!changed && !forward) {
+		return nil
+	}
+
+	log.Printf("Name %v resolved to %v", r, target)
+
+	return c.queues.Hashes.Publish(ctx, next, 9)
+}
+
+// addAlias 将别名引用附加到已索引的目标文档上，返回目标是否已索引。
+// 与目录引用相同，超过 MaxReferences 时仅更新引用计数和采样的名称。
+func (c *Crawler) addAlias(ctx context.Context, a *t.AnnotatedResource) (bool, error) {
+	i, err := c.getExistingItem(ctx, a)
+	if err != nil {
+		return false, err
+	}
+
+	if i == nil || i.Index == c.indexes.Invalids || i.Index == c.indexes.Partials {
+		return false, nil
+	}
+
+	if hasReference(i.References, &a.Reference) {
+		return true, nil
+	}
+
+	log.Printf("Adding alias %s to %v", a.Reference.Name, a.Resource)
+
+	if c.referencesFull(i.References) {
+		return true, c.addOverflowReference(ctx, i)
+	}
+
+	refs, _ := appendReference(i.References, &a.Reference)
+
+	return true, c.addReference(ctx, i, refs)
+}
+
+// nameMinAge 返回名称再次解析前的最短时间。
+func (c *Crawler) nameMinAge() time.Duration {
+	if c.config.NameRefreshInterval < c.config.MinUpdateAge {
+		return c.config.NameRefreshInterval
+	}
+
+	return c.config.MinUpdateAge
+}
+
+// resolveName 在 StatTimeout 内解析名称。
+func (c *Crawler) resolveName(ctx context.Context, resolver protocol.Resolver, r *t.AnnotatedResource) (*t.Resource, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.StatTimeout)
+	defer cancel()
+
+	return resolver.Resolve(ctx, r)
+}
+
+// indexName 将名称解析到 target 的结果写入索引 idx；目标变化时，将新目标加入历史。
+func indexName(ctx context.Context, idx index.Index, id string, name *indexTypes.Name, found bool, target string, config *Config, now time.Time) error {
+	interval := uint64(config.NameRefreshInterval / time.Second)
+
+	if !found {
+		return idx.Index(ctx, id, &indexTypes.Name{
+			Name:            id,
+			Target:          target,
+			FirstSeen:       now,
+			LastSeen:        now,
+			RefreshInterval: interval,
+			History: []indexTypes.NameTarget{
+				{Target: target, FirstSeen: now},
+			},
+		})
+	}
+
+	name.LastSeen = now
+	name.RefreshInterval = interval
+
+	if name.Target != target {
+		name.Target = target
+		name.History = append(name.History, indexTypes.NameTarget{Target: target, FirstSeen: now})
+
+		if n := int(config.MaxNameHistory); n > 0 && len(name.History) > n {
+			name.History = name.History[len(name.History)-n:]
+		}
+	}
+
+	return idx.Update(ctx, id, name)
+}
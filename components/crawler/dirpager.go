@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"context"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// dirPager 将大目录的链接按页写入 Pages 索引，避免在内存中保留全部链接。
+// 重试或从检查点继续时重新写入相同的页，已存在的页不视为错误。
+type dirPager struct {
+	index    index.Index
+	dir      string
+	pageSize uint
+
+	links indexTypes.Links
+	pages uint64
+}
+
+// add 添加链接，页满时写入。
+func (p *dirPager) add(ctx context.Context, l indexTypes.Link) error {
+	p.links = append(p.links, l)
+
+	if uint(len(p.links)) >= p.pageSize {
+		return p.flush(ctx)
+	}
+
+	return nil
+}
+
+// flush 写入当前页（如有链接）。
+func (p *dirPager) flush(ctx context.Context) error {
+	if len(p.links) == 0 {
+		return nil
+	}
+
+	page := &indexTypes.DirectoryPage{
+		Directory: p.dir,
+		Page:      p.pages,
+		Offset:    p.pages * uint64(p.pageSize),
+		Links:     p.links,
+	}
+
+	if err := indexOnce(ctx, p.index, indexTypes.DirectoryPageID(p.dir, p.pages), page); err != nil {
+		return err
+	}
+
+	p.pages++
+	p.links = nil
+
+	return nil
+}
+
+// newDirPager 返回目录 dir 的分页器；未配置 Pages 索引时返回 nil。
+func (c *Crawler) newDirPager(dir string) *dirPager {
+	if c.indexes.Pages == nil {
+		return nil
+	}
+
+	pageSize := c.config.DirPageSize
+	if pageSize == 0 {
+		pageSize = DefaultConfig().DirPageSize
+	}
+
+	return &dirPager{
+		index:    c.indexes.Pages,
+		dir:      dir,
+		pageSize: pageSize,
+	}
+}
@@ -0,0 +1,178 @@
+package crawler
+
+import (
+	"container/heap"
+	"context"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ipfs-search/ipfs-search/components/graph"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+const (
+	dirStatsMaxKeys       = 1024 // 直方图中统计的不同键的最大数量，超出的新键不再计数。
+	dirStatsHistogramSize = 32   // 目录文档中保留的直方图条目数量。
+	dirStatsLargest       = 10   // 目录文档中保留的最大条目数量。
+	maxExtensionLength    = 10   // 更长的“扩展名”多半是文件名的一部分。
+	dirDepthMax           = 32   // 计算目录深度时最多跟随的父目录层数。
+	dirDepthPaths         = 8    // 计算目录深度时最多考虑的路径数量。
+)
+
+// histogram 对键计数，不同键的数量有上限。
+type histogram map[string]uint64
+
+func (h histogram) add(key string) {
+	if _, ok := h[key]; ok || len(h) < dirStatsMaxKeys {
+		h[key]++
+	}
+}
+
+// counts 返回按计数降序（计数相同时按键）排列的前 dirStatsHistogramSize 项。
+func (h histogram) counts() []indexTypes.Count {
+	if len(h) == 0 {
+		return nil
+	}
+
+	counts := make([]indexTypes.Count, 0, len(h))
+	for k, c := range h {
+		counts = append(counts, indexTypes.Count{Key: k, Count: c})
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Key < counts[j].Key
+	})
+
+	if len(counts) > dirStatsHistogramSize {
+		counts = counts[:dirStatsHistogramSize]
+	}
+
+	return counts
+}
+
+// linkHeap 是按大小排列的最小堆，用于保留最大的条目。
+type linkHeap indexTypes.Links
+
+func (h linkHeap) Len() int           { return len(h) }
+func (h linkHeap) Less(i, j int) bool { return h[i].Size < h[j].Size }
+func (h linkHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *linkHeap) Push(x interface{}) { *h = append(*h, x.(indexTypes.Link)) }
+
+func (h *linkHeap) Pop() interface{} {
+	old := *h
+	l := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return l
+}
+
+// dirStats 在列举目录时累积统计信息，内存占用与目录大小无关。
+type dirStats struct {
+	totalSize  uint64
+	types      histogram
+	extensions histogram
+	mimeTypes  histogram
+	largest    linkHeap
+}
+
+func newDirStats() *dirStats {
+	return &dirStats{
+		types:      make(histogram),
+		extensions: make(histogram),
+		mimeTypes:  make(histogram),
+	}
+}
+
+// extension 返回小写、不含点的扩展名；无扩展名或不像扩展名时返回空字符串。
+func extension(name string) string {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+
+	if len(ext) > maxExtensionLength {
+		return ""
+	}
+
+	for _, c := range ext {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return ""
+		}
+	}
+
+	return ext
+}
+
+// add 将链接计入统计。
+func (s *dirStats) add(l indexTypes.Link) {
+	s.totalSize += l.Size
+	s.types.add(string(l.Type))
+
+	if l.Type == indexTypes.FileLinkType {
+		if ext := extension(l.Name); ext != "" {
+			s.extensions.add(ext)
+
+			if t := mime.TypeByExtension("." + ext); t != "" {
+				t, _, _ = strings.Cut(t, ";")
+				s.mimeTypes.add(strings.TrimSpace(t))
+			}
+		}
+	}
+
+	switch {
+	case len(s.largest) < dirStatsLargest:
+		heap.Push(&s.largest, l)
+	case l.Size > s.largest[0].Size:
+		s.largest[0] = l
+		heap.Fix(&s.largest, 0)
+	}
+}
+
+// result 返回用于目录文档的统计信息。
+func (s *dirStats) result() *indexTypes.DirectoryStats {
+	largest := make(indexTypes.Links, len(s.largest))
+	copy(largest, s.largest)
+
+	sort.SliceStable(largest, func(i, j int) bool {
+		if largest[i].Size != largest[j].Size {
+			return largest[i].Size > largest[j].Size
+		}
+		return largest[i].Name < largest[j].Name
+	})
+
+	if len(largest) == 0 {
+		largest = nil
+	}
+
+	return &indexTypes.DirectoryStats{
+		TotalSize:  s.totalSize,
+		Types:      s.types.counts(),
+		Extensions: s.extensions.counts(),
+		MimeTypes:  s.mimeTypes.counts(),
+		Largest:    largest,
+	}
+}
+
+// dirDepth 根据边存储中到根的路径，返回目录 id 的最小深度（父目录层数），最大为 dirDepthMax；
+// 未配置边存储时返回 nil。父目录在列举时写入到子目录的边，因此父目录尚未爬取的目录深度为 0。
+func (c *Crawler) dirDepth(ctx context.Context, id string) (*uint64, error) {
+	if c.indexes.Graph == nil {
+		return nil, nil
+	}
+
+	paths, err := graph.Paths(ctx, c.indexes.Graph, id, dirDepthMax, dirDepthPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	depth := uint64(dirDepthMax)
+	for _, p := range paths {
+		if d := uint64(len(p.Names)); d < depth {
+			depth = d
+		}
+	}
+
+	return &depth, nil
+}
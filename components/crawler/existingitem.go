@@ -16,10 +16,13 @@ type existingItem struct {
 
 func (c *Crawler) getExistingItem(ctx context.Context, r *t.AnnotatedResource) (*existingItem, error) {
 	indexes := []index.Index{c.indexes.Files, c.indexes.Directories, c.indexes.Invalids, c.indexes.Partials}
+	if c.indexes.IPLD != nil {
+		indexes = append(indexes, c.indexes.IPLD)
+	}
 
 	update := &index_types.Update{}
 
-	index, err := index.MultiGet(ctx, indexes, r.ID, update, "references", "last-seen")
+	index, err := index.MultiGet(ctx, indexes, r.ID, update, "references", "last-seen", "reference_count", "reference_names")
 	if err != nil {
 		return nil, err
 	}
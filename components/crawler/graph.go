@@ -0,0 +1,35 @@
+package crawler
+
+import (
+	"context"
+	"time"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// edgeBatchSize 为目录条目写入边存储的批量大小。
+const edgeBatchSize = 1024
+
+// referenceEdge 返回从引用的父目录到 r 的边；没有父目录时返回 false。
+func referenceEdge(r *t.AnnotatedResource, now time.Time) (indexTypes.ReferenceEdge, bool) {
+	if r.Reference.Parent == nil {
+		return indexTypes.ReferenceEdge{}, false
+	}
+
+	return indexTypes.ReferenceEdge{
+		ParentHash: r.Reference.Parent.ID,
+		ChildHash:  r.ID,
+		Name:       r.Reference.Name,
+		FirstSeen:  now,
+	}, true
+}
+
+// addEdges 将边写入边存储；未配置边存储时不执行任何操作。
+func (c *Crawler) addEdges(ctx context.Context, edges ...indexTypes.ReferenceEdge) error {
+	if c.indexes.Graph == nil || len(edges) == 0 {
+		return nil
+	}
+
+	return c.indexes.Graph.AddEdges(ctx, edges...)
+}
@@ -51,6 +51,15 @@ func (c *Crawler) indexInvalid(ctx context.Context, r *t.AnnotatedResource, err
 	})
 }
 
+// indexOnce 写入 ID 和内容确定的文档；之前中断的尝试可能已写入该文档，因此 index.ErrDocumentExists 不视为错误。
+func indexOnce(ctx context.Context, i index.Index, id string, properties interface{}) error {
+	if err := i.Index(ctx, id, properties); err != nil && !errors.Is(err, index.ErrDocumentExists) {
+		return err
+	}
+
+	return nil
+}
+
 // 获取文件属性（含元数据提取）
 func (c *Crawler) getFileProperties(ctx context.Context, r *t.AnnotatedResource) (interface{}, error) {
 	var err error
@@ -101,6 +110,16 @@ func (c *Crawler) getProperties(ctx context.Context, r *t.AnnotatedResource) (in
 
 		return c.indexes.Directories, d, err
 
+	case t.IPLDType: // IPLD 文档
+		if c.indexes.IPLD == nil {
+			// 未配置 IPLD 索引时视为不支持的类型
+			return nil, nil, t.ErrUnsupportedType
+		}
+
+		d, err := c.getIPLDProperties(ctx, r)
+
+		return c.indexes.IPLD, d, err
+
 	case t.UnsupportedType: // 不支持的资源类型
 		// Index unsupported items as invalid.
 		err = t.ErrUnsupportedType
@@ -1,6 +1,7 @@
 package crawler
 
 import (
+	"github.com/ipfs-search/ipfs-search/components/graph"
 	"github.com/ipfs-search/ipfs-search/components/index"
 )
 
@@ -10,4 +11,12 @@ type Indexes struct {
 	Directories index.Index
 	Invalids    index.Index
 	Partials    index.Index
+	References  index.Index // Optional reverse-reference index, with all edges of documents exceeding Config.MaxReferences.
+	Pages       index.Index // Optional index of pages of links of directories exceeding Config.MaxDirSize.
+	Checkpoints index.Index // Optional index of directory listing progress, to resume interrupted listings.
+	IPLD        index.Index // Optional index of IPLD documents; without it, they are indexed as unsupported.
+	Names       index.Index // Optional index of IPNS names; without it, names are resolved but not recorded.
+	Domains     index.Index // Optional index of DNSLink domains, like Names.
+	Providers   index.Index // Optional index of peers announcing content; written from sniffed announcements, not crawling.
+	Graph       graph.Store // Optional store for all parent to child edges.
 }
@@ -0,0 +1,197 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// ipldLink 为 IPLD 文档中的链接，Path 为其在文档中的位置，如 attributes/0/image。
+type ipldLink struct {
+	Path string
+	CID  string
+}
+
+// ipldDocument 为 IPLD 文档的可索引内容。
+type ipldDocument struct {
+	strings   []string
+	links     []ipldLink
+	seen      map[string]bool
+	maxLinks  int
+	truncated bool
+}
+
+// parseIPLD 从 dag-json 编码的文档中提取字符串值和链接（最多 maxLinks 个，为 0 时不限制）。
+func parseIPLD(data []byte, maxLinks uint) (*ipldDocument, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	doc := &ipldDocument{
+		seen:     make(map[string]bool),
+		maxLinks: int(maxLinks),
+	}
+	doc.walk(nil, v)
+
+	return doc, nil
+}
+
+// addLink 添加路径 path 上指向 c 的链接，每个 CID 只添加一次。
+func (d *ipldDocument) addLink(path []string, c string) {
+	if d.seen[c] {
+		return
+	}
+
+	if d.maxLinks > 0 && len(d.links) >= d.maxLinks {
+		d.truncated = true
+		return
+	}
+
+	d.seen[c] = true
+	d.links = append(d.links, ipldLink{
+		Path: strings.Join(path, "/"),
+		CID:  c,
+	})
+}
+
+// walk 按确定的顺序遍历值 v，收集字符串和链接。
+func (d *ipldDocument) walk(path []string, v interface{}) {
+	switch x := v.(type) {
+	case string:
+		if x != "" {
+			d.strings = append(d.strings, x)
+		}
+
+	case []interface{}:
+		for i, e := range x {
+			d.walk(append(path, strconv.Itoa(i)), e)
+		}
+
+	case map[string]interface{}:
+		if v, ok := x["/"]; ok && len(x) == 1 {
+			// dag-json 中 {"/": "<cid>"} 为链接，{"/": {"bytes": ...}} 为字节。
+			if s, ok := v.(string); ok {
+				if _, err := cid.Decode(s); err != nil {
+					log.Printf("Ignoring invalid link %s in IPLD document: %v", s, err)
+					return
+				}
+
+				d.addLink(path, s)
+			}
+
+			return
+		}
+
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			d.walk(append(path, k), x[k])
+		}
+	}
+}
+
+// cids 返回文档链接的 CID。
+func (d *ipldDocument) cids() []string {
+	cids := make([]string, len(d.links))
+	for i, l := range d.links {
+		cids[i] = l.CID
+	}
+
+	return cids
+}
+
+// getIPLDProperties 获取 IPLD 文档的属性，并将其链接加入队列。
+func (c *Crawler) getIPLDProperties(ctx context.Context, r *t.AnnotatedResource) (interface{}, error) {
+	ctx, span := c.Tracer.Start(ctx, "crawler.getIPLDProperties")
+	defer span.End()
+
+	id, err := cid.Decode(r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, c.config.StatTimeout)
+	defer cancel()
+
+	data, err := c.protocol.GetDAG(fetchCtx, r)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	doc, err := parseIPLD(data, c.config.MaxDAGLinks)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decoding IPLD document: %v", t.ErrInvalidResource, err)
+	}
+
+	if doc.truncated {
+		log.Printf("IPLD document %v has more than %d links, ignoring the rest.", r, c.config.MaxDAGLinks)
+	}
+
+	if err := c.queueIPLDLinks(ctx, r, doc.links); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return &indexTypes.IPLD{
+		Document: makeDocument(r),
+		Codec:    t.CodecName(id),
+		Data:     json.RawMessage(data),
+		Content:  strings.Join(doc.strings, "\n"),
+		Links:    doc.cids(),
+	}, nil
+}
+
+// queueIPLDLinks 将链接加入 hashes 队列，以其在文档中的路径作为引用名称，并写入边存储。
+func (c *Crawler) queueIPLDLinks(ctx context.Context, r *t.AnnotatedResource, links []ipldLink) error {
+	edges := make([]indexTypes.ReferenceEdge, 0, len(links))
+
+	// 与目录条目相同，同一文档中的链接使用相同的随机优先级。
+	priority := uint8(1 + rand.Intn(7))
+	now := time.Now().Truncate(time.Second)
+
+	for _, l := range links {
+		entry := &t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       l.CID,
+			},
+			Source: t.DirectorySource,
+			Reference: t.Reference{
+				Parent: r.Resource,
+				Name:   l.Path,
+			},
+			Providers: r.Providers, // 文档的提供者很可能也提供其链接的内容。
+		}
+
+		if err := c.queues.Hashes.Publish(ctx, entry, priority); err != nil {
+			return err
+		}
+
+		if edge, ok := referenceEdge(entry, now); ok {
+			edges = append(edges, edge)
+		}
+	}
+
+	return c.addEdges(ctx, edges...)
+}
@@ -0,0 +1,113 @@
+package crawler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	index_types "github.com/ipfs-search/ipfs-search/components/index/types"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// referenceLimits 返回文档中存储引用的上限。
+func (c *Crawler) referenceLimits() index_types.ReferenceLimits {
+	return index_types.ReferenceLimits{
+		Max:      c.config.MaxReferences,
+		MaxNames: c.config.MaxReferenceNames,
+	}
+}
+
+// referencesFull 返回已存储的引用是否已达到上限。
+func (c *Crawler) referencesFull(refs index_types.References) bool {
+	return c.referenceLimits().Full(refs)
+}
+
+// referenceEdgeID 返回引用边的确定性 ID，使重复的引用映射到同一文档。
+func referenceEdgeID(parent, name, child string) string {
+	h := sha256.New()
+	for _, s := range []string{parent, name, child} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// addOverflowReference 处理超出 MaxReferences 的引用：完整的边写入引用索引，
+// 文档中仅更新引用计数和采样的名称。首次超出上限时，已存储的引用也写入引用索引，
+// 因此引用索引包含超出上限的文档的所有边。
+// 边的 ID 是确定的，部分失败后重试时已写入的边不视为错误。
+// 没有引用索引时无法识别重复的引用，计数可能偏高；并发更新时计数为近似值。
+func (c *Crawler) addOverflowReference(ctx context.Context, i *existingItem) error {
+	r := &i.AnnotatedResource.Reference
+
+	if c.indexes.References != nil {
+		id := referenceEdgeID(r.Parent.ID, r.Name, i.AnnotatedResource.ID)
+
+		found, err := c.indexes.References.Get(ctx, id, &index_types.ReferenceEdge{})
+		if err != nil {
+			return err
+		}
+
+		if found {
+			// 已记录的引用
+			return nil
+		}
+
+		if i.ReferenceCount == nil || *i.ReferenceCount <= uint64(len(i.References)) {
+			// 首次超出上限
+			if err := c.indexStoredReferences(ctx, i); err != nil {
+				return err
+			}
+		}
+
+		edge := &index_types.ReferenceEdge{
+			ParentHash: r.Parent.ID,
+			ChildHash:  i.AnnotatedResource.ID,
+			Name:       r.Name,
+			FirstSeen:  time.Now().Truncate(time.Second),
+		}
+
+		if err := indexOnce(ctx, c.indexes.References, id, edge); err != nil {
+			return err
+		}
+	}
+
+	count, names := c.referenceLimits().Overflow(i.References, i.ReferenceCount, i.ReferenceNames, r.Name)
+
+	return i.Index.Update(ctx, i.AnnotatedResource.ID, &index_types.Update{
+		ReferenceCount: &count,
+		ReferenceNames: names,
+	})
+}
+
+// indexStoredReferences 将文档中已存储的引用写入引用索引。
+func (c *Crawler) indexStoredReferences(ctx context.Context, i *existingItem) error {
+	now := time.Now().Truncate(time.Second)
+
+	for _, ref := range i.References {
+		id := referenceEdgeID(ref.ParentHash, ref.Name, i.AnnotatedResource.ID)
+
+		edge := &index_types.ReferenceEdge{
+			ParentHash: ref.ParentHash,
+			ChildHash:  i.AnnotatedResource.ID,
+			Name:       ref.Name,
+			FirstSeen:  now,
+		}
+
+		if err := indexOnce(ctx, c.indexes.References, id, edge); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasReference 返回 refs 中是否已包含引用 r。
+func hasReference(refs index_types.References, r *t.Reference) bool {
+	return refs.Contains(index_types.Reference{
+		ParentHash: r.Parent.ID,
+		Name:       r.Name,
+	})
+}
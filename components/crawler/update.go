@@ -2,6 +2,7 @@ package crawler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/ipfs-search/ipfs-search/components/index"
 	index_types "github.com/ipfs-search/ipfs-search/components/index/types"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
@@ -36,6 +38,24 @@ func appendReference(refs index_types.References, r *t.Reference) (index_types.R
 	}), true
 }
 
+// addReference 添加新引用：索引支持时在服务器端原子地追加，否则写入完整的引用列表 refs。
+func (c *Crawler) addReference(ctx context.Context, i *existingItem, refs index_types.References) error {
+	ref := index_types.Reference{
+		ParentHash: i.AnnotatedResource.Reference.Parent.ID,
+		Name:       i.AnnotatedResource.Reference.Name,
+	}
+
+	err := index.AppendReference(ctx, i.Index, i.AnnotatedResource.ID, ref)
+	if !errors.Is(err, index.ErrNotSupported) {
+		return err
+	}
+
+	// 并发更新时可能丢失引用。
+	return i.Index.Update(ctx, i.AnnotatedResource.ID, &index_types.Update{
+		References: refs,
+	})
+}
+
 // updateExisting 更新已知存在的项目
 func (c *Crawler) updateExisting(ctx context.Context, i *existingItem) error {
 	ctx, span := c.Tracer.Start(ctx, "crawler.updateExisting")
@@ -44,6 +64,23 @@ func (c *Crawler) updateExisting(ctx context.Context, i *existingItem) error {
 	switch i.Source {
 	case t.DirectorySource:
 		// 从目录引用的Item, 考虑更新引用（但不更新最后访问时间）
+		if edge, ok := referenceEdge(i.AnnotatedResource, time.Now().Truncate(time.Second)); ok {
+			if err := c.addEdges(ctx, edge); err != nil {
+				return err
+			}
+		}
+
+		ref := &i.AnnotatedResource.Reference
+		if ref.Parent != nil && c.referencesFull(i.References) && !hasReference(i.References, ref) {
+			span.AddEvent("Updating",
+				trace.WithAttributes(
+					attribute.String("reason", "reference-overflow"),
+					attribute.Stringer("new-reference", ref),
+				))
+
+			return c.addOverflowReference(ctx, i)
+		}
+
 		refs, refsUpdated := appendReference(i.References, &i.AnnotatedResource.Reference)
 
 		if refsUpdated {
@@ -53,9 +90,7 @@ func (c *Crawler) updateExisting(ctx context.Context, i *existingItem) error {
 					attribute.Stringer("new-reference", &i.AnnotatedResource.Reference),
 				))
 
-			return i.Index.Update(ctx, i.AnnotatedResource.ID, &index_types.Update{
-				References: refs,
-			})
+			return c.addReference(ctx, i, refs)
 		}
 
 	case t.SnifferSource, t.UnknownSource:
@@ -162,3 +197,20 @@ func (c *Crawler) updateMaybeExisting(ctx context.Context, r *t.AnnotatedResourc
 
 	return false, nil
 }
+
+// updateConcurrent 在写入新文档因文档已存在而失败时调用：另一个工作进程同时爬取了该资源（热门 CID 常见），
+// 因此重新读取已有文档并更新，以免丢失资源携带的引用。仍未找到文档时返回临时错误，以便重新投递。
+func (c *Crawler) updateConcurrent(ctx context.Context, r *t.AnnotatedResource) error {
+	log.Printf("Updating %v, indexed concurrently", r)
+
+	exists, err := c.updateMaybeExisting(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: %v exists but was not found", index.ErrTemporary, r)
+	}
+
+	return nil
+}
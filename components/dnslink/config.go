@@ -0,0 +1,18 @@
+package dnslink
+
+import (
+	"time"
+)
+
+// Config configures DNSLink resolution.
+type Config struct {
+	Server  string        // DNS server (host:port) to query TXT records from; the system resolver when empty.
+	Timeout time.Duration // Timeout for DNS queries.
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Timeout: 10 * time.Second,
+	}
+}
@@ -0,0 +1,171 @@
+// Package dnslink resolves domains to IPFS content through DNSLink TXT records.
+// Ref: https://dnslink.dev/
+package dnslink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const (
+	recordPrefix    = "dnslink="
+	subdomainPrefix = "_dnslink."
+)
+
+// IsDomain returns whether name is a domain name, rather than a CID or IPNS key; the latter never contain dots.
+func IsDomain(name string) bool {
+	return strings.Contains(name, ".")
+}
+
+// Resolver resolves DNSLink domains by querying DNS directly.
+type Resolver struct {
+	cfg      *Config
+	resolver *net.Resolver
+}
+
+// New returns a Resolver querying the configured DNS server or, when not set, the system resolver.
+func New(cfg *Config) *Resolver {
+	resolver := net.DefaultResolver
+
+	if cfg.Server != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, cfg.Server)
+			},
+		}
+	}
+
+	return &Resolver{
+		cfg:      cfg,
+		resolver: resolver,
+	}
+}
+
+// Resolve returns the resource the DNSLink record of the domain r points to: IPFS content, an IPNS name or
+// another DNSLink domain. Records on _dnslink.<domain> take precedence over records on the domain itself.
+// Paths into IPFS content can't be resolved through DNS and are considered invalid.
+func (r *Resolver) Resolve(ctx context.Context, resource *t.AnnotatedResource) (*t.Resource, error) {
+	if resource.Protocol != t.DNSLinkProtocol {
+		// Resolving anything but domains is a programming error.
+		panic(fmt.Sprintf("cannot resolve %v", resource))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	defer cancel()
+
+	domain := strings.TrimSuffix(resource.ID, ".")
+
+	for _, name := range []string{subdomainPrefix + domain + ".", domain + "."} {
+		link, err := r.lookup(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if link != "" {
+			return parseLink(link)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no DNSLink record for %s", t.ErrInvalidResource, domain)
+}
+
+// lookup returns the value of the first DNSLink record of name, in lexicographic order, or "" when there
+// is none.
+func (r *Resolver) lookup(ctx context.Context, name string) (string, error) {
+	records, err := r.resolver.LookupTXT(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	var links []string
+
+	for _, record := range records {
+		if strings.HasPrefix(record, recordPrefix) {
+			links = append(links, strings.TrimSpace(strings.TrimPrefix(record, recordPrefix)))
+		}
+	}
+
+	if len(links) == 0 {
+		return "", nil
+	}
+
+	sort.Strings(links)
+
+	return links[0], nil
+}
+
+// parseLink returns the resource of a DNSLink value like /ipfs/<cid>, /ipns/<name> or /ipns/<domain>.
+func parseLink(link string) (*t.Resource, error) {
+	parts := strings.Split(strings.TrimPrefix(link, "/"), "/")
+	if len(parts) < 2 || parts[1] == "" {
+		return nil, fmt.Errorf("%w: invalid DNSLink %s", t.ErrInvalidResource, link)
+	}
+
+	if len(parts) > 2 && strings.Join(parts[2:], "") != "" {
+		return nil, fmt.Errorf("%w: unsupported DNSLink to a path %s", t.ErrInvalidResource, link)
+	}
+
+	id := parts[1]
+
+	switch parts[0] {
+	case "ipfs":
+		return &t.Resource{Protocol: t.IPFSProtocol, ID: id}, nil
+	case "ipns":
+		if IsDomain(id) {
+			return &t.Resource{Protocol: t.DNSLinkProtocol, ID: id}, nil
+		}
+
+		return &t.Resource{Protocol: t.IPNSProtocol, ID: id}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported DNSLink %s", t.ErrInvalidResource, link)
+	}
+}
+
+// Protocol wraps a protocol, resolving DNSLink domains through a Resolver and other names through the
+// wrapped protocol.
+type Protocol struct {
+	protocol.Protocol
+	resolver *Resolver
+}
+
+// Wrap returns p, resolving DNSLink domains through r.
+func (r *Resolver) Wrap(p protocol.Protocol) *Protocol {
+	return &Protocol{
+		Protocol: p,
+		resolver: r,
+	}
+}
+
+// Resolve resolves DNSLink domains through DNS, other names through the wrapped protocol when it implements
+// protocol.Resolver.
+func (p *Protocol) Resolve(ctx context.Context, r *t.AnnotatedResource) (*t.Resource, error) {
+	if r.Protocol == t.DNSLinkProtocol {
+		return p.resolver.Resolve(ctx, r)
+	}
+
+	if resolver, ok := p.Protocol.(protocol.Resolver); ok {
+		return resolver.Resolve(ctx, r)
+	}
+
+	return nil, protocol.ErrNotResolver
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ protocol.Protocol = &Protocol{}
+	_ protocol.Resolver = &Protocol{}
+)
@@ -0,0 +1,214 @@
+package dnslink
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// stubServer is a DNS server answering TXT queries from records, NXDOMAIN for unknown names.
+type stubServer struct {
+	conn    net.PacketConn
+	records map[string][]string
+}
+
+func newStubServer(records map[string][]string) (*stubServer, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &stubServer{conn, records}
+	go s.serve()
+
+	return s, nil
+}
+
+func (s *stubServer) addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *stubServer) serve() {
+	buf := make([]byte, 512)
+
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		if resp, err := s.answer(buf[:n]); err == nil {
+			s.conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+func (s *stubServer) answer(query []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+
+	h, err := p.Start(query)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	records, ok := s.records[strings.ToLower(q.Name.String())]
+
+	h.Response = true
+	h.Authoritative = true
+	h.RCode = dnsmessage.RCodeSuccess
+
+	if !ok {
+		h.RCode = dnsmessage.RCodeNameError
+	}
+
+	b := dnsmessage.NewBuilder(nil, h)
+	b.EnableCompression()
+
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	if q.Type == dnsmessage.TypeTXT {
+		for _, r := range records {
+			err := b.TXTResource(dnsmessage.ResourceHeader{
+				Name:  q.Name,
+				Class: dnsmessage.ClassINET,
+				TTL:   60,
+			}, dnsmessage.TXTResource{TXT: []string{r}})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return b.Finish()
+}
+
+func (s *stubServer) close() {
+	s.conn.Close()
+}
+
+type ResolverTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	server *stubServer
+	r      *Resolver
+}
+
+func (s *ResolverTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	var err error
+
+	s.server, err = newStubServer(map[string][]string{
+		"_dnslink.example.com.": {"v=spf1 -all", "dnslink=/ipfs/QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"},
+		"legacy.example.com.":   {"dnslink=/ipfs/QmPZ9gcCEpqKTo6aq61g2nXGUhM4iCL3ewB6LDXZCtioEB"},
+		"_dnslink.multi.com.": {
+			"dnslink=/ipfs/QmZ",
+			"dnslink=/ipfs/QmA",
+		},
+		"_dnslink.name.com.":  {"dnslink=/ipns/k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8"},
+		"_dnslink.alias.com.": {"dnslink=/ipns/example.com"},
+		"_dnslink.path.com.":  {"dnslink=/ipfs/QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv/docs"},
+		"nolink.com.":         {"v=spf1 -all"},
+	})
+	s.Require().NoError(err)
+
+	cfg := DefaultConfig()
+	cfg.Server = s.server.addr()
+
+	s.r = New(cfg)
+}
+
+func (s *ResolverTestSuite) TearDownTest() {
+	s.server.close()
+}
+
+func (s *ResolverTestSuite) resolve(domain string) (*t.Resource, error) {
+	return s.r.Resolve(s.ctx, &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.DNSLinkProtocol,
+			ID:       domain,
+		},
+	})
+}
+
+func (s *ResolverTestSuite) TestResolve() {
+	target, err := s.resolve("example.com")
+
+	s.NoError(err)
+	s.Equal(&t.Resource{Protocol: t.IPFSProtocol, ID: "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}, target)
+}
+
+func (s *ResolverTestSuite) TestResolveLegacy() {
+	target, err := s.resolve("legacy.example.com")
+
+	s.NoError(err)
+	s.Equal("QmPZ9gcCEpqKTo6aq61g2nXGUhM4iCL3ewB6LDXZCtioEB", target.ID)
+}
+
+func (s *ResolverTestSuite) TestResolveMultiple() {
+	target, err := s.resolve("multi.com")
+
+	s.NoError(err)
+	s.Equal("QmA", target.ID)
+}
+
+func (s *ResolverTestSuite) TestResolveNames() {
+	target, err := s.resolve("name.com")
+
+	s.NoError(err)
+	s.Equal(&t.Resource{Protocol: t.IPNSProtocol, ID: "k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8"}, target)
+
+	target, err = s.resolve("alias.com")
+
+	s.NoError(err)
+	s.Equal(&t.Resource{Protocol: t.DNSLinkProtocol, ID: "example.com"}, target)
+}
+
+func (s *ResolverTestSuite) TestResolveInvalid() {
+	for _, domain := range []string{"path.com", "nolink.com", "unknown.com"} {
+		_, err := s.resolve(domain)
+
+		s.ErrorIs(err, t.ErrInvalidResource, domain)
+	}
+}
+
+func (s *ResolverTestSuite) TestWrap() {
+	p := s.r.Wrap(&protocol.Mock{})
+
+	target, err := p.Resolve(s.ctx, &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.DNSLinkProtocol,
+			ID:       "example.com",
+		},
+	})
+
+	s.NoError(err)
+	s.Equal("QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv", target.ID)
+}
+
+func TestResolverTestSuite(t *testing.T) {
+	suite.Run(t, new(ResolverTestSuite))
+}
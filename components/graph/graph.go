@@ -0,0 +1,26 @@
+// Package graph provides a store for parent to child edges of the DAG, as found in directory listings,
+// and walks over it to find ancestors, descendants and full paths of resources.
+package graph
+
+import (
+	"context"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// Parents finds edges to parents of a child.
+type Parents interface {
+	// Parents returns at most limit edges with child as ChildHash.
+	Parents(ctx context.Context, child string, limit int) ([]indexTypes.ReferenceEdge, error)
+}
+
+// Store stores and queries edges between parents and children.
+type Store interface {
+	Parents
+
+	// AddEdges adds edges to the store; existing edges are ignored.
+	AddEdges(ctx context.Context, edges ...indexTypes.ReferenceEdge) error
+
+	// Children returns at most limit edges with parent as ParentHash.
+	Children(ctx context.Context, parent string, limit int) ([]indexTypes.ReferenceEdge, error)
+}
@@ -0,0 +1,35 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// Mock mocks the Store interface.
+type Mock struct {
+	mock.Mock
+}
+
+// AddEdges mocks the AddEdges method on the Store interface.
+func (m *Mock) AddEdges(ctx context.Context, edges ...indexTypes.ReferenceEdge) error {
+	args := m.Called(ctx, edges)
+	return args.Error(0)
+}
+
+// Parents mocks the Parents method on the Store interface.
+func (m *Mock) Parents(ctx context.Context, child string, limit int) ([]indexTypes.ReferenceEdge, error) {
+	args := m.Called(ctx, child, limit)
+	return args.Get(0).([]indexTypes.ReferenceEdge), args.Error(1)
+}
+
+// Children mocks the Children method on the Store interface.
+func (m *Mock) Children(ctx context.Context, parent string, limit int) ([]indexTypes.ReferenceEdge, error) {
+	args := m.Called(ctx, parent, limit)
+	return args.Get(0).([]indexTypes.ReferenceEdge), args.Error(1)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ Store = &Mock{}
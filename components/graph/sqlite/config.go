@@ -0,0 +1,11 @@
+package sqlite
+
+// Config contains configuration for the SQLite edge store.
+type Config struct {
+	Path string // Path of the database file. When empty, an in-memory database is used.
+}
+
+// DefaultConfig returns the default configuration for the SQLite edge store.
+func DefaultConfig() *Config {
+	return &Config{}
+}
@@ -0,0 +1,145 @@
+// Package sqlite implements an edge store in an embedded SQLite database.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	// Pure Go SQLite driver.
+	_ "modernc.org/sqlite"
+
+	"github.com/ipfs-search/ipfs-search/components/graph"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS edges (
+	parent TEXT NOT NULL,
+	name TEXT NOT NULL,
+	child TEXT NOT NULL,
+	first_seen INTEGER NOT NULL,
+	PRIMARY KEY (parent, name, child)
+) WITHOUT ROWID;
+CREATE INDEX IF NOT EXISTS edges_child ON edges (child);
+`
+
+// Store stores edges in a SQLite database.
+type Store struct {
+	cfg *Config
+	*instr.Instrumentation
+
+	db *sql.DB
+}
+
+// New opens the database, creating the edges table when required.
+func New(ctx context.Context, cfg *Config, i *instr.Instrumentation) (*Store, error) {
+	if cfg == nil {
+		panic("New Config cannot be nil.")
+	}
+
+	if i == nil {
+		panic("New Instrumentation cannot be nil.")
+	}
+
+	dsn := ":memory:"
+	if cfg.Path != "" {
+		dsn = "file:" + cfg.Path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Single writer; also keeps in-memory databases from being connection-local.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{
+		cfg:             cfg,
+		Instrumentation: i,
+		db:              db,
+	}, nil
+}
+
+// AddEdges adds edges in a single transaction; existing edges keep their first-seen time.
+func (s *Store) AddEdges(ctx context.Context, edges ...indexTypes.ReferenceEdge) error {
+	ctx, span := s.Tracer.Start(ctx, "graph.sqlite.AddEdges")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT OR IGNORE INTO edges (parent, name, child, first_seen) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range edges {
+		if _, err := stmt.ExecContext(ctx, e.ParentHash, e.Name, e.ChildHash, e.FirstSeen.Unix()); err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Parents returns at most limit edges to child.
+func (s *Store) Parents(ctx context.Context, child string, limit int) ([]indexTypes.ReferenceEdge, error) {
+	return s.query(ctx, `SELECT parent, name, child, first_seen FROM edges WHERE child = ? ORDER BY first_seen, parent, name LIMIT ?`, child, limit)
+}
+
+// Children returns at most limit edges from parent.
+func (s *Store) Children(ctx context.Context, parent string, limit int) ([]indexTypes.ReferenceEdge, error) {
+	return s.query(ctx, `SELECT parent, name, child, first_seen FROM edges WHERE parent = ? ORDER BY name, child LIMIT ?`, parent, limit)
+}
+
+func (s *Store) query(ctx context.Context, query string, id string, limit int) ([]indexTypes.ReferenceEdge, error) {
+	ctx, span := s.Tracer.Start(ctx, "graph.sqlite.query")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, query, id, limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []indexTypes.ReferenceEdge
+
+	for rows.Next() {
+		var (
+			e         indexTypes.ReferenceEdge
+			firstSeen int64
+		)
+
+		if err := rows.Scan(&e.ParentHash, &e.Name, &e.ChildHash, &firstSeen); err != nil {
+			return nil, err
+		}
+
+		e.FirstSeen = time.Unix(firstSeen, 0).UTC()
+		edges = append(edges, e)
+	}
+
+	return edges, rows.Err()
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ graph.Store = &Store{}
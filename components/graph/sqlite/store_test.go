@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type StoreTestSuite struct {
+	suite.Suite
+	ctx context.Context
+	now time.Time
+
+	s *Store
+}
+
+func (s *StoreTestSuite) SetupTest() {
+	var err error
+
+	s.ctx = context.Background()
+	s.now = time.Now().Truncate(time.Second).UTC()
+
+	// Empty path; in-memory database.
+	s.s, err = New(s.ctx, &Config{}, instr.New())
+	s.Require().NoError(err)
+}
+
+func (s *StoreTestSuite) TearDownTest() {
+	s.NoError(s.s.Close())
+}
+
+func (s *StoreTestSuite) TestAddEdges() {
+	edges := []indexTypes.ReferenceEdge{
+		{ParentHash: "QmRoot", ChildHash: "QmDir", Name: "dir", FirstSeen: s.now},
+		{ParentHash: "QmDir", ChildHash: "QmFile", Name: "b.pdf", FirstSeen: s.now},
+		{ParentHash: "QmDir", ChildHash: "QmFile", Name: "a.pdf", FirstSeen: s.now},
+	}
+
+	s.NoError(s.s.AddEdges(s.ctx, edges...))
+
+	children, err := s.s.Children(s.ctx, "QmDir", 10)
+	s.NoError(err)
+	s.Equal([]indexTypes.ReferenceEdge{edges[2], edges[1]}, children)
+
+	parents, err := s.s.Parents(s.ctx, "QmDir", 10)
+	s.NoError(err)
+	s.Equal(edges[:1], parents)
+}
+
+func (s *StoreTestSuite) TestAddExisting() {
+	edge := indexTypes.ReferenceEdge{ParentHash: "QmDir", ChildHash: "QmFile", Name: "a.pdf", FirstSeen: s.now}
+	s.NoError(s.s.AddEdges(s.ctx, edge))
+
+	later := edge
+	later.FirstSeen = s.now.Add(time.Hour)
+	s.NoError(s.s.AddEdges(s.ctx, later))
+
+	// First seen is kept.
+	parents, err := s.s.Parents(s.ctx, "QmFile", 10)
+	s.NoError(err)
+	s.Equal([]indexTypes.ReferenceEdge{edge}, parents)
+}
+
+func (s *StoreTestSuite) TestLimit() {
+	s.NoError(s.s.AddEdges(s.ctx,
+		indexTypes.ReferenceEdge{ParentHash: "QmDir", ChildHash: "QmA", Name: "a", FirstSeen: s.now},
+		indexTypes.ReferenceEdge{ParentHash: "QmDir", ChildHash: "QmB", Name: "b", FirstSeen: s.now},
+	))
+
+	children, err := s.s.Children(s.ctx, "QmDir", 1)
+	s.NoError(err)
+	s.Len(children, 1)
+}
+
+func (s *StoreTestSuite) TestNotFound() {
+	parents, err := s.s.Parents(s.ctx, "QmUnknown", 10)
+	s.NoError(err)
+	s.Empty(parents)
+}
+
+func TestStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(StoreTestSuite))
+}
@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"context"
+	"path"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// Direction determines whether a walk follows edges to parents or to children.
+type Direction int
+
+const (
+	// Ancestors walks from children to parents.
+	Ancestors Direction = iota
+	// Descendants walks from parents to children.
+	Descendants
+)
+
+// Step is an edge encountered in a walk, at the given depth (starting at 1).
+type Step struct {
+	indexTypes.ReferenceEdge
+	Depth int
+}
+
+// edges returns edges from id in the given direction.
+func edges(ctx context.Context, s Store, id string, dir Direction, limit int) ([]indexTypes.ReferenceEdge, error) {
+	if dir == Ancestors {
+		return s.Parents(ctx, id, limit)
+	}
+
+	return s.Children(ctx, id, limit)
+}
+
+// Walk returns the edges reachable from id in breadth-first order, up to depth levels.
+// At most limit edges are queried per resource; resources are visited once.
+func Walk(ctx context.Context, s Store, id string, dir Direction, depth, limit int) ([]Step, error) {
+	var steps []Step
+
+	visited := map[string]bool{id: true}
+	level := []string{id}
+
+	for d := 1; d <= depth && len(level) > 0; d++ {
+		var next []string
+
+		for _, current := range level {
+			found, err := edges(ctx, s, current, dir, limit)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, e := range found {
+				steps = append(steps, Step{ReferenceEdge: e, Depth: d})
+
+				other := e.ParentHash
+				if dir == Descendants {
+					other = e.ChildHash
+				}
+
+				if !visited[other] {
+					visited[other] = true
+					next = append(next, other)
+				}
+			}
+		}
+
+		level = next
+	}
+
+	return steps, nil
+}
+
+// Path is a full path to a resource, starting at Root and following Names.
+type Path struct {
+	Root  string
+	Names []string
+}
+
+// String returns the path like `/ipfs/<root>/a/b/file.pdf`.
+func (p Path) String() string {
+	return path.Join(append([]string{"/ipfs", p.Root}, p.Names...)...)
+}
+
+// Paths returns up to limit full paths to id, following at most depth parents. Paths start at a resource
+// without known parents, or at the last parent within depth. When id has no known parents, its own path
+// is returned. Parents already on a path are skipped, so cycles are not followed.
+func Paths(ctx context.Context, s Parents, id string, depth, limit int) ([]Path, error) {
+	var (
+		paths  []Path
+		onPath = map[string]bool{}
+		walk   func(current string, names []string, d int) error
+	)
+
+	walk = func(current string, names []string, d int) error {
+		var parents []indexTypes.ReferenceEdge
+
+		if d < depth {
+			var err error
+			if parents, err = s.Parents(ctx, current, limit); err != nil {
+				return err
+			}
+		}
+
+		onPath[current] = true
+		defer delete(onPath, current)
+
+		found := false
+
+		for _, p := range parents {
+			if len(paths) >= limit {
+				return nil
+			}
+
+			if onPath[p.ParentHash] {
+				continue
+			}
+
+			found = true
+
+			if err := walk(p.ParentHash, append(names, p.Name), d+1); err != nil {
+				return err
+			}
+		}
+
+		if !found && len(paths) < limit {
+			p := Path{Root: current, Names: make([]string, len(names))}
+			for i, n := range names {
+				p.Names[len(names)-1-i] = n
+			}
+
+			paths = append(paths, p)
+		}
+
+		return nil
+	}
+
+	if err := walk(id, nil, 0); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
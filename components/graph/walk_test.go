@@ -0,0 +1,119 @@
+package graph_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/graph"
+	"github.com/ipfs-search/ipfs-search/components/graph/sqlite"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type WalkTestSuite struct {
+	suite.Suite
+	ctx context.Context
+
+	s *sqlite.Store
+}
+
+func (s *WalkTestSuite) SetupTest() {
+	var err error
+
+	s.ctx = context.Background()
+
+	s.s, err = sqlite.New(s.ctx, &sqlite.Config{}, instr.New())
+	s.Require().NoError(err)
+
+	// QmRoot/a/b/file.pdf and QmOther/file.pdf
+	now := time.Now()
+	s.Require().NoError(s.s.AddEdges(s.ctx,
+		indexTypes.ReferenceEdge{ParentHash: "QmRoot", ChildHash: "QmA", Name: "a", FirstSeen: now},
+		indexTypes.ReferenceEdge{ParentHash: "QmA", ChildHash: "QmB", Name: "b", FirstSeen: now},
+		indexTypes.ReferenceEdge{ParentHash: "QmB", ChildHash: "QmFile", Name: "file.pdf", FirstSeen: now},
+		indexTypes.ReferenceEdge{ParentHash: "QmOther", ChildHash: "QmFile", Name: "file.pdf", FirstSeen: now.Add(time.Second)},
+	))
+}
+
+func (s *WalkTestSuite) TearDownTest() {
+	s.NoError(s.s.Close())
+}
+
+func pathStrings(paths []graph.Path) []string {
+	var strs []string
+	for _, p := range paths {
+		strs = append(strs, p.String())
+	}
+
+	return strs
+}
+
+func (s *WalkTestSuite) TestPaths() {
+	paths, err := graph.Paths(s.ctx, s.s, "QmFile", 10, 10)
+	s.NoError(err)
+	s.Equal([]string{"/ipfs/QmRoot/a/b/file.pdf", "/ipfs/QmOther/file.pdf"}, pathStrings(paths))
+	s.Equal(graph.Path{Root: "QmRoot", Names: []string{"a", "b", "file.pdf"}}, paths[0])
+}
+
+func (s *WalkTestSuite) TestPathsDepth() {
+	paths, err := graph.Paths(s.ctx, s.s, "QmFile", 2, 10)
+	s.NoError(err)
+	s.Equal([]string{"/ipfs/QmA/b/file.pdf", "/ipfs/QmOther/file.pdf"}, pathStrings(paths))
+}
+
+func (s *WalkTestSuite) TestPathsLimit() {
+	paths, err := graph.Paths(s.ctx, s.s, "QmFile", 10, 1)
+	s.NoError(err)
+	s.Equal([]string{"/ipfs/QmRoot/a/b/file.pdf"}, pathStrings(paths))
+}
+
+func (s *WalkTestSuite) TestPathsUnreferenced() {
+	paths, err := graph.Paths(s.ctx, s.s, "QmRoot", 10, 10)
+	s.NoError(err)
+	s.Equal([]string{"/ipfs/QmRoot"}, pathStrings(paths))
+}
+
+func (s *WalkTestSuite) TestPathsCycle() {
+	// QmRoot/a/b/loop -> QmA
+	s.Require().NoError(s.s.AddEdges(s.ctx,
+		indexTypes.ReferenceEdge{ParentHash: "QmB", ChildHash: "QmRoot", Name: "loop", FirstSeen: time.Now()},
+	))
+
+	paths, err := graph.Paths(s.ctx, s.s, "QmB", 10, 10)
+	s.NoError(err)
+	// The cycle back to QmB is not followed.
+	s.Equal([]string{"/ipfs/QmRoot/a/b"}, pathStrings(paths))
+}
+
+func (s *WalkTestSuite) TestAncestors() {
+	steps, err := graph.Walk(s.ctx, s.s, "QmFile", graph.Ancestors, 2, 10)
+	s.NoError(err)
+
+	var parents []string
+	for _, step := range steps {
+		parents = append(parents, step.ParentHash)
+	}
+
+	s.Equal([]string{"QmB", "QmOther", "QmA"}, parents)
+	s.Equal(2, steps[2].Depth)
+}
+
+func (s *WalkTestSuite) TestDescendants() {
+	steps, err := graph.Walk(s.ctx, s.s, "QmRoot", graph.Descendants, 10, 10)
+	s.NoError(err)
+
+	var children []string
+	for _, step := range steps {
+		children = append(children, step.ChildHash)
+	}
+
+	s.Equal([]string{"QmA", "QmB", "QmFile"}, children)
+	s.Equal(3, steps[2].Depth)
+}
+
+func TestWalkTestSuite(t *testing.T) {
+	suite.Run(t, new(WalkTestSuite))
+}
@@ -0,0 +1,62 @@
+package index
+
+import (
+	"context"
+
+	"github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// ReferenceAppender is optionally implemented by indexes which can atomically append a reference to a
+// document, only when the reference is not already present. This prevents lost updates when references
+// are concurrently added from multiple workers.
+type ReferenceAppender interface {
+	// AppendReference adds ref to the references of document id, unless it is already present. Wrapping
+	// indexes return ErrNotSupported when their wrapped index does not support appending references.
+	AppendReference(ctx context.Context, id string, ref types.Reference) error
+}
+
+// AppendReference atomically appends ref to the document with id in i, returning ErrNotSupported when i
+// does not implement ReferenceAppender.
+func AppendReference(ctx context.Context, i Index, id string, ref types.Reference) error {
+	a, ok := i.(ReferenceAppender)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	return a.AppendReference(ctx, id, ref)
+}
+
+// ReferencesAppender is optionally implemented by indexes which can atomically append several references to a
+// document in a single write, skipping those already present.
+type ReferencesAppender interface {
+	// AppendReferences adds refs to the references of document id, skipping those already present.
+	AppendReferences(ctx context.Context, id string, refs []types.Reference) error
+}
+
+// AppendReferences atomically appends refs to the document with id in i; in a single write when i implements
+// ReferencesAppender, with a write per reference otherwise. Returns ErrNotSupported when i implements neither.
+func AppendReferences(ctx context.Context, i Index, id string, refs []types.Reference) error {
+	if a, ok := i.(ReferencesAppender); ok {
+		return a.AppendReferences(ctx, id, refs)
+	}
+
+	if _, ok := i.(ReferenceAppender); !ok {
+		return ErrNotSupported
+	}
+
+	for _, ref := range refs {
+		if err := AppendReference(ctx, i, id, ref); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SupportsAppendReference returns whether references can be appended to documents in i.
+func SupportsAppendReference(i Index) bool {
+	_, single := i.(ReferenceAppender)
+	_, multiple := i.(ReferencesAppender)
+
+	return single || multiple
+}
@@ -0,0 +1,23 @@
+package attribution
+
+import (
+	"time"
+)
+
+// Config configures path resolution.
+type Config struct {
+	Interval   time.Duration // Resolve paths of documents with new references after this time.
+	MaxPending int           // Drop new documents when this many are pending.
+	MaxDepth   int           // Follow at most this many parents.
+	MaxPaths   int           // Store at most this many paths per document, also limiting parents per level.
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Interval:   time.Minute,
+		MaxPending: 100000,
+		MaxDepth:   32,
+		MaxPaths:   16,
+	}
+}
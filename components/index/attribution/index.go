@@ -0,0 +1,253 @@
+// Package attribution resolves full paths and root directories of documents from their references,
+// in the background.
+package attribution
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/graph"
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const debug bool = false
+
+// Index wraps a backing index, queueing documents which are written with references, or have references
+// appended, for path resolution. Work() periodically resolves the paths of queued documents by following
+// parents up to MaxDepth levels and updates the Roots and Paths of the documents.
+//
+// Pending documents are kept in memory; they are lost on exit and resolved again when another reference
+// is added.
+type Index struct {
+	cfg      *Config
+	backing  index.Index
+	parents  graph.Parents
+	counters counters
+
+	mu      sync.Mutex
+	pending map[string]struct{}
+
+	*instr.Instrumentation
+}
+
+// New returns a new attributing index, finding parents through parents. Paths are only resolved when
+// Work() is running.
+func New(backing index.Index, parents graph.Parents, cfg *Config, instr *instr.Instrumentation) *Index {
+	if backing == nil {
+		panic("attribution.New backing cannot be nil.")
+	}
+
+	if parents == nil {
+		panic("attribution.New parents cannot be nil.")
+	}
+
+	if cfg == nil {
+		panic("attribution.New Config cannot be nil.")
+	}
+
+	return &Index{
+		cfg:             cfg,
+		backing:         backing,
+		parents:         parents,
+		pending:         make(map[string]struct{}),
+		Instrumentation: instr,
+	}
+}
+
+// String returns the name of the index, for convenient logging.
+func (i *Index) String() string {
+	return fmt.Sprintf("'%s' attributed", i.backing)
+}
+
+// Stats returns a snapshot of the counters.
+func (i *Index) Stats() Stats {
+	return Stats{
+		Enqueued: i.counters.enqueued.Load(),
+		Dropped:  i.counters.dropped.Load(),
+		Resolved: i.counters.resolved.Load(),
+		Failed:   i.counters.failed.Load(),
+	}
+}
+
+// Work resolves paths of pending documents every interval, until the context is closed.
+func (i *Index) Work(ctx context.Context) error {
+	ticker := time.NewTicker(i.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			i.resolvePending(ctx)
+		}
+	}
+}
+
+// Flush resolves the paths of pending documents right away, e.g. before shutting down.
+func (i *Index) Flush(ctx context.Context) {
+	i.resolvePending(ctx)
+}
+
+// enqueue queues id for resolution.
+func (i *Index) enqueue(id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.pending[id]; ok {
+		return
+	}
+
+	if len(i.pending) >= i.cfg.MaxPending {
+		i.counters.dropped.Add(1)
+		return
+	}
+
+	i.pending[id] = struct{}{}
+	i.counters.enqueued.Add(1)
+}
+
+// resolvePending resolves the paths of all pending documents.
+func (i *Index) resolvePending(ctx context.Context) {
+	i.mu.Lock()
+	pending := i.pending
+	i.pending = make(map[string]struct{}, len(pending))
+	i.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	ctx, span := i.Tracer.Start(ctx, "index.attribution.resolvePending")
+	defer span.End()
+
+	for id := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := i.Resolve(ctx, id); err != nil {
+			i.counters.failed.Add(1)
+			log.Printf("attribution %s: error resolving paths of %s: %s", i, id, err)
+		}
+	}
+
+	if debug {
+		log.Printf("attribution %s: resolved %d documents, %+v", i, len(pending), i.Stats())
+	}
+}
+
+// Resolve resolves the paths of the document with id and writes them to the backing index.
+// Documents without known parents are not updated.
+func (i *Index) Resolve(ctx context.Context, id string) error {
+	paths, err := graph.Paths(ctx, i.parents, id, i.cfg.MaxDepth, i.cfg.MaxPaths)
+	if err != nil {
+		return err
+	}
+
+	u := new(indexTypes.Update)
+	roots := make(map[string]bool)
+
+	for _, p := range paths {
+		if len(p.Names) == 0 {
+			// No parents.
+			continue
+		}
+
+		u.Paths = append(u.Paths, p.String())
+
+		if !roots[p.Root] {
+			roots[p.Root] = true
+			u.Roots = append(u.Roots, p.Root)
+		}
+	}
+
+	if len(u.Paths) == 0 {
+		return nil
+	}
+
+	if err := i.backing.Update(ctx, id, u); err != nil {
+		return err
+	}
+
+	i.counters.resolved.Add(1)
+
+	return nil
+}
+
+// hasReferences returns whether properties contain references.
+func hasReferences(properties interface{}) bool {
+	switch p := properties.(type) {
+	case *indexTypes.File:
+		return len(p.References) > 0
+	case *indexTypes.Directory:
+		return len(p.References) > 0
+	case *indexTypes.Update:
+		return len(p.References) > 0
+	default:
+		return false
+	}
+}
+
+// Index a document's properties, identified by id. Documents with references are queued for resolution.
+func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
+	if err := i.backing.Index(ctx, id, properties); err != nil {
+		return err
+	}
+
+	if hasReferences(properties) {
+		i.enqueue(id)
+	}
+
+	return nil
+}
+
+// Update a document's properties, given id. Documents with new references are queued for resolution.
+func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
+	if err := i.backing.Update(ctx, id, properties); err != nil {
+		return err
+	}
+
+	if hasReferences(properties) {
+		i.enqueue(id)
+	}
+
+	return nil
+}
+
+// AppendReference appends the reference in the backing index and queues the document for resolution.
+// Returns index.ErrNotSupported when the backing index does not support appending references.
+func (i *Index) AppendReference(ctx context.Context, id string, ref indexTypes.Reference) error {
+	if err := index.AppendReference(ctx, i.backing, id, ref); err != nil {
+		return err
+	}
+
+	i.enqueue(id)
+
+	return nil
+}
+
+// Delete item from index, dropping it from pending documents.
+func (i *Index) Delete(ctx context.Context, id string) error {
+	i.mu.Lock()
+	delete(i.pending, id)
+	i.mu.Unlock()
+
+	return i.backing.Delete(ctx, id)
+}
+
+// Get retreives `fields` from document with `id` from the backing index.
+func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	return i.backing.Get(ctx, id, dst, fields...)
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ index.Index             = &Index{}
+	_ index.ReferenceAppender = &Index{}
+)
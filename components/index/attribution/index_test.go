@@ -0,0 +1,161 @@
+package attribution
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/graph"
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testID = "QmFile"
+
+var noEdges []indexTypes.ReferenceEdge
+
+type AttributionTestSuite struct {
+	suite.Suite
+	ctx context.Context
+
+	backing *index.Mock
+	parents *graph.Mock
+	i       *Index
+}
+
+func (s *AttributionTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.backing = &index.Mock{}
+	s.backing.Test(s.T())
+
+	s.parents = &graph.Mock{}
+	s.parents.Test(s.T())
+
+	s.i = New(s.backing, s.parents, &Config{Interval: time.Hour, MaxPending: 2, MaxDepth: 10, MaxPaths: 10}, instr.New())
+}
+
+func (s *AttributionTestSuite) TearDownTest() {
+	s.backing.AssertExpectations(s.T())
+	s.parents.AssertExpectations(s.T())
+}
+
+func (s *AttributionTestSuite) edge(parent, name, child string) indexTypes.ReferenceEdge {
+	return indexTypes.ReferenceEdge{ParentHash: parent, Name: name, ChildHash: child}
+}
+
+func (s *AttributionTestSuite) TestIndexResolves() {
+	f := &indexTypes.File{
+		Document: indexTypes.Document{
+			References: indexTypes.References{{ParentHash: "QmDir", Name: "file.pdf"}},
+		},
+	}
+
+	s.backing.On("Index", mock.Anything, testID, f).Return(nil).Once()
+	s.NoError(s.i.Index(s.ctx, testID, f))
+
+	s.parents.On("Parents", mock.Anything, testID, 10).Return([]indexTypes.ReferenceEdge{
+		s.edge("QmDir", "file.pdf", testID),
+		s.edge("QmOther", "copy.pdf", testID),
+	}, nil).Once()
+	s.parents.On("Parents", mock.Anything, "QmDir", 10).Return([]indexTypes.ReferenceEdge{
+		s.edge("QmRoot", "dir", "QmDir"),
+	}, nil).Once()
+	s.parents.On("Parents", mock.Anything, "QmRoot", 10).Return(noEdges, nil).Once()
+	s.parents.On("Parents", mock.Anything, "QmOther", 10).Return(noEdges, nil).Once()
+
+	s.backing.On("Update", mock.Anything, testID, &indexTypes.Update{
+		Roots: []string{"QmRoot", "QmOther"},
+		Paths: []string{"/ipfs/QmRoot/dir/file.pdf", "/ipfs/QmOther/copy.pdf"},
+	}).Return(nil).Once()
+
+	s.i.resolvePending(s.ctx)
+
+	s.Equal(Stats{Enqueued: 1, Resolved: 1}, s.i.Stats())
+}
+
+func (s *AttributionTestSuite) TestIndexWithoutReferences() {
+	f := &indexTypes.File{}
+
+	s.backing.On("Index", mock.Anything, testID, f).Return(nil).Once()
+	s.NoError(s.i.Index(s.ctx, testID, f))
+
+	// Nothing pending.
+	s.i.resolvePending(s.ctx)
+	s.Equal(Stats{}, s.i.Stats())
+}
+
+func (s *AttributionTestSuite) TestUpdateLastSeen() {
+	u := &indexTypes.Update{LastSeen: &time.Time{}}
+
+	s.backing.On("Update", mock.Anything, testID, u).Return(nil).Once()
+	s.NoError(s.i.Update(s.ctx, testID, u))
+
+	s.Equal(Stats{}, s.i.Stats())
+}
+
+func (s *AttributionTestSuite) TestAppendReference() {
+	backing := &index.AppenderMock{}
+	s.i = New(backing, s.parents, s.i.cfg, instr.New())
+
+	ref := indexTypes.Reference{ParentHash: "QmDir", Name: "file.pdf"}
+	backing.On("AppendReference", mock.Anything, testID, ref).Return(nil).Once()
+
+	s.NoError(s.i.AppendReference(s.ctx, testID, ref))
+	s.Equal(Stats{Enqueued: 1}, s.i.Stats())
+
+	backing.AssertExpectations(s.T())
+}
+
+func (s *AttributionTestSuite) TestAppendReferenceNotSupported() {
+	err := s.i.AppendReference(s.ctx, testID, indexTypes.Reference{ParentHash: "QmDir", Name: "file.pdf"})
+	s.ErrorIs(err, index.ErrNotSupported)
+	s.Equal(Stats{}, s.i.Stats())
+}
+
+func (s *AttributionTestSuite) TestMaxPending() {
+	u := &indexTypes.Update{References: indexTypes.References{{ParentHash: "QmDir", Name: "file.pdf"}}}
+	s.backing.On("Update", mock.Anything, mock.Anything, u).Return(nil).Times(4)
+
+	for _, id := range []string{"a", "b", "b", "c"} {
+		s.NoError(s.i.Update(s.ctx, id, u))
+	}
+
+	s.Equal(Stats{Enqueued: 2, Dropped: 1}, s.i.Stats())
+}
+
+func (s *AttributionTestSuite) TestResolveUnreferenced() {
+	s.parents.On("Parents", mock.Anything, testID, 10).Return(noEdges, nil).Once()
+
+	// No update written.
+	s.NoError(s.i.Resolve(s.ctx, testID))
+}
+
+func (s *AttributionTestSuite) TestReferenceParents() {
+	files, dirs := &index.Mock{}, &index.Mock{}
+
+	files.On("Get", mock.Anything, testID, mock.Anything, []string{"references"}).
+		Run(func(args mock.Arguments) {
+			u := args.Get(2).(*indexTypes.Update)
+			u.References = indexTypes.References{
+				{ParentHash: "QmDir", Name: "file.pdf"},
+				{ParentHash: "QmOther", Name: "copy.pdf"},
+			}
+		}).
+		Return(true, nil).Once()
+	dirs.On("Get", mock.Anything, testID, mock.Anything, []string{"references"}).Return(false, nil).Maybe()
+
+	edges, err := ReferenceParents{files, dirs}.Parents(s.ctx, testID, 1)
+	s.NoError(err)
+	s.Equal([]indexTypes.ReferenceEdge{s.edge("QmDir", "file.pdf", testID)}, edges)
+
+	files.AssertExpectations(s.T())
+}
+
+func TestAttributionTestSuite(t *testing.T) {
+	suite.Run(t, new(AttributionTestSuite))
+}
@@ -0,0 +1,41 @@
+package attribution
+
+import (
+	"context"
+
+	"github.com/ipfs-search/ipfs-search/components/graph"
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// ReferenceParents finds parents from the references of documents in indexes.
+type ReferenceParents []index.Index
+
+// Parents returns at most limit edges from the references of the document with id child.
+func (p ReferenceParents) Parents(ctx context.Context, child string, limit int) ([]indexTypes.ReferenceEdge, error) {
+	u := new(indexTypes.Update)
+
+	i, err := index.MultiGet(ctx, p, child, u, "references")
+	if err != nil || i == nil {
+		return nil, err
+	}
+
+	refs := u.References
+	if len(refs) > limit {
+		refs = refs[:limit]
+	}
+
+	edges := make([]indexTypes.ReferenceEdge, len(refs))
+	for n, r := range refs {
+		edges[n] = indexTypes.ReferenceEdge{
+			ParentHash: r.ParentHash,
+			ChildHash:  child,
+			Name:       r.Name,
+		}
+	}
+
+	return edges, nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ graph.Parents = ReferenceParents{}
@@ -0,0 +1,21 @@
+package attribution
+
+import (
+	"sync/atomic"
+)
+
+// counters tracks path resolution.
+type counters struct {
+	enqueued atomic.Int64
+	dropped  atomic.Int64
+	resolved atomic.Int64
+	failed   atomic.Int64
+}
+
+// Stats is a snapshot of the counters of an attributing index.
+type Stats struct {
+	Enqueued int64 // Documents queued for resolution, when not already pending.
+	Dropped  int64 // Documents not queued as too many were pending.
+	Resolved int64 // Documents for which paths were written.
+	Failed   int64 // Documents for which resolution or writing failed.
+}
@@ -0,0 +1,95 @@
+package bleve
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	blevesearch "github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// Client manages embedded Bleve indexes stored in a single directory.
+type Client struct {
+	cfg *ClientConfig
+	*instr.Instrumentation
+
+	mutex   sync.Mutex
+	indexes []blevesearch.Index
+}
+
+// NewClient instantiates a new Bleve client.
+func NewClient(cfg *ClientConfig, i *instr.Instrumentation) (*Client, error) {
+	if cfg == nil {
+		panic("NewClient ClientConfig cannot be nil.")
+	}
+
+	if i == nil {
+		panic("NewClient Instrumentation cannot be nil.")
+	}
+
+	if cfg.Path != "" {
+		if err := os.MkdirAll(cfg.Path, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Client{
+		cfg:             cfg,
+		Instrumentation: i,
+	}, nil
+}
+
+// open opens the index with the given name, creating it with mapping m when it does not exist yet.
+func (c *Client) open(name string, m mapping.IndexMapping) (blevesearch.Index, error) {
+	if c.cfg.Path == "" {
+		return blevesearch.NewMemOnly(m)
+	}
+
+	path := filepath.Join(c.cfg.Path, name)
+
+	idx, err := blevesearch.Open(path)
+	if errors.Is(err, blevesearch.ErrorIndexPathDoesNotExist) {
+		log.Printf("Creating Bleve index %s at %s", name, path)
+		return blevesearch.New(path, m)
+	}
+
+	return idx, err
+}
+
+// NewIndex returns the index with the given name, using mapping m when creating it.
+func (c *Client) NewIndex(name string, m mapping.IndexMapping) (*Index, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	idx, err := c.open(name, m)
+	if err != nil {
+		return nil, err
+	}
+
+	c.indexes = append(c.indexes, idx)
+
+	return New(c, idx, &Config{Name: name}), nil
+}
+
+// Close closes all indexes opened by the client.
+func (c *Client) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var err error
+
+	for _, idx := range c.indexes {
+		if closeErr := idx.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+
+	c.indexes = nil
+
+	return err
+}
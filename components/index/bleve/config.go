@@ -0,0 +1,18 @@
+package bleve
+
+// ClientConfig contains configuration for the Bleve client.
+type ClientConfig struct {
+	Path string // Directory in which indexes are stored. When empty, indexes are kept in memory.
+}
+
+// DefaultClientConfig returns the default configuration for the Bleve client.
+func DefaultClientConfig() *ClientConfig {
+	return &ClientConfig{
+		Path: "bleve",
+	}
+}
+
+// Config holds configuration for a Bleve index.
+type Config struct {
+	Name string // Name of the index.
+}
@@ -0,0 +1,223 @@
+package bleve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	blevesearch "github.com/blevesearch/bleve/v2"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+const debug bool = false
+
+// sourcePrefix is prepended to document ID's to store the original JSON source as internal data.
+const sourcePrefix = "source:"
+
+// Index wraps an embedded Bleve index for storing documents.
+type Index struct {
+	cfg *Config
+	c   *Client
+	idx blevesearch.Index
+
+	// Serialises writes, so that Update's read-merge-write is atomic.
+	writeMutex sync.Mutex
+}
+
+// New returns a new index.
+func New(client *Client, idx blevesearch.Index, cfg *Config) *Index {
+	if client == nil {
+		panic("Index.New Client cannot be nil.")
+	}
+
+	if idx == nil {
+		panic("Index.New Bleve index cannot be nil.")
+	}
+
+	if cfg == nil {
+		panic("Index.New Config cannot be nil.")
+	}
+
+	return &Index{
+		cfg: cfg,
+		c:   client,
+		idx: idx,
+	}
+}
+
+// String returns the name of the index, for convenient logging.
+func (i *Index) String() string {
+	return i.cfg.Name
+}
+
+func sourceKey(id string) []byte {
+	return []byte(sourcePrefix + id)
+}
+
+// getSource returns the stored source for the document with id, or nil when not found.
+func (i *Index) getSource(id string) (map[string]interface{}, error) {
+	b, err := i.idx.GetInternal(sourceKey(id))
+	if err != nil || b == nil {
+		return nil, err
+	}
+
+	doc := make(map[string]interface{})
+	err = json.Unmarshal(b, &doc)
+
+	return doc, err
+}
+
+// write indexes doc and stores its source, atomically.
+func (i *Index) write(id string, doc map[string]interface{}) error {
+	source, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	b := i.idx.NewBatch()
+	b.SetInternal(sourceKey(id), source)
+
+	if err := b.Index(id, doc); err != nil {
+		return err
+	}
+
+	if debug {
+		log.Printf("bleve %s: writing %s", i, id)
+	}
+
+	return i.idx.Batch(b)
+}
+
+// Index a document's properties, identified by id.
+// Returns index.ErrDocumentExists when the document has already been indexed, like OpenSearch's `create`.
+// Hence, like with OpenSearch's sync writes, the second of concurrent crawls of a resource fails to index it; the
+// crawler then updates the existing document instead, so that its reference is not lost.
+func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.bleve.Index")
+	defer span.End()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	doc, err := index.ToDocument(properties)
+	if err != nil {
+		return err
+	}
+
+	i.writeMutex.Lock()
+	defer i.writeMutex.Unlock()
+
+	existing, err := i.getSource(id)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if existing != nil {
+		err = fmt.Errorf("%w: %s in %s", index.ErrDocumentExists, id, i)
+		span.RecordError(err)
+		return err
+	}
+
+	return i.write(id, doc)
+}
+
+// Update a document's properties, given id. Properties are merged with the existing document, like
+// OpenSearch's partial `doc` update.
+// Returns index.ErrDocumentMissing when the document does not exist.
+func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.bleve.Update")
+	defer span.End()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	update, err := index.ToDocument(properties)
+	if err != nil {
+		return err
+	}
+
+	i.writeMutex.Lock()
+	defer i.writeMutex.Unlock()
+
+	doc, err := i.getSource(id)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if doc == nil {
+		err = fmt.Errorf("%w: %s in %s", index.ErrDocumentMissing, id, i)
+		span.RecordError(err)
+		return err
+	}
+
+	index.MergeDocument(doc, update)
+
+	return i.write(id, doc)
+}
+
+// Delete item from index.
+func (i *Index) Delete(ctx context.Context, id string) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.bleve.Delete")
+	defer span.End()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if debug {
+		log.Printf("bleve %s: delete %s", i, id)
+	}
+
+	i.writeMutex.Lock()
+	defer i.writeMutex.Unlock()
+
+	b := i.idx.NewBatch()
+	b.Delete(id)
+	b.DeleteInternal(sourceKey(id))
+
+	return i.idx.Batch(b)
+}
+
+// Get retrieves `fields` from document with `id` from the index, returning:
+// - (true, decoding_error) if found (decoding error set when errors in json)
+// - (false, nil) when not found
+// - (false, error) otherwise
+func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	ctx, span := i.c.Tracer.Start(ctx, "index.bleve.Get")
+	defer span.End()
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	doc, err := i.getSource(id)
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+
+	if debug {
+		log.Printf("bleve %s: get %s, found: %v", i, id, doc != nil)
+	}
+
+	if doc == nil {
+		return false, nil
+	}
+
+	b, err := json.Marshal(index.SelectFields(doc, fields...))
+	if err != nil {
+		return true, err
+	}
+
+	return true, json.Unmarshal(b, dst)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ index.Index = &Index{}
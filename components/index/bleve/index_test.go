@@ -0,0 +1,263 @@
+package bleve
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testID = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+
+type IndexTestSuite struct {
+	suite.Suite
+	ctx context.Context
+	now time.Time
+
+	client *Client
+	files  *Index
+	dirs   *Index
+}
+
+func (s *IndexTestSuite) SetupTest() {
+	var err error
+
+	s.ctx = context.Background()
+	s.now = time.Now().Truncate(time.Second).UTC()
+
+	// Empty path; in-memory indexes.
+	s.client, err = NewClient(&ClientConfig{}, instr.New())
+	s.Require().NoError(err)
+
+	s.files, err = s.client.NewIndex("files", FilesMapping())
+	s.Require().NoError(err)
+
+	s.dirs, err = s.client.NewIndex("directories", DirectoriesMapping())
+	s.Require().NoError(err)
+}
+
+func (s *IndexTestSuite) TearDownTest() {
+	s.NoError(s.client.Close())
+}
+
+func (s *IndexTestSuite) testFile() *indexTypes.File {
+	return &indexTypes.File{
+		Document: indexTypes.Document{
+			FirstSeen: s.now,
+			LastSeen:  s.now,
+			References: indexTypes.References{
+				{ParentHash: "QmParent", Name: "holiday.jpg"},
+			},
+			Size: 15,
+		},
+		Content:  "the quick brown fox",
+		Metadata: indexTypes.Metadata{"title": []interface{}{"Fantastic voyage"}},
+	}
+}
+
+func (s *IndexTestSuite) TestString() {
+	s.Equal("files", s.files.String())
+}
+
+func (s *IndexTestSuite) TestDiskClient() {
+	dir := s.T().TempDir()
+
+	client, err := NewClient(&ClientConfig{Path: dir}, instr.New())
+	s.Require().NoError(err)
+
+	idx, err := client.NewIndex("files", FilesMapping())
+	s.Require().NoError(err)
+	s.NoError(idx.Index(s.ctx, testID, s.testFile()))
+	s.NoError(client.Close())
+
+	// Reopen existing index.
+	client, err = NewClient(&ClientConfig{Path: dir}, instr.New())
+	s.Require().NoError(err)
+
+	idx, err = client.NewIndex("files", FilesMapping())
+	s.Require().NoError(err)
+
+	dst := &indexTypes.File{}
+	found, err := idx.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.True(found)
+	s.Equal(s.testFile(), dst)
+
+	s.NoError(client.Close())
+}
+
+func (s *IndexTestSuite) TestIndexGet() {
+	f := s.testFile()
+
+	s.NoError(s.files.Index(s.ctx, testID, f))
+
+	dst := &indexTypes.File{}
+	found, err := s.files.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.True(found)
+	s.Equal(f, dst)
+}
+
+func (s *IndexTestSuite) TestIndexExisting() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+
+	err := s.files.Index(s.ctx, testID, s.testFile())
+	s.ErrorIs(err, index.ErrDocumentExists)
+}
+
+func (s *IndexTestSuite) TestGetFields() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+
+	dst := &indexTypes.File{}
+	found, err := s.files.Get(s.ctx, testID, dst, "references", "last-seen")
+	s.NoError(err)
+	s.True(found)
+
+	s.Equal(s.now, dst.LastSeen)
+	s.Equal(s.testFile().References, dst.References)
+	s.Empty(dst.Content)
+	s.Zero(dst.Size)
+}
+
+func (s *IndexTestSuite) TestGetNotFound() {
+	dst := &indexTypes.Update{}
+	found, err := s.files.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.False(found)
+}
+
+func (s *IndexTestSuite) TestGetCanceled() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	cancel()
+
+	found, err := s.files.Get(ctx, testID, &indexTypes.Update{})
+	s.ErrorIs(err, context.Canceled)
+	s.False(found)
+}
+
+func (s *IndexTestSuite) TestScan() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.NoError(s.files.Index(s.ctx, "QmOther", &indexTypes.File{Content: "a lazy dog"}))
+
+	var ids []string
+	sources := make(map[string]string)
+	err := s.files.Scan(s.ctx, func(id string, source json.RawMessage) error {
+		ids = append(ids, id)
+		sources[id] = string(source)
+		return nil
+	}, "size")
+
+	s.NoError(err)
+	s.Equal([]string{"QmOther", testID}, ids)
+	s.Equal(map[string]string{
+		testID:    `{"size":15}`,
+		"QmOther": `{"size":0}`,
+	}, sources)
+}
+
+func (s *IndexTestSuite) TestUpdate() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+
+	later := s.now.Add(time.Hour)
+	refs := append(s.testFile().References, indexTypes.Reference{ParentHash: "QmOther", Name: "beach.jpg"})
+
+	s.NoError(s.files.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &later}))
+	s.NoError(s.files.Update(s.ctx, testID, &indexTypes.Update{References: refs}))
+
+	dst := &indexTypes.File{}
+	found, err := s.files.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.True(found)
+
+	// Updated fields.
+	s.Equal(later, dst.LastSeen)
+	s.Equal(refs, dst.References)
+
+	// Other fields untouched.
+	s.Equal(s.now, dst.FirstSeen)
+	s.Equal("the quick brown fox", dst.Content)
+
+	// Updated references are searchable.
+	res, err := s.files.Search(s.ctx, "references.name:beach.jpg", 0, 10)
+	s.NoError(err)
+	s.Equal(uint64(1), res.Total)
+}
+
+func (s *IndexTestSuite) TestUpdateMissing() {
+	err := s.files.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &s.now})
+	s.ErrorIs(err, index.ErrDocumentMissing)
+}
+
+func (s *IndexTestSuite) TestDelete() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.NoError(s.files.Delete(s.ctx, testID))
+
+	found, err := s.files.Get(s.ctx, testID, &indexTypes.File{})
+	s.NoError(err)
+	s.False(found)
+
+	res, err := s.files.Search(s.ctx, "fox", 0, 10)
+	s.NoError(err)
+	s.Zero(res.Total)
+}
+
+func (s *IndexTestSuite) TestSearchFile() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+
+	for _, q := range []string{"fox", "content:brown", "references.name:holiday.jpg", "metadata.title:voyage", "references.parent_hash:QmParent"} {
+		res, err := s.files.Search(s.ctx, q, 0, 10)
+		s.NoError(err)
+		s.Require().Len(res.Hits, 1, q)
+		s.Equal(testID, res.Hits[0].ID)
+	}
+
+	res, err := s.files.Search(s.ctx, "elephant", 0, 10)
+	s.NoError(err)
+	s.Empty(res.Hits)
+}
+
+func (s *IndexTestSuite) TestSearchDirectory() {
+	d := &indexTypes.Directory{
+		Document: indexTypes.Document{
+			FirstSeen: s.now,
+			LastSeen:  s.now,
+		},
+		Links: indexTypes.Links{
+			{Hash: "QmChild", Name: "readme.md", Size: 4, Type: indexTypes.FileLinkType},
+		},
+	}
+
+	s.NoError(s.dirs.Index(s.ctx, testID, d))
+
+	res, err := s.dirs.Search(s.ctx, "links.Name:readme.md", 0, 10)
+	s.NoError(err)
+	s.Require().Len(res.Hits, 1)
+	s.Equal(testID, res.Hits[0].ID)
+}
+
+func (s *IndexTestSuite) TestInvalidsNotSearchable() {
+	invalids, err := s.client.NewIndex("invalids", InvalidsMapping())
+	s.Require().NoError(err)
+
+	s.NoError(invalids.Index(s.ctx, testID, &indexTypes.Invalid{Error: "unsupported type"}))
+
+	res, err := invalids.Search(s.ctx, "unsupported", 0, 10)
+	s.NoError(err)
+	s.Empty(res.Hits)
+
+	dst := &indexTypes.Invalid{}
+	found, err := invalids.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.True(found)
+	s.Equal("unsupported type", dst.Error)
+}
+
+func TestIndexTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexTestSuite))
+}
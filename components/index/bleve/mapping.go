@@ -0,0 +1,291 @@
+package bleve
+
+import (
+	"github.com/blevesearch/bleve/v2/mapping"
+)
+
+// Mappings below mirror the OpenSearch mappings in docs/indices; fields which are not explicitly
+// mapped are stored but not indexed.
+
+func newIndexMapping(doc *mapping.DocumentMapping) *mapping.IndexMappingImpl {
+	m := mapping.NewIndexMapping()
+	m.IndexDynamic = false
+	m.StoreDynamic = false
+	m.DocValuesDynamic = false
+	m.DefaultMapping = doc
+
+	return m
+}
+
+func textField() *mapping.FieldMapping {
+	f := mapping.NewTextFieldMapping()
+	f.Store = false
+	return f
+}
+
+func keywordField() *mapping.FieldMapping {
+	f := mapping.NewKeywordFieldMapping()
+	f.Store = false
+	return f
+}
+
+func numericField() *mapping.FieldMapping {
+	f := mapping.NewNumericFieldMapping()
+	f.Store = false
+	return f
+}
+
+func dateField() *mapping.FieldMapping {
+	f := mapping.NewDateTimeFieldMapping()
+	f.Store = false
+	return f
+}
+
+func addFields(m *mapping.DocumentMapping, fields map[string]*mapping.FieldMapping) {
+	for name, f := range fields {
+		m.AddFieldMappingsAt(name, f)
+	}
+}
+
+// documentMapping returns a mapping for the common fields of indexTypes.Document.
+func documentMapping() *mapping.DocumentMapping {
+	doc := mapping.NewDocumentStaticMapping()
+
+	addFields(doc, map[string]*mapping.FieldMapping{
+		"first-seen":      dateField(),
+		"last-seen":       dateField(),
+		"size":            numericField(),
+		"reference_count": numericField(),
+		"reference_names": textField(),
+		"roots":           keywordField(),
+		"paths":           keywordField(),
+	})
+
+	references := mapping.NewDocumentStaticMapping()
+	addFields(references, map[string]*mapping.FieldMapping{
+		"name":        textField(),
+		"parent_hash": keywordField(),
+	})
+	doc.AddSubDocumentMapping("references", references)
+
+	site := mapping.NewDocumentStaticMapping()
+	addFields(site, map[string]*mapping.FieldMapping{
+		"root":  keywordField(),
+		"title": textField(),
+		"links": keywordField(),
+		"rank":  numericField(),
+	})
+	doc.AddSubDocumentMapping("site", site)
+
+	return doc
+}
+
+// FilesMapping returns the mapping for an index of indexTypes.File.
+func FilesMapping() mapping.IndexMapping {
+	doc := documentMapping()
+
+	addFields(doc, map[string]*mapping.FieldMapping{
+		"content":           textField(),
+		"ipfs_tika_version": keywordField(),
+		"urls":              keywordField(),
+	})
+
+	language := mapping.NewDocumentStaticMapping()
+	addFields(language, map[string]*mapping.FieldMapping{
+		"confidence": keywordField(),
+		"language":   keywordField(),
+		"rawScore":   numericField(),
+	})
+	doc.AddSubDocumentMapping("language", language)
+
+	metadata := mapping.NewDocumentStaticMapping()
+	addFields(metadata, map[string]*mapping.FieldMapping{
+		"title":             textField(),
+		"name":              textField(),
+		"author":            textField(),
+		"description":       textField(),
+		"producer":          textField(),
+		"publisher":         textField(),
+		"keywords":          textField(),
+		"xmpDM:album":       textField(),
+		"xmpDM:albumArtist": textField(),
+		"xmpDM:artist":      textField(),
+		"xmpDM:composer":    textField(),
+		"isbn":              keywordField(),
+		"language":          keywordField(),
+		"resourceName":      keywordField(),
+		"Content-Type":      keywordField(),
+		"X-Parsed-By":       keywordField(),
+		"created":           dateField(),
+		"date":              dateField(),
+		"modified":          dateField(),
+	})
+	doc.AddSubDocumentMapping("metadata", metadata)
+
+	classification := mapping.NewDocumentStaticMapping()
+	addFields(classification, map[string]*mapping.FieldMapping{
+		"drawing": numericField(),
+		"hentai":  numericField(),
+		"neutral": numericField(),
+		"porn":    numericField(),
+		"sexy":    numericField(),
+	})
+
+	nsfw := mapping.NewDocumentStaticMapping()
+	addFields(nsfw, map[string]*mapping.FieldMapping{
+		"modelCid":          keywordField(),
+		"nsfwServerVersion": keywordField(),
+	})
+	nsfw.AddSubDocumentMapping("classification", classification)
+	doc.AddSubDocumentMapping("nsfw", nsfw)
+
+	return newIndexMapping(doc)
+}
+
+// DirectoriesMapping returns the mapping for an index of indexTypes.Directory.
+func DirectoriesMapping() mapping.IndexMapping {
+	doc := documentMapping()
+
+	addFields(doc, map[string]*mapping.FieldMapping{
+		"link_count": numericField(),
+		"pages":      numericField(),
+	})
+	doc.AddSubDocumentMapping("links", linksMapping())
+	doc.AddSubDocumentMapping("stats", statsMapping())
+
+	class := mapping.NewDocumentStaticMapping()
+	addFields(class, map[string]*mapping.FieldMapping{
+		"type":       keywordField(),
+		"confidence": numericField(),
+	})
+	doc.AddSubDocumentMapping("class", class)
+
+	return newIndexMapping(doc)
+}
+
+// statsMapping returns the mapping for indexTypes.DirectoryStats.
+func statsMapping() *mapping.DocumentMapping {
+	stats := mapping.NewDocumentStaticMapping()
+	addFields(stats, map[string]*mapping.FieldMapping{
+		"total_size": numericField(),
+		"depth":      numericField(),
+	})
+
+	for _, h := range []string{"types", "extensions", "mime_types"} {
+		counts := mapping.NewDocumentStaticMapping()
+		addFields(counts, map[string]*mapping.FieldMapping{
+			"key":   keywordField(),
+			"count": numericField(),
+		})
+		stats.AddSubDocumentMapping(h, counts)
+	}
+
+	stats.AddSubDocumentMapping("largest", linksMapping())
+
+	return stats
+}
+
+// linksMapping returns the mapping for indexTypes.Links.
+func linksMapping() *mapping.DocumentMapping {
+	links := mapping.NewDocumentStaticMapping()
+	addFields(links, map[string]*mapping.FieldMapping{
+		"Hash": keywordField(),
+		"Name": textField(),
+		"Size": numericField(),
+		"Type": keywordField(),
+	})
+
+	return links
+}
+
+// PagesMapping returns the mapping for an index of indexTypes.DirectoryPage.
+func PagesMapping() mapping.IndexMapping {
+	doc := mapping.NewDocumentStaticMapping()
+	addFields(doc, map[string]*mapping.FieldMapping{
+		"directory": keywordField(),
+		"page":      numericField(),
+		"offset":    numericField(),
+	})
+	doc.AddSubDocumentMapping("links", linksMapping())
+
+	return newIndexMapping(doc)
+}
+
+// IPLDMapping returns the mapping for an index of indexTypes.IPLD; data is stored but not indexed.
+func IPLDMapping() mapping.IndexMapping {
+	doc := documentMapping()
+
+	addFields(doc, map[string]*mapping.FieldMapping{
+		"codec":   keywordField(),
+		"content": textField(),
+		"links":   keywordField(),
+	})
+
+	return newIndexMapping(doc)
+}
+
+// NamesMapping returns the mapping for an index of indexTypes.Name.
+func NamesMapping() mapping.IndexMapping {
+	doc := mapping.NewDocumentStaticMapping()
+	addFields(doc, map[string]*mapping.FieldMapping{
+		"name":             keywordField(),
+		"target":           keywordField(),
+		"first-seen":       dateField(),
+		"last-seen":        dateField(),
+		"refresh-interval": numericField(),
+	})
+
+	history := mapping.NewDocumentStaticMapping()
+	addFields(history, map[string]*mapping.FieldMapping{
+		"target":     keywordField(),
+		"first-seen": dateField(),
+	})
+	doc.AddSubDocumentMapping("history", history)
+
+	return newIndexMapping(doc)
+}
+
+// ProvidersMapping returns the mapping for an index of indexTypes.Providers.
+func ProvidersMapping() mapping.IndexMapping {
+	doc := mapping.NewDocumentStaticMapping()
+	addFields(doc, map[string]*mapping.FieldMapping{
+		"provider-count":  numericField(),
+		"count":           numericField(),
+		"first-announced": dateField(),
+		"last-announced":  dateField(),
+	})
+
+	providers := mapping.NewDocumentStaticMapping()
+	addFields(providers, map[string]*mapping.FieldMapping{
+		"peer":            keywordField(),
+		"first-announced": dateField(),
+		"last-announced":  dateField(),
+		"count":           numericField(),
+	})
+	doc.AddSubDocumentMapping("providers", providers)
+
+	return newIndexMapping(doc)
+}
+
+// InvalidsMapping returns the mapping for an index of indexTypes.Invalid; like in OpenSearch, errors are not indexed.
+func InvalidsMapping() mapping.IndexMapping {
+	return newIndexMapping(mapping.NewDocumentStaticMapping())
+}
+
+// PartialsMapping returns the mapping for an index of indexTypes.Partial.
+func PartialsMapping() mapping.IndexMapping {
+	return newIndexMapping(mapping.NewDocumentStaticMapping())
+}
+
+// ReferencesMapping returns the mapping for an index of indexTypes.ReferenceEdge.
+func ReferencesMapping() mapping.IndexMapping {
+	doc := mapping.NewDocumentStaticMapping()
+	addFields(doc, map[string]*mapping.FieldMapping{
+		"parent_hash": keywordField(),
+		"child_hash":  keywordField(),
+		"name":        textField(),
+		"first-seen":  dateField(),
+	})
+
+	return newIndexMapping(doc)
+}
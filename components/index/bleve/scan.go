@@ -0,0 +1,82 @@
+package bleve
+
+import (
+	"context"
+	"encoding/json"
+
+	blevesearch "github.com/blevesearch/bleve/v2"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+// scanBatchSize is the number of documents read at once when scanning.
+const scanBatchSize = 1000
+
+// scanBatch returns the ids of up to scanBatchSize documents with an id after `after`, ordered by id.
+func (i *Index) scanBatch(ctx context.Context, after string) ([]string, error) {
+	req := blevesearch.NewSearchRequestOptions(blevesearch.NewMatchAllQuery(), scanBatchSize, 0, false)
+	req.SortBy([]string{"_id"})
+
+	if after != "" {
+		req.SetSearchAfter([]string{after})
+	}
+
+	res, err := i.idx.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(res.Hits))
+	for n, h := range res.Hits {
+		ids[n] = h.ID
+	}
+
+	return ids, nil
+}
+
+// Scan calls f for every document, in order of id, until f returns an error. Documents are read in batches, so
+// that f may write to the index.
+func (i *Index) Scan(ctx context.Context, f func(id string, source json.RawMessage) error, fields ...string) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.bleve.Scan")
+	defer span.End()
+
+	var after string
+
+	for {
+		ids, err := i.scanBatch(ctx, after)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		for _, id := range ids {
+			b, err := i.idx.GetInternal(sourceKey(id))
+			if err != nil {
+				return err
+			}
+
+			if b == nil {
+				// Deleted since the batch was read.
+				continue
+			}
+
+			source, err := index.SelectSource(b, fields...)
+			if err != nil {
+				return err
+			}
+
+			if err := f(id, source); err != nil {
+				return err
+			}
+		}
+
+		after = ids[len(ids)-1]
+	}
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ index.Scanner = &Index{}
@@ -0,0 +1,48 @@
+package bleve
+
+import (
+	"context"
+
+	blevesearch "github.com/blevesearch/bleve/v2"
+)
+
+// Hit represents a single document matching a query.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// SearchResult represents a page of results for a query.
+type SearchResult struct {
+	Total uint64 // Total number of matching documents.
+	Hits  []Hit
+}
+
+// Search performs a query string query (ref: https://blevesearch.com/docs/Query-String-Query/),
+// returning up to `size` hits starting at `from`.
+func (i *Index) Search(ctx context.Context, query string, from, size int) (*SearchResult, error) {
+	ctx, span := i.c.Tracer.Start(ctx, "index.bleve.Search")
+	defer span.End()
+
+	req := blevesearch.NewSearchRequestOptions(blevesearch.NewQueryStringQuery(query), size, from, false)
+
+	res, err := i.idx.SearchInContext(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	result := &SearchResult{
+		Total: res.Total,
+		Hits:  make([]Hit, len(res.Hits)),
+	}
+
+	for n, h := range res.Hits {
+		result.Hits[n] = Hit{
+			ID:    h.ID,
+			Score: h.Score,
+		}
+	}
+
+	return result, nil
+}
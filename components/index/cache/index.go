@@ -2,11 +2,13 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
 
 	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/index/types"
 	"github.com/ipfs-search/ipfs-search/instr"
 )
 
@@ -193,6 +195,61 @@ func (i *Index) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// AppendReference atomically appends a reference in the backing index and then in the cache. When the
+// caching index does not support appending references, the cached document is deleted instead.
+// Returns index.ErrNotSupported when the backing index does not support appending references, or an
+// error of type ErrCache if the caching index returned an error.
+func (i *Index) AppendReference(ctx context.Context, id string, ref types.Reference) error {
+	ctx, span := i.Tracer.Start(ctx, "index.cache.AppendReference")
+	defer span.End()
+
+	// Backing index first; it is our source of truth.
+	if err := index.AppendReference(ctx, i.backingIndex, id, ref); err != nil {
+		return err
+	}
+
+	err := index.AppendReference(ctx, i.cachingIndex, id, ref)
+	if errors.Is(err, index.ErrNotSupported) {
+		if debug {
+			log.Printf("cache %s: invalidating %s", i, id)
+		}
+
+		err = i.cachingIndex.Delete(ctx, id)
+	}
+
+	if err != nil {
+		return ErrCache{err, fmt.Sprintf("cache error in appending reference to %s: %s", id, err.Error())}
+	}
+
+	return nil
+}
+
+// AppendReferences is like AppendReference, appending several references with a single write where supported.
+func (i *Index) AppendReferences(ctx context.Context, id string, refs []types.Reference) error {
+	ctx, span := i.Tracer.Start(ctx, "index.cache.AppendReferences")
+	defer span.End()
+
+	// Backing index first; it is our source of truth.
+	if err := index.AppendReferences(ctx, i.backingIndex, id, refs); err != nil {
+		return err
+	}
+
+	err := index.AppendReferences(ctx, i.cachingIndex, id, refs)
+	if errors.Is(err, index.ErrNotSupported) {
+		if debug {
+			log.Printf("cache %s: invalidating %s", i, id)
+		}
+
+		err = i.cachingIndex.Delete(ctx, id)
+	}
+
+	if err != nil {
+		return ErrCache{err, fmt.Sprintf("cache error in appending references to %s: %s", id, err.Error())}
+	}
+
+	return nil
+}
+
 // Get retreives *all* fields from document with `id` from the cache, falling back to the backing index.
 // Returns: (exists, err) where err is of type ErrCache if there was (only) an error from the
 // caching index.
@@ -240,5 +297,9 @@ func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...s
 	return found, err
 }
 
-// Compile-time assurance that implementation satisfies interface.
-var _ index.Index = &Index{}
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ index.Index              = &Index{}
+	_ index.ReferenceAppender  = &Index{}
+	_ index.ReferencesAppender = &Index{}
+)
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/index/types"
 	"github.com/ipfs-search/ipfs-search/instr"
 )
 
@@ -260,6 +261,66 @@ func (s *CacheTestSuite) AfterTest() {
 	s.backingIndex.AssertExpectations(s.T())
 }
 
+var testRef = types.Reference{ParentHash: "parent", Name: "name"}
+
+func (s *CacheTestSuite) TestAppendReferenceNotSupported() {
+	err := s.i.AppendReference(s.ctx, testID, testRef)
+	s.ErrorIs(err, index.ErrNotSupported)
+}
+
+func (s *CacheTestSuite) TestAppendReference() {
+	backing, caching := &index.AppenderMock{}, &index.AppenderMock{}
+	s.i.backingIndex, s.i.cachingIndex = backing, caching
+
+	backing.On("AppendReference", mock.Anything, testID, testRef).Return(nil).Once()
+	caching.On("AppendReference", mock.Anything, testID, testRef).Return(nil).Once()
+
+	s.NoError(s.i.AppendReference(s.ctx, testID, testRef))
+
+	backing.AssertExpectations(s.T())
+	caching.AssertExpectations(s.T())
+}
+
+// TestAppendReferences tests whether references are appended in a single write to the backing index, and one
+// by one to a caching index only supporting single references.
+func (s *CacheTestSuite) TestAppendReferences() {
+	otherRef := types.Reference{ParentHash: "parent", Name: "other"}
+	backing, caching := &index.ReferencesAppenderMock{}, &index.AppenderMock{}
+	s.i.backingIndex, s.i.cachingIndex = backing, caching
+
+	backing.On("AppendReferences", mock.Anything, testID, []types.Reference{testRef, otherRef}).Return(nil).Once()
+	caching.On("AppendReference", mock.Anything, testID, testRef).Return(nil).Once()
+	caching.On("AppendReference", mock.Anything, testID, otherRef).Return(nil).Once()
+
+	s.NoError(s.i.AppendReferences(s.ctx, testID, []types.Reference{testRef, otherRef}))
+
+	backing.AssertExpectations(s.T())
+	caching.AssertExpectations(s.T())
+}
+
+func (s *CacheTestSuite) TestAppendReferenceInvalidates() {
+	backing := &index.AppenderMock{}
+	s.i.backingIndex = backing
+
+	backing.On("AppendReference", mock.Anything, testID, testRef).Return(nil).Once()
+	s.cachingIndex.On("Delete", mock.Anything, testID).Return(nil).Once()
+
+	s.NoError(s.i.AppendReference(s.ctx, testID, testRef))
+
+	backing.AssertExpectations(s.T())
+	s.cachingIndex.AssertExpectations(s.T())
+}
+
+func (s *CacheTestSuite) TestAppendReferenceBackingFail() {
+	backing := &index.AppenderMock{}
+	s.i.backingIndex = backing
+
+	backing.On("AppendReference", mock.Anything, testID, testRef).Return(testErr).Once()
+
+	s.ErrorIs(s.i.AppendReference(s.ctx, testID, testRef), testErr)
+	s.cachingIndex.AssertNotCalled(s.T(), "Delete", mock.Anything, mock.Anything)
+}
+
 func TestCacheTestSuite(t *testing.T) {
 	suite.Run(t, new(CacheTestSuite))
 }
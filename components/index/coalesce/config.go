@@ -0,0 +1,19 @@
+package coalesce
+
+import (
+	"time"
+)
+
+// Config configures coalescing of updates.
+type Config struct {
+	Window     time.Duration // Pending updates are flushed after this time.
+	MaxPending int           // Flush early when this many documents have pending updates.
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Window:     5 * time.Second,
+		MaxPending: 10000,
+	}
+}
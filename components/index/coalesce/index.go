@@ -0,0 +1,368 @@
+// Package coalesce merges repeated updates to the same document before writing them to an index.
+package coalesce
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const debug bool = false
+
+// Index wraps a backing index, merging updates (of type indexTypes.Update) to the same document within a
+// flush window: references are joined and the latest last-seen is kept. Likewise, references appended to the
+// same document are collected and appended with a single write, when the backing index supports it.
+//
+// Like the OpenSearch BulkIndexer, updates are written asynchronously; errors are logged. Writes failing with
+// index.ErrTemporary (e.g. with sync writes of OpenSearch) are queued again, to be retried with the next flush.
+// Pending updates are merged into results of Get, so that read-modify-write cycles see them.
+type Index struct {
+	cfg      *Config
+	backing  index.Index
+	counters counters
+
+	mu       sync.Mutex
+	pending  map[string]*indexTypes.Update
+	flushing map[string]*indexTypes.Update // Updates being written, still merged into Get results.
+
+	appending         map[string]indexTypes.References // References to append, per document.
+	appendingFlushing map[string]indexTypes.References // References being appended, still merged into Get results.
+
+	full chan struct{}
+
+	*instr.Instrumentation
+}
+
+// New returns a new coalescing index. Updates are only written when Work() is running.
+func New(backing index.Index, cfg *Config, instr *instr.Instrumentation) *Index {
+	if backing == nil {
+		panic("coalesce.New backing cannot be nil.")
+	}
+
+	if cfg == nil {
+		panic("coalesce.New Config cannot be nil.")
+	}
+
+	return &Index{
+		cfg:             cfg,
+		backing:         backing,
+		pending:         make(map[string]*indexTypes.Update),
+		appending:       make(map[string]indexTypes.References),
+		full:            make(chan struct{}, 1),
+		Instrumentation: instr,
+	}
+}
+
+// String returns the name of the index, for convenient logging.
+func (i *Index) String() string {
+	return fmt.Sprintf("'%s' coalesced", i.backing)
+}
+
+// Stats returns a snapshot of the write amplification counters.
+func (i *Index) Stats() Stats {
+	return Stats{
+		Received: i.counters.received.Load(),
+		Written:  i.counters.written.Load(),
+	}
+}
+
+// Work flushes pending updates every window, or when too many are pending, until the context is closed.
+// Remaining updates are flushed on exit.
+func (i *Index) Work(ctx context.Context) error {
+	ticker := time.NewTicker(i.cfg.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Use background context, as the current context is closed.
+			i.flush(context.Background())
+
+			if n := i.pendingCount(); n > 0 {
+				log.Printf("coalesce %s: dropping %d documents with failed writes on exit", i, n)
+			}
+
+			return ctx.Err()
+		case <-ticker.C:
+			i.flush(ctx)
+		case <-i.full:
+			i.flush(ctx)
+		}
+	}
+}
+
+// Flush writes pending updates and references to the backing index right away, e.g. before shutting down.
+func (i *Index) Flush(ctx context.Context) {
+	i.flush(ctx)
+}
+
+// flush writes all pending updates to the backing index, and then appends pending references.
+func (i *Index) flush(ctx context.Context) {
+	i.mu.Lock()
+	pending := i.pending
+	i.pending = make(map[string]*indexTypes.Update, len(pending))
+	i.flushing = pending
+	appending := i.appending
+	i.appending = make(map[string]indexTypes.References, len(appending))
+	i.appendingFlushing = appending
+	i.mu.Unlock()
+
+	defer func() {
+		i.mu.Lock()
+		i.flushing = nil
+		i.appendingFlushing = nil
+		i.mu.Unlock()
+	}()
+
+	if len(pending) == 0 && len(appending) == 0 {
+		return
+	}
+
+	ctx, span := i.Tracer.Start(ctx, "index.coalesce.flush")
+	defer span.End()
+
+	for id, u := range pending {
+		i.write(ctx, id, u)
+	}
+
+	for id, refs := range appending {
+		i.writeReferences(ctx, id, refs)
+	}
+
+	if debug {
+		log.Printf("coalesce %s: flushed %d updates and references of %d documents, %+v", i, len(pending), len(appending), i.Stats())
+	}
+}
+
+func (i *Index) write(ctx context.Context, id string, u *indexTypes.Update) {
+	i.counters.written.Add(1)
+
+	if err := i.backing.Update(ctx, id, u); err != nil {
+		log.Printf("coalesce %s: error writing update to %s: %s", i, id, err)
+
+		if errors.Is(err, index.ErrTemporary) {
+			i.requeue(id, u)
+		}
+	}
+}
+
+func (i *Index) writeReferences(ctx context.Context, id string, refs indexTypes.References) {
+	i.counters.written.Add(1)
+
+	if err := index.AppendReferences(ctx, i.backing, id, refs); err != nil {
+		log.Printf("coalesce %s: error appending references to %s: %s", i, id, err)
+
+		if errors.Is(err, index.ErrTemporary) {
+			i.requeueReferences(id, refs)
+		}
+	}
+}
+
+// requeue merges an update which failed to be written with the pending update for id, if any, to retry it.
+func (i *Index) requeue(id string, u *indexTypes.Update) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// Pending updates are newer.
+	if pending, ok := i.pending[id]; ok {
+		mergeUpdate(u, pending)
+	}
+
+	i.pending[id] = u
+}
+
+// requeueReferences adds references which failed to be appended to those pending for id, to retry them.
+func (i *Index) requeueReferences(id string, refs indexTypes.References) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, ref := range i.appending[id] {
+		if !refs.Contains(ref) {
+			refs = append(refs, ref)
+		}
+	}
+
+	i.appending[id] = refs
+}
+
+// pendingCount returns the number of documents with pending updates or references.
+func (i *Index) pendingCount() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return len(i.pending) + len(i.appending)
+}
+
+// takeReferences removes and returns references pending to be appended to id, if any.
+func (i *Index) takeReferences(id string) indexTypes.References {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	refs, ok := i.appending[id]
+	if ok {
+		delete(i.appending, id)
+	}
+
+	return refs
+}
+
+// appendPending writes references pending to be appended to id.
+func (i *Index) appendPending(ctx context.Context, id string) {
+	if refs := i.takeReferences(id); refs != nil {
+		i.writeReferences(ctx, id, refs)
+	}
+}
+
+// signalFull triggers a flush when too many documents have pending writes; callers must hold mu.
+func (i *Index) signalFull() {
+	if len(i.pending)+len(i.appending) >= i.cfg.MaxPending {
+		select {
+		case i.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// take removes and returns the pending update for id, if any.
+func (i *Index) take(id string) *indexTypes.Update {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	u, ok := i.pending[id]
+	if ok {
+		delete(i.pending, id)
+	}
+
+	return u
+}
+
+// Index a document's properties, identified by id. Pending updates for the document are written first,
+// pending references are appended afterwards.
+func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
+	if u := i.take(id); u != nil {
+		i.write(ctx, id, u)
+	}
+
+	defer i.appendPending(ctx, id)
+
+	return i.backing.Index(ctx, id, properties)
+}
+
+// Update a document's properties, given id. Updates of type indexTypes.Update are merged with pending
+// updates and written later, other updates are written immediately.
+func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
+	u, ok := asUpdate(properties)
+	if !ok {
+		if pending := i.take(id); pending != nil {
+			i.write(ctx, id, pending)
+		}
+
+		defer i.appendPending(ctx, id)
+
+		return i.backing.Update(ctx, id, properties)
+	}
+
+	i.counters.received.Add(1)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if pending, ok := i.pending[id]; ok {
+		mergeUpdate(pending, u)
+		return nil
+	}
+
+	// Copy, as the caller might reuse properties.
+	pending := new(indexTypes.Update)
+	mergeUpdate(pending, u)
+	i.pending[id] = pending
+	i.signalFull()
+
+	return nil
+}
+
+// AppendReference collects ref to be appended to the document later, after pending updates, together with
+// other references appended within the window. Returns index.ErrNotSupported when the backing index does not
+// support appending references.
+func (i *Index) AppendReference(ctx context.Context, id string, ref indexTypes.Reference) error {
+	return i.AppendReferences(ctx, id, []indexTypes.Reference{ref})
+}
+
+// AppendReferences is like AppendReference, for several references.
+func (i *Index) AppendReferences(ctx context.Context, id string, refs []indexTypes.Reference) error {
+	if !index.SupportsAppendReference(i.backing) {
+		return index.ErrNotSupported
+	}
+
+	i.counters.received.Add(int64(len(refs)))
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	existing := i.appending[id]
+
+	for _, ref := range refs {
+		if !existing.Contains(ref) {
+			existing = append(existing, ref)
+		}
+	}
+
+	i.appending[id] = existing
+	i.signalFull()
+
+	return nil
+}
+
+// Delete item from index, dropping pending updates and references.
+func (i *Index) Delete(ctx context.Context, id string) error {
+	i.take(id)
+	i.takeReferences(id)
+
+	return i.backing.Delete(ctx, id)
+}
+
+// Get retreives `fields` from document with `id` from the backing index. When dst is an indexTypes.Update
+// and the document is found, pending updates are merged into it.
+func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	found, err := i.backing.Get(ctx, id, dst, fields...)
+
+	if u, ok := dst.(*indexTypes.Update); ok && found {
+		i.mu.Lock()
+		if flushing, ok := i.flushing[id]; ok {
+			mergeUpdate(u, flushing)
+		}
+		if pending, ok := i.pending[id]; ok {
+			mergeUpdate(u, pending)
+		}
+		if refs, ok := i.appendingFlushing[id]; ok {
+			mergeUpdate(u, &indexTypes.Update{References: refs})
+		}
+		if refs, ok := i.appending[id]; ok {
+			mergeUpdate(u, &indexTypes.Update{References: refs})
+		}
+		i.mu.Unlock()
+	}
+
+	return found, err
+}
+
+// Scan calls f for every document in the backing index; pending updates are not included.
+func (i *Index) Scan(ctx context.Context, f func(id string, source json.RawMessage) error, fields ...string) error {
+	return index.Scan(ctx, i.backing, f, fields...)
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ index.Index              = &Index{}
+	_ index.ReferenceAppender  = &Index{}
+	_ index.ReferencesAppender = &Index{}
+	_ index.Scanner            = &Index{}
+)
@@ -0,0 +1,290 @@
+package coalesce
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testID = "testID"
+
+var (
+	ref1 = indexTypes.Reference{ParentHash: "parent1", Name: "name1"}
+	ref2 = indexTypes.Reference{ParentHash: "parent2", Name: "name2"}
+	ref3 = indexTypes.Reference{ParentHash: "parent3", Name: "name3"}
+)
+
+type CoalesceTestSuite struct {
+	suite.Suite
+	ctx context.Context
+	now time.Time
+
+	backing *index.Mock
+	i       *Index
+}
+
+func (s *CoalesceTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.now = time.Now().Truncate(time.Second)
+
+	s.backing = &index.Mock{}
+	s.backing.Test(s.T())
+
+	s.i = New(s.backing, &Config{Window: time.Hour, MaxPending: 100}, instr.New())
+}
+
+func (s *CoalesceTestSuite) TearDownTest() {
+	s.backing.AssertExpectations(s.T())
+}
+
+func (s *CoalesceTestSuite) TestMergeUpdates() {
+	later := s.now.Add(time.Minute)
+
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{References: indexTypes.References{ref1, ref2}}))
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &later}))
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &s.now, References: indexTypes.References{ref1, ref3}}))
+
+	s.backing.On("Update", mock.Anything, testID, &indexTypes.Update{
+		LastSeen:   &later,
+		References: indexTypes.References{ref1, ref2, ref3},
+	}).Return(nil).Once()
+
+	s.i.flush(s.ctx)
+
+	s.Equal(Stats{Received: 3, Written: 1}, s.i.Stats())
+	s.Equal(int64(2), s.i.Stats().Saved())
+	s.Equal(3.0, s.i.Stats().Ratio())
+}
+
+func (s *CoalesceTestSuite) TestMergeCountsAndPaths() {
+	five, three := uint64(5), uint64(3)
+
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{ReferenceCount: &five, ReferenceNames: indexTypes.ReferenceNames{"a"}}))
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{ReferenceCount: &three, Paths: []string{"/ipfs/root/a"}, Roots: []string{"root"}}))
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{ReferenceNames: indexTypes.ReferenceNames{"a", "b"}}))
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{Site: &indexTypes.SitePage{Root: "root", Rank: 1}}))
+
+	s.backing.On("Update", mock.Anything, testID, &indexTypes.Update{
+		ReferenceCount: &five,
+		ReferenceNames: indexTypes.ReferenceNames{"a", "b"},
+		Roots:          []string{"root"},
+		Paths:          []string{"/ipfs/root/a"},
+		Site:           &indexTypes.SitePage{Root: "root", Rank: 1},
+	}).Return(nil).Once()
+
+	s.i.flush(s.ctx)
+}
+
+func (s *CoalesceTestSuite) TestPassthroughUpdate() {
+	props := map[string]string{"a": "b"}
+
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{References: indexTypes.References{ref1}}))
+
+	// Pending update is written first.
+	s.backing.On("Update", mock.Anything, testID, &indexTypes.Update{References: indexTypes.References{ref1}}).Return(nil).Once()
+	s.backing.On("Update", mock.Anything, testID, props).Return(nil).Once()
+
+	s.NoError(s.i.Update(s.ctx, testID, props))
+
+	s.i.flush(s.ctx)
+}
+
+func (s *CoalesceTestSuite) TestDeleteDropsPending() {
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{References: indexTypes.References{ref1}}))
+
+	s.backing.On("Delete", mock.Anything, testID).Return(nil).Once()
+	s.NoError(s.i.Delete(s.ctx, testID))
+
+	s.i.flush(s.ctx)
+	s.backing.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CoalesceTestSuite) TestGetMergesPending() {
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &s.now, References: indexTypes.References{ref2}}))
+
+	dst := new(indexTypes.Update)
+	s.backing.On("Get", mock.Anything, testID, dst, []string{"references", "last-seen"}).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*indexTypes.Update).References = indexTypes.References{ref1}
+		}).
+		Return(true, nil).Once()
+
+	found, err := s.i.Get(s.ctx, testID, dst, "references", "last-seen")
+	s.True(found)
+	s.NoError(err)
+	s.Equal(&indexTypes.Update{LastSeen: &s.now, References: indexTypes.References{ref1, ref2}}, dst)
+}
+
+func (s *CoalesceTestSuite) TestGetNotFound() {
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{References: indexTypes.References{ref2}}))
+
+	dst := new(indexTypes.Update)
+	s.backing.On("Get", mock.Anything, testID, dst, []string(nil)).Return(false, nil).Once()
+
+	found, err := s.i.Get(s.ctx, testID, dst)
+	s.False(found)
+	s.NoError(err)
+	s.Empty(dst.References)
+}
+
+// TestRequeueTemporary tests whether updates failing with a temporary error are merged with later updates and
+// written again with the next flush.
+func (s *CoalesceTestSuite) TestRequeueTemporary() {
+	later := s.now.Add(time.Minute)
+
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &s.now, References: indexTypes.References{ref1}}))
+
+	s.backing.On("Update", mock.Anything, testID, &indexTypes.Update{
+		LastSeen:   &s.now,
+		References: indexTypes.References{ref1},
+	}).Return(index.ErrTemporary).Once()
+
+	s.i.flush(s.ctx)
+
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &later, References: indexTypes.References{ref2}}))
+
+	s.backing.On("Update", mock.Anything, testID, &indexTypes.Update{
+		LastSeen:   &later,
+		References: indexTypes.References{ref1, ref2},
+	}).Return(nil).Once()
+
+	s.i.flush(s.ctx)
+}
+
+// TestDropPermanent tests whether updates failing with other errors are not retried.
+func (s *CoalesceTestSuite) TestDropPermanent() {
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &s.now}))
+
+	s.backing.On("Update", mock.Anything, testID, mock.Anything).Return(index.ErrDocumentMissing).Once()
+
+	s.i.flush(s.ctx)
+	s.i.flush(s.ctx)
+}
+
+func (s *CoalesceTestSuite) TestWorkFlushesOnFullAndExit() {
+	s.i.cfg.MaxPending = 1
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	done := make(chan struct{})
+
+	go func() {
+		s.i.Work(ctx)
+		close(done)
+	}()
+
+	written := make(chan struct{})
+	s.backing.On("Update", mock.Anything, testID, mock.Anything).Return(nil).Once().
+		Run(func(mock.Arguments) { close(written) })
+
+	s.NoError(s.i.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &s.now}))
+
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		s.Fail("update not flushed when full")
+	}
+
+	s.backing.On("Update", mock.Anything, "otherID", mock.Anything).Return(nil).Once()
+	s.i.cfg.MaxPending = 100
+	s.NoError(s.i.Update(s.ctx, "otherID", &indexTypes.Update{LastSeen: &s.now}))
+
+	cancel()
+	<-done
+}
+
+// appender returns a coalescing index with a backing index supporting appending references.
+func (s *CoalesceTestSuite) appender() (*Index, *index.ReferencesAppenderMock) {
+	backing := &index.ReferencesAppenderMock{}
+	backing.Test(s.T())
+
+	return New(backing, &Config{Window: time.Hour, MaxPending: 100}, instr.New()), backing
+}
+
+func (s *CoalesceTestSuite) TestAppendReferenceNotSupported() {
+	s.ErrorIs(s.i.AppendReference(s.ctx, testID, ref1), index.ErrNotSupported)
+}
+
+// TestAppendReferencesCoalesced tests whether references appended within the window are written at once, after
+// pending updates.
+func (s *CoalesceTestSuite) TestAppendReferencesCoalesced() {
+	i, backing := s.appender()
+
+	s.NoError(i.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &s.now}))
+	s.NoError(i.AppendReference(s.ctx, testID, ref1))
+	s.NoError(i.AppendReference(s.ctx, testID, ref2))
+	s.NoError(i.AppendReference(s.ctx, testID, ref1))
+
+	updated := backing.On("Update", mock.Anything, testID, &indexTypes.Update{LastSeen: &s.now}).Return(nil).Once()
+	backing.On("AppendReferences", mock.Anything, testID, []indexTypes.Reference{ref1, ref2}).
+		Return(nil).
+		NotBefore(updated).
+		Once()
+
+	i.flush(s.ctx)
+	backing.AssertExpectations(s.T())
+
+	s.Equal(Stats{Received: 4, Written: 2}, i.Stats())
+}
+
+func (s *CoalesceTestSuite) TestRequeueReferencesTemporary() {
+	i, backing := s.appender()
+
+	s.NoError(i.AppendReference(s.ctx, testID, ref1))
+
+	backing.On("AppendReferences", mock.Anything, testID, []indexTypes.Reference{ref1}).
+		Return(index.ErrTemporary).
+		Once()
+
+	i.flush(s.ctx)
+
+	s.NoError(i.AppendReference(s.ctx, testID, ref2))
+
+	backing.On("AppendReferences", mock.Anything, testID, []indexTypes.Reference{ref1, ref2}).
+		Return(nil).
+		Once()
+
+	i.flush(s.ctx)
+	backing.AssertExpectations(s.T())
+}
+
+func (s *CoalesceTestSuite) TestGetMergesAppending() {
+	i, backing := s.appender()
+
+	s.NoError(i.AppendReference(s.ctx, testID, ref2))
+
+	backing.On("Get", mock.Anything, testID, mock.Anything, []string(nil)).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*indexTypes.Update).References = indexTypes.References{ref1}
+		}).
+		Return(true, nil).Once()
+
+	dst := new(indexTypes.Update)
+	found, err := i.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.True(found)
+	s.Equal(indexTypes.References{ref1, ref2}, dst.References)
+}
+
+func (s *CoalesceTestSuite) TestDeleteDropsAppending() {
+	i, backing := s.appender()
+
+	s.NoError(i.AppendReference(s.ctx, testID, ref1))
+
+	backing.On("Delete", mock.Anything, testID).Return(nil).Once()
+	s.NoError(i.Delete(s.ctx, testID))
+
+	// Nothing left to flush.
+	i.flush(s.ctx)
+	backing.AssertExpectations(s.T())
+}
+
+func TestCoalesceTestSuite(t *testing.T) {
+	suite.Run(t, new(CoalesceTestSuite))
+}
@@ -0,0 +1,63 @@
+package coalesce
+
+import (
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// mergeUpdate merges src into dst; references are the union of both (in order), last-seen and the
+// reference count the maximum. Other fields are replaced by those of src, when set.
+func mergeUpdate(dst, src *indexTypes.Update) {
+	if src.LastSeen != nil && (dst.LastSeen == nil || src.LastSeen.After(*dst.LastSeen)) {
+		lastSeen := *src.LastSeen
+		dst.LastSeen = &lastSeen
+	}
+
+	if src.ReferenceCount != nil && (dst.ReferenceCount == nil || *src.ReferenceCount > *dst.ReferenceCount) {
+		count := *src.ReferenceCount
+		dst.ReferenceCount = &count
+	}
+
+	if src.ReferenceNames != nil {
+		dst.ReferenceNames = src.ReferenceNames
+	}
+
+	if src.Roots != nil {
+		dst.Roots = src.Roots
+	}
+
+	if src.Paths != nil {
+		dst.Paths = src.Paths
+	}
+
+	if src.Site != nil {
+		dst.Site = src.Site
+	}
+
+	if len(src.References) == 0 {
+		return
+	}
+
+	existing := make(map[indexTypes.Reference]bool, len(dst.References))
+	for _, r := range dst.References {
+		existing[r] = true
+	}
+
+	for _, r := range src.References {
+		if !existing[r] {
+			existing[r] = true
+			dst.References = append(dst.References, r)
+		}
+	}
+}
+
+// asUpdate returns properties as an Update, or false if they are of another type.
+func asUpdate(properties interface{}) (*indexTypes.Update, bool) {
+	switch u := properties.(type) {
+	case *indexTypes.Update:
+		return u, u != nil
+	case indexTypes.Update:
+		return &u, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,31 @@
+package coalesce
+
+import (
+	"sync/atomic"
+)
+
+// counters tracks write amplification.
+type counters struct {
+	received atomic.Int64
+	written  atomic.Int64
+}
+
+// Stats is a snapshot of the counters of a coalescing index.
+type Stats struct {
+	Received int64 // Updates and references to append received.
+	Written  int64 // Updates and appends of references written to the backing index.
+}
+
+// Saved returns the number of writes saved by coalescing.
+func (s Stats) Saved() int64 {
+	return s.Received - s.Written
+}
+
+// Ratio returns the number of received updates per written update; the write amplification which was avoided.
+func (s Stats) Ratio() float64 {
+	if s.Written == 0 {
+		return 0
+	}
+
+	return float64(s.Received) / float64(s.Written)
+}
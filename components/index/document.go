@@ -0,0 +1,96 @@
+package index
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ToDocument converts properties into their JSON object representation, as stored by backends without native
+// support for structs; nil properties yield an empty document.
+func ToDocument(properties interface{}) (map[string]interface{}, error) {
+	doc := make(map[string]interface{})
+
+	if properties == nil {
+		return doc, nil
+	}
+
+	b, err := json.Marshal(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, &doc)
+
+	return doc, err
+}
+
+// MergeDocument recursively merges the JSON object src into dst, like OpenSearch's partial `doc` update:
+// nested objects are merged, all other values (including arrays) are replaced.
+func MergeDocument(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcObj, srcIsObj := v.(map[string]interface{})
+		dstObj, dstIsObj := dst[k].(map[string]interface{})
+
+		if srcIsObj && dstIsObj {
+			MergeDocument(dstObj, srcObj)
+			continue
+		}
+
+		dst[k] = v
+	}
+}
+
+// SelectFields returns a copy of the JSON object doc, containing only the given (dotted) fields, like
+// OpenSearch's `_source` includes. When no fields are given, doc is returned as-is.
+func SelectFields(doc map[string]interface{}, fields ...string) map[string]interface{} {
+	if len(fields) == 0 {
+		return doc
+	}
+
+	result := make(map[string]interface{}, len(fields))
+
+	for _, field := range fields {
+		selectField(doc, result, strings.Split(field, "."))
+	}
+
+	return result
+}
+
+// SelectSource is like SelectFields, for a JSON encoded document.
+func SelectSource(source []byte, fields ...string) (json.RawMessage, error) {
+	if len(fields) == 0 {
+		return source, nil
+	}
+
+	doc := make(map[string]interface{})
+	if err := json.Unmarshal(source, &doc); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(SelectFields(doc, fields...))
+}
+
+func selectField(src, dst map[string]interface{}, path []string) {
+	v, ok := src[path[0]]
+	if !ok {
+		return
+	}
+
+	if len(path) == 1 {
+		dst[path[0]] = v
+		return
+	}
+
+	srcObj, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	dstObj, ok := dst[path[0]].(map[string]interface{})
+	if !ok {
+		dstObj = make(map[string]interface{})
+		dst[path[0]] = dstObj
+	}
+
+	selectField(srcObj, dstObj, path[1:])
+}
@@ -0,0 +1,73 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDocument(t *testing.T) {
+	doc, err := ToDocument(struct {
+		Size  uint64 `json:"size"`
+		Empty string `json:"empty,omitempty"`
+	}{Size: 5})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"size": 5.0}, doc)
+
+	doc, err = ToDocument(nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{}, doc)
+}
+
+func TestMergeDocument(t *testing.T) {
+	dst := map[string]interface{}{
+		"size":       1.0,
+		"references": []interface{}{"a"},
+		"language": map[string]interface{}{
+			"language":   "en",
+			"confidence": "low",
+		},
+	}
+
+	src := map[string]interface{}{
+		"references": []interface{}{"a", "b"},
+		"last-seen":  "now",
+		"language": map[string]interface{}{
+			"confidence": "high",
+		},
+	}
+
+	MergeDocument(dst, src)
+
+	assert.Equal(t, map[string]interface{}{
+		"size":       1.0,
+		"references": []interface{}{"a", "b"},
+		"last-seen":  "now",
+		"language": map[string]interface{}{
+			"language":   "en",
+			"confidence": "high",
+		},
+	}, dst)
+}
+
+func TestSelectFields(t *testing.T) {
+	doc := map[string]interface{}{
+		"size":      1.0,
+		"last-seen": "now",
+		"language": map[string]interface{}{
+			"language":   "en",
+			"confidence": "low",
+		},
+	}
+
+	assert.Equal(t, doc, SelectFields(doc))
+
+	assert.Equal(t, map[string]interface{}{
+		"last-seen": "now",
+		"language": map[string]interface{}{
+			"language": "en",
+		},
+	}, SelectFields(doc, "last-seen", "language.language", "missing", "size.invalid"))
+}
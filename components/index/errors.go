@@ -0,0 +1,19 @@
+package index
+
+import (
+	"errors"
+)
+
+var (
+	// ErrDocumentExists is returned by indexes which enforce create semantics, when indexing a document which already exists.
+	ErrDocumentExists = errors.New("document already exists")
+
+	// ErrDocumentMissing is returned by indexes which enforce update semantics, when updating a document which does not exist.
+	ErrDocumentMissing = errors.New("document missing")
+
+	// ErrNotSupported is returned by indexes for optional operations which are not supported, e.g. by wrapped indexes.
+	ErrNotSupported = errors.New("operation not supported by index")
+
+	// ErrTemporary matches write errors which might not occur when the write is retried.
+	ErrTemporary = errors.New("temporary index error")
+)
@@ -0,0 +1,233 @@
+package meilisearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+// getResponse represents the response to a getRequest.
+type getResponse struct {
+	found bool
+	err   error
+}
+
+// getRequest represents a single document to get.
+type getRequest struct {
+	ctx    context.Context
+	index  string
+	id     string
+	fields []string
+	dst    interface{}
+	resp   chan getResponse
+}
+
+// bulkGetter batches gets, similar to bulkgetter.BulkGetter; documents are fetched using a filter on their
+// id, with a single request per index for each batch.
+type bulkGetter struct {
+	c            *Client
+	batchSize    int
+	batchTimeout time.Duration
+	queue        chan *getRequest
+}
+
+func newBulkGetter(c *Client, batchSize int, batchTimeout time.Duration) *bulkGetter {
+	if batchSize == 0 {
+		batchSize = 100
+	}
+
+	if batchTimeout == 0 {
+		batchTimeout = 100 * time.Millisecond
+	}
+
+	return &bulkGetter{
+		c:            c,
+		batchSize:    batchSize,
+		batchTimeout: batchTimeout,
+		queue:        make(chan *getRequest, 5*batchSize),
+	}
+}
+
+// get queues a request, returning a channel on which the response is sent.
+func (g *bulkGetter) get(ctx context.Context, name, id string, dst interface{}, fields []string) <-chan getResponse {
+	req := &getRequest{
+		ctx:    ctx,
+		index:  name,
+		id:     id,
+		fields: fields,
+		dst:    dst,
+		resp:   make(chan getResponse, 1),
+	}
+
+	select {
+	case <-ctx.Done():
+		req.resp <- getResponse{err: ctx.Err()}
+	case g.queue <- req:
+	}
+
+	return req.resp
+}
+
+// work processes batches of requests until the context closes.
+func (g *bulkGetter) work(ctx context.Context) error {
+	log.Println("Starting worker for Meilisearch bulk getter.")
+
+	for {
+		batch, err := g.populateBatch(ctx)
+
+		g.execute(ctx, batch)
+
+		if err != nil {
+			log.Printf("Meilisearch bulk getter exiting, error: %s", err)
+			return err
+		}
+	}
+}
+
+func (g *bulkGetter) populateBatch(ctx context.Context) ([]*getRequest, error) {
+	batch := make([]*getRequest, 0, g.batchSize)
+
+	timeout := time.NewTimer(g.batchTimeout)
+	defer timeout.Stop()
+
+	for len(batch) < g.batchSize {
+		select {
+		case <-ctx.Done():
+			return batch, ctx.Err()
+		case <-timeout.C:
+			return batch, nil
+		case req := <-g.queue:
+			batch = append(batch, req)
+		}
+	}
+
+	return batch, nil
+}
+
+// execute performs the requests in a batch, grouped by index.
+func (g *bulkGetter) execute(ctx context.Context, batch []*getRequest) {
+	byIndex := make(map[string][]*getRequest)
+
+	for _, req := range batch {
+		if err := req.ctx.Err(); err != nil {
+			req.resp <- getResponse{err: err}
+			continue
+		}
+
+		byIndex[req.index] = append(byIndex[req.index], req)
+	}
+
+	for name, reqs := range byIndex {
+		docs, err := g.fetch(ctx, name, reqs)
+		if err != nil {
+			for _, req := range reqs {
+				req.resp <- getResponse{err: err}
+			}
+
+			continue
+		}
+
+		for _, req := range reqs {
+			req.resp <- g.c.decode(docs[req.id], req.dst, req.fields)
+		}
+	}
+}
+
+// quote returns a string literal for use in Meilisearch filters.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// fetch returns documents for requests on a single index, keyed by id.
+func (g *bulkGetter) fetch(ctx context.Context, name string, reqs []*getRequest) (map[string]map[string]interface{}, error) {
+	var (
+		ids     []string
+		seenIDs = make(map[string]bool)
+	)
+
+	for _, req := range reqs {
+		if !seenIDs[req.id] {
+			seenIDs[req.id] = true
+			ids = append(ids, quote(encodeID(req.id)))
+		}
+	}
+
+	body := map[string]interface{}{
+		"filter": primaryKey + " IN [" + strings.Join(ids, ", ") + "]",
+		"limit":  len(ids),
+	}
+
+	if fields := g.c.attributeFields(reqs); fields != nil {
+		body["fields"] = fields
+	}
+
+	var res struct {
+		Results []map[string]interface{} `json:"results"`
+	}
+
+	if err := g.c.do(ctx, http.MethodPost, indexPath(name, "documents", "fetch"), body, &res); err != nil {
+		return nil, fmt.Errorf("error fetching documents from %s: %w", name, err)
+	}
+
+	docs := make(map[string]map[string]interface{}, len(res.Results))
+	for _, doc := range res.Results {
+		if id, ok := doc[primaryKey].(string); ok {
+			docs[decodeID(id)] = doc
+		}
+	}
+
+	return docs, nil
+}
+
+// attributeFields returns the union of the (top-level) attributes required for requests, or nil
+// when any of the requests requires the full document.
+func (c *Client) attributeFields(reqs []*getRequest) []string {
+	set := map[string]bool{primaryKey: true}
+
+	for _, req := range reqs {
+		if len(req.fields) == 0 {
+			return nil
+		}
+
+		for _, f := range req.fields {
+			for _, attr := range c.attributes.attributesFor(f) {
+				set[attr] = true
+			}
+		}
+	}
+
+	fields := make([]string, 0, len(set))
+	for f := range set {
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// decode writes the selected fields of a fetched document into dst.
+func (c *Client) decode(doc map[string]interface{}, dst interface{}, fields []string) getResponse {
+	if doc == nil {
+		return getResponse{}
+	}
+
+	// Copy, as documents may be shared between requests.
+	src := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		src[k] = v
+	}
+
+	src = index.SelectFields(c.attributes.fromDocument(src), fields...)
+
+	b, err := json.Marshal(src)
+	if err == nil {
+		err = json.Unmarshal(b, dst)
+	}
+
+	return getResponse{found: true, err: err}
+}
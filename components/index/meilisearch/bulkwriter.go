@@ -0,0 +1,163 @@
+package meilisearch
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// action represents the type of a write operation.
+type action int
+
+const (
+	addAction    action = iota // Add or replace documents.
+	updateAction               // Add or partially update documents.
+	deleteAction               // Delete documents.
+)
+
+// operation represents a single queued write.
+type operation struct {
+	index  string
+	action action
+	id     string
+	doc    map[string]interface{}
+	span   trace.Span
+}
+
+// bulkWriter batches write operations, similar to opensearchutil.BulkIndexer. Operations are flushed when
+// the batch is full or after the flush interval; failures are logged and recorded on the operation's span.
+type bulkWriter struct {
+	c             *Client
+	batchSize     int
+	flushInterval time.Duration
+	queue         chan operation
+}
+
+func newBulkWriter(c *Client, batchSize int, flushInterval time.Duration) *bulkWriter {
+	if batchSize == 0 {
+		batchSize = 1000
+	}
+
+	if flushInterval == 0 {
+		flushInterval = 5 * time.Second
+	}
+
+	return &bulkWriter{
+		c:             c,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		queue:         make(chan operation, 2*batchSize),
+	}
+}
+
+// add queues an operation, blocking when the queue is full.
+func (w *bulkWriter) add(ctx context.Context, op operation) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case w.queue <- op:
+		return nil
+	}
+}
+
+// work processes queued operations until the context closes, after which pending operations are flushed.
+func (w *bulkWriter) work(ctx context.Context) error {
+	log.Println("Starting worker for Meilisearch bulk writer.")
+
+	batch := make([]operation, 0, w.batchSize)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Drain operations already queued, flushing with a fresh context.
+			for {
+				select {
+				case op := <-w.queue:
+					batch = append(batch, op)
+				default:
+					w.flush(context.Background(), batch)
+					log.Printf("Meilisearch bulk writer exiting, error: %s", ctx.Err())
+					return ctx.Err()
+				}
+			}
+		case <-ticker.C:
+			w.flush(ctx, batch)
+			batch = batch[:0]
+		case op := <-w.queue:
+			batch = append(batch, op)
+
+			if len(batch) >= w.batchSize {
+				w.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush writes a batch, grouping consecutive operations on the same index with the same action into a
+// single request. Grouping only consecutive operations preserves the order of writes.
+func (w *bulkWriter) flush(ctx context.Context, batch []operation) {
+	for start := 0; start < len(batch); {
+		end := start + 1
+		for end < len(batch) && batch[end].index == batch[start].index && batch[end].action == batch[start].action {
+			end++
+		}
+
+		w.execute(ctx, batch[start:end])
+
+		start = end
+	}
+}
+
+// execute performs a single request for operations sharing index and action.
+func (w *bulkWriter) execute(ctx context.Context, ops []operation) {
+	var (
+		method, path string
+		body         interface{}
+	)
+
+	name := ops[0].index
+
+	switch ops[0].action {
+	case addAction, updateAction:
+		docs := make([]map[string]interface{}, len(ops))
+		for i, op := range ops {
+			docs[i] = op.doc
+		}
+
+		method, path, body = http.MethodPost, indexPath(name, "documents"), docs
+		if ops[0].action == updateAction {
+			method = http.MethodPut
+		}
+	case deleteAction:
+		ids := make([]string, len(ops))
+		for i, op := range ops {
+			ids[i] = encodeID(op.id)
+		}
+
+		method, path, body = http.MethodPost, indexPath(name, "documents", "delete-batch"), ids
+	}
+
+	if debug {
+		log.Printf("meilisearch: flushing %d operations to %s", len(ops), name)
+	}
+
+	// Meilisearch processes writes asynchronously as tasks; like BulkIndexer, we do not wait for completion.
+	if err := w.c.do(ctx, method, path, body, nil); err != nil {
+		log.Printf("Error flushing %d operations to %s: %s", len(ops), name, err)
+
+		for _, op := range ops {
+			op.span.RecordError(err)
+		}
+	}
+
+	for _, op := range ops {
+		op.span.End()
+	}
+}
@@ -0,0 +1,171 @@
+package meilisearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// ErrHTTP represents non-2xx responses from Meilisearch.
+var ErrHTTP = errors.New("HTTP Error")
+
+// primaryKey is the attribute holding document ID's.
+const primaryKey = "id"
+
+// Client for a Meilisearch instance.
+type Client struct {
+	cfg        *ClientConfig
+	httpClient *http.Client
+	baseURL    *url.URL
+	attributes *metadataAttributes
+
+	bulkWriter *bulkWriter
+	bulkGetter *bulkGetter
+
+	*instr.Instrumentation
+}
+
+// NewClient returns a configured Meilisearch client, or an error.
+func NewClient(cfg *ClientConfig, i *instr.Instrumentation) (*Client, error) {
+	if cfg == nil {
+		panic("NewClient ClientConfig cannot be nil.")
+	}
+
+	if i == nil {
+		panic("NewClient Instrumentation cannot be nil.")
+	}
+
+	baseURL, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Meilisearch URL: %w", err)
+	}
+
+	c := &Client{
+		cfg:             cfg,
+		httpClient:      &http.Client{Transport: cfg.Transport},
+		baseURL:         baseURL,
+		attributes:      newMetadataAttributes(cfg.MetadataAttributes),
+		Instrumentation: i,
+	}
+
+	c.bulkWriter = newBulkWriter(c, cfg.BulkWriterBatchSize, cfg.BulkWriterFlushInterval)
+	c.bulkGetter = newBulkGetter(c, cfg.BulkGetterBatchSize, cfg.BulkGetterBatchTimeout)
+
+	return c, nil
+}
+
+// Work starts (and stops) the client's batching workers. Pending writes are flushed when the context closes.
+func (c *Client) Work(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error { return c.bulkWriter.work(ctx) })
+	g.Go(func() error { return c.bulkGetter.work(ctx) })
+
+	return g.Wait()
+}
+
+// NewIndex returns a new index given the name.
+func (c *Client) NewIndex(name string) *Index {
+	return New(c, &Config{Name: name})
+}
+
+// CreateIndex creates an index (when it doesn't exist yet) and configures its attributes with settings.
+func (c *Client) CreateIndex(ctx context.Context, name string, settings *Settings) error {
+	err := c.do(ctx, http.MethodPost, "/indexes", map[string]string{
+		"uid":        name,
+		"primaryKey": primaryKey,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	searchable := settings.Searchable
+	if settings.Metadata {
+		searchable = append(append([]string{}, searchable...), c.attributes.names()...)
+	}
+
+	if len(searchable) == 0 {
+		// All attributes, the default.
+		searchable = []string{"*"}
+	}
+
+	// Documents are fetched by filtering on their ID.
+	filterable := append([]string{primaryKey}, settings.Filterable...)
+
+	sortable := settings.Sortable
+	if sortable == nil {
+		sortable = []string{}
+	}
+
+	return c.do(ctx, http.MethodPatch, indexPath(name, "settings"), map[string]interface{}{
+		"filterableAttributes": filterable,
+		"sortableAttributes":   sortable,
+		"searchableAttributes": searchable,
+	}, nil)
+}
+
+func indexPath(name string, elem ...string) string {
+	return "/indexes/" + url.PathEscape(name) + "/" + strings.Join(elem, "/")
+}
+
+// do performs a JSON request, decoding the response into dst when it's not nil.
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, dst interface{}) error {
+	var reqBody io.Reader
+
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			// Errors here are programming errors.
+			panic(fmt.Sprintf("encoding request body: %s", err))
+		}
+
+		reqBody = bytes.NewReader(b)
+	}
+
+	u := *c.baseURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		panic(fmt.Sprintf("creating request: %s", err))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error executing request: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("%w: %s %s: %s (%s)", ErrHTTP, method, path, res.Status, msg)
+	}
+
+	if debug {
+		log.Printf("meilisearch: %s %s: %s", method, path, res.Status)
+	}
+
+	if dst == nil {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(dst)
+}
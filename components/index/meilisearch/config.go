@@ -0,0 +1,41 @@
+package meilisearch
+
+import (
+	"net/http"
+	"time"
+)
+
+// ClientConfig configures the Meilisearch client.
+type ClientConfig struct {
+	URL       string
+	APIKey    string // Optional API key, sent as bearer token.
+	Transport http.RoundTripper
+
+	BulkWriterBatchSize     int           // Flush write operations when this many are pending.
+	BulkWriterFlushInterval time.Duration // Flush pending write operations after this time.
+
+	BulkGetterBatchSize    int           // Maximum number of documents fetched in a single request.
+	BulkGetterBatchTimeout time.Duration // Fetch documents after this time, even when the batch is not full.
+
+	MetadataAttributes []string // Metadata fields stored as (flattened) attributes; other metadata is dropped.
+}
+
+// Config represents the configuration for a Meilisearch index.
+type Config struct {
+	Name string
+}
+
+// DefaultClientConfig returns the default configuration for the client.
+func DefaultClientConfig() *ClientConfig {
+	return &ClientConfig{
+		URL:                     "http://localhost:7700",
+		BulkWriterBatchSize:     1000,
+		BulkWriterFlushInterval: 5 * time.Second,
+		BulkGetterBatchSize:     48,
+		BulkGetterBatchTimeout:  150 * time.Millisecond,
+		MetadataAttributes: []string{
+			"title", "dc:title", "dc:creator", "dc:description", "dc:subject",
+			"Content-Type", "language", "xmpDM:artist", "xmpDM:album",
+		},
+	}
+}
@@ -0,0 +1,184 @@
+package meilisearch
+
+import (
+	"encoding/base32"
+	"strings"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+const (
+	metadataField  = "metadata"
+	metadataPrefix = "metadata_"
+	encodedPrefix  = "_" // Prefix of encoded document ID's.
+)
+
+// idEncoding only yields characters allowed in Meilisearch document ID's.
+var idEncoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// validID returns whether id is a valid Meilisearch document ID: alphanumeric, `-` and `_`.
+func validID(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	for _, r := range id {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// encodeID returns the Meilisearch document ID for id. CID's are used as-is, other ID's (e.g. domain
+// names) are base32 encoded behind encodedPrefix; as are ID's starting with encodedPrefix, so that
+// encoding is reversible.
+func encodeID(id string) string {
+	if validID(id) && !strings.HasPrefix(id, encodedPrefix) {
+		return id
+	}
+
+	return encodedPrefix + idEncoding.EncodeToString([]byte(id))
+}
+
+// decodeID reverses encodeID.
+func decodeID(id string) string {
+	if !strings.HasPrefix(id, encodedPrefix) {
+		return id
+	}
+
+	b, err := idEncoding.DecodeString(strings.TrimPrefix(id, encodedPrefix))
+	if err != nil {
+		// Not written by encodeID.
+		return id
+	}
+
+	return string(b)
+}
+
+// metadataAttributes maps whitelisted metadata fields to flat attribute names and back.
+type metadataAttributes struct {
+	toAttribute map[string]string
+	toField     map[string]string
+}
+
+// sanitize returns an attribute name for a metadata field, replacing characters which are not
+// allowed or are meaningful in Meilisearch attribute names (e.g. `.` for nested fields).
+func sanitize(field string) string {
+	return metadataPrefix + strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+
+		return '_'
+	}, field)
+}
+
+func newMetadataAttributes(fields []string) *metadataAttributes {
+	a := &metadataAttributes{
+		toAttribute: make(map[string]string, len(fields)),
+		toField:     make(map[string]string, len(fields)),
+	}
+
+	for _, f := range fields {
+		attr := sanitize(f)
+		a.toAttribute[f] = attr
+		a.toField[attr] = f
+	}
+
+	return a
+}
+
+// names returns the attribute names for all whitelisted metadata fields.
+func (a *metadataAttributes) names() []string {
+	names := make([]string, 0, len(a.toField))
+	for attr := range a.toField {
+		names = append(names, attr)
+	}
+
+	return names
+}
+
+// flatten moves whitelisted fields from the `metadata` object to top-level attributes, dropping the rest.
+func (a *metadataAttributes) flatten(doc map[string]interface{}) {
+	metadata, ok := doc[metadataField].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	delete(doc, metadataField)
+
+	for field, v := range metadata {
+		if attr, ok := a.toAttribute[field]; ok {
+			doc[attr] = v
+		}
+	}
+}
+
+// unflatten reverses flatten.
+func (a *metadataAttributes) unflatten(doc map[string]interface{}) {
+	var metadata map[string]interface{}
+
+	for attr, v := range doc {
+		field, ok := a.toField[attr]
+		if !ok {
+			continue
+		}
+
+		if metadata == nil {
+			metadata = make(map[string]interface{})
+		}
+
+		metadata[field] = v
+		delete(doc, attr)
+	}
+
+	if metadata != nil {
+		doc[metadataField] = metadata
+	}
+}
+
+// toDocument converts properties into a Meilisearch document with the given id.
+func (a *metadataAttributes) toDocument(id string, properties interface{}) (map[string]interface{}, error) {
+	doc, err := index.ToDocument(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	a.flatten(doc)
+	doc[primaryKey] = encodeID(id)
+
+	return doc, nil
+}
+
+// fromDocument converts a Meilisearch document back into its original JSON form.
+func (a *metadataAttributes) fromDocument(doc map[string]interface{}) map[string]interface{} {
+	delete(doc, primaryKey)
+	a.unflatten(doc)
+
+	return doc
+}
+
+// attributesFor returns the top-level attributes required to select a (dotted) field.
+func (a *metadataAttributes) attributesFor(field string) []string {
+	if field == metadataField {
+		return a.names()
+	}
+
+	if !strings.HasPrefix(field, metadataField+".") {
+		return []string{strings.SplitN(field, ".", 2)[0]}
+	}
+
+	path := strings.TrimPrefix(field, metadataField+".")
+
+	var attrs []string
+
+	for f, attr := range a.toAttribute {
+		if path == f || strings.HasPrefix(path, f+".") {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	return attrs
+}
@@ -0,0 +1,61 @@
+package meilisearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	assert.Equal(t, "metadata_dc_title", sanitize("dc:title"))
+	assert.Equal(t, "metadata_Content_Type", sanitize("Content-Type"))
+	assert.Equal(t, "metadata_a_b", sanitize("a.b"))
+}
+
+func TestEncodeID(t *testing.T) {
+	assert.Equal(t, "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp", encodeID("QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"))
+
+	for _, id := range []string{"example.com", "_id", "ipfs.io/ipns/x"} {
+		encoded := encodeID(id)
+		assert.True(t, validID(encoded), encoded)
+		assert.NotEqual(t, id, encoded)
+		assert.Equal(t, id, decodeID(encoded))
+	}
+}
+
+func TestDocumentRoundtrip(t *testing.T) {
+	a := newMetadataAttributes([]string{"title", "dc:title"})
+
+	doc, err := a.toDocument("id1", map[string]interface{}{
+		"content": "text",
+		"metadata": map[string]interface{}{
+			"title":    "A",
+			"dc:title": "B",
+			"other":    "C",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":                "id1",
+		"content":           "text",
+		"metadata_title":    "A",
+		"metadata_dc_title": "B",
+	}, doc)
+
+	assert.Equal(t, map[string]interface{}{
+		"content": "text",
+		"metadata": map[string]interface{}{
+			"title":    "A",
+			"dc:title": "B",
+		},
+	}, a.fromDocument(doc))
+}
+
+func TestAttributesFor(t *testing.T) {
+	a := newMetadataAttributes([]string{"title"})
+
+	assert.Equal(t, []string{"references"}, a.attributesFor("references.name"))
+	assert.Equal(t, []string{"metadata_title"}, a.attributesFor("metadata"))
+	assert.Equal(t, []string{"metadata_title"}, a.attributesFor("metadata.title"))
+	assert.Empty(t, a.attributesFor("metadata.other"))
+}
@@ -0,0 +1,117 @@
+package meilisearch
+
+import (
+	"context"
+	"log"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+const debug bool = false
+
+// Index wraps a Meilisearch index to store documents.
+//
+// Contrary to OpenSearch, writes do not enforce create or update semantics: Index replaces existing
+// documents and Update creates missing documents.
+type Index struct {
+	cfg *Config
+	c   *Client
+}
+
+// New returns a new index.
+func New(client *Client, cfg *Config) *Index {
+	if client == nil {
+		panic("Index.New Client cannot be nil.")
+	}
+
+	if cfg == nil {
+		panic("Index.New Config cannot be nil.")
+	}
+
+	return &Index{
+		c:   client,
+		cfg: cfg,
+	}
+}
+
+// String returns the name of the index, for convenient logging.
+func (i *Index) String() string {
+	return i.cfg.Name
+}
+
+// write queues an operation on the client's bulk writer.
+func (i *Index) write(ctx context.Context, a action, id string, properties interface{}) error {
+	// The span ends when the operation is flushed.
+	ctx, span := i.c.Tracer.Start(ctx, "index.meilisearch.write")
+
+	op := operation{
+		index:  i.cfg.Name,
+		action: a,
+		id:     id,
+		span:   span,
+	}
+
+	if a != deleteAction {
+		doc, err := i.c.attributes.toDocument(id, properties)
+		if err != nil {
+			// Errors here are programming errors.
+			panic(err)
+		}
+
+		op.doc = doc
+	}
+
+	err := i.c.bulkWriter.add(ctx, op)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error adding to bulk writer.")
+		span.End()
+	}
+
+	return err
+}
+
+// Index a document's properties, identified by id.
+func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
+	return i.write(ctx, addAction, id, properties)
+}
+
+// Update a document's properties, given id. Only the given top-level properties are replaced.
+func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
+	return i.write(ctx, updateAction, id, properties)
+}
+
+// Delete item from index.
+func (i *Index) Delete(ctx context.Context, id string) error {
+	return i.write(ctx, deleteAction, id, nil)
+}
+
+// Get retreives `fields` from document with `id` from the index, returning:
+// - (true, decoding_error) if found (decoding error set when errors in json)
+// - (false, nil) when not found
+// - (false, error) otherwise
+func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	ctx, span := i.c.Tracer.Start(ctx, "index.meilisearch.Get")
+	defer span.End()
+
+	resp := <-i.c.bulkGetter.get(ctx, i.cfg.Name, id, dst, fields)
+
+	if debug {
+		if resp.found {
+			log.Printf("meilisearch: found %s in %s", id, i)
+		} else if resp.err != nil {
+			log.Printf("meilisearch: error getting %s in %s: %v", id, i, resp.err)
+		}
+	}
+
+	if resp.err != nil {
+		span.RecordError(resp.err)
+	}
+
+	return resp.found, resp.err
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ index.Index = &Index{}
@@ -0,0 +1,449 @@
+package meilisearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testID = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+
+// fakeServer implements the subset of the Meilisearch API used by the client, in memory.
+type fakeServer struct {
+	sync.Mutex
+	docs     map[string]map[string]map[string]interface{}
+	settings map[string]map[string][]string
+	requests []string
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "indexes" {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	docs := f.docs[parts[1]]
+	if docs == nil {
+		docs = make(map[string]map[string]interface{})
+		f.docs[parts[1]] = docs
+	}
+
+	switch strings.Join(parts[2:], "/") {
+	case "settings":
+		var settings map[string][]string
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		f.settings[parts[1]] = settings
+	case "documents":
+		var batch []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		for _, doc := range batch {
+			id := doc["id"].(string)
+			if !validID(id) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if existing, ok := docs[id]; ok && r.Method == http.MethodPut {
+				for k, v := range doc {
+					existing[k] = v
+				}
+			} else {
+				docs[id] = doc
+			}
+		}
+	case "documents/delete-batch":
+		var ids []string
+		if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		for _, id := range ids {
+			delete(docs, id)
+		}
+	case "documents/fetch":
+		var req struct {
+			Filter string   `json:"filter"`
+			Fields []string `json:"fields"`
+			Offset int      `json:"offset"`
+			Limit  int      `json:"limit"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var ids []string
+		if req.Filter == "" {
+			// Page through all documents, ordered by id.
+			for id := range docs {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+
+			if req.Offset > len(ids) {
+				req.Offset = len(ids)
+			}
+			ids = ids[req.Offset:]
+
+			if req.Limit < len(ids) {
+				ids = ids[:req.Limit]
+			}
+		} else if err := json.Unmarshal([]byte(strings.TrimPrefix(req.Filter, "id IN ")), &ids); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		results := []map[string]interface{}{}
+		for _, id := range ids {
+			if doc, ok := docs[id]; ok {
+				result := make(map[string]interface{})
+				for k, v := range doc {
+					if req.Fields == nil || contains(req.Fields, k) {
+						result[k] = v
+					}
+				}
+				results = append(results, result)
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+		return
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+type IndexTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	cancel func()
+	now    time.Time
+	done   chan struct{}
+
+	fake   *fakeServer
+	server *httptest.Server
+	client *Client
+	files  *Index
+}
+
+func (s *IndexTestSuite) SetupTest() {
+	var err error
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.now = time.Now().Truncate(time.Second).UTC()
+
+	s.fake = &fakeServer{
+		docs:     make(map[string]map[string]map[string]interface{}),
+		settings: make(map[string]map[string][]string),
+	}
+	s.server = httptest.NewServer(s.fake)
+
+	s.client, err = NewClient(&ClientConfig{
+		URL:                     s.server.URL,
+		BulkWriterBatchSize:     10,
+		BulkWriterFlushInterval: 10 * time.Millisecond,
+		BulkGetterBatchTimeout:  10 * time.Millisecond,
+		MetadataAttributes:      []string{"title", "dc:creator"},
+	}, instr.New())
+	s.Require().NoError(err)
+
+	s.files = s.client.NewIndex("ipfs_files")
+
+	s.done = make(chan struct{})
+	go func() {
+		s.client.Work(s.ctx)
+		close(s.done)
+	}()
+}
+
+func (s *IndexTestSuite) TearDownTest() {
+	s.cancel()
+	<-s.done
+	s.server.Close()
+}
+
+// flush waits for pending writes to be sent.
+func (s *IndexTestSuite) flush() {
+	time.Sleep(50 * time.Millisecond)
+}
+
+func (s *IndexTestSuite) testFile() *indexTypes.File {
+	return &indexTypes.File{
+		Document: indexTypes.Document{
+			FirstSeen: s.now,
+			LastSeen:  s.now,
+			References: indexTypes.References{
+				{ParentHash: "QmParent", Name: "holiday.jpg"},
+			},
+			Size: 15,
+		},
+		Content: "the quick brown fox",
+		Metadata: indexTypes.Metadata{
+			"title":         []interface{}{"Fantastic voyage"},
+			"dc:creator":    []interface{}{"Jules Verne"},
+			"X-TIKA:Parsed": []interface{}{"dropped"},
+		},
+	}
+}
+
+func (s *IndexTestSuite) TestString() {
+	s.Equal("ipfs_files", s.files.String())
+}
+
+func (s *IndexTestSuite) TestCreateIndex() {
+	s.NoError(s.client.CreateIndex(s.ctx, "ipfs_files", FilesSettings()))
+	s.NoError(s.client.CreateIndex(s.ctx, "ipns_names", NamesSettings()))
+	s.NoError(s.client.CreateIndex(s.ctx, "ipfs_partials", PartialsSettings()))
+
+	// Settings are configured per index; metadata attributes are only searchable in files.
+	files := s.fake.settings["ipfs_files"]
+	s.Equal([]string{"content", "references.name", "reference_names", "urls", "metadata_title", "metadata_dc_creator"}, files["searchableAttributes"])
+	s.Contains(files["filterableAttributes"], "references.parent_hash")
+	s.Contains(files["sortableAttributes"], "size")
+
+	s.Equal(map[string][]string{
+		"searchableAttributes": {"name"},
+		"filterableAttributes": {"id", "target", "history.target", "last-seen", "first-seen"},
+		"sortableAttributes":   {"last-seen", "first-seen"},
+	}, s.fake.settings["ipns_names"])
+
+	s.Equal(map[string][]string{
+		"searchableAttributes": {"*"},
+		"filterableAttributes": {"id"},
+		"sortableAttributes":   {},
+	}, s.fake.settings["ipfs_partials"])
+}
+
+func (s *IndexTestSuite) TestIndexGet() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.flush()
+
+	dst := new(indexTypes.File)
+	found, err := s.files.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.True(found)
+
+	expected := s.testFile()
+	delete(expected.Metadata, "X-TIKA:Parsed")
+	s.Equal(expected, dst)
+}
+
+func (s *IndexTestSuite) TestMetadataFlattened() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.flush()
+
+	s.fake.Lock()
+	doc := s.fake.docs["ipfs_files"][testID]
+	s.fake.Unlock()
+
+	s.Equal(testID, doc["id"])
+	s.Equal([]interface{}{"Fantastic voyage"}, doc["metadata_title"])
+	s.Equal([]interface{}{"Jules Verne"}, doc["metadata_dc_creator"])
+	s.NotContains(doc, "metadata")
+	s.NotContains(doc, "metadata_X_TIKA_Parsed")
+}
+
+func (s *IndexTestSuite) TestDottedID() {
+	names := s.client.NewIndex("ipfs_names")
+	id := "example.com"
+
+	s.NoError(names.Index(s.ctx, id, &indexTypes.Update{References: s.testFile().References}))
+	s.flush()
+
+	s.fake.Lock()
+	s.Len(s.fake.docs["ipfs_names"], 1)
+	s.fake.Unlock()
+
+	dst := new(indexTypes.Update)
+	found, err := names.Get(s.ctx, id, dst)
+	s.NoError(err)
+	s.True(found)
+	s.Equal(s.testFile().References, dst.References)
+
+	s.NoError(names.Delete(s.ctx, id))
+	s.flush()
+
+	s.fake.Lock()
+	s.Empty(s.fake.docs["ipfs_names"])
+	s.fake.Unlock()
+}
+
+func (s *IndexTestSuite) TestGetNotFound() {
+	dst := new(indexTypes.File)
+	found, err := s.files.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.False(found)
+}
+
+func (s *IndexTestSuite) TestGetFields() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.flush()
+
+	dst := new(indexTypes.File)
+	found, err := s.files.Get(s.ctx, testID, dst, "references", "metadata.title")
+	s.NoError(err)
+	s.True(found)
+
+	s.Equal(s.testFile().References, dst.References)
+	s.Equal(indexTypes.Metadata{"title": []interface{}{"Fantastic voyage"}}, dst.Metadata)
+	s.Empty(dst.Content)
+}
+
+func (s *IndexTestSuite) TestScan() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.NoError(s.files.Index(s.ctx, "QmOther", &indexTypes.File{Content: "a lazy dog"}))
+	s.flush()
+
+	sources := make(map[string]string)
+	err := s.files.Scan(s.ctx, func(id string, source json.RawMessage) error {
+		sources[id] = string(source)
+		return nil
+	}, "size", "metadata.title")
+
+	s.NoError(err)
+	s.Equal(map[string]string{
+		testID:    `{"metadata":{"title":["Fantastic voyage"]},"size":15}`,
+		"QmOther": `{"size":0}`,
+	}, sources)
+}
+
+func (s *IndexTestSuite) TestBatchedGet() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.flush()
+
+	s.fake.Lock()
+	s.fake.requests = nil
+	s.fake.Unlock()
+
+	var wg sync.WaitGroup
+
+	ids := []string{testID, testID, "QmMissing"}
+	found := make([]bool, len(ids))
+
+	for n, id := range ids {
+		wg.Add(1)
+		go func(n int, id string) {
+			defer wg.Done()
+
+			var err error
+			found[n], err = s.files.Get(s.ctx, id, new(indexTypes.Update), "references", "last-seen")
+			s.NoError(err)
+		}(n, id)
+	}
+
+	wg.Wait()
+
+	s.Equal([]bool{true, true, false}, found)
+
+	s.fake.Lock()
+	defer s.fake.Unlock()
+	s.Equal([]string{"POST /indexes/ipfs_files/documents/fetch"}, s.fake.requests)
+}
+
+func (s *IndexTestSuite) TestUpdate() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+
+	later := s.now.Add(time.Hour)
+	refs := indexTypes.References{
+		{ParentHash: "QmParent", Name: "holiday.jpg"},
+		{ParentHash: "QmOther", Name: "beach.jpg"},
+	}
+
+	s.NoError(s.files.Update(s.ctx, testID, &indexTypes.Update{
+		References: refs,
+		LastSeen:   &later,
+	}))
+	s.flush()
+
+	dst := new(indexTypes.File)
+	found, err := s.files.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.True(found)
+
+	s.Equal(refs, dst.References)
+	s.Equal(later, dst.LastSeen)
+	s.Equal(s.now, dst.FirstSeen)
+	s.Equal("the quick brown fox", dst.Content)
+}
+
+func (s *IndexTestSuite) TestDelete() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.NoError(s.files.Delete(s.ctx, testID))
+	s.flush()
+
+	found, err := s.files.Get(s.ctx, testID, new(indexTypes.File))
+	s.NoError(err)
+	s.False(found)
+}
+
+func (s *IndexTestSuite) TestWritesBatched() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.NoError(s.files.Index(s.ctx, "QmOther", s.testFile()))
+	s.NoError(s.files.Delete(s.ctx, "QmOther"))
+	s.flush()
+
+	s.fake.Lock()
+	defer s.fake.Unlock()
+
+	s.Equal([]string{
+		"POST /indexes/ipfs_files/documents",
+		"POST /indexes/ipfs_files/documents/delete-batch",
+	}, s.fake.requests)
+	s.Contains(s.fake.docs["ipfs_files"], testID)
+	s.NotContains(s.fake.docs["ipfs_files"], "QmOther")
+}
+
+func (s *IndexTestSuite) TestFlushOnClose() {
+	// Closing immediately after queueing; before the flush interval.
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+
+	s.cancel()
+	<-s.done
+
+	s.fake.Lock()
+	defer s.fake.Unlock()
+	s.Contains(s.fake.docs["ipfs_files"], testID)
+}
+
+func TestIndexTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexTestSuite))
+}
@@ -0,0 +1,73 @@
+package meilisearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+// scanBatchSize is the number of documents fetched at once when scanning.
+const scanBatchSize = 1000
+
+// scanBatch returns up to scanBatchSize documents starting at offset, with the attributes required for fields.
+func (i *Index) scanBatch(ctx context.Context, offset int, fields []string) ([]map[string]interface{}, error) {
+	body := map[string]interface{}{
+		"offset": offset,
+		"limit":  scanBatchSize,
+	}
+
+	if attrs := i.c.attributeFields([]*getRequest{{fields: fields}}); attrs != nil {
+		body["fields"] = attrs
+	}
+
+	var res struct {
+		Results []map[string]interface{} `json:"results"`
+	}
+
+	if err := i.c.do(ctx, http.MethodPost, indexPath(i.cfg.Name, "documents", "fetch"), body, &res); err != nil {
+		return nil, fmt.Errorf("error scanning documents of %s: %w", i, err)
+	}
+
+	return res.Results, nil
+}
+
+// Scan calls f for every document, until f returns an error. Documents are fetched in pages, so that documents
+// written while scanning may be skipped or returned twice. Pending writes are not included.
+func (i *Index) Scan(ctx context.Context, f func(id string, source json.RawMessage) error, fields ...string) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.meilisearch.Scan")
+	defer span.End()
+
+	for offset := 0; ; offset += scanBatchSize {
+		batch, err := i.scanBatch(ctx, offset, fields)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		for _, doc := range batch {
+			id, ok := doc[primaryKey].(string)
+			if !ok {
+				continue
+			}
+
+			source, err := json.Marshal(index.SelectFields(i.c.attributes.fromDocument(doc), fields...))
+			if err != nil {
+				return err
+			}
+
+			if err := f(decodeID(id), source); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < scanBatchSize {
+			return nil
+		}
+	}
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ index.Scanner = &Index{}
@@ -0,0 +1,54 @@
+package meilisearch
+
+import (
+	"context"
+	"net/http"
+)
+
+// Hit represents a single document matching a query.
+type Hit struct {
+	ID string
+}
+
+// SearchResult represents a page of results for a query.
+type SearchResult struct {
+	Total uint64 // Estimated total number of matching documents.
+	Hits  []Hit
+}
+
+// Search performs a full text search (ref: https://www.meilisearch.com/docs/reference/api/search),
+// returning up to `size` hits starting at `from`.
+func (i *Index) Search(ctx context.Context, query string, from, size int) (*SearchResult, error) {
+	ctx, span := i.c.Tracer.Start(ctx, "index.meilisearch.Search")
+	defer span.End()
+
+	body := map[string]interface{}{
+		"q":                    query,
+		"offset":               from,
+		"limit":                size,
+		"attributesToRetrieve": []string{primaryKey},
+	}
+
+	var res struct {
+		Hits []struct {
+			ID string `json:"id"`
+		} `json:"hits"`
+		EstimatedTotalHits uint64 `json:"estimatedTotalHits"`
+	}
+
+	if err := i.c.do(ctx, http.MethodPost, indexPath(i.cfg.Name, "search"), body, &res); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	result := &SearchResult{
+		Total: res.EstimatedTotalHits,
+		Hits:  make([]Hit, len(res.Hits)),
+	}
+
+	for n, h := range res.Hits {
+		result.Hits[n] = Hit{ID: decodeID(h.ID)}
+	}
+
+	return result, nil
+}
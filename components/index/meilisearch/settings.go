@@ -0,0 +1,100 @@
+package meilisearch
+
+// Settings configures the attributes of an index, for the type of documents stored in it.
+type Settings struct {
+	Searchable []string // Attributes searched, in order of importance.
+	Filterable []string
+	Sortable   []string
+
+	Metadata bool // Whether metadata attributes are searchable.
+}
+
+// merge returns s with the attributes of other appended.
+func (s Settings) merge(other Settings) *Settings {
+	return &Settings{
+		Searchable: append(append([]string{}, s.Searchable...), other.Searchable...),
+		Filterable: append(append([]string{}, s.Filterable...), other.Filterable...),
+		Sortable:   append(append([]string{}, s.Sortable...), other.Sortable...),
+		Metadata:   s.Metadata || other.Metadata,
+	}
+}
+
+// documentSettings returns the settings for attributes of indexTypes.Document.
+func documentSettings() Settings {
+	return Settings{
+		Searchable: []string{"references.name", "reference_names"},
+		Filterable: []string{"references.parent_hash", "roots", "site.root", "last-seen", "first-seen", "size"},
+		Sortable:   []string{"last-seen", "first-seen", "size", "site.rank"},
+	}
+}
+
+// FilesSettings returns the settings for an index of indexTypes.File; searchable attributes mirror the default
+// fields of the OpenSearch files index.
+func FilesSettings() *Settings {
+	return Settings{
+		Searchable: []string{"content"},
+	}.merge(documentSettings()).merge(Settings{
+		Searchable: []string{"urls"},
+		Metadata:   true,
+	})
+}
+
+// DirectoriesSettings returns the settings for an index of indexTypes.Directory.
+func DirectoriesSettings() *Settings {
+	return documentSettings().merge(Settings{
+		Searchable: []string{"links.Name"},
+		Filterable: []string{"stats.types.key", "stats.mime_types.key", "stats.depth", "class.type"},
+		Sortable:   []string{"link_count", "stats.total_size", "stats.depth"},
+	})
+}
+
+// InvalidsSettings returns the settings for an index of indexTypes.Invalid.
+func InvalidsSettings() *Settings {
+	return &Settings{}
+}
+
+// PartialsSettings returns the settings for an index of indexTypes.Partial.
+func PartialsSettings() *Settings {
+	return &Settings{}
+}
+
+// ReferencesSettings returns the settings for an index of indexTypes.ReferenceEdge.
+func ReferencesSettings() *Settings {
+	return &Settings{
+		Searchable: []string{"name"},
+		Filterable: []string{"parent_hash", "child_hash", "first-seen"},
+	}
+}
+
+// PagesSettings returns the settings for an index of indexTypes.DirectoryPage.
+func PagesSettings() *Settings {
+	return &Settings{
+		Searchable: []string{"links.Name"},
+		Filterable: []string{"directory"},
+	}
+}
+
+// IPLDSettings returns the settings for an index of indexTypes.IPLD.
+func IPLDSettings() *Settings {
+	return documentSettings().merge(Settings{
+		Searchable: []string{"content"},
+		Filterable: []string{"codec", "links"},
+	})
+}
+
+// NamesSettings returns the settings for an index of indexTypes.Name.
+func NamesSettings() *Settings {
+	return &Settings{
+		Searchable: []string{"name"},
+		Filterable: []string{"target", "history.target", "last-seen", "first-seen"},
+		Sortable:   []string{"last-seen", "first-seen"},
+	}
+}
+
+// ProvidersSettings returns the settings for an index of indexTypes.Providers.
+func ProvidersSettings() *Settings {
+	return &Settings{
+		Filterable: []string{"providers.peer", "provider-count", "last-announced"},
+		Sortable:   []string{"provider-count", "last-announced"},
+	}
+}
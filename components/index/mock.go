@@ -2,7 +2,12 @@ package index
 
 import (
 	"context"
+	"encoding/json"
+	"time"
+
 	"github.com/stretchr/testify/mock"
+
+	"github.com/ipfs-search/ipfs-search/components/index/types"
 )
 
 // Mock mocks the Index interface.
@@ -36,3 +41,68 @@ func (m *Mock) Delete(ctx context.Context, id string) error {
 
 // Compile-time assurance that implementation satisfies interface.
 var _ Index = &Mock{}
+
+// AppenderMock mocks an Index implementing ReferenceAppender.
+type AppenderMock struct {
+	Mock
+}
+
+// AppendReference mocks the AppendReference method on the ReferenceAppender interface.
+func (m *AppenderMock) AppendReference(ctx context.Context, id string, ref types.Reference) error {
+	args := m.Called(ctx, id, ref)
+	return args.Error(0)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ ReferenceAppender = &AppenderMock{}
+
+// ReferencesAppenderMock mocks an Index implementing ReferenceAppender and ReferencesAppender.
+type ReferencesAppenderMock struct {
+	AppenderMock
+}
+
+// AppendReferences mocks the AppendReferences method on the ReferencesAppender interface.
+func (m *ReferencesAppenderMock) AppendReferences(ctx context.Context, id string, refs []types.Reference) error {
+	args := m.Called(ctx, id, refs)
+	return args.Error(0)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ ReferencesAppender = &ReferencesAppenderMock{}
+
+// ProvidersMock mocks an Index implementing ProvidersMerger and Expirer.
+type ProvidersMock struct {
+	Mock
+}
+
+// MergeProviders mocks the MergeProviders method on the ProvidersMerger interface.
+func (m *ProvidersMock) MergeProviders(ctx context.Context, id string, records []types.ProviderRecord, cutoff time.Time, maxProviders int) error {
+	args := m.Called(ctx, id, records, cutoff, maxProviders)
+	return args.Error(0)
+}
+
+// DeleteBefore mocks the DeleteBefore method on the Expirer interface.
+func (m *ProvidersMock) DeleteBefore(ctx context.Context, field string, t time.Time) (int64, error) {
+	args := m.Called(ctx, field, t)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ ProvidersMerger = &ProvidersMock{}
+	_ Expirer         = &ProvidersMock{}
+)
+
+// ScannerMock mocks an Index implementing Scanner.
+type ScannerMock struct {
+	Mock
+}
+
+// Scan mocks the Scan method on the Scanner interface.
+func (m *ScannerMock) Scan(ctx context.Context, f func(id string, source json.RawMessage) error, fields ...string) error {
+	args := m.Called(ctx, f, fields)
+	return args.Error(0)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ Scanner = &ScannerMock{}
@@ -0,0 +1,22 @@
+package multi
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+// ErrorPolicy determines how errors from a target are handled.
+type ErrorPolicy int
+
+const (
+	// FailOnError returns errors from the target to the caller.
+	FailOnError ErrorPolicy = iota
+	// IgnoreErrors logs and counts errors from the target, but does not return them.
+	IgnoreErrors
+)
+
+// Target is a secondary index which writes are fanned out to.
+type Target struct {
+	Index    index.Index
+	Policy   ErrorPolicy // How errors from this target are handled.
+	Fallback bool        // Read from this target when a document is not found on (or cannot be read from) the primary.
+}
@@ -0,0 +1,325 @@
+// Package multi fans out writes to multiple indexes while reading from a primary, e.g. for migrations.
+package multi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const debug bool = false
+
+// Index writes to a primary and secondary indexes simultaneously and reads from the primary, falling back
+// to secondary targets when configured.
+//
+// Errors from the primary are always returned. Documents found on a fallback but not on the primary, as
+// well as writes succeeding on some targets only, are counted as divergence.
+type Index struct {
+	targets  []Target // Primary first.
+	counters *counters
+
+	*instr.Instrumentation
+}
+
+// New returns a new index writing to the primary and all secondaries.
+func New(primary index.Index, secondaries []Target, instr *instr.Instrumentation) *Index {
+	if primary == nil {
+		panic("multi.New primary cannot be nil.")
+	}
+
+	targets := append([]Target{{Index: primary, Policy: FailOnError}}, secondaries...)
+
+	return &Index{
+		targets:         targets,
+		counters:        newCounters(len(targets)),
+		Instrumentation: instr,
+	}
+}
+
+// String returns the name of the index, for convenient logging.
+func (i *Index) String() string {
+	names := make([]string, len(i.targets))
+	for n, t := range i.targets {
+		names[n] = fmt.Sprintf("'%s'", t.Index)
+	}
+
+	return strings.Join(names, " + ")
+}
+
+// Stats returns a snapshot of the divergence and error counters.
+func (i *Index) Stats() Stats {
+	s := Stats{
+		DivergentWrites: i.counters.divergentWrites.Load(),
+		FallbackReads:   i.counters.fallbackReads.Load(),
+		Targets:         make([]TargetStats, len(i.targets)),
+	}
+
+	for n, t := range i.targets {
+		s.Targets[n] = TargetStats{
+			Name:        fmt.Sprint(t.Index),
+			WriteErrors: i.counters.writeErrors[n].Load(),
+			ReadErrors:  i.counters.readErrors[n].Load(),
+		}
+	}
+
+	return s
+}
+
+type indexWrite func(index.Index) error
+
+// write performs f on all targets concurrently, returning the first error from the targets whose errors
+// should be returned.
+func (i *Index) write(ctx context.Context, op, id string, f indexWrite) error {
+	ctx, span := i.Tracer.Start(ctx, "index.multi."+op)
+	defer span.End()
+
+	errs := make([]error, len(i.targets))
+
+	var wg sync.WaitGroup
+	for n, t := range i.targets {
+		n, t := n, t
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[n] = f(t.Index)
+		}()
+	}
+	wg.Wait()
+
+	var (
+		err    error
+		failed int
+	)
+
+	for n, t := range i.targets {
+		if errs[n] == nil {
+			continue
+		}
+
+		if errors.Is(errs[n], index.ErrNotSupported) {
+			// Not supported by a wrapped index; the caller falls back to another operation.
+			err = errs[n]
+			continue
+		}
+
+		failed++
+		i.counters.writeErrors[n].Add(1)
+		span.RecordError(errs[n])
+
+		if t.Policy == IgnoreErrors {
+			log.Printf("multi %s: ignoring error in %s of %s to %s: %s", i, op, id, t.Index, errs[n])
+			continue
+		}
+
+		if err == nil {
+			err = errs[n]
+		}
+	}
+
+	if failed > 0 && failed < len(i.targets) {
+		i.counters.divergentWrites.Add(1)
+	}
+
+	return err
+}
+
+// Index a document's properties, identified by id.
+func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
+	return i.write(ctx, "Index", id, func(t index.Index) error {
+		return t.Index(ctx, id, properties)
+	})
+}
+
+// Update a document's properties, given id.
+func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
+	return i.write(ctx, "Update", id, func(t index.Index) error {
+		return t.Update(ctx, id, properties)
+	})
+}
+
+// Delete item from index.
+func (i *Index) Delete(ctx context.Context, id string) error {
+	return i.write(ctx, "Delete", id, func(t index.Index) error {
+		return t.Delete(ctx, id)
+	})
+}
+
+// AppendReference appends a reference on all targets, returning index.ErrNotSupported when any of the
+// targets does not support appending references (regardless of its error policy).
+func (i *Index) AppendReference(ctx context.Context, id string, ref types.Reference) error {
+	for _, t := range i.targets {
+		if _, ok := t.Index.(index.ReferenceAppender); !ok {
+			return index.ErrNotSupported
+		}
+	}
+
+	return i.write(ctx, "AppendReference", id, func(t index.Index) error {
+		return index.AppendReference(ctx, t, id, ref)
+	})
+}
+
+// AppendReferences appends references on all targets, like AppendReference.
+func (i *Index) AppendReferences(ctx context.Context, id string, refs []types.Reference) error {
+	for _, t := range i.targets {
+		if !index.SupportsAppendReference(t.Index) {
+			return index.ErrNotSupported
+		}
+	}
+
+	return i.write(ctx, "AppendReferences", id, func(t index.Index) error {
+		return index.AppendReferences(ctx, t, id, refs)
+	})
+}
+
+// MergeProviders merges provider records on all targets, returning index.ErrNotSupported when any of the
+// targets does not support merging providers (regardless of its error policy).
+func (i *Index) MergeProviders(ctx context.Context, id string, records []types.ProviderRecord, cutoff time.Time, maxProviders int) error {
+	for _, t := range i.targets {
+		if _, ok := t.Index.(index.ProvidersMerger); !ok {
+			return index.ErrNotSupported
+		}
+	}
+
+	return i.write(ctx, "MergeProviders", id, func(t index.Index) error {
+		return index.MergeProviders(ctx, t, id, records, cutoff, maxProviders)
+	})
+}
+
+// DeleteBefore deletes expired documents on all targets supporting it, returning the number of documents
+// deleted from the primary; index.ErrNotSupported when the primary does not support it.
+func (i *Index) DeleteBefore(ctx context.Context, field string, t time.Time) (int64, error) {
+	if _, ok := i.targets[0].Index.(index.Expirer); !ok {
+		return 0, index.ErrNotSupported
+	}
+
+	var deleted int64
+
+	err := i.write(ctx, "DeleteBefore", field, func(target index.Index) error {
+		n, err := index.DeleteBefore(ctx, target, field, t)
+		if errors.Is(err, index.ErrNotSupported) {
+			// Documents expire on their next write instead.
+			return nil
+		}
+
+		if target == i.targets[0].Index {
+			deleted = n
+		}
+
+		return err
+	})
+
+	return deleted, err
+}
+
+// Get retreives `fields` from document with `id` from the primary. When the document is not found, or
+// on errors, fallback targets are tried in order. Errors from the primary are only returned when no
+// fallback target has the document.
+//
+// Documents found on a fallback are copied to the primary (read-repair), so that subsequent updates do not
+// fail on the primary. Fallback targets hence need to return complete documents, e.g. without caching.
+// When the copy fails, the document is reported as not found along with the error.
+func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	ctx, span := i.Tracer.Start(ctx, "index.multi.Get")
+	defer span.End()
+
+	found, err := i.targets[0].Index.Get(ctx, id, dst, fields...)
+	if found {
+		return found, err
+	}
+
+	if err != nil {
+		i.counters.readErrors[0].Add(1)
+		span.RecordError(err)
+	}
+
+	for n, t := range i.targets[1:] {
+		if !t.Fallback {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		fbFound, fbErr := t.Index.Get(ctx, id, dst, fields...)
+		if fbErr != nil {
+			i.counters.readErrors[n+1].Add(1)
+			span.RecordError(fbErr)
+
+			if t.Policy == FailOnError && err == nil {
+				err = fbErr
+			}
+		}
+
+		if fbFound {
+			if debug {
+				log.Printf("multi %s: %s found on fallback %s", i, id, t.Index)
+			}
+
+			i.counters.fallbackReads.Add(1)
+
+			if repairErr := i.repair(ctx, t.Index, id); repairErr != nil {
+				i.counters.writeErrors[0].Add(1)
+				span.RecordError(repairErr)
+
+				return false, repairErr
+			}
+
+			return true, fbErr
+		}
+	}
+
+	return false, err
+}
+
+// repair copies the document with id from fallback to the primary. Documents created concurrently on the primary
+// are left as-is.
+func (i *Index) repair(ctx context.Context, fallback index.Index, id string) error {
+	doc := make(map[string]interface{})
+
+	found, err := fallback.Get(ctx, id, &doc)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		// Deleted in the meantime.
+		return nil
+	}
+
+	if debug {
+		log.Printf("multi %s: copying %s from fallback %s", i, id, fallback)
+	}
+
+	err = i.targets[0].Index.Index(ctx, id, doc)
+	if errors.Is(err, index.ErrDocumentExists) {
+		return nil
+	}
+
+	return err
+}
+
+// Scan calls f for every document in the primary; fallback targets are not scanned.
+func (i *Index) Scan(ctx context.Context, f func(id string, source json.RawMessage) error, fields ...string) error {
+	return index.Scan(ctx, i.targets[0].Index, f, fields...)
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ index.Index              = &Index{}
+	_ index.ReferenceAppender  = &Index{}
+	_ index.ReferencesAppender = &Index{}
+	_ index.ProvidersMerger    = &Index{}
+	_ index.Expirer            = &Index{}
+	_ index.Scanner            = &Index{}
+)
@@ -0,0 +1,239 @@
+package multi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/index/cache"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testID = "testID"
+
+var (
+	testErr   = errors.New("errr")
+	testProps = map[string]interface{}{"a": "b"}
+)
+
+type MultiTestSuite struct {
+	suite.Suite
+	ctx context.Context
+
+	primary  *index.Mock
+	required *index.Mock
+	ignored  *index.Mock
+	i        *Index
+}
+
+func (s *MultiTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.primary = &index.Mock{}
+	s.primary.Test(s.T())
+	s.required = &index.Mock{}
+	s.required.Test(s.T())
+	s.ignored = &index.Mock{}
+	s.ignored.Test(s.T())
+
+	s.i = New(s.primary, []Target{
+		{Index: s.required, Policy: FailOnError},
+		{Index: s.ignored, Policy: IgnoreErrors, Fallback: true},
+	}, instr.New())
+}
+
+func (s *MultiTestSuite) TearDownTest() {
+	s.primary.AssertExpectations(s.T())
+	s.required.AssertExpectations(s.T())
+	s.ignored.AssertExpectations(s.T())
+}
+
+func (s *MultiTestSuite) TestIndex() {
+	for _, m := range []*index.Mock{s.primary, s.required, s.ignored} {
+		m.On("Index", mock.Anything, testID, testProps).Return(nil).Once()
+	}
+
+	s.NoError(s.i.Index(s.ctx, testID, testProps))
+	s.Zero(s.i.Stats().DivergentWrites)
+}
+
+func (s *MultiTestSuite) TestUpdateIgnoredError() {
+	s.primary.On("Update", mock.Anything, testID, testProps).Return(nil).Once()
+	s.required.On("Update", mock.Anything, testID, testProps).Return(nil).Once()
+	s.ignored.On("Update", mock.Anything, testID, testProps).Return(testErr).Once()
+
+	s.NoError(s.i.Update(s.ctx, testID, testProps))
+
+	stats := s.i.Stats()
+	s.Equal(int64(1), stats.DivergentWrites)
+	s.Equal([]int64{0, 0, 1}, []int64{
+		stats.Targets[0].WriteErrors,
+		stats.Targets[1].WriteErrors,
+		stats.Targets[2].WriteErrors,
+	})
+}
+
+func (s *MultiTestSuite) TestDeleteRequiredError() {
+	s.primary.On("Delete", mock.Anything, testID).Return(nil).Once()
+	s.required.On("Delete", mock.Anything, testID).Return(testErr).Once()
+	s.ignored.On("Delete", mock.Anything, testID).Return(nil).Once()
+
+	s.ErrorIs(s.i.Delete(s.ctx, testID), testErr)
+	s.Equal(int64(1), s.i.Stats().DivergentWrites)
+}
+
+func (s *MultiTestSuite) TestAllFailedNotDivergent() {
+	for _, m := range []*index.Mock{s.primary, s.required, s.ignored} {
+		m.On("Delete", mock.Anything, testID).Return(testErr).Once()
+	}
+
+	s.ErrorIs(s.i.Delete(s.ctx, testID), testErr)
+	s.Zero(s.i.Stats().DivergentWrites)
+}
+
+func (s *MultiTestSuite) TestGetPrimary() {
+	dst := new(struct{})
+	s.primary.On("Get", mock.Anything, testID, dst, []string{"f"}).Return(true, nil).Once()
+
+	found, err := s.i.Get(s.ctx, testID, dst, "f")
+	s.True(found)
+	s.NoError(err)
+	s.Zero(s.i.Stats().FallbackReads)
+}
+
+// expectRepair expects the document to be read from the fallback and indexed on the primary, returning err.
+func (s *MultiTestSuite) expectRepair(err error) {
+	s.ignored.On("Get", mock.Anything, testID, mock.AnythingOfType("*map[string]interface {}"), []string(nil)).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*map[string]interface{}) = map[string]interface{}{"a": "b"}
+		}).
+		Return(true, nil).
+		Once()
+
+	s.primary.On("Index", mock.Anything, testID, map[string]interface{}{"a": "b"}).Return(err).Once()
+}
+
+func (s *MultiTestSuite) TestGetFallback() {
+	dst := new(struct{})
+	s.primary.On("Get", mock.Anything, testID, dst, []string{"f"}).Return(false, nil).Once()
+	s.ignored.On("Get", mock.Anything, testID, dst, []string{"f"}).Return(true, nil).Once()
+
+	// The document is copied to the primary.
+	s.expectRepair(nil)
+
+	found, err := s.i.Get(s.ctx, testID, dst, "f")
+	s.True(found)
+	s.NoError(err)
+	s.Equal(int64(1), s.i.Stats().FallbackReads)
+}
+
+func (s *MultiTestSuite) TestGetFallbackCreatedConcurrently() {
+	dst := new(struct{})
+	s.primary.On("Get", mock.Anything, testID, dst, []string{"f"}).Return(false, nil).Once()
+	s.ignored.On("Get", mock.Anything, testID, dst, []string{"f"}).Return(true, nil).Once()
+
+	s.expectRepair(index.ErrDocumentExists)
+
+	found, err := s.i.Get(s.ctx, testID, dst, "f")
+	s.True(found)
+	s.NoError(err)
+}
+
+func (s *MultiTestSuite) TestGetFallbackRepairError() {
+	dst := new(struct{})
+	s.primary.On("Get", mock.Anything, testID, dst, []string{"f"}).Return(false, nil).Once()
+	s.ignored.On("Get", mock.Anything, testID, dst, []string{"f"}).Return(true, nil).Once()
+
+	s.expectRepair(testErr)
+
+	// Reported as not found, rather than failing updates later.
+	found, err := s.i.Get(s.ctx, testID, dst, "f")
+	s.False(found)
+	s.ErrorIs(err, testErr)
+	s.Equal(int64(1), s.i.Stats().Targets[0].WriteErrors)
+}
+
+func (s *MultiTestSuite) TestGetPrimaryErrorFallbackFound() {
+	dst := new(struct{})
+	s.primary.On("Get", mock.Anything, testID, dst, []string(nil)).Return(false, testErr).Once()
+	s.ignored.On("Get", mock.Anything, testID, dst, []string(nil)).Return(true, nil).Once()
+	s.expectRepair(nil)
+
+	found, err := s.i.Get(s.ctx, testID, dst)
+	s.True(found)
+	s.NoError(err)
+	s.Equal(int64(1), s.i.Stats().Targets[0].ReadErrors)
+}
+
+func (s *MultiTestSuite) TestGetNotFound() {
+	dst := new(struct{})
+	s.primary.On("Get", mock.Anything, testID, dst, []string(nil)).Return(false, testErr).Once()
+	s.ignored.On("Get", mock.Anything, testID, dst, []string(nil)).Return(false, errors.New("ignored")).Once()
+
+	found, err := s.i.Get(s.ctx, testID, dst)
+	s.False(found)
+	s.ErrorIs(err, testErr)
+	s.Zero(s.i.Stats().FallbackReads)
+}
+
+func (s *MultiTestSuite) TestString() {
+	s.Equal(3, len(strings.Split(s.i.String(), " + ")))
+}
+
+type cacheStruct struct {
+	A string
+}
+
+// TestCache tests composing with cache.Index, caching fanned out writes.
+func (s *MultiTestSuite) TestCache() {
+	caching := &index.Mock{}
+	caching.Test(s.T())
+
+	props := &cacheStruct{"b"}
+	c := cache.New(s.i, caching, cacheStruct{}, instr.New())
+
+	for _, m := range []*index.Mock{s.primary, s.required, s.ignored} {
+		m.On("Index", mock.Anything, testID, props).Return(nil).Once()
+	}
+	caching.On("Index", mock.Anything, testID, props).Return(nil).Once()
+
+	s.NoError(c.Index(s.ctx, testID, props))
+	caching.AssertExpectations(s.T())
+}
+
+func (s *MultiTestSuite) TestMergeProvidersNotSupported() {
+	s.ErrorIs(s.i.MergeProviders(s.ctx, testID, nil, time.Now(), 10), index.ErrNotSupported)
+}
+
+func (s *MultiTestSuite) TestDeleteBefore() {
+	primary := &index.ProvidersMock{}
+	primary.Test(s.T())
+	secondary := &index.ProvidersMock{}
+	secondary.Test(s.T())
+
+	i := New(primary, []Target{
+		{Index: secondary, Policy: IgnoreErrors},
+		{Index: s.ignored, Policy: IgnoreErrors},
+	}, instr.New())
+
+	cutoff := time.Now()
+	primary.On("DeleteBefore", mock.Anything, "last-announced", cutoff).Return(int64(3), nil).Once()
+	secondary.On("DeleteBefore", mock.Anything, "last-announced", cutoff).Return(int64(2), nil).Once()
+
+	deleted, err := i.DeleteBefore(s.ctx, "last-announced", cutoff)
+	s.NoError(err)
+	s.Equal(int64(3), deleted)
+
+	primary.AssertExpectations(s.T())
+	secondary.AssertExpectations(s.T())
+}
+
+func TestMultiTestSuite(t *testing.T) {
+	suite.Run(t, new(MultiTestSuite))
+}
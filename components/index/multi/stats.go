@@ -0,0 +1,34 @@
+package multi
+
+import (
+	"sync/atomic"
+)
+
+// counters tracks errors and divergence between targets.
+type counters struct {
+	divergentWrites atomic.Int64
+	fallbackReads   atomic.Int64
+	writeErrors     []atomic.Int64 // Per target, primary first.
+	readErrors      []atomic.Int64 // Per target, primary first.
+}
+
+func newCounters(targets int) *counters {
+	return &counters{
+		writeErrors: make([]atomic.Int64, targets),
+		readErrors:  make([]atomic.Int64, targets),
+	}
+}
+
+// TargetStats represents the counters for a single target.
+type TargetStats struct {
+	Name        string
+	WriteErrors int64
+	ReadErrors  int64
+}
+
+// Stats is a snapshot of the counters of a multi index.
+type Stats struct {
+	DivergentWrites int64 // Writes which succeeded on some targets but failed on others.
+	FallbackReads   int64 // Documents not found on the primary but found on a fallback target.
+	Targets         []TargetStats
+}
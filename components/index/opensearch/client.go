@@ -14,6 +14,7 @@ import (
 
 	"github.com/ipfs-search/ipfs-search/components/index"
 	"github.com/ipfs-search/ipfs-search/components/index/opensearch/bulkgetter"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
 	"github.com/ipfs-search/ipfs-search/instr"
 )
 
@@ -22,6 +23,7 @@ type Client struct {
 	searchClient *opensearch.Client
 	bulkIndexer  opensearchutil.BulkIndexer
 	bulkGetter   bulkgetter.AsyncGetter
+	syncWrites   bool
 
 	*instr.Instrumentation
 }
@@ -29,9 +31,16 @@ type Client struct {
 // ClientConfig 配置搜索索引。
 type ClientConfig struct {
 	URL       string
+	Username  string // 可选，基本认证的用户名。
+	Password  string // 可选，基本认证的密码。
 	Transport http.RoundTripper
 	Debug     bool
 
+	// SyncWrites 使 Index、Update 和 Delete 阻塞，直到批量索引器返回条目的结果（即下一次刷新之后）。
+	SyncWrites bool
+	// SyncFlushTimeout 在 SyncWrites 时替代更长的 BulkIndexerFlushTimeout，以免写入在低负载时阻塞数分钟。
+	SyncFlushTimeout time.Duration
+
 	BulkIndexerWorkers      int
 	BulkIndexerFlushBytes   int
 	BulkIndexerFlushTimeout time.Duration
@@ -73,6 +82,7 @@ func NewClient(cfg *ClientConfig, i *instr.Instrumentation) (*Client, error) {
 		searchClient:    c,
 		bulkIndexer:     bi,
 		bulkGetter:      bg,
+		syncWrites:      cfg.SyncWrites,
 		Instrumentation: i,
 	}, nil // 返回配置好的客户端。
 }
@@ -94,6 +104,14 @@ func (c *Client) NewIndex(name string) index.Index {
 	)
 }
 
+// NewReferencedIndex 根据给定的名称返回一个新索引，限制添加到文档的引用数量。
+func (c *Client) NewReferencedIndex(name string, limits indexTypes.ReferenceLimits) index.Index {
+	return New(
+		c,
+		&Config{Name: name, References: limits},
+	)
+}
+
 func getSearchClient(cfg *ClientConfig, i *instr.Instrumentation) (*opensearch.Client, error) {
 	b := backoff.Backoff{
 		Factor: 2.0,
@@ -103,6 +121,8 @@ func getSearchClient(cfg *ClientConfig, i *instr.Instrumentation) (*opensearch.C
 	// 参考：https://pkg.go.dev/github.com/opensearch-project/opensearch-go@v1.0.0#Config
 	clientConfig := opensearch.Config{
 		Addresses:    []string{cfg.URL},
+		Username:     cfg.Username,
+		Password:     cfg.Password,
 		Transport:    cfg.Transport,
 		DisableRetry: cfg.Debug,
 		// 重试/退避管理
@@ -126,13 +146,22 @@ func getSearchClient(cfg *ClientConfig, i *instr.Instrumentation) (*opensearch.C
 	return opensearch.NewClient(clientConfig)
 }
 
+// flushInterval 返回批量索引器的刷新时间间隔；同步写入时，每个写入都等待刷新，因此使用较短的 SyncFlushTimeout。
+func flushInterval(cfg *ClientConfig) time.Duration {
+	if cfg.SyncWrites && cfg.SyncFlushTimeout > 0 && cfg.SyncFlushTimeout < cfg.BulkIndexerFlushTimeout {
+		return cfg.SyncFlushTimeout
+	}
+
+	return cfg.BulkIndexerFlushTimeout
+}
+
 // getBulkIndexer 返回一个配置好的 BulkIndexer 或者一个错误。
 func getBulkIndexer(client *opensearch.Client, cfg *ClientConfig, i *instr.Instrumentation) (opensearchutil.BulkIndexer, error) {
 	iCfg := opensearchutil.BulkIndexerConfig{
-		Client:        client,                      // 设置 OpenSearch 客户端。
-		NumWorkers:    cfg.BulkIndexerWorkers,      // 设置批量索引器的工作线程数。
-		FlushBytes:    cfg.BulkIndexerFlushBytes,   // 设置批量索引器的刷新字节数。
-		FlushInterval: cfg.BulkIndexerFlushTimeout, // 设置批量索引器的刷新时间间隔。
+		Client:        client,                    // 设置 OpenSearch 客户端。
+		NumWorkers:    cfg.BulkIndexerWorkers,    // 设置批量索引器的工作线程数。
+		FlushBytes:    cfg.BulkIndexerFlushBytes, // 设置批量索引器的刷新字节数。
+		FlushInterval: flushInterval(cfg),        // 设置批量索引器的刷新时间间隔。
 		OnFlushStart: func(ctx context.Context) context.Context {
 			// 在刷新开始时，启动一个新的追踪 span。
 			newCtx, _ := i.Tracer.Start(ctx, "index.opensearch.BulkIndexerFlush")
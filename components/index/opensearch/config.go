@@ -1,6 +1,11 @@
 package opensearch
 
+import (
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
 // Config represents the configuration for an OpenSearch index.
 type Config struct {
-	Name string
+	Name       string
+	References indexTypes.ReferenceLimits // 添加到文档的引用的上限；为零值时不限制。
 }
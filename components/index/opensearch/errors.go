@@ -0,0 +1,83 @@
+package opensearch
+
+import (
+	"fmt"
+	"net/http"
+
+	opensearchutil "github.com/opensearch-project/opensearch-go/v2/opensearchutil"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+// WriteError 表示同步写入模式下，批量索引器未能写入的单个条目。
+type WriteError struct {
+	Action     string
+	DocumentID string
+	Status     int    // 条目的 HTTP 状态码；请求本身失败时为 0。
+	Type       string // OpenSearch 错误类型，例如 `version_conflict_engine_exception`。
+	Reason     string
+	Err        error // 请求本身失败时的错误。
+}
+
+// newWriteError 根据批量响应条目（或请求错误）创建 WriteError。
+func newWriteError(item opensearchutil.BulkIndexerItem, res opensearchutil.BulkIndexerResponseItem, err error) *WriteError {
+	return &WriteError{
+		Action:     item.Action,
+		DocumentID: item.DocumentID,
+		Status:     res.Status,
+		Type:       res.Error.Type,
+		Reason:     res.Error.Reason,
+		Err:        err,
+	}
+}
+
+// Error 返回错误信息。
+func (e *WriteError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("error in %s of %s: %s", e.Action, e.DocumentID, e.Err)
+	}
+
+	return fmt.Sprintf("error in %s of %s: %d %s: %s", e.Action, e.DocumentID, e.Status, e.Type, e.Reason)
+}
+
+// Unwrap 返回底层错误；创建已存在的文档返回 index.ErrDocumentExists，更新不存在的文档返回 index.ErrDocumentMissing。
+func (e *WriteError) Unwrap() error {
+	if e.Err != nil {
+		return e.Err
+	}
+
+	switch {
+	case e.Action == "create" && e.Status == http.StatusConflict:
+		return index.ErrDocumentExists
+	case e.Action == "update" && e.Status == http.StatusNotFound:
+		return index.ErrDocumentMissing
+	}
+
+	return nil
+}
+
+// Is 使临时错误匹配 index.ErrTemporary。
+func (e *WriteError) Is(target error) bool {
+	return target == index.ErrTemporary && e.Temporary()
+}
+
+// Temporary 返回重试写入是否可能成功，即请求失败、更新时的版本冲突、请求过多或服务器错误时。
+func (e *WriteError) Temporary() bool {
+	if e.Err != nil {
+		return true
+	}
+
+	switch {
+	case e.conflict():
+		return true
+	case e.Status == http.StatusTooManyRequests, e.Status >= http.StatusInternalServerError:
+		return true
+	}
+
+	return false
+}
+
+// conflict 返回是否为更新时的版本冲突。
+func (e *WriteError) conflict() bool {
+	return e.Err == nil && e.Action == "update" && e.Status == http.StatusConflict
+}
@@ -7,12 +7,17 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strings"
+	"time"
 
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
 	opensearchutil "github.com/opensearch-project/opensearch-go/v2/opensearchutil"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/ipfs-search/ipfs-search/components/index"
 	"github.com/ipfs-search/ipfs-search/components/index/opensearch/bulkgetter"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
 )
 
 const debug bool = false
@@ -46,6 +51,9 @@ func (i *Index) String() string {
 	return i.cfg.Name
 }
 
+// conflictRetries 为脚本更新发生版本冲突时，在批量索引器中重试的最大次数。
+const conflictRetries = 5
+
 // getBody 将一个 interface{} 序列化为 io.ReadSeeker。
 func getBody(v interface{}) (io.ReadSeeker, error) {
 	b, err := json.Marshal(v)
@@ -67,55 +75,122 @@ func (i *Index) index(
 	defer span.End()
 
 	var (
-		body io.ReadSeeker
-		err  error
+		body     []byte
+		err      error
+		scripted bool
 	)
 
 	if properties != nil {
-		if action == "update" {
+		if sc, ok := properties.(*script); ok && action == "update" {
+			// 脚本更新。
+			scripted = true
+			body, err = json.Marshal(newScriptUpdate(sc))
+		} else if action == "update" {
 			// 对于更新操作，更新的字段需要包装在 `doc` 字段中。
-			body, err = getBody(struct {
+			body, err = json.Marshal(struct {
 				Doc interface{} `json:"doc"`
 			}{properties})
 		} else {
-			body, err = getBody(properties) // 序列化 properties。
+			body, err = json.Marshal(properties) // 序列化 properties。
 		}
 		if err != nil {
 			panic(err)
 		}
 	}
 
+	// 同步写入模式下，条目的结果通过 done 返回。
+	var done chan error
+	if i.c.syncWrites {
+		done = make(chan error, 1)
+	}
+
+	retries := 0
+	if scripted {
+		retries = conflictRetries
+	}
+
+	ctx, span = i.c.Tracer.Start(ctx, "index.opensearch.bulkIndexer.Add")
+	defer span.End()
+
+	err = i.add(ctx, span, action, id, body, retries, done)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Error adding to BulkIndexer.")
+
+		return err
+	}
+
+	if done == nil {
+		return nil
+	}
+
+	// 等待条目被刷新。
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err = <-done:
+		if err != nil {
+			span.SetStatus(codes.Error, "Error writing item.")
+		}
+
+		return err
+	}
+}
+
+// add 将条目加入批量索引器，写入错误记录到 span，结果发送到 done（如果不为 nil）。
+// 脚本在服务器端读取并修改文档，因此版本冲突的脚本更新可以安全地重新执行：
+// 最多重新加入 retries 次，使异步写入模式下并发的更新（例如引用）不会丢失。
+func (i *Index) add(ctx context.Context, span trace.Span, action, id string, body []byte, retries int, done chan<- error) error {
 	item := opensearchutil.BulkIndexerItem{
 		Index:      i.cfg.Name,
 		Action:     action,
-		Body:       body,
 		DocumentID: id,
 		Version:    nil,
+		OnSuccess: func(
+			ctx context.Context,
+			item opensearchutil.BulkIndexerItem,
+			res opensearchutil.BulkIndexerResponseItem,
+		) {
+			if done != nil {
+				done <- nil
+			}
+		},
 		OnFailure: func(
 			ctx context.Context,
 			item opensearchutil.BulkIndexerItem,
 			res opensearchutil.BulkIndexerResponseItem, err error,
 		) {
-			if err == nil {
-				err = fmt.Errorf("Error flushing: %+v (%s)", res, id)
+			writeErr := newWriteError(item, res, err)
+
+			if retries > 0 && writeErr.conflict() {
+				// 回调在刷新期间执行，此时加入条目可能阻塞，因此在新的 goroutine 中重新加入。
+				go func() {
+					if err := i.add(context.Background(), span, action, id, body, retries-1, done); err != nil {
+						log.Printf("Error retrying %s of %s after version conflict: %v", action, id, err)
+
+						if done != nil {
+							done <- err
+						}
+					}
+				}()
+
+				return
 			}
 
-			span.RecordError(err)
-			log.Println(err)
+			span.RecordError(writeErr)
+			log.Println(writeErr)
 
+			if done != nil {
+				done <- writeErr
+			}
 		},
 	}
 
-	ctx, span = i.c.Tracer.Start(ctx, "index.opensearch.bulkIndexer.Add")
-	defer span.End()
-
-	err = i.c.bulkIndexer.Add(ctx, item)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Error adding to BulkIndexer.")
+	if body != nil {
+		item.Body = bytes.NewReader(body)
 	}
 
-	return err
+	return i.c.bulkIndexer.Add(ctx, item)
 }
 
 // Index 根据 id 索引文档的属性。
@@ -133,6 +208,72 @@ func (i *Index) Delete(ctx context.Context, id string) error {
 	return i.index(ctx, "delete", id, nil)
 }
 
+// DeleteBefore 通过 delete-by-query 删除 field 早于 t 的文档，返回删除的文档数量。
+// 尚未刷新的批量写入不受影响；版本冲突的文档被跳过。
+func (i *Index) DeleteBefore(ctx context.Context, field string, t time.Time) (int64, error) {
+	ctx, span := i.c.Tracer.Start(ctx, "index.opensearch.DeleteBefore")
+	defer span.End()
+
+	body, err := getBody(map[string]interface{}{
+		"query": map[string]interface{}{
+			"range": map[string]interface{}{
+				field: map[string]interface{}{"lt": t},
+			},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	req := opensearchapi.DeleteByQueryRequest{
+		Index:     []string{i.cfg.Name},
+		Body:      body,
+		Conflicts: "proceed",
+	}
+
+	res, err := req.Do(ctx, i.c.searchClient)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		err = fmt.Errorf("error deleting from %s: %s", i, res)
+		span.RecordError(err)
+
+		return 0, err
+	}
+
+	var response struct {
+		Deleted int64 `json:"deleted"`
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&response)
+
+	return response.Deleted, err
+}
+
+// withLegacyFields 为请求的 `nsfw` 字段补充旧的拼写错误字段 `nfsw`，以便读取尚未迁移的文档。
+func withLegacyFields(fields []string) []string {
+	const nsfwField = "nsfw"
+
+	var legacy []string
+
+	for _, f := range fields {
+		if f == nsfwField || strings.HasPrefix(f, nsfwField+".") {
+			legacy = append(legacy, indexTypes.LegacyNSFWField+strings.TrimPrefix(f, nsfwField))
+		}
+	}
+
+	if len(legacy) == 0 {
+		return fields
+	}
+
+	return append(append([]string{}, fields...), legacy...)
+}
+
 // Get 从索引中检索具有 `id` 的文档的 `fields`，返回：
 // - (true, decoding_error) 如果找到（当 JSON 解码出错时设置解码错误）
 // - (false, nil) 如果未找到
@@ -144,7 +285,7 @@ func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...s
 	req := bulkgetter.GetRequest{
 		Index:      i.cfg.Name,
 		DocumentID: id,
-		Fields:     fields,
+		Fields:     withLegacyFields(fields),
 	}
 
 	resp := <-i.c.bulkGetter.Get(ctx, &req, dst) // 异步获取文档。
@@ -3,7 +3,9 @@ package opensearch
 // TODO: Test whether indexed items with omitempty are actually left out - otherwise
 // non-updating references will overwrite the existing!
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -14,7 +16,9 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/ipfs-search/ipfs-search/components/index"
 	"github.com/ipfs-search/ipfs-search/components/index/opensearch/bulkgetter"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
 )
 
 type IndexTestSuite struct {
@@ -89,6 +93,21 @@ func (s *IndexTestSuite) TestNewClient() {
 	s.NotNil(client)
 }
 
+func (s *IndexTestSuite) TestFlushInterval() {
+	cfg := &ClientConfig{
+		BulkIndexerFlushTimeout: 5 * time.Minute,
+		SyncFlushTimeout:        time.Second,
+	}
+	s.Equal(5*time.Minute, flushInterval(cfg))
+
+	// Synchronous writes wait for flushes, use the shorter interval.
+	cfg.SyncWrites = true
+	s.Equal(time.Second, flushInterval(cfg))
+
+	cfg.BulkIndexerFlushTimeout = 100 * time.Millisecond
+	s.Equal(100*time.Millisecond, flushInterval(cfg))
+}
+
 func (s *IndexTestSuite) TestNew() {
 	client, _ := NewClient(&ClientConfig{}, s.instr)
 	idx := New(client, &Config{Name: "test"})
@@ -327,6 +346,443 @@ func (s *IndexTestSuite) TestDelete() {
 	s.mockAPIHandler.AssertExpectations(s.T())
 }
 
+func (s *IndexTestSuite) TestIndexSync() {
+	s.mockClient.syncWrites = true
+	idx := New(s.mockClient, &Config{Name: "test"})
+
+	// Note whitespace here! This is NDJSON
+	request := []byte(`{"create":{"_index":"test","_id":"objId"}}
+{"field1":"hoi"}
+`)
+	response := []byte(`{
+	   "took": 30,
+	   "errors": false,
+	   "items": [
+	      {
+	         "create": {
+	            "_index": "test",
+	            "_id": "objId",
+	            "result": "created",
+	            "status": 201
+	         }
+	      }
+	   ]
+	}`)
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_bulk", request).
+		Return(httpmock.Response{
+			Body: response,
+		}).
+		Once()
+
+	err := idx.Index(s.ctx, "objId", map[string]string{"field1": "hoi"})
+	s.NoError(err)
+
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *IndexTestSuite) TestIndexSyncConflict() {
+	s.mockClient.syncWrites = true
+	idx := New(s.mockClient, &Config{Name: "test"})
+
+	// Note whitespace here! This is NDJSON
+	request := []byte(`{"create":{"_index":"test","_id":"objId"}}
+{"field1":"hoi"}
+`)
+	response := []byte(`{
+	   "took": 30,
+	   "errors": true,
+	   "items": [
+	      {
+	         "create": {
+	            "_index": "test",
+	            "_id": "objId",
+	            "status": 409,
+	            "error": {
+	               "type": "version_conflict_engine_exception",
+	               "reason": "[objId]: version conflict, document already exists"
+	            }
+	         }
+	      }
+	   ]
+	}`)
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_bulk", request).
+		Return(httpmock.Response{
+			Body: response,
+		}).
+		Once()
+
+	err := idx.Index(s.ctx, "objId", map[string]string{"field1": "hoi"})
+	s.ErrorIs(err, index.ErrDocumentExists)
+
+	var writeErr *WriteError
+	s.ErrorAs(err, &writeErr)
+	s.Equal(409, writeErr.Status)
+	s.False(writeErr.Temporary())
+	s.NotErrorIs(err, index.ErrTemporary)
+
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *IndexTestSuite) TestUpdateSyncTemporary() {
+	s.mockClient.syncWrites = true
+	idx := New(s.mockClient, &Config{Name: "test"})
+
+	// Note whitespace here! This is NDJSON
+	request := []byte(`{"update":{"_index":"test","_id":"objId"}}
+{"doc":{"field1":"hoi"}}
+`)
+	response := []byte(`{
+	   "took": 30,
+	   "errors": true,
+	   "items": [
+	      {
+	         "update": {
+	            "_index": "test",
+	            "_id": "objId",
+	            "status": 429,
+	            "error": {
+	               "type": "es_rejected_execution_exception",
+	               "reason": "rejected execution"
+	            }
+	         }
+	      }
+	   ]
+	}`)
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_bulk", request).
+		Return(httpmock.Response{
+			Body: response,
+		}).
+		Once()
+
+	err := idx.Update(s.ctx, "objId", map[string]string{"field1": "hoi"})
+
+	var writeErr *WriteError
+	s.ErrorAs(err, &writeErr)
+	s.True(writeErr.Temporary())
+	s.ErrorIs(err, index.ErrTemporary)
+
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *IndexTestSuite) TestAppendReference() {
+	idx := New(s.mockClient, &Config{Name: "test"}).(*Index)
+
+	response := []byte(`{
+	   "took": 30,
+	   "errors": false,
+	   "items": [
+	      {
+	         "update": {
+	            "_index": "test",
+	            "_id": "objId",
+	            "result": "noop",
+	            "status": 200
+	         }
+	      }
+	   ]
+	}`)
+
+	matchRequest := mock.MatchedBy(func(body []byte) bool {
+		lines := bytes.Split(body, []byte("\n"))
+
+		return bytes.Equal(lines[0], []byte(`{"update":{"_index":"test","_id":"objId"}}`)) &&
+			bytes.Contains(lines[1], []byte(`"lang":"painless"`)) &&
+			bytes.Contains(lines[1], []byte(`"reference":{"parent_hash":"QmParent","name":"name"}`))
+	})
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_bulk", matchRequest).
+		Return(httpmock.Response{
+			Body: response,
+		}).
+		Once()
+
+	err := idx.AppendReference(s.ctx, "objId", indexTypes.Reference{ParentHash: "QmParent", Name: "name"})
+	s.NoError(err)
+
+	// Ensure flushing
+	s.ctxCancel()
+	time.Sleep(100 * time.Millisecond)
+
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *IndexTestSuite) TestAppendReferenceConflict() {
+	s.mockClient.syncWrites = true
+	idx := New(s.mockClient, &Config{Name: "test"}).(*Index)
+
+	conflict := []byte(`{
+	   "took": 30,
+	   "errors": true,
+	   "items": [
+	      {
+	         "update": {
+	            "_index": "test",
+	            "_id": "objId",
+	            "status": 409,
+	            "error": {
+	               "type": "version_conflict_engine_exception",
+	               "reason": "[objId]: version conflict, required seqNo [1], primary term [1]. current document has seqNo [2] and primary term [1]"
+	            }
+	         }
+	      }
+	   ]
+	}`)
+	response := []byte(`{
+	   "took": 30,
+	   "errors": false,
+	   "items": [
+	      {
+	         "update": {
+	            "_index": "test",
+	            "_id": "objId",
+	            "result": "updated",
+	            "status": 200
+	         }
+	      }
+	   ]
+	}`)
+
+	matchRequest := mock.MatchedBy(func(body []byte) bool {
+		lines := bytes.Split(body, []byte("\n"))
+
+		return bytes.Equal(lines[0], []byte(`{"update":{"_index":"test","_id":"objId"}}`)) &&
+			bytes.Contains(lines[1], []byte(`"reference":{"parent_hash":"QmParent","name":"name"}`))
+	})
+
+	// The conflicting script update is retried.
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_bulk", matchRequest).
+		Return(httpmock.Response{
+			Body: conflict,
+		}).
+		Once()
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_bulk", matchRequest).
+		Return(httpmock.Response{
+			Body: response,
+		}).
+		Once()
+
+	err := idx.AppendReference(s.ctx, "objId", indexTypes.Reference{ParentHash: "QmParent", Name: "name"})
+	s.NoError(err)
+
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *IndexTestSuite) TestAppendReferenceLimits() {
+	idx := New(s.mockClient, &Config{
+		Name:       "test",
+		References: indexTypes.ReferenceLimits{Max: 2, MaxNames: 5},
+	}).(*Index)
+
+	response := []byte(`{
+	   "took": 30,
+	   "errors": false,
+	   "items": [
+	      {
+	         "update": {
+	            "_index": "test",
+	            "_id": "objId",
+	            "result": "updated",
+	            "status": 200
+	         }
+	      }
+	   ]
+	}`)
+
+	// Limits are enforced by the script, as the stored references might have changed since they were read.
+	matchRequest := mock.MatchedBy(func(body []byte) bool {
+		lines := bytes.Split(body, []byte("\n"))
+
+		return bytes.Contains(lines[1], []byte(`overflow(ctx._source, params.reference, params.max_reference_names)`)) &&
+			bytes.Contains(lines[1], []byte(`"max_reference_names":5,"max_references":2`))
+	})
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_bulk", matchRequest).
+		Return(httpmock.Response{
+			Body: response,
+		}).
+		Once()
+
+	err := idx.AppendReference(s.ctx, "objId", indexTypes.Reference{ParentHash: "QmParent", Name: "name"})
+	s.NoError(err)
+
+	// Ensure flushing
+	s.ctxCancel()
+	time.Sleep(100 * time.Millisecond)
+
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *IndexTestSuite) TestAppendReferences() {
+	idx := New(s.mockClient, &Config{Name: "test"}).(*Index)
+
+	response := []byte(`{
+	   "took": 30,
+	   "errors": false,
+	   "items": [
+	      {
+	         "update": {
+	            "_index": "test",
+	            "_id": "objId",
+	            "result": "updated",
+	            "status": 200
+	         }
+	      }
+	   ]
+	}`)
+
+	matchRequest := mock.MatchedBy(func(body []byte) bool {
+		lines := bytes.Split(body, []byte("\n"))
+
+		return bytes.Equal(lines[0], []byte(`{"update":{"_index":"test","_id":"objId"}}`)) &&
+			bytes.Contains(lines[1], []byte(`"references":[{"parent_hash":"QmParent","name":"a"},{"parent_hash":"QmParent","name":"b"}]`))
+	})
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_bulk", matchRequest).
+		Return(httpmock.Response{
+			Body: response,
+		}).
+		Once()
+
+	err := idx.AppendReferences(s.ctx, "objId", []indexTypes.Reference{
+		{ParentHash: "QmParent", Name: "a"},
+		{ParentHash: "QmParent", Name: "b"},
+	})
+	s.NoError(err)
+
+	// Ensure flushing
+	s.ctxCancel()
+	time.Sleep(100 * time.Millisecond)
+
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *IndexTestSuite) TestMergeProviders() {
+	idx := New(s.mockClient, &Config{Name: "test"}).(*Index)
+
+	response := []byte(`{
+	   "took": 30,
+	   "errors": false,
+	   "items": [
+	      {
+	         "update": {
+	            "_index": "test",
+	            "_id": "objId",
+	            "result": "created",
+	            "status": 201
+	         }
+	      }
+	   ]
+	}`)
+
+	announced := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	matchRequest := mock.MatchedBy(func(body []byte) bool {
+		lines := bytes.Split(body, []byte("\n"))
+
+		return bytes.Equal(lines[0], []byte(`{"update":{"_index":"test","_id":"objId"}}`)) &&
+			bytes.Contains(lines[1], []byte(`"records":[{"peer":"QmPeer","first-announced":"2024-01-02T03:04:05Z","last-announced":"2024-01-02T03:04:05Z","count":2}]`)) &&
+			bytes.Contains(lines[1], []byte(`"cutoff":"2024-01-01T03:04:05Z"`)) &&
+			bytes.Contains(lines[1], []byte(`"max_providers":10`)) &&
+			bytes.HasSuffix(lines[1], []byte(`,"scripted_upsert":true,"upsert":{}}`))
+	})
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_bulk", matchRequest).
+		Return(httpmock.Response{
+			Body: response,
+		}).
+		Once()
+
+	err := idx.MergeProviders(s.ctx, "objId", []indexTypes.ProviderRecord{
+		{Peer: "QmPeer", FirstAnnounced: announced, LastAnnounced: announced, Count: 2},
+	}, announced.Add(-24*time.Hour), 10)
+	s.NoError(err)
+
+	// Ensure flushing
+	s.ctxCancel()
+	time.Sleep(100 * time.Millisecond)
+
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *IndexTestSuite) TestDeleteBefore() {
+	idx := New(s.mockClient, &Config{Name: "test"}).(*Index)
+
+	request := []byte(`{"query":{"range":{"last-announced":{"lt":"2024-01-02T03:04:05Z"}}}}`)
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/test/_delete_by_query?conflicts=proceed", request).
+		Return(httpmock.Response{
+			Body: []byte(`{"took": 10, "deleted": 3, "version_conflicts": 0, "failures": []}`),
+		}).
+		Once()
+
+	deleted, err := idx.DeleteBefore(s.ctx, "last-announced", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	s.NoError(err)
+	s.Equal(int64(3), deleted)
+
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *IndexTestSuite) TestScan() {
+	idx := New(s.mockClient, &Config{Name: "test"}).(*Index)
+
+	request := []byte(`{"query":{"match_all":{}},"sort":["_doc"]}`)
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/test/_search?_source_includes=last-seen&scroll=300000ms&size=1000", request).
+		Return(httpmock.Response{
+			Body: []byte(`{"_scroll_id": "scroll", "hits": {"hits": [{"_id": "a", "_source": {"last-seen": "2024-01-02T03:04:05Z"}}]}}`),
+		}).
+		Once()
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_search/scroll?scroll=300000ms&scroll_id=scroll", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`{"_scroll_id": "scroll", "hits": {"hits": [{"_id": "b", "_source": {}}]}}`),
+		}).
+		Once()
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/_search/scroll?scroll=300000ms&scroll_id=scroll", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`{"_scroll_id": "scroll", "hits": {"hits": []}}`),
+		}).
+		Once()
+
+	s.mockAPIHandler.
+		On("Handle", "DELETE", "/_search/scroll/scroll", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`{"succeeded": true, "num_freed": 1}`),
+		}).
+		Once()
+
+	sources := make(map[string]string)
+	err := idx.Scan(s.ctx, func(id string, source json.RawMessage) error {
+		sources[id] = string(source)
+		return nil
+	}, "last-seen")
+
+	s.NoError(err)
+	s.Equal(map[string]string{
+		"a": `{"last-seen": "2024-01-02T03:04:05Z"}`,
+		"b": `{}`,
+	}, sources)
+
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
 func (s *IndexTestSuite) TestGetFound() {
 	idx := New(s.mockClient, &Config{Name: "test"})
 
@@ -375,6 +831,25 @@ func (s *IndexTestSuite) TestGetNotFound() {
 	s.mockAsyncGetter.AssertExpectations(s.T())
 }
 
+func (s *IndexTestSuite) TestGetLegacyFields() {
+	idx := New(s.mockClient, &Config{Name: "test"})
+
+	dst := struct{}{}
+
+	s.mockAsyncGetter.On(
+		"Get",
+		mock.Anything,
+		&bulkgetter.GetRequest{Index: "test", DocumentID: "objId", Fields: []string{"nsfw", "size", "nsfw.modelCid", "nfsw", "nfsw.modelCid"}},
+		&dst,
+	).Return(bulkgetter.GetResponse{Found: true, Error: nil})
+
+	result, err := idx.Get(s.ctx, "objId", &dst, "nsfw", "size", "nsfw.modelCid")
+	s.NoError(err)
+	s.True(result)
+
+	s.mockAsyncGetter.AssertExpectations(s.T())
+}
+
 func TestIndexTestSuite(t *testing.T) {
 	suite.Run(t, new(IndexTestSuite))
 }
@@ -0,0 +1,127 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+const (
+	scanBatchSize = 1000            // 每次 scroll 请求返回的文档数量。
+	scanKeepAlive = 5 * time.Minute // 两次 scroll 请求之间保留搜索上下文的时间。
+)
+
+// scanPage 是 scroll 请求的响应。
+type scanPage struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []struct {
+			ID     string          `json:"_id"`
+			Source json.RawMessage `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// decodeScanPage 解码并关闭 scroll 请求的响应。
+func (i *Index) decodeScanPage(res *opensearchapi.Response) (*scanPage, error) {
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("error scanning %s: %s", i, res)
+	}
+
+	page := new(scanPage)
+	err := json.NewDecoder(res.Body).Decode(page)
+
+	return page, err
+}
+
+// clearScroll 释放 scroll 的搜索上下文；失败时仅记录日志，上下文会在 scanKeepAlive 后过期。
+func (i *Index) clearScroll(ctx context.Context, scrollID string) {
+	req := opensearchapi.ClearScrollRequest{
+		ScrollID: []string{scrollID},
+	}
+
+	res, err := req.Do(ctx, i.c.searchClient)
+	if err != nil {
+		log.Printf("Error clearing scroll of %s: %s", i, err)
+		return
+	}
+
+	res.Body.Close()
+}
+
+// Scan 通过 scroll 按索引顺序遍历所有文档，对每个文档调用 f；f 返回错误时停止遍历并返回该错误。
+// 尚未刷新的批量写入不包含在内。
+func (i *Index) Scan(ctx context.Context, f func(id string, source json.RawMessage) error, fields ...string) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.opensearch.Scan")
+	defer span.End()
+
+	body, err := getBody(map[string]interface{}{
+		"query": map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		},
+		"sort": []string{"_doc"},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	size := scanBatchSize
+	req := opensearchapi.SearchRequest{
+		Index:          []string{i.cfg.Name},
+		Body:           body,
+		Scroll:         scanKeepAlive,
+		Size:           &size,
+		SourceIncludes: fields,
+	}
+
+	var scrollID string
+
+	defer func() {
+		if scrollID != "" {
+			i.clearScroll(ctx, scrollID)
+		}
+	}()
+
+	res, err := req.Do(ctx, i.c.searchClient)
+
+	for err == nil {
+		var page *scanPage
+		if page, err = i.decodeScanPage(res); err != nil {
+			break
+		}
+
+		scrollID = page.ScrollID
+
+		if len(page.Hits.Hits) == 0 {
+			return nil
+		}
+
+		for _, hit := range page.Hits.Hits {
+			if err := f(hit.ID, hit.Source); err != nil {
+				return err
+			}
+		}
+
+		scroll := opensearchapi.ScrollRequest{
+			ScrollID: scrollID,
+			Scroll:   scanKeepAlive,
+		}
+
+		res, err = scroll.Do(ctx, i.c.searchClient)
+	}
+
+	span.RecordError(err)
+
+	return err
+}
+
+// 编译时保证实现满足接口要求。
+var _ index.Scanner = &Index{}
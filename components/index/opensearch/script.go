@@ -0,0 +1,250 @@
+package opensearch
+
+import (
+	"context"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// script 表示一个 painless 更新脚本。
+type script struct {
+	Source string                 `json:"source"`
+	Lang   string                 `json:"lang"`
+	Params map[string]interface{} `json:"params"`
+
+	upsert bool // 文档不存在时以空文档运行脚本（scripted upsert）。
+}
+
+// scriptUpdate 是脚本更新的请求体。
+type scriptUpdate struct {
+	Script         *script   `json:"script"`
+	ScriptedUpsert bool      `json:"scripted_upsert,omitempty"`
+	Upsert         *struct{} `json:"upsert,omitempty"`
+}
+
+// newScriptUpdate 返回脚本 sc 的更新请求体。
+func newScriptUpdate(sc *script) *scriptUpdate {
+	u := &scriptUpdate{Script: sc}
+
+	if sc.upsert {
+		u.ScriptedUpsert = true
+		u.Upsert = &struct{}{}
+	}
+
+	return u
+}
+
+// overflowReferenceFunction 与 types.ReferenceLimits.Overflow 相同：引用达到上限时，仅增加引用计数并以蓄水池抽样的方式采样名称。
+const overflowReferenceFunction = `
+void overflow(Map source, Map reference, long maxNames) {
+	long count = source.references.size();
+	if (source.reference_count != null && ((Number) source.reference_count).longValue() > count) {
+		count = ((Number) source.reference_count).longValue();
+	}
+	count++;
+	source.reference_count = count;
+
+	List names = source.reference_names == null ? new ArrayList() : new ArrayList(source.reference_names);
+	if (maxNames == 0 || names.contains(reference.name)) {
+		return;
+	}
+	if (names.size() < maxNames) {
+		names.add(reference.name);
+	} else {
+		long j = (long) (Math.random() * count);
+		if (j < maxNames) {
+			names.set((int) j, reference.name);
+		}
+	}
+	source.reference_names = names;
+}
+
+boolean full(Map source, long max) {
+	return max > 0 && source.references.size() >= max;
+}
+`
+
+// appendReferenceSource 仅在引用不存在时添加引用；否则不执行任何操作（noop），从而避免无谓的写入。
+// 引用达到 max_references 时不再添加，而是更新引用计数和采样的名称。
+const appendReferenceSource = overflowReferenceFunction + `
+if (ctx._source.references == null) {
+	ctx._source.references = [];
+}
+for (ref in ctx._source.references) {
+	if (ref.parent_hash == params.reference.parent_hash && ref.name == params.reference.name) {
+		ctx.op = 'none';
+		return;
+	}
+}
+if (full(ctx._source, params.max_references)) {
+	overflow(ctx._source, params.reference, params.max_reference_names);
+	return;
+}
+ctx._source.references.add(params.reference);
+`
+
+// appendReferencesSource 添加所有尚不存在的引用，超出 max_references 的引用与 appendReferenceSource 相同；
+// 没有新增引用时不执行任何操作（noop）。
+const appendReferencesSource = overflowReferenceFunction + `
+if (ctx._source.references == null) {
+	ctx._source.references = [];
+}
+boolean added = false;
+for (reference in params.references) {
+	boolean found = false;
+	for (ref in ctx._source.references) {
+		if (ref.parent_hash == reference.parent_hash && ref.name == reference.name) {
+			found = true;
+			break;
+		}
+	}
+	if (found) {
+		continue;
+	}
+	if (full(ctx._source, params.max_references)) {
+		overflow(ctx._source, reference, params.max_reference_names);
+	} else {
+		ctx._source.references.add(reference);
+	}
+	added = true;
+}
+if (!added) {
+	ctx.op = 'none';
+}
+`
+
+// mergeProvidersSource 合并提供者记录，与 providers.Indexer 的合并逻辑相同：丢弃最后公告早于 cutoff 的节点，
+// 仅保留最近公告的 max_providers 个节点并更新汇总字段；没有剩余节点时删除文档（新文档则不执行任何操作）。
+const mergeProvidersSource = `
+long millis(def t) {
+	return ZonedDateTime.parse(t).toInstant().toEpochMilli();
+}
+
+boolean created = ctx._source.providers == null;
+List all = new ArrayList();
+if (!created) {
+	all.addAll(ctx._source.providers);
+}
+all.addAll(params.records);
+
+Map peers = new HashMap();
+for (r in all) {
+	def existing = peers.get(r.peer);
+	if (existing == null) {
+		peers.put(r.peer, new HashMap(r));
+		continue;
+	}
+	if (millis(r['first-announced']) < millis(existing['first-announced'])) {
+		existing['first-announced'] = r['first-announced'];
+	}
+	if (millis(r['last-announced']) > millis(existing['last-announced'])) {
+		existing['last-announced'] = r['last-announced'];
+	}
+	existing.count += r.count;
+}
+
+def last = ctx._source['last-announced'];
+for (r in params.records) {
+	if (last == null || millis(r['last-announced']) > millis(last)) {
+		last = r['last-announced'];
+	}
+}
+
+long cutoff = millis(params.cutoff);
+List providers = new ArrayList();
+for (r in peers.values()) {
+	if (millis(r['last-announced']) > cutoff) {
+		r.put('millis', millis(r['last-announced']));
+		providers.add(r);
+	}
+}
+
+if (providers.isEmpty()) {
+	ctx.op = created ? 'none' : 'delete';
+	return;
+}
+
+providers.sort((a, b) -> Long.compare((long) b.millis, (long) a.millis));
+if (providers.size() > params.max_providers) {
+	providers = new ArrayList(providers.subList(0, params.max_providers));
+}
+
+long count = 0;
+def first = null;
+for (r in providers) {
+	r.remove('millis');
+	count += r.count;
+	if (first == null || millis(r['first-announced']) < millis(first)) {
+		first = r['first-announced'];
+	}
+}
+
+ctx._source.providers = providers;
+ctx._source['provider-count'] = providers.size();
+ctx._source.count = count;
+ctx._source['first-announced'] = first;
+ctx._source['last-announced'] = last;
+`
+
+// AppendReference 使用 painless 脚本在服务器端原子地添加引用（如果尚不存在）。
+// 当前客户端版本的 BulkIndexer 不支持 retry_on_conflict，因此并发更新同一文档导致的版本冲突由批量索引器重试，
+// 同步和异步写入模式下均不会丢失引用；多次重试后仍冲突时返回（在同步写入模式下）临时错误。
+// 存储的引用达到 Config.References 的上限时，仅更新引用计数和采样的名称，因此并发添加也不会超出上限。
+func (i *Index) AppendReference(ctx context.Context, id string, ref indexTypes.Reference) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.opensearch.AppendReference")
+	defer span.End()
+
+	return i.index(ctx, "update", id, &script{
+		Source: appendReferenceSource,
+		Lang:   "painless",
+		Params: map[string]interface{}{
+			"reference":           ref,
+			"max_references":      i.cfg.References.Max,
+			"max_reference_names": i.cfg.References.MaxNames,
+		},
+	})
+}
+
+// AppendReferences 与 AppendReference 相同，但在一次脚本更新中添加多个引用。
+func (i *Index) AppendReferences(ctx context.Context, id string, refs []indexTypes.Reference) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.opensearch.AppendReferences")
+	defer span.End()
+
+	return i.index(ctx, "update", id, &script{
+		Source: appendReferencesSource,
+		Lang:   "painless",
+		Params: map[string]interface{}{
+			"references":          refs,
+			"max_references":      i.cfg.References.Max,
+			"max_reference_names": i.cfg.References.MaxNames,
+		},
+	})
+}
+
+// MergeProviders 使用 painless 脚本在服务器端原子地合并提供者记录，文档不存在时创建文档。
+// 与 AppendReference 相同，版本冲突时重试合并。
+func (i *Index) MergeProviders(ctx context.Context, id string, records []indexTypes.ProviderRecord, cutoff time.Time, maxProviders int) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.opensearch.MergeProviders")
+	defer span.End()
+
+	return i.index(ctx, "update", id, &script{
+		Source: mergeProvidersSource,
+		Lang:   "painless",
+		Params: map[string]interface{}{
+			"records":       records,
+			"cutoff":        cutoff,
+			"max_providers": maxProviders,
+		},
+		upsert: true,
+	})
+}
+
+// 编译时保证实现满足接口要求。
+var (
+	_ index.ReferenceAppender  = &Index{}
+	_ index.ReferencesAppender = &Index{}
+	_ index.ProvidersMerger    = &Index{}
+	_ index.Expirer            = &Index{}
+)
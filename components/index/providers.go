@@ -0,0 +1,46 @@
+package index
+
+import (
+	"context"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// ProvidersMerger is optionally implemented by indexes which can atomically merge provider records into a
+// document, creating it when missing. This prevents lost updates when announcements of the same resource are
+// merged concurrently, or before earlier writes are visible.
+type ProvidersMerger interface {
+	// MergeProviders merges records into the providers of document id, dropping peers which last announced
+	// before cutoff and all but the maxProviders most recently announcing peers. Documents without remaining
+	// peers are deleted.
+	MergeProviders(ctx context.Context, id string, records []types.ProviderRecord, cutoff time.Time, maxProviders int) error
+}
+
+// MergeProviders atomically merges records into the document with id in i, returning ErrNotSupported when i
+// does not implement ProvidersMerger.
+func MergeProviders(ctx context.Context, i Index, id string, records []types.ProviderRecord, cutoff time.Time, maxProviders int) error {
+	m, ok := i.(ProvidersMerger)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	return m.MergeProviders(ctx, id, records, cutoff, maxProviders)
+}
+
+// Expirer is optionally implemented by indexes which can delete documents by the value of a date field.
+type Expirer interface {
+	// DeleteBefore deletes documents with field before t, returning the number of deleted documents.
+	DeleteBefore(ctx context.Context, field string, t time.Time) (int64, error)
+}
+
+// DeleteBefore deletes documents in i with field before t, returning ErrNotSupported when i does not implement
+// Expirer.
+func DeleteBefore(ctx context.Context, i Index, field string, t time.Time) (int64, error) {
+	e, ok := i.(Expirer)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+
+	return e.DeleteBefore(ctx, field, t)
+}
@@ -0,0 +1,205 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	radix "github.com/mediocregopher/radix/v4"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// Hash fields for references, the reference count and sampled names, according to the `redis` tags on types.Update.
+const (
+	referencesField     = "r"
+	referenceCountField = "c"
+	referenceNamesField = "n"
+)
+
+// maxAppendAttempts is the number of times an append is attempted when the key is concurrently modified.
+const maxAppendAttempts = 10
+
+// ErrConflict is returned when a transaction failed repeatedly due to concurrent modification.
+var ErrConflict = errors.New("too many concurrent modifications")
+
+// errAborted signals a transaction aborted due to a WATCH'ed key being modified.
+var errAborted = errors.New("transaction aborted")
+
+// AppendReference atomically adds ref to the references of id, unless it's already present.
+//
+// As references are stored compressed, they cannot be modified by a Lua script; instead the key is
+// WATCH'ed and the transaction retried when concurrently modified. Absent documents are not created,
+// as a partial document would otherwise be cached. When the stored references reached Config.References.Max,
+// only the reference count and sampled names are updated, so that concurrent appends cannot exceed the limit.
+func (i *Index) AppendReference(ctx context.Context, id string, ref types.Reference) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.redis.AppendReference")
+	defer span.End()
+
+	key := i.getKey(id)
+
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		err := i.c.radixClient.Do(ctx, radix.WithConn(key, func(ctx context.Context, conn radix.Conn) error {
+			return appendReference(ctx, conn, key, ref, i.cfg.References)
+		}))
+
+		if !errors.Is(err, errAborted) {
+			if err != nil {
+				span.RecordError(err)
+			}
+
+			return err
+		}
+
+		if debug {
+			log.Printf("redis %s: append to %s aborted, retrying", i, key)
+		}
+	}
+
+	span.RecordError(ErrConflict)
+
+	return ErrConflict
+}
+
+// appendReference performs a single WATCH/MULTI/EXEC attempt on conn.
+func appendReference(ctx context.Context, conn radix.Conn, key string, ref types.Reference, limits types.ReferenceLimits) (err error) {
+	if err := conn.Do(ctx, radix.Cmd(nil, "WATCH", key)); err != nil {
+		return err
+	}
+
+	inMulti := false
+
+	defer func() {
+		// Reset connection state on errors, before it is returned to the pool.
+		if err != nil && !errors.Is(err, errAborted) {
+			reset := "UNWATCH"
+			if inMulti {
+				reset = "DISCARD"
+			}
+
+			conn.Do(ctx, radix.Cmd(nil, reset))
+		}
+	}()
+
+	var exists bool
+	if err := conn.Do(ctx, radix.Cmd(&exists, "EXISTS", key)); err != nil {
+		return err
+	}
+
+	var data []byte
+	if err := conn.Do(ctx, radix.Cmd(&data, "HGET", key, referencesField)); err != nil {
+		return err
+	}
+
+	refs := types.References{}
+	if len(data) > 0 {
+		if err := refs.UnmarshalBinary(data); err != nil {
+			return err
+		}
+	}
+
+	if !exists || refs.Contains(ref) {
+		return conn.Do(ctx, radix.Cmd(nil, "UNWATCH"))
+	}
+
+	var fields []string
+	if limits.Full(refs) {
+		fields, err = overflowFields(ctx, conn, key, refs, ref, limits)
+	} else {
+		fields, err = referencesFields(append(refs, ref))
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := conn.Do(ctx, radix.Cmd(nil, "MULTI")); err != nil {
+		return err
+	}
+
+	inMulti = true
+
+	if err := conn.Do(ctx, radix.Cmd(nil, "HSET", append([]string{key}, fields...)...)); err != nil {
+		return err
+	}
+
+	// EXEC returns null when the transaction was aborted.
+	mb := radix.Maybe{}
+	if err := conn.Do(ctx, radix.Cmd(&mb, "EXEC")); err != nil {
+		return err
+	}
+
+	if mb.Null {
+		return errAborted
+	}
+
+	return nil
+}
+
+// referencesFields returns the hash fields to write refs.
+func referencesFields(refs types.References) ([]string, error) {
+	data, err := refs.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{referencesField, string(data)}, nil
+}
+
+// overflowFields returns the hash fields to write when adding ref beyond the limits of refs: the incremented
+// reference count and sampled names.
+func overflowFields(ctx context.Context, conn radix.Conn, key string, refs types.References, ref types.Reference, limits types.ReferenceLimits) ([]string, error) {
+	var countData, namesData []byte
+
+	if err := conn.Do(ctx, radix.Cmd(&countData, "HGET", key, referenceCountField)); err != nil {
+		return nil, err
+	}
+
+	if err := conn.Do(ctx, radix.Cmd(&namesData, "HGET", key, referenceNamesField)); err != nil {
+		return nil, err
+	}
+
+	var count *uint64
+	if len(countData) > 0 {
+		c, err := strconv.ParseUint(string(countData), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		count = &c
+	}
+
+	names := types.ReferenceNames{}
+	if len(namesData) > 0 {
+		if err := names.UnmarshalBinary(namesData); err != nil {
+			return nil, err
+		}
+	}
+
+	newCount, newNames := limits.Overflow(refs, count, names, ref.Name)
+
+	fields := []string{referenceCountField, strconv.FormatUint(newCount, 10)}
+
+	if len(newNames) > 0 {
+		data, err := newNames.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, referenceNamesField, string(data))
+	}
+
+	return fields, nil
+}
+
+// AppendReference is a no-op, as references are not stored.
+func (i *ExistsIndex) AppendReference(ctx context.Context, id string, ref types.Reference) error {
+	return nil
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var (
+	_ index.ReferenceAppender = &Index{}
+	_ index.ReferenceAppender = &ExistsIndex{}
+)
@@ -4,10 +4,12 @@ import (
 	"context"
 	"log"
 	"strings"
+	"time"
 
 	radix "github.com/mediocregopher/radix/v4"
 
 	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/index/types"
 	"github.com/ipfs-search/ipfs-search/instr"
 )
 
@@ -90,6 +92,24 @@ func (c *Client) NewIndex(name, prefix string, existsIndex bool) index.Index {
 	)
 }
 
+// NewReferencedIndex returns a new index with given name and prefix, limiting the references appended to its
+// documents.
+func (c *Client) NewReferencedIndex(name, prefix string, limits types.ReferenceLimits) index.Index {
+	return New(
+		c,
+		&Config{Name: name, Prefix: prefix, References: limits},
+	)
+}
+
+// NewExpiringIndex returns a new index with given name and prefix, in which documents expire after ttl
+// without writes.
+func (c *Client) NewExpiringIndex(name, prefix string, ttl time.Duration) index.Index {
+	return New(
+		c,
+		&Config{Name: name, Prefix: prefix, TTL: ttl},
+	)
+}
+
 // Close closes the Redis client connection.
 func (c *Client) Close(ctx context.Context) error {
 	return c.radixClient.Close()
@@ -1,7 +1,16 @@
 package redis
 
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
 // Config holds configuration for a Redis index.
 type Config struct {
-	Name   string // Name of the index.
-	Prefix string // Key prefix.
+	Name   string        // Name of the index.
+	Prefix string        // Key prefix.
+	TTL    time.Duration // Optional expiry of documents, reset on every write; documents are kept forever when 0.
+
+	References types.ReferenceLimits // Limits of references appended to documents; unlimited when zero.
 }
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"log"
+	"strconv"
 
 	"github.com/ipfs-search/ipfs-search/components/index"
 
@@ -49,9 +50,6 @@ func (i *Index) getKey(key string) string {
 }
 
 func (i *Index) set(ctx context.Context, id string, properties interface{}) error {
-	key := i.getKey(id)
-	args := []string{key}
-
 	flattened, err := resp3.Flatten(properties, nil)
 	if err != nil {
 		return err
@@ -61,6 +59,13 @@ func (i *Index) set(ctx context.Context, id string, properties interface{}) erro
 		panic("Redis cannot index without properties.")
 	}
 
+	return i.hset(ctx, id, flattened)
+}
+
+func (i *Index) hset(ctx context.Context, id string, flattened []string) error {
+	key := i.getKey(id)
+	args := []string{key}
+
 	if debug {
 		log.Printf("redis %s: writing to %s", i, key)
 	}
@@ -68,7 +73,16 @@ func (i *Index) set(ctx context.Context, id string, properties interface{}) erro
 	args = append(args, flattened...)
 
 	action := radix.Cmd(nil, "HSET", args...)
-	return i.c.radixClient.Do(ctx, action)
+	if err := i.c.radixClient.Do(ctx, action); err != nil {
+		return err
+	}
+
+	if i.cfg.TTL > 0 {
+		action = radix.Cmd(nil, "PEXPIRE", key, strconv.FormatInt(i.cfg.TTL.Milliseconds(), 10))
+		return i.c.radixClient.Do(ctx, action)
+	}
+
+	return nil
 }
 
 // String returns the name of the index, for convenient logging.
@@ -84,12 +98,21 @@ func (i *Index) Index(ctx context.Context, id string, properties interface{}) er
 	return i.set(ctx, id, properties)
 }
 
-// Update a document's properties, given id
+// Update a document's properties, given id. Updates without any (non-empty) cached properties are ignored.
 func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
 	ctx, span := i.c.Tracer.Start(ctx, "index.redis.Update")
 	defer span.End()
 
-	return i.set(ctx, id, properties)
+	flattened, err := resp3.Flatten(properties, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(flattened) == 0 {
+		return nil
+	}
+
+	return i.hset(ctx, id, flattened)
 }
 
 // Delete item from index
@@ -68,6 +68,26 @@ func (s *RedisTestSuite) TestGetKey() {
 	s.Equal(indexPrefix+":"+testId, k)
 }
 
+func (s *RedisTestSuite) TestIndexTTL() {
+	var cmds []string
+
+	i := s.stubIndex(func(_ context.Context, args []string) interface{} {
+		cmds = append(cmds, args[0])
+
+		if args[0] == "PEXPIRE" {
+			s.Equal(indexPrefix+":"+testId, args[1])
+			s.Equal("3600000", args[2])
+		}
+
+		return nil
+	})
+	i.cfg.TTL = time.Hour
+
+	err := i.Index(s.ctx, testId, &types.DirectoryCheckpoint{Offset: 1, FirstName: "a", LastName: "a"})
+	s.NoError(err)
+	s.Equal([]string{"HSET", "PEXPIRE"}, cmds)
+}
+
 func (s *RedisTestSuite) TestSetLastSeenOnly() {
 	i := s.stubIndex(func(_ context.Context, args []string) interface{} {
 		s.Len(args, 4)
@@ -186,6 +206,17 @@ func (s *RedisTestSuite) TestString() {
 // func (s *RedisTestSuite) TestIndex() {}
 // func (s *RedisTestSuite) TestUpdate() {}
 
+func (s *RedisTestSuite) TestUpdateEmpty() {
+	i := s.stubIndex(func(_ context.Context, args []string) interface{} {
+		s.Fail("unexpected command", args)
+		return nil
+	})
+
+	// Nothing to cache; skipped.
+	err := i.Update(s.ctx, testId, &types.Update{Paths: []string{"/ipfs/QmRoot/a"}})
+	s.NoError(err)
+}
+
 func (s *RedisTestSuite) TestDelete() {
 	i := s.stubIndex(func(_ context.Context, args []string) interface{} {
 		s.Len(args, 2)
@@ -263,6 +294,145 @@ func (s *RedisTestSuite) TestError() {
 	s.Equal(testErr, err)
 }
 
+// appendStub returns a stub for AppendReference, with refs stored and recording commands.
+func (s *RedisTestSuite) appendStub(exists bool, refs types.References, aborts int, cmds *[]string) stubFunc {
+	rBytes, _ := refs.MarshalBinary()
+
+	return func(_ context.Context, args []string) interface{} {
+		*cmds = append(*cmds, args[0])
+
+		switch args[0] {
+		case "EXISTS":
+			if exists {
+				return 1
+			}
+			return 0
+		case "HGET":
+			s.Equal("r", args[2])
+			if !exists {
+				return nil
+			}
+			return rBytes
+		case "HSET":
+			newRefs := types.References{}
+			s.NoError(newRefs.UnmarshalBinary([]byte(args[3])))
+			s.Equal(append(refs, types.Reference{ParentHash: "p2", Name: "f2"}), newRefs)
+			return "QUEUED"
+		case "EXEC":
+			if aborts > 0 {
+				aborts--
+				return nil
+			}
+			return []interface{}{0}
+		}
+
+		return "OK"
+	}
+}
+
+func (s *RedisTestSuite) TestAppendReference() {
+	var cmds []string
+	refs := types.References{{ParentHash: "p1", Name: "f1"}}
+	i := s.stubIndex(s.appendStub(true, refs, 0, &cmds))
+
+	err := i.AppendReference(s.ctx, testId, types.Reference{ParentHash: "p2", Name: "f2"})
+	s.NoError(err)
+	s.Equal([]string{"WATCH", "EXISTS", "HGET", "MULTI", "HSET", "EXEC"}, cmds)
+}
+
+func (s *RedisTestSuite) TestAppendReferenceExisting() {
+	var cmds []string
+	refs := types.References{{ParentHash: "p2", Name: "f2"}}
+	i := s.stubIndex(s.appendStub(true, refs, 0, &cmds))
+
+	err := i.AppendReference(s.ctx, testId, types.Reference{ParentHash: "p2", Name: "f2"})
+	s.NoError(err)
+	s.Equal([]string{"WATCH", "EXISTS", "HGET", "UNWATCH"}, cmds)
+}
+
+func (s *RedisTestSuite) TestAppendReferenceMissing() {
+	var cmds []string
+	i := s.stubIndex(s.appendStub(false, nil, 0, &cmds))
+
+	err := i.AppendReference(s.ctx, testId, types.Reference{ParentHash: "p2", Name: "f2"})
+	s.NoError(err)
+	s.Equal([]string{"WATCH", "EXISTS", "HGET", "UNWATCH"}, cmds)
+}
+
+func (s *RedisTestSuite) TestAppendReferenceRetry() {
+	var cmds []string
+	refs := types.References{{ParentHash: "p1", Name: "f1"}}
+	i := s.stubIndex(s.appendStub(true, refs, 1, &cmds))
+
+	err := i.AppendReference(s.ctx, testId, types.Reference{ParentHash: "p2", Name: "f2"})
+	s.NoError(err)
+	s.Len(cmds, 12)
+}
+
+func (s *RedisTestSuite) TestAppendReferenceConflict() {
+	var cmds []string
+	refs := types.References{{ParentHash: "p1", Name: "f1"}}
+	i := s.stubIndex(s.appendStub(true, refs, maxAppendAttempts, &cmds))
+
+	err := i.AppendReference(s.ctx, testId, types.Reference{ParentHash: "p2", Name: "f2"})
+	s.ErrorIs(err, ErrConflict)
+}
+
+// TestAppendReferenceFullConcurrently tests whether a reference is counted rather than appended, when another
+// worker appended the last reference within the limit concurrently.
+func (s *RedisTestSuite) TestAppendReferenceFullConcurrently() {
+	ref1 := types.Reference{ParentHash: "p1", Name: "f1"}
+	ref2 := types.Reference{ParentHash: "p2", Name: "f2"}
+	ref3 := types.Reference{ParentHash: "p3", Name: "f3"}
+
+	stored := types.References{ref1}
+	aborted := false
+
+	var hset []string
+
+	i := s.stubIndex(func(_ context.Context, args []string) interface{} {
+		switch args[0] {
+		case "EXISTS":
+			return 1
+		case "HGET":
+			switch args[2] {
+			case "r":
+				data, _ := stored.MarshalBinary()
+				return data
+			case "c", "n":
+				return nil
+			}
+			s.Failf("unexpected field", "%s", args[2])
+		case "HSET":
+			hset = args[2:]
+			return "QUEUED"
+		case "EXEC":
+			if !aborted {
+				// Another worker appends ref3 between WATCH and EXEC.
+				aborted = true
+				stored = append(stored, ref3)
+
+				return nil
+			}
+
+			return []interface{}{0}
+		}
+
+		return "OK"
+	})
+	i.cfg.References = types.ReferenceLimits{Max: 2, MaxNames: 5}
+
+	err := i.AppendReference(s.ctx, testId, ref2)
+	s.NoError(err)
+
+	s.Require().Len(hset, 4)
+	s.Equal([]string{"c", "3", "n"}, hset[:3])
+
+	names := types.ReferenceNames{}
+	s.NoError(names.UnmarshalBinary([]byte(hset[3])))
+	s.Equal(types.ReferenceNames{"f2"}, names)
+}
+
 func TestRedisTestSuite(t *testing.T) {
 	suite.Run(t, new(RedisTestSuite))
 }
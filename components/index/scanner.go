@@ -0,0 +1,23 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Scanner is optionally implemented by indexes which can iterate over all of their documents.
+type Scanner interface {
+	// Scan calls f with the id and JSON source of every document, limited to fields when given. Scanning stops
+	// at the first error returned by f, which is returned.
+	Scan(ctx context.Context, f func(id string, source json.RawMessage) error, fields ...string) error
+}
+
+// Scan calls f for every document in i, returning ErrNotSupported when i does not implement Scanner.
+func Scan(ctx context.Context, i Index, f func(id string, source json.RawMessage) error, fields ...string) error {
+	s, ok := i.(Scanner)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	return s.Scan(ctx, f, fields...)
+}
@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	// Pure Go SQLite driver, including FTS5.
+	_ "modernc.org/sqlite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// Client represents a SQLite database holding one or more indexes.
+type Client struct {
+	cfg *ClientConfig
+	*instr.Instrumentation
+
+	db *sql.DB
+}
+
+// NewClient opens the SQLite database.
+func NewClient(cfg *ClientConfig, i *instr.Instrumentation) (*Client, error) {
+	if cfg == nil {
+		panic("NewClient ClientConfig cannot be nil.")
+	}
+
+	if i == nil {
+		panic("NewClient Instrumentation cannot be nil.")
+	}
+
+	dsn := ":memory:"
+	if cfg.Path != "" {
+		dsn = "file:" + cfg.Path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows for a single writer; a single connection prevents SQLITE_BUSY on concurrent
+	// transactions and keeps in-memory databases from being connection-local.
+	db.SetMaxOpenConns(1)
+
+	return &Client{
+		cfg:             cfg,
+		Instrumentation: i,
+		db:              db,
+	}, nil
+}
+
+// quoteIdentifier quotes a table name for use in SQL statements.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// createTables creates the document and full-text tables for an index, when they don't exist yet.
+func (c *Client) createTables(ctx context.Context, name string) error {
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (id TEXT PRIMARY KEY, source TEXT NOT NULL)`, quoteIdentifier(name)),
+		fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(id UNINDEXED, content, names, title)`, quoteIdentifier(name+"_fts")),
+	}
+
+	for _, s := range statements {
+		if _, err := c.db.ExecContext(ctx, s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewIndex returns the index with the given name, creating its tables when required.
+func (c *Client) NewIndex(ctx context.Context, name string) (*Index, error) {
+	if err := c.createTables(ctx, name); err != nil {
+		return nil, err
+	}
+
+	return New(c, &Config{Name: name}), nil
+}
+
+// Close closes the database.
+func (c *Client) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,18 @@
+package sqlite
+
+// ClientConfig contains configuration for the SQLite client.
+type ClientConfig struct {
+	Path string // Path of the database file. When empty, an in-memory database is used.
+}
+
+// DefaultClientConfig returns the default configuration for the SQLite client.
+func DefaultClientConfig() *ClientConfig {
+	return &ClientConfig{
+		Path: "ipfs-search.db",
+	}
+}
+
+// Config holds configuration for a SQLite index.
+type Config struct {
+	Name string // Name of the index, used as table name.
+}
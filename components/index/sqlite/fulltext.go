@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"strings"
+)
+
+// titleFields are the metadata fields used as title.
+var titleFields = []string{"title", "dc:title"}
+
+type fullText struct {
+	content string
+	names   string
+	title   string
+}
+
+// appendStrings appends string values in v, which is either a string or a JSON array.
+func appendStrings(dst []string, v interface{}) []string {
+	switch x := v.(type) {
+	case string:
+		return append(dst, x)
+	case []interface{}:
+		for _, e := range x {
+			dst = appendStrings(dst, e)
+		}
+	}
+
+	return dst
+}
+
+// getFullText returns the full-text searchable values of a (JSON) document.
+func getFullText(doc map[string]interface{}) fullText {
+	var names, titles []string
+
+	if refs, ok := doc["references"].([]interface{}); ok {
+		for _, r := range refs {
+			if ref, ok := r.(map[string]interface{}); ok {
+				names = appendStrings(names, ref["name"])
+			}
+		}
+	}
+
+	names = appendStrings(names, doc["reference_names"])
+
+	if metadata, ok := doc["metadata"].(map[string]interface{}); ok {
+		for _, f := range titleFields {
+			titles = appendStrings(titles, metadata[f])
+		}
+	}
+
+	content, _ := doc["content"].(string)
+
+	return fullText{
+		content: content,
+		names:   strings.Join(names, "\n"),
+		title:   strings.Join(titles, "\n"),
+	}
+}
@@ -0,0 +1,260 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+const debug bool = false
+
+// Index stores documents as JSON in a SQLite table, with a FTS5 table for full-text search.
+type Index struct {
+	cfg *Config
+	c   *Client
+
+	table    string
+	ftsTable string
+}
+
+// New returns a new index. The tables for the index are expected to exist; use Client.NewIndex to create them.
+func New(client *Client, cfg *Config) *Index {
+	if client == nil {
+		panic("Index.New Client cannot be nil.")
+	}
+
+	if cfg == nil {
+		panic("Index.New Config cannot be nil.")
+	}
+
+	if cfg.Name == "" {
+		panic("Name cannot be empty")
+	}
+
+	return &Index{
+		cfg:      cfg,
+		c:        client,
+		table:    quoteIdentifier(cfg.Name),
+		ftsTable: quoteIdentifier(cfg.Name + "_fts"),
+	}
+}
+
+// String returns the name of the index, for convenient logging.
+func (i *Index) String() string {
+	return i.cfg.Name
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+// getSource returns the stored document with id, or nil when not found.
+func (i *Index) getSource(ctx context.Context, q queryer, id string) (map[string]interface{}, error) {
+	var source string
+
+	query := fmt.Sprintf(`SELECT source FROM %s WHERE id = ?`, i.table)
+
+	err := q.QueryRowContext(ctx, query, id).Scan(&source)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]interface{})
+	err = json.Unmarshal([]byte(source), &doc)
+
+	return doc, err
+}
+
+// writeFullText replaces the full-text entry for a document.
+func (i *Index) writeFullText(ctx context.Context, tx *sql.Tx, id string, doc map[string]interface{}) error {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, i.ftsTable), id); err != nil {
+		return err
+	}
+
+	t := getFullText(doc)
+	insert := fmt.Sprintf(`INSERT INTO %s (id, content, names, title) VALUES (?, ?, ?, ?)`, i.ftsTable)
+
+	_, err := tx.ExecContext(ctx, insert, id, t.content, t.names, t.title)
+
+	return err
+}
+
+// transaction runs f within a transaction, which is committed unless f returns an error.
+func (i *Index) transaction(ctx context.Context, f func(*sql.Tx) error) error {
+	tx, err := i.c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := f(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Printf("sqlite %s: error rolling back: %v", i, rbErr)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Index a document's properties, identified by id.
+// Returns index.ErrDocumentExists when the document has already been indexed, like OpenSearch's `create`.
+// Hence, like with OpenSearch's sync writes, the second of concurrent crawls of a resource fails to index it; the
+// crawler then updates the existing document instead, so that its reference is not lost.
+func (i *Index) Index(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.sqlite.Index")
+	defer span.End()
+
+	doc, err := index.ToDocument(properties)
+	if err != nil {
+		return err
+	}
+
+	source, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		log.Printf("sqlite %s: index %s", i, id)
+	}
+
+	err = i.transaction(ctx, func(tx *sql.Tx) error {
+		insert := fmt.Sprintf(`INSERT INTO %s (id, source) VALUES (?, ?) ON CONFLICT (id) DO NOTHING`, i.table)
+
+		res, err := tx.ExecContext(ctx, insert, id, string(source))
+		if err != nil {
+			return err
+		}
+
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("%w: %s in %s", index.ErrDocumentExists, id, i)
+		}
+
+		return i.writeFullText(ctx, tx, id, doc)
+	})
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+// Update a document's properties, given id. Properties are merged with the existing document, like
+// OpenSearch's partial `doc` update.
+// Returns index.ErrDocumentMissing when the document does not exist.
+func (i *Index) Update(ctx context.Context, id string, properties interface{}) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.sqlite.Update")
+	defer span.End()
+
+	update, err := index.ToDocument(properties)
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		log.Printf("sqlite %s: update %s", i, id)
+	}
+
+	err = i.transaction(ctx, func(tx *sql.Tx) error {
+		doc, err := i.getSource(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if doc == nil {
+			return fmt.Errorf("%w: %s in %s", index.ErrDocumentMissing, id, i)
+		}
+
+		index.MergeDocument(doc, update)
+
+		source, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET source = ? WHERE id = ?`, i.table), string(source), id); err != nil {
+			return err
+		}
+
+		return i.writeFullText(ctx, tx, id, doc)
+	})
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+// Delete item from index.
+func (i *Index) Delete(ctx context.Context, id string) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.sqlite.Delete")
+	defer span.End()
+
+	if debug {
+		log.Printf("sqlite %s: delete %s", i, id)
+	}
+
+	err := i.transaction(ctx, func(tx *sql.Tx) error {
+		for _, table := range []string{i.table, i.ftsTable} {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, table), id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+// Get retrieves `fields` from document with `id` from the index, returning:
+// - (true, decoding_error) if found (decoding error set when errors in json)
+// - (false, nil) when not found
+// - (false, error) otherwise
+func (i *Index) Get(ctx context.Context, id string, dst interface{}, fields ...string) (bool, error) {
+	ctx, span := i.c.Tracer.Start(ctx, "index.sqlite.Get")
+	defer span.End()
+
+	doc, err := i.getSource(ctx, i.c.db, id)
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+
+	if debug {
+		log.Printf("sqlite %s: get %s, found: %v", i, id, doc != nil)
+	}
+
+	if doc == nil {
+		return false, nil
+	}
+
+	b, err := json.Marshal(index.SelectFields(doc, fields...))
+	if err != nil {
+		return true, err
+	}
+
+	return true, json.Unmarshal(b, dst)
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ index.Index = &Index{}
@@ -0,0 +1,226 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+const testID = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+
+type IndexTestSuite struct {
+	suite.Suite
+	ctx context.Context
+	now time.Time
+
+	client *Client
+	files  *Index
+}
+
+func (s *IndexTestSuite) SetupTest() {
+	var err error
+
+	s.ctx = context.Background()
+	s.now = time.Now().Truncate(time.Second).UTC()
+
+	// Empty path; in-memory database.
+	s.client, err = NewClient(&ClientConfig{}, instr.New())
+	s.Require().NoError(err)
+
+	s.files, err = s.client.NewIndex(s.ctx, "ipfs_files")
+	s.Require().NoError(err)
+}
+
+func (s *IndexTestSuite) TearDownTest() {
+	s.NoError(s.client.Close())
+}
+
+func (s *IndexTestSuite) testFile() *indexTypes.File {
+	return &indexTypes.File{
+		Document: indexTypes.Document{
+			FirstSeen: s.now,
+			LastSeen:  s.now,
+			References: indexTypes.References{
+				{ParentHash: "QmParent", Name: "holiday.jpg"},
+			},
+			Size: 15,
+		},
+		Content:  "the quick brown fox",
+		Metadata: indexTypes.Metadata{"title": []interface{}{"Fantastic voyage"}},
+	}
+}
+
+func (s *IndexTestSuite) TestString() {
+	s.Equal("ipfs_files", s.files.String())
+}
+
+func (s *IndexTestSuite) TestFileClient() {
+	path := filepath.Join(s.T().TempDir(), "test.db")
+
+	client, err := NewClient(&ClientConfig{Path: path}, instr.New())
+	s.Require().NoError(err)
+
+	idx, err := client.NewIndex(s.ctx, "ipfs_files")
+	s.Require().NoError(err)
+	s.NoError(idx.Index(s.ctx, testID, s.testFile()))
+	s.NoError(client.Close())
+
+	// Reopen existing database.
+	client, err = NewClient(&ClientConfig{Path: path}, instr.New())
+	s.Require().NoError(err)
+
+	idx, err = client.NewIndex(s.ctx, "ipfs_files")
+	s.Require().NoError(err)
+
+	dst := &indexTypes.File{}
+	found, err := idx.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.True(found)
+	s.Equal(s.testFile(), dst)
+
+	s.NoError(client.Close())
+}
+
+func (s *IndexTestSuite) TestIndexGet() {
+	f := s.testFile()
+
+	s.NoError(s.files.Index(s.ctx, testID, f))
+
+	dst := &indexTypes.File{}
+	found, err := s.files.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.True(found)
+	s.Equal(f, dst)
+}
+
+func (s *IndexTestSuite) TestIndexExisting() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+
+	err := s.files.Index(s.ctx, testID, s.testFile())
+	s.ErrorIs(err, index.ErrDocumentExists)
+
+	// Full-text entry is not duplicated.
+	res, err := s.files.Search(s.ctx, "fox", 0, 10)
+	s.NoError(err)
+	s.Equal(uint64(1), res.Total)
+}
+
+func (s *IndexTestSuite) TestGetNotFound() {
+	found, err := s.files.Get(s.ctx, testID, &indexTypes.Update{})
+	s.NoError(err)
+	s.False(found)
+}
+
+// TestCrawlerUpdate follows the crawler's update flow: get references and last-seen, append a reference, update.
+func (s *IndexTestSuite) TestCrawlerUpdate() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+
+	existing := &indexTypes.Update{}
+	idx, err := index.MultiGet(s.ctx, []index.Index{s.files}, testID, existing, "references", "last-seen")
+	s.NoError(err)
+	s.Equal(s.files, idx)
+	s.Equal(s.now, *existing.LastSeen)
+	s.Equal(s.testFile().References, existing.References)
+
+	refs := append(existing.References, indexTypes.Reference{ParentHash: "QmOther", Name: "beach.jpg"})
+	s.NoError(s.files.Update(s.ctx, testID, &indexTypes.Update{References: refs}))
+
+	later := s.now.Add(time.Hour)
+	s.NoError(s.files.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &later}))
+
+	dst := &indexTypes.File{}
+	found, err := s.files.Get(s.ctx, testID, dst)
+	s.NoError(err)
+	s.True(found)
+
+	// Updated fields.
+	s.Equal(later, dst.LastSeen)
+	s.Equal(refs, dst.References)
+
+	// Other fields untouched.
+	s.Equal(s.now, dst.FirstSeen)
+	s.Equal("the quick brown fox", dst.Content)
+
+	// New reference name is searchable.
+	res, err := s.files.Search(s.ctx, "names:beach", 0, 10)
+	s.NoError(err)
+	s.Require().Len(res.Hits, 1)
+	s.Equal(testID, res.Hits[0].ID)
+}
+
+func (s *IndexTestSuite) TestUpdateMissing() {
+	err := s.files.Update(s.ctx, testID, &indexTypes.Update{LastSeen: &s.now})
+	s.ErrorIs(err, index.ErrDocumentMissing)
+}
+
+func (s *IndexTestSuite) TestDelete() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.NoError(s.files.Delete(s.ctx, testID))
+
+	found, err := s.files.Get(s.ctx, testID, &indexTypes.File{})
+	s.NoError(err)
+	s.False(found)
+
+	res, err := s.files.Search(s.ctx, "fox", 0, 10)
+	s.NoError(err)
+	s.Zero(res.Total)
+	s.Empty(res.Hits)
+}
+
+func (s *IndexTestSuite) TestScan() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.NoError(s.files.Index(s.ctx, "QmOther", &indexTypes.File{Content: "a lazy dog"}))
+
+	sources := make(map[string]string)
+	err := s.files.Scan(s.ctx, func(id string, source json.RawMessage) error {
+		sources[id] = string(source)
+		return nil
+	}, "size")
+
+	s.NoError(err)
+	s.Equal(map[string]string{
+		testID:    `{"size":15}`,
+		"QmOther": `{"size":0}`,
+	}, sources)
+
+	// Errors from f stop scanning.
+	stop := errors.New("stop")
+	calls := 0
+	err = s.files.Scan(s.ctx, func(id string, source json.RawMessage) error {
+		calls++
+		return stop
+	})
+
+	s.ErrorIs(err, stop)
+	s.Equal(1, calls)
+}
+
+func (s *IndexTestSuite) TestSearch() {
+	s.NoError(s.files.Index(s.ctx, testID, s.testFile()))
+	s.NoError(s.files.Index(s.ctx, "QmOther", &indexTypes.File{Content: "a lazy dog"}))
+
+	for _, q := range []string{"fox", "content:brown", "holiday", "title:voyage"} {
+		res, err := s.files.Search(s.ctx, q, 0, 10)
+		s.NoError(err)
+		s.Require().Len(res.Hits, 1, q)
+		s.Equal(testID, res.Hits[0].ID)
+		s.Equal(uint64(1), res.Total)
+	}
+
+	res, err := s.files.Search(s.ctx, "elephant", 0, 10)
+	s.NoError(err)
+	s.Empty(res.Hits)
+}
+
+func TestIndexTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexTestSuite))
+}
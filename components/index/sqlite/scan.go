@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+)
+
+// scanBatchSize is the number of documents read at once when scanning.
+const scanBatchSize = 1000
+
+// scanned is a document read when scanning.
+type scanned struct {
+	id     string
+	source string
+}
+
+// scanBatch returns up to scanBatchSize documents with an id after `after`, ordered by id.
+func (i *Index) scanBatch(ctx context.Context, after string) ([]scanned, error) {
+	query := fmt.Sprintf(`SELECT id, source FROM %s WHERE id > ? ORDER BY id LIMIT ?`, i.table)
+
+	rows, err := i.c.db.QueryContext(ctx, query, after, scanBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var batch []scanned
+
+	for rows.Next() {
+		var doc scanned
+		if err := rows.Scan(&doc.id, &doc.source); err != nil {
+			return nil, err
+		}
+
+		batch = append(batch, doc)
+	}
+
+	return batch, rows.Err()
+}
+
+// Scan calls f for every document, in order of id, until f returns an error. Documents are read in batches, so
+// that f may write to the index.
+func (i *Index) Scan(ctx context.Context, f func(id string, source json.RawMessage) error, fields ...string) error {
+	ctx, span := i.c.Tracer.Start(ctx, "index.sqlite.Scan")
+	defer span.End()
+
+	var after string
+
+	for {
+		batch, err := i.scanBatch(ctx, after)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for _, doc := range batch {
+			source, err := index.SelectSource([]byte(doc.source), fields...)
+			if err != nil {
+				return err
+			}
+
+			if err := f(doc.id, source); err != nil {
+				return err
+			}
+		}
+
+		after = batch[len(batch)-1].id
+	}
+}
+
+// Compile-time assurance that implementation satisfies interface.
+var _ index.Scanner = &Index{}
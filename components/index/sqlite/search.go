@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+)
+
+// Hit represents a single document matching a query.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// SearchResult represents a page of results for a query.
+type SearchResult struct {
+	Total uint64 // Total number of matching documents.
+	Hits  []Hit
+}
+
+// Search performs a FTS5 full-text query (ref: https://www.sqlite.org/fts5.html#full_text_query_syntax)
+// over content, reference names and titles, returning up to `size` hits starting at `from`, ordered by relevance.
+func (i *Index) Search(ctx context.Context, query string, from, size int) (*SearchResult, error) {
+	ctx, span := i.c.Tracer.Start(ctx, "index.sqlite.Search")
+	defer span.End()
+
+	result := &SearchResult{}
+
+	count := fmt.Sprintf(`SELECT count(*) FROM %s WHERE %s MATCH ?`, i.ftsTable, i.ftsTable)
+	if err := i.c.db.QueryRowContext(ctx, count, query).Scan(&result.Total); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// bm25() returns lower values for better matches.
+	q := fmt.Sprintf(`SELECT id, -bm25(%s) FROM %s WHERE %s MATCH ? ORDER BY rank LIMIT ? OFFSET ?`, i.ftsTable, i.ftsTable, i.ftsTable)
+
+	rows, err := i.c.db.QueryContext(ctx, q, query, size, from)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h Hit
+		if err := rows.Scan(&h.ID, &h.Score); err != nil {
+			return nil, err
+		}
+
+		result.Hits = append(result.Hits, h)
+	}
+
+	return result, rows.Err()
+}
@@ -23,8 +23,15 @@ type Link struct {
 type Links []Link
 
 // Directory represents a directory resource in an Index.
+//
+// Links of large directories are stored in DirectoryPages instead; Links is then empty.
 type Directory struct {
 	Document
 
-	Links Links `json:"links"`
+	Links     Links  `json:"links"`
+	LinkCount uint64 `json:"link_count,omitempty"` // Total number of links.
+	Pages     uint64 `json:"pages,omitempty"`      // Number of DirectoryPages, when paged.
+
+	Stats *DirectoryStats `json:"stats,omitempty"`
+	Class *DirectoryClass `json:"class,omitempty"`
 }
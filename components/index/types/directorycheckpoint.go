@@ -0,0 +1,8 @@
+package types
+
+// DirectoryCheckpoint represents the progress of listing a Directory, allowing an interrupted listing to resume.
+type DirectoryCheckpoint struct {
+	Offset    uint64 `json:"offset" redis:"o"`     // Number of processed entries.
+	FirstName string `json:"first_name" redis:"f"` // Name of the first entry, to verify the listing order.
+	LastName  string `json:"last_name" redis:"n"`  // Name of the last processed entry.
+}
@@ -0,0 +1,7 @@
+package types
+
+// DirectoryClass represents the kind of content of a Directory, as detected from its links.
+type DirectoryClass struct {
+	Type       string  `json:"type"`       // E.g. website, code, dataset or nft-collection.
+	Confidence float64 `json:"confidence"` // Between 0 and 1.
+}
@@ -0,0 +1,18 @@
+package types
+
+import (
+	"fmt"
+)
+
+// DirectoryPage represents a page of links of a large Directory, in a separate index.
+type DirectoryPage struct {
+	Directory string `json:"directory"` // CID of the Directory.
+	Page      uint64 `json:"page"`      // Page number, starting at 0.
+	Offset    uint64 `json:"offset"`    // Index of the first link in the directory listing.
+	Links     Links  `json:"links"`
+}
+
+// DirectoryPageID returns the document ID for page of directory.
+func DirectoryPageID(directory string, page uint64) string {
+	return fmt.Sprintf("%s-%d", directory, page)
+}
@@ -0,0 +1,19 @@
+package types
+
+// Count of a key in a histogram.
+type Count struct {
+	Key   string `json:"key"`
+	Count uint64 `json:"count"`
+}
+
+// DirectoryStats represents aggregates over the direct entries of a Directory.
+//
+// Histograms are ordered by descending count and truncated to the most frequent keys.
+type DirectoryStats struct {
+	TotalSize  uint64  `json:"total_size"`           // Sum of the declared sizes of entries.
+	Types      []Count `json:"types"`                // Number of entries by LinkType.
+	Extensions []Count `json:"extensions,omitempty"` // Number of files by lowercase extension, without the dot.
+	MimeTypes  []Count `json:"mime_types,omitempty"` // Number of files by MIME type, guessed from the extension.
+	Largest    Links   `json:"largest,omitempty"`    // Largest entries, by descending size.
+	Depth      *uint64 `json:"depth,omitempty"`      // Least number of parents to a root, when known.
+}
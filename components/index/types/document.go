@@ -6,8 +6,13 @@ import (
 
 // Document represents a common properties of resources in an Index.
 type Document struct {
-	FirstSeen  time.Time  `json:"first-seen"`
-	LastSeen   time.Time  `json:"last-seen"`
-	References References `json:"references"`
-	Size       uint64     `json:"size"`
+	FirstSeen      time.Time      `json:"first-seen"`
+	LastSeen       time.Time      `json:"last-seen"`
+	References     References     `json:"references"`
+	ReferenceCount uint64         `json:"reference_count,omitempty"` // Total references, when more than stored.
+	ReferenceNames ReferenceNames `json:"reference_names,omitempty"` // Sample of names, when more references than stored.
+	Roots          []string       `json:"roots,omitempty"`           // Root CIDs of Paths.
+	Paths          []string       `json:"paths,omitempty"`           // Full paths, like /ipfs/<root>/a/b/file.pdf.
+	Site           *SitePage      `json:"site,omitempty"`            // Position in a static website, for HTML pages.
+	Size           uint64         `json:"size"`
 }
@@ -1,5 +1,9 @@
 package types
 
+import (
+	"encoding/json"
+)
+
 // Language represents the language of a File.
 type Language struct {
 	Confidence string  `json:"confidence"`
@@ -19,5 +23,32 @@ type File struct {
 	Language        Language `json:"language"`
 	Metadata        Metadata `json:"metadata"`
 	URLs            []string `json:"urls"`
-	NSFW            *NSFW    `json:"nfsw,omitempty"`
+	NSFW            *NSFW    `json:"nsfw,omitempty"`
+}
+
+// LegacyNSFWField is the misspelled field name under which NSFW data was stored in older documents.
+const LegacyNSFWField = "nfsw"
+
+// UnmarshalJSON decodes a File, accepting NSFW data under the legacy `nfsw` field as well.
+// When both are present, `nsfw` takes precedence.
+func (f *File) UnmarshalJSON(data []byte) error {
+	// Alias type without methods, preventing infinite recursion.
+	type file File
+
+	aux := struct {
+		*file
+		LegacyNSFW *NSFW `json:"nfsw,omitempty"`
+	}{
+		file: (*file)(f),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if f.NSFW == nil {
+		f.NSFW = aux.LegacyNSFW
+	}
+
+	return nil
 }
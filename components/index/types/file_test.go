@@ -0,0 +1,74 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FileTestSuite struct {
+	suite.Suite
+}
+
+func (s *FileTestSuite) TestMarshalNSFW() {
+	f := File{
+		NSFW: &NSFW{ModelCID: "model"},
+	}
+
+	data, err := json.Marshal(&f)
+	s.NoError(err)
+
+	raw := map[string]interface{}{}
+	s.NoError(json.Unmarshal(data, &raw))
+
+	s.Contains(raw, "nsfw")
+	s.NotContains(raw, LegacyNSFWField)
+}
+
+func (s *FileTestSuite) TestUnmarshalNSFW() {
+	data := []byte(`{"content": "hoi", "nsfw": {"modelCid": "model", "classification": {"porn": 0.5}}}`)
+
+	f := File{}
+	s.NoError(json.Unmarshal(data, &f))
+
+	s.Equal("hoi", f.Content)
+	s.Require().NotNil(f.NSFW)
+	s.Equal("model", f.NSFW.ModelCID)
+	s.Equal(0.5, f.NSFW.Classification.Porn)
+}
+
+func (s *FileTestSuite) TestUnmarshalLegacyNSFW() {
+	data := []byte(`{"content": "hoi", "size": 15, "nfsw": {"modelCid": "legacy"}}`)
+
+	f := File{}
+	s.NoError(json.Unmarshal(data, &f))
+
+	s.Equal("hoi", f.Content)
+	s.Equal(uint64(15), f.Size)
+	s.Require().NotNil(f.NSFW)
+	s.Equal("legacy", f.NSFW.ModelCID)
+}
+
+func (s *FileTestSuite) TestUnmarshalPrefersNSFW() {
+	data := []byte(`{"nfsw": {"modelCid": "legacy"}, "nsfw": {"modelCid": "current"}}`)
+
+	f := File{}
+	s.NoError(json.Unmarshal(data, &f))
+
+	s.Require().NotNil(f.NSFW)
+	s.Equal("current", f.NSFW.ModelCID)
+}
+
+func (s *FileTestSuite) TestUnmarshalWithoutNSFW() {
+	data := []byte(`{"content": "hoi"}`)
+
+	f := File{}
+	s.NoError(json.Unmarshal(data, &f))
+
+	s.Nil(f.NSFW)
+}
+
+func TestFileTestSuite(t *testing.T) {
+	suite.Run(t, new(FileTestSuite))
+}
@@ -0,0 +1,15 @@
+package types
+
+import (
+	"encoding/json"
+)
+
+// IPLD represents a structured IPLD document, e.g. dag-cbor or dag-json, in an Index.
+type IPLD struct {
+	Document
+
+	Codec   string          `json:"codec"`             // Multicodec of the document, e.g. dag-cbor.
+	Data    json.RawMessage `json:"data"`              // dag-json encoding of the document; stored but not indexed.
+	Content string          `json:"content,omitempty"` // String values in the document, for full-text search.
+	Links   []string        `json:"links,omitempty"`   // CIDs linked to from the document.
+}
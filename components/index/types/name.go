@@ -0,0 +1,21 @@
+package types
+
+import (
+	"time"
+)
+
+// NameTarget represents content an IPNS name has resolved to.
+type NameTarget struct {
+	Target    string    `json:"target"`     // CID the name resolved to.
+	FirstSeen time.Time `json:"first-seen"` // First resolution to Target.
+}
+
+// Name represents an IPNS name and the content it currently resolves to.
+type Name struct {
+	Name            string       `json:"name"`
+	Target          string       `json:"target"`            // CID the name currently resolves to.
+	FirstSeen       time.Time    `json:"first-seen"`        // First resolution of the name.
+	LastSeen        time.Time    `json:"last-seen"`         // Last resolution of the name.
+	RefreshInterval uint64       `json:"refresh-interval"`  // Seconds after last-seen until the name is resolved again.
+	History         []NameTarget `json:"history,omitempty"` // Targets the name has resolved to, the current one last.
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"time"
+)
+
+// ProviderRecord represents the announcements of content by a peer.
+type ProviderRecord struct {
+	Peer           string    `json:"peer"`
+	FirstAnnounced time.Time `json:"first-announced"`
+	LastAnnounced  time.Time `json:"last-announced"`
+	Count          uint64    `json:"count"` // Announcements of the content by the peer.
+}
+
+// Providers represents the peers providing content, the most recently announcing peer first.
+type Providers struct {
+	Providers      []ProviderRecord `json:"providers"`
+	ProviderCount  int              `json:"provider-count"`  // Peers in Providers.
+	Count          uint64           `json:"count"`           // Announcements by peers in Providers.
+	FirstAnnounced time.Time        `json:"first-announced"` // First announcement by peers in Providers.
+	LastAnnounced  time.Time        `json:"last-announced"`  // Last announcement by any peer.
+}
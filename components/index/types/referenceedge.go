@@ -0,0 +1,13 @@
+package types
+
+import (
+	"time"
+)
+
+// ReferenceEdge represents a single parent→child reference, stored in the reverse-reference index.
+type ReferenceEdge struct {
+	ParentHash string    `json:"parent_hash"`
+	ChildHash  string    `json:"child_hash"`
+	Name       string    `json:"name"`
+	FirstSeen  time.Time `json:"first-seen"`
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"math/rand"
 
 	cbor "github.com/fxamacker/cbor/v2"
 	lz4 "github.com/pierrec/lz4/v4"
@@ -17,9 +18,9 @@ type Reference struct {
 // References is a collection of references to a Document.
 type References []Reference
 
-// MarshalBinary marshalls into LZ4 compressed BSON.
-func (r References) MarshalBinary() ([]byte, error) {
-	data, err := cbor.Marshal([]Reference(r))
+// marshalCompressed marshalls v into LZ4 compressed CBOR.
+func marshalCompressed(v interface{}) ([]byte, error) {
+	data, err := cbor.Marshal(v)
 	if err != nil {
 		return nil, err
 	}
@@ -36,8 +37,8 @@ func (r References) MarshalBinary() ([]byte, error) {
 	return compressed.Bytes(), nil
 }
 
-// UnmarshalBinary unmarshalls from LZ4 compressed BSON.
-func (r *References) UnmarshalBinary(data []byte) error {
+// unmarshalCompressed unmarshalls LZ4 compressed CBOR into v.
+func unmarshalCompressed(data []byte, v interface{}) error {
 	compressed := bytes.NewBuffer(data)
 	uncompressed := new(bytes.Buffer)
 
@@ -46,5 +47,97 @@ func (r *References) UnmarshalBinary(data []byte) error {
 		return err
 	}
 
-	return cbor.Unmarshal(uncompressed.Bytes(), r)
+	return cbor.Unmarshal(uncompressed.Bytes(), v)
+}
+
+// MarshalBinary marshalls into LZ4 compressed BSON.
+func (r References) MarshalBinary() ([]byte, error) {
+	return marshalCompressed([]Reference(r))
+}
+
+// UnmarshalBinary unmarshalls from LZ4 compressed BSON.
+func (r *References) UnmarshalBinary(data []byte) error {
+	return unmarshalCompressed(data, r)
+}
+
+// Contains returns whether a reference with the same parent and name is present.
+func (r References) Contains(ref Reference) bool {
+	for _, existing := range r {
+		if existing.ParentHash == ref.ParentHash && existing.Name == ref.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReferenceNames is a sample of distinct names of references to a Document, stored when it has more
+// references than are stored.
+type ReferenceNames []string
+
+// MarshalBinary marshalls into LZ4 compressed CBOR.
+func (n ReferenceNames) MarshalBinary() ([]byte, error) {
+	return marshalCompressed([]string(n))
+}
+
+// UnmarshalBinary unmarshalls from LZ4 compressed CBOR.
+func (n *ReferenceNames) UnmarshalBinary(data []byte) error {
+	return unmarshalCompressed(data, n)
+}
+
+// Contains returns whether name is present.
+func (n ReferenceNames) Contains(name string) bool {
+	for _, existing := range n {
+		if existing == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sample adds name to a sample of at most max distinct names, by reservoir sampling; count is the total number of
+// references, including that with name.
+func (n ReferenceNames) Sample(name string, count uint64, max uint) ReferenceNames {
+	if max == 0 || n.Contains(name) {
+		return n
+	}
+
+	if uint(len(n)) < max {
+		return append(n, name)
+	}
+
+	if j := rand.Int63n(int64(count)); j < int64(max) {
+		sampled := make(ReferenceNames, len(n))
+		copy(sampled, n)
+		sampled[j] = name
+
+		return sampled
+	}
+
+	return n
+}
+
+// ReferenceLimits caps the references stored in a Document. Beyond Max, references are counted and their names
+// sampled instead.
+type ReferenceLimits struct {
+	Max      uint // Maximum number of stored references, unlimited when 0.
+	MaxNames uint // Maximum number of distinct names sampled beyond Max.
+}
+
+// Full returns whether refs reached the limit.
+func (l ReferenceLimits) Full(refs References) bool {
+	return l.Max > 0 && uint(len(refs)) >= l.Max
+}
+
+// Overflow returns the reference count and sampled names of a document with refs, count and names stored, after
+// adding a reference with name beyond the limit.
+func (l ReferenceLimits) Overflow(refs References, count *uint64, names ReferenceNames, name string) (uint64, ReferenceNames) {
+	n := uint64(len(refs))
+	if count != nil && *count > n {
+		n = *count
+	}
+	n++
+
+	return n, names.Sample(name, n, l.MaxNames)
 }
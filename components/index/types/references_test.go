@@ -65,6 +65,30 @@ func (s *ReferencesTestSuite) TestMarshallUnmarshallBinary() {
 	// log.Printf("%s", json)
 }
 
+func (s *ReferencesTestSuite) TestReferenceLimits() {
+	limits := ReferenceLimits{Max: 2, MaxNames: 2}
+	refs := References{testRefs[0]}
+
+	s.False(limits.Full(refs))
+	s.True(limits.Full(append(refs, testRefs[1])))
+	s.False(ReferenceLimits{}.Full(testRefs))
+
+	// Counted from the stored references.
+	count, names := limits.Overflow(testRefs[:2], nil, nil, "a")
+	s.Equal(uint64(3), count)
+	s.Equal(ReferenceNames{"a"}, names)
+
+	// Counted from the stored count.
+	stored := uint64(10)
+	count, names = limits.Overflow(testRefs[:2], &stored, ReferenceNames{"a"}, "b")
+	s.Equal(uint64(11), count)
+	s.Equal(ReferenceNames{"a", "b"}, names)
+
+	// The sample is limited to MaxNames.
+	_, names = limits.Overflow(testRefs[:2], &stored, ReferenceNames{"a", "b"}, "c")
+	s.Len(names, 2)
+}
+
 func TestReferencesTestSuite(t *testing.T) {
 	suite.Run(t, new(ReferencesTestSuite))
 }
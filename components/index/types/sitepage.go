@@ -0,0 +1,9 @@
+package types
+
+// SitePage represents an HTML page of a static website, with the site's directory as root.
+type SitePage struct {
+	Root  string   `json:"root"`            // CID of the directory of the site.
+	Title string   `json:"title,omitempty"` // Title of the page.
+	Links []string `json:"links,omitempty"` // CIDs of entries of the site linked to from the page.
+	Rank  float64  `json:"rank"`            // PageRank within the site; ranks of pages of a site sum to 1.
+}
@@ -6,6 +6,11 @@ import (
 
 // Update represents the updatable part of a Document.
 type Update struct {
-	LastSeen   *time.Time `json:"last-seen,omitempty" redis:"l,omitempty"`
-	References References `json:"references,omitempty" redis:"r,omitempty"`
+	LastSeen       *time.Time     `json:"last-seen,omitempty" redis:"l,omitempty"`
+	References     References     `json:"references,omitempty" redis:"r,omitempty"`
+	ReferenceCount *uint64        `json:"reference_count,omitempty" redis:"c,omitempty"`
+	ReferenceNames ReferenceNames `json:"reference_names,omitempty" redis:"n,omitempty"`
+	Roots          []string       `json:"roots,omitempty" redis:"-"`
+	Paths          []string       `json:"paths,omitempty" redis:"-"`
+	Site           *SitePage      `json:"site,omitempty" redis:"-"`
 }
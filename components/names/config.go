@@ -0,0 +1,19 @@
+package names
+
+import (
+	"time"
+)
+
+// Config configures refreshing of names.
+type Config struct {
+	Interval   time.Duration // Check for names due for refreshing this often.
+	MaxPending int           // Drop new names when this many are pending.
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Interval:   time.Minute,
+		MaxPending: 100000,
+	}
+}
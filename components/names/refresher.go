@@ -0,0 +1,183 @@
+// Package names periodically re-resolves IPNS names and DNSLink domains, to track updates of the content they point to.
+package names
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const debug bool = false
+
+// refreshPriority is the queue priority of refreshed names; below newly discovered content.
+const refreshPriority uint8 = 1
+
+// Refresher schedules names, IPNS names or DNSLink domains, for resolving again. Add() schedules a name at
+// a given time; Work() periodically publishes due names to the queue, from which they are crawled again.
+//
+// Scheduled names are kept in memory. On start, Load() schedules the names stored in the names and domains
+// indexes again, so that they are still refreshed after a restart.
+type Refresher struct {
+	cfg      *Config
+	queue    queue.Publisher
+	counters counters
+
+	mu      sync.Mutex
+	pending map[t.Resource]time.Time
+
+	*instr.Instrumentation
+}
+
+// New returns a new Refresher, publishing due names to queue. Names are only refreshed when Work() is running.
+func New(queue queue.Publisher, cfg *Config, instr *instr.Instrumentation) *Refresher {
+	if queue == nil {
+		panic("names.New queue cannot be nil.")
+	}
+
+	if cfg == nil {
+		panic("names.New Config cannot be nil.")
+	}
+
+	return &Refresher{
+		cfg:             cfg,
+		queue:           queue,
+		pending:         make(map[t.Resource]time.Time),
+		Instrumentation: instr,
+	}
+}
+
+// Stats returns a snapshot of the counters.
+func (r *Refresher) Stats() Stats {
+	return Stats{
+		Scheduled: r.counters.scheduled.Load(),
+		Dropped:   r.counters.dropped.Load(),
+		Refreshed: r.counters.refreshed.Load(),
+		Failed:    r.counters.failed.Load(),
+	}
+}
+
+// Add schedules name to be resolved again at the given time, replacing earlier schedules of name.
+func (r *Refresher) Add(name *t.Resource, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.pending[*name]; !ok {
+		if len(r.pending) >= r.cfg.MaxPending {
+			r.counters.dropped.Add(1)
+			return
+		}
+
+		r.counters.scheduled.Add(1)
+	}
+
+	r.pending[*name] = at
+}
+
+// Load schedules the names stored in idx, with the given protocol, at their last resolution plus their refresh
+// interval; names stored without a refresh interval after interval. Overdue names are refreshed on the next tick.
+// Returns the number of scheduled names, or index.ErrNotSupported when idx cannot be scanned.
+func (r *Refresher) Load(ctx context.Context, idx index.Index, protocol t.Protocol, interval time.Duration) (int, error) {
+	ctx, span := r.Tracer.Start(ctx, "names.Load")
+	defer span.End()
+
+	var loaded int
+
+	err := index.Scan(ctx, idx, func(id string, source json.RawMessage) error {
+		var name indexTypes.Name
+		if err := json.Unmarshal(source, &name); err != nil {
+			log.Printf("names: error decoding %s in %s: %s", id, idx, err)
+			return nil
+		}
+
+		refresh := interval
+		if name.RefreshInterval > 0 {
+			refresh = time.Duration(name.RefreshInterval) * time.Second
+		}
+
+		r.Add(&t.Resource{Protocol: protocol, ID: id}, name.LastSeen.Add(refresh))
+		loaded++
+
+		return nil
+	}, "last-seen", "refresh-interval")
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return loaded, err
+}
+
+// Work publishes due names every interval, until the context is closed.
+func (r *Refresher) Work(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			r.refresh(ctx, now)
+		}
+	}
+}
+
+// due removes and returns names scheduled at or before now.
+func (r *Refresher) due(now time.Time) []t.Resource {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []t.Resource
+
+	for name, at := range r.pending {
+		if !at.After(now) {
+			due = append(due, name)
+			delete(r.pending, name)
+		}
+	}
+
+	return due
+}
+
+// refresh publishes names due at now.
+func (r *Refresher) refresh(ctx context.Context, now time.Time) {
+	due := r.due(now)
+	if len(due) == 0 {
+		return
+	}
+
+	ctx, span := r.Tracer.Start(ctx, "names.refresh")
+	defer span.End()
+
+	for j := range due {
+		name := &due[j]
+		resource := &t.AnnotatedResource{
+			Resource: name,
+		}
+
+		if err := r.queue.Publish(ctx, resource, refreshPriority); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			r.counters.failed.Add(1)
+			log.Printf("names: error queueing %s: %s", name, err)
+
+			continue
+		}
+
+		r.counters.refreshed.Add(1)
+	}
+
+	if debug {
+		log.Printf("names: refreshed %d names, %+v", len(due), r.Stats())
+	}
+}
@@ -0,0 +1,172 @@
+package names
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type RefresherTestSuite struct {
+	suite.Suite
+
+	ctx   context.Context
+	now   time.Time
+	queue *queue.Mock
+	cfg   *Config
+	r     *Refresher
+}
+
+func (s *RefresherTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.now = time.Now()
+	s.queue = &queue.Mock{}
+	s.cfg = DefaultConfig()
+	s.r = New(s.queue, s.cfg, instr.New())
+}
+
+func name(id string) *t.Resource {
+	return &t.Resource{
+		Protocol: t.IPNSProtocol,
+		ID:       id,
+	}
+}
+
+func (s *RefresherTestSuite) expectPublish(id string, err error) {
+	s.queue.
+		On("Publish", mock.Anything, &t.AnnotatedResource{
+			Resource: name(id),
+		}, refreshPriority).
+		Return(err).
+		Once()
+}
+
+func (s *RefresherTestSuite) TestRefreshDue() {
+	s.r.Add(name("due"), s.now.Add(-time.Second))
+	s.r.Add(name("later"), s.now.Add(time.Hour))
+
+	s.expectPublish("due", nil)
+
+	s.r.refresh(s.ctx, s.now)
+	s.queue.AssertExpectations(s.T())
+
+	// Refreshed names are no longer pending.
+	s.r.refresh(s.ctx, s.now)
+	s.queue.AssertNumberOfCalls(s.T(), "Publish", 1)
+
+	s.expectPublish("later", nil)
+
+	s.r.refresh(s.ctx, s.now.Add(time.Hour))
+	s.queue.AssertExpectations(s.T())
+
+	s.Equal(Stats{Scheduled: 2, Refreshed: 2}, s.r.Stats())
+}
+
+func (s *RefresherTestSuite) TestAddReschedules() {
+	s.r.Add(name("name"), s.now.Add(-time.Second))
+	s.r.Add(name("name"), s.now.Add(time.Hour))
+
+	s.r.refresh(s.ctx, s.now)
+	s.queue.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything, mock.Anything)
+
+	s.Equal(Stats{Scheduled: 1}, s.r.Stats())
+}
+
+func (s *RefresherTestSuite) TestRefreshDomain() {
+	domain := &t.Resource{
+		Protocol: t.DNSLinkProtocol,
+		ID:       "name",
+	}
+
+	// Names are scheduled by protocol and ID.
+	s.r.Add(name("name"), s.now)
+	s.r.Add(domain, s.now)
+
+	s.expectPublish("name", nil)
+	s.queue.
+		On("Publish", mock.Anything, &t.AnnotatedResource{Resource: domain}, refreshPriority).
+		Return(nil).
+		Once()
+
+	s.r.refresh(s.ctx, s.now)
+	s.queue.AssertExpectations(s.T())
+
+	s.Equal(Stats{Scheduled: 2, Refreshed: 2}, s.r.Stats())
+}
+
+func (s *RefresherTestSuite) TestAddMaxPending() {
+	s.cfg.MaxPending = 1
+
+	s.r.Add(name("a"), s.now)
+	s.r.Add(name("b"), s.now)
+
+	s.Equal(Stats{Scheduled: 1, Dropped: 1}, s.r.Stats())
+}
+
+func (s *RefresherTestSuite) TestLoad() {
+	idx := &index.ScannerMock{}
+
+	lastSeen := s.now.Add(-2 * time.Hour).UTC().Truncate(time.Second).Format(time.RFC3339)
+
+	idx.
+		On("Scan", mock.Anything, mock.Anything, []string{"last-seen", "refresh-interval"}).
+		Run(func(args mock.Arguments) {
+			f := args.Get(1).(func(string, json.RawMessage) error)
+
+			// Due after an hour, hence overdue.
+			s.NoError(f("due", json.RawMessage(`{"last-seen":"`+lastSeen+`","refresh-interval":3600}`)))
+			// Due after three hours.
+			s.NoError(f("later", json.RawMessage(`{"last-seen":"`+lastSeen+`","refresh-interval":10800}`)))
+			// Stored without interval, due after the given interval.
+			s.NoError(f("legacy", json.RawMessage(`{"last-seen":"`+lastSeen+`"}`)))
+		}).
+		Return(nil).
+		Once()
+
+	loaded, err := s.r.Load(s.ctx, idx, t.IPNSProtocol, time.Hour)
+	s.NoError(err)
+	s.Equal(3, loaded)
+	idx.AssertExpectations(s.T())
+
+	s.expectPublish("due", nil)
+	s.expectPublish("legacy", nil)
+
+	s.r.refresh(s.ctx, s.now)
+	s.queue.AssertExpectations(s.T())
+
+	s.expectPublish("later", nil)
+
+	s.r.refresh(s.ctx, s.now.Add(time.Hour))
+	s.queue.AssertExpectations(s.T())
+
+	s.Equal(Stats{Scheduled: 3, Refreshed: 3}, s.r.Stats())
+}
+
+func (s *RefresherTestSuite) TestLoadNotSupported() {
+	_, err := s.r.Load(s.ctx, &index.Mock{}, t.IPNSProtocol, time.Hour)
+	s.ErrorIs(err, index.ErrNotSupported)
+}
+
+func (s *RefresherTestSuite) TestRefreshFailed() {
+	s.r.Add(name("name"), s.now)
+
+	s.expectPublish("name", errors.New("publish failed"))
+
+	s.r.refresh(s.ctx, s.now)
+	s.queue.AssertExpectations(s.T())
+
+	s.Equal(Stats{Scheduled: 1, Failed: 1}, s.r.Stats())
+}
+
+func TestRefresherTestSuite(t *testing.T) {
+	suite.Run(t, new(RefresherTestSuite))
+}
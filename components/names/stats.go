@@ -0,0 +1,21 @@
+package names
+
+import (
+	"sync/atomic"
+)
+
+// counters tracks refreshing of names.
+type counters struct {
+	scheduled atomic.Int64
+	dropped   atomic.Int64
+	refreshed atomic.Int64
+	failed    atomic.Int64
+}
+
+// Stats is a snapshot of the counters of a Refresher.
+type Stats struct {
+	Scheduled int64 // Names scheduled, when not already pending.
+	Dropped   int64 // Names not scheduled as too many were pending.
+	Refreshed int64 // Names queued for resolving again.
+	Failed    int64 // Names which could not be queued.
+}
@@ -0,0 +1,46 @@
+package protocol
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/ipfs/go-cid"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// absolutePath returns the absolute (CID or name only) path for a resource, e.g. /ipfs/<cid> or /ipns/<name>.
+// DNSLink domains are resolved as names: /ipns/<domain>.
+func absolutePath(r *t.AnnotatedResource) string {
+	if r.Protocol == t.DNSLinkProtocol {
+		return fmt.Sprintf("/%s/%s", t.IPNSProtocol, r.ID)
+	}
+
+	return fmt.Sprintf("/%s/%s", r.Protocol, r.ID)
+}
+
+// isPathReference returns whether ref names an entry in a (UnixFS) directory.
+func isPathReference(ref *t.Reference) bool {
+	if ref.Parent.Protocol == t.DNSLinkProtocol {
+		return false
+	}
+
+	if c, err := cid.Decode(ref.Parent.ID); err == nil && t.IsIPLDDocument(c) {
+		return false
+	}
+
+	return true
+}
+
+// GatewayPath returns the (escaped/raw) path to request a resource from a gateway.
+// If a reference is available, it is used to generate the filename to facilitate content
+// type detection (e.g. /ipfs/<parent_hash>/my_file.jpg instead of /ipfs/<file_hash>/).
+// References from DNSLink domains are aliases and references from IPLD documents are fields rather than
+// paths, hence they are ignored.
+func GatewayPath(r *t.AnnotatedResource) string {
+	if ref := r.Reference; ref.Name != "" && isPathReference(&ref) {
+		return fmt.Sprintf("/%s/%s/%s", ref.Parent.Protocol, ref.Parent.ID, url.PathEscape(ref.Name))
+	}
+
+	return absolutePath(r)
+}
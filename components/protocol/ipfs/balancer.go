@@ -0,0 +1,315 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	ipfs "github.com/ipfs/go-ipfs-api"
+	"golang.org/x/sync/errgroup"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const (
+	// affinitySize is the number of resources for which the node they were requested from is remembered.
+	affinitySize = 100000
+
+	// initialLatency is assumed for nodes without successful requests.
+	initialLatency = 100 * time.Millisecond
+
+	// latencyWeight is the weight of new samples in the moving average of latencies.
+	latencyWeight = 0.2
+)
+
+// node is an IPFS node requests are balanced over.
+type node struct {
+	apiURL     string
+	gatewayURL *url.URL
+	shell      *ipfs.Shell
+	slots      chan struct{} // Nil for unlimited concurrency.
+	peers      *lru.Cache    // Last connection attempts to providers.
+
+	mu           sync.Mutex
+	latency      time.Duration // Moving average of successful requests.
+	timeouts     int           // Consecutive timeouts.
+	ejectedUntil time.Time
+}
+
+func parseGatewayURL(rawURL string) *url.URL {
+	gatewayURL, err := url.Parse(rawURL)
+	if err != nil {
+		panic(fmt.Sprintf("could not parse IPFS Gateway URL, error: %v", err))
+	}
+
+	if !gatewayURL.IsAbs() {
+		panic(fmt.Sprintf("gateway URL is not absolute: %s", gatewayURL))
+	}
+
+	return gatewayURL
+}
+
+func newNode(apiURL, gatewayURL string, maxConcurrency int, client *http.Client) *node {
+	peers, err := lru.New(peersSize)
+	if err != nil {
+		panic(err)
+	}
+
+	n := &node{
+		apiURL:     apiURL,
+		gatewayURL: parseGatewayURL(gatewayURL),
+		shell:      ipfs.NewShellWithClient(apiURL, client),
+		peers:      peers,
+		latency:    initialLatency,
+	}
+
+	if maxConcurrency > 0 {
+		n.slots = make(chan struct{}, maxConcurrency)
+	}
+
+	return n
+}
+
+func (n *node) healthy(now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return !now.Before(n.ejectedUntil)
+}
+
+func (n *node) available() bool {
+	return n.slots == nil || len(n.slots) < cap(n.slots)
+}
+
+func (n *node) weight() float64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return 1 / n.latency.Seconds()
+}
+
+// balancer selects nodes for requests, by affinity or weighted by latency, and ejects nodes after consecutive
+// timeouts.
+type balancer struct {
+	config *Config
+	nodes  []*node
+
+	// affinity maps resource ID's to the node they were last requested from.
+	affinity *lru.Cache
+}
+
+func newBalancer(config *Config, client *http.Client) *balancer {
+	nodes := []*node{newNode(config.APIURL, config.GatewayURL, config.MaxConcurrency, client)}
+
+	for _, n := range config.Nodes {
+		gatewayURL, maxConcurrency := n.GatewayURL, n.MaxConcurrency
+		if gatewayURL == "" {
+			gatewayURL = config.GatewayURL
+		}
+
+		if maxConcurrency == 0 {
+			maxConcurrency = config.MaxConcurrency
+		}
+
+		nodes = append(nodes, newNode(n.APIURL, gatewayURL, maxConcurrency, client))
+	}
+
+	affinity, err := lru.New(affinitySize)
+	if err != nil {
+		panic(err)
+	}
+
+	return &balancer{config, nodes, affinity}
+}
+
+// affine returns the node a resource, or else its parent, was last requested from, when healthy.
+func (b *balancer) affine(r *t.AnnotatedResource, now time.Time) *node {
+	if r == nil {
+		return nil
+	}
+
+	keys := []string{r.ID}
+	if r.Reference.Parent != nil {
+		keys = append(keys, r.Reference.Parent.ID)
+	}
+
+	for _, k := range keys {
+		if v, ok := b.affinity.Get(k); ok {
+			if n := v.(*node); n.healthy(now) {
+				return n
+			}
+		}
+	}
+
+	return nil
+}
+
+// pick returns the node to request a resource from: the affine node or else a random one weighted by latency,
+// preferring healthy nodes with available capacity.
+func (b *balancer) pick(r *t.AnnotatedResource) *node {
+	if len(b.nodes) == 1 {
+		return b.nodes[0]
+	}
+
+	now := time.Now()
+
+	if n := b.affine(r, now); n != nil {
+		return n
+	}
+
+	var healthy, available []*node
+
+	for _, n := range b.nodes {
+		if n.healthy(now) {
+			healthy = append(healthy, n)
+
+			if n.available() {
+				available = append(available, n)
+			}
+		}
+	}
+
+	switch {
+	case len(available) > 0:
+		return weighted(available)
+	case len(healthy) > 0:
+		return weighted(healthy)
+	default:
+		// Rather than failing all requests, try any node.
+		return weighted(b.nodes)
+	}
+}
+
+func weighted(nodes []*node) *node {
+	weights := make([]float64, len(nodes))
+
+	var total float64
+	for i, n := range nodes {
+		weights[i] = n.weight()
+		total += weights[i]
+	}
+
+	x := rand.Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return nodes[i]
+		}
+
+		x -= w
+	}
+
+	return nodes[len(nodes)-1]
+}
+
+// do calls fn with the shell of the node picked for r, within its concurrency limit, and updates the health and
+// affinity of the node according to the result. The node is first connected to the providers of r, if any.
+// r may be nil.
+func (b *balancer) do(ctx context.Context, r *t.AnnotatedResource, fn func(*ipfs.Shell) error) error {
+	n := b.pick(r)
+
+	err := b.call(ctx, n, r, fn)
+
+	if err == nil && r != nil && len(b.nodes) > 1 {
+		b.affinity.Add(r.ID, n)
+	}
+
+	return err
+}
+
+// all calls fn with the shells of all nodes concurrently, e.g. to store content which might later be requested
+// from any of them, returning the first error.
+func (b *balancer) all(ctx context.Context, fn func(*ipfs.Shell) error) error {
+	errg, ctx := errgroup.WithContext(ctx)
+
+	for _, n := range b.nodes {
+		n := n
+
+		errg.Go(func() error {
+			if err := b.call(ctx, n, nil, fn); err != nil {
+				return fmt.Errorf("IPFS node %s: %w", n.apiURL, err)
+			}
+
+			return nil
+		})
+	}
+
+	return errg.Wait()
+}
+
+// call calls fn with the shell of node n, within its concurrency limit, and updates the health of the node
+// according to the result.
+func (b *balancer) call(ctx context.Context, n *node, r *t.AnnotatedResource, fn func(*ipfs.Shell) error) error {
+	entered := time.Now()
+
+	if n.slots != nil {
+		select {
+		case n.slots <- struct{}{}:
+			defer func() { <-n.slots }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	start := time.Now()
+
+	if r != nil && len(r.Providers) > 0 {
+		n.connect(ctx, r.Providers)
+	}
+
+	err := fn(n.shell)
+	d := time.Since(start)
+
+	// The HTTP client sets no timeouts of its own, so hung nodes fail by the caller's deadline (e.g. the crawler's
+	// timeouts). These count as timeouts of the node when it used up at least half of the budget, rather than
+	// waiting for a slot. Cancelled calls say nothing about the node.
+	switch {
+	case ctx.Err() == nil:
+		b.report(n, d, err)
+	case err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && d >= start.Sub(entered):
+		b.report(n, d, context.DeadlineExceeded)
+	}
+
+	return err
+}
+
+// isTimeout returns whether an error is caused by a timeout, either of the caller's context or of the transport of
+// the HTTP client of the node (e.g. when dialing or awaiting response headers).
+func isTimeout(err error) bool {
+	var netErr net.Error
+
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// report updates the latency and health of a node after a request; other errors than timeouts are considered
+// successful requests, e.g. for invalid resources.
+func (b *balancer) report(n *node, d time.Duration, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if isTimeout(err) {
+		n.timeouts++
+
+		if b.config.EjectTimeouts > 0 && n.timeouts >= b.config.EjectTimeouts && len(b.nodes) > 1 {
+			log.Printf("Ejecting IPFS node %s for %s after %d consecutive timeouts.", n.apiURL, b.config.EjectDuration, n.timeouts)
+
+			n.ejectedUntil = time.Now().Add(b.config.EjectDuration)
+			n.timeouts = 0
+		}
+
+		return
+	}
+
+	n.timeouts = 0
+
+	if err == nil {
+		n.latency = time.Duration((1-latencyWeight)*float64(n.latency) + latencyWeight*float64(d))
+	}
+}
@@ -0,0 +1,251 @@
+package ipfs
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ipfs "github.com/ipfs/go-ipfs-api"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const (
+	dirID  = "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"
+	fileID = "QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN"
+)
+
+// testNode is an IPFS API answering ls and files/stat requests, counting them.
+type testNode struct {
+	server   *httptest.Server
+	requests int32
+	delay    time.Duration
+}
+
+func newTestNode() *testNode {
+	n := new(testNode)
+
+	n.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&n.requests, 1)
+		time.Sleep(n.delay)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/v0/ls":
+			w.Write([]byte(`{"Objects":[{"Hash":"` + dirID + `","Links":[{"Name":"file.txt","Hash":"` + fileID + `","Size":0,"Type":2}]}]}`))
+		case "/api/v0/files/stat":
+			w.Write([]byte(`{"Size":5,"CumulativeSize":5,"Type":"file"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return n
+}
+
+func (n *testNode) count() int {
+	return int(atomic.LoadInt32(&n.requests))
+}
+
+type BalancerTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	nodes  []*testNode
+	config *Config
+}
+
+func (s *BalancerTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.nodes = []*testNode{newTestNode(), newTestNode()}
+
+	s.config = DefaultConfig()
+	s.config.APIURL = s.nodes[0].server.URL
+	s.config.GatewayURL = "http://gateway0"
+	s.config.Nodes = []Node{{APIURL: s.nodes[1].server.URL, GatewayURL: "http://gateway1"}}
+	s.config.EjectTimeouts = 1
+}
+
+func (s *BalancerTestSuite) TearDownTest() {
+	for _, n := range s.nodes {
+		n.server.Close()
+	}
+}
+
+func (s *BalancerTestSuite) newIPFS() *IPFS {
+	return New(s.config, http.DefaultClient, instr.New())
+}
+
+func resource(id string) *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       id,
+		},
+	}
+}
+
+func (s *BalancerTestSuite) TestAffinity() {
+	i := s.newIPFS()
+
+	dir := resource(dirID)
+	out := make(chan *t.AnnotatedResource, 1)
+
+	s.Require().NoError(i.Ls(s.ctx, dir, out))
+	entry := <-out
+
+	lister := s.nodes[0]
+	if s.nodes[1].count() == 1 {
+		lister = s.nodes[1]
+	}
+
+	// Entries are requested from the node which listed the directory.
+	for n := 0; n < 10; n++ {
+		s.Require().NoError(i.Stat(s.ctx, entry))
+	}
+
+	s.Equal(11, lister.count())
+
+	// Content is requested from its gateway.
+	gatewayURL := "http://gateway0/ipfs/" + dirID + "/file.txt"
+	if lister == s.nodes[1] {
+		gatewayURL = "http://gateway1/ipfs/" + dirID + "/file.txt"
+	}
+
+	s.Equal(gatewayURL, i.GatewayURL(entry))
+}
+
+func (s *BalancerTestSuite) TestAll() {
+	b := newBalancer(s.config, http.DefaultClient)
+
+	err := b.all(s.ctx, func(shell *ipfs.Shell) error {
+		return shell.Request("files/stat", "/ipfs/"+fileID).Exec(s.ctx, nil)
+	})
+
+	s.NoError(err)
+
+	// Every node is called, regardless of affinity.
+	for _, n := range s.nodes {
+		s.Equal(1, n.count())
+	}
+}
+
+func (s *BalancerTestSuite) TestAllError() {
+	b := newBalancer(s.config, http.DefaultClient)
+
+	err := b.all(s.ctx, func(shell *ipfs.Shell) error {
+		return shell.Request("block/put").Exec(s.ctx, nil)
+	})
+
+	s.ErrorContains(err, "IPFS node")
+}
+
+func (s *BalancerTestSuite) TestEject() {
+	s.config.EjectDuration = 50 * time.Millisecond
+	b := newBalancer(s.config, http.DefaultClient)
+
+	b.report(b.nodes[0], time.Second, &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded})
+
+	for n := 0; n < 10; n++ {
+		s.Equal(b.nodes[1], b.pick(resource(fileID)))
+	}
+
+	time.Sleep(s.config.EjectDuration)
+
+	s.True(b.nodes[0].healthy(time.Now()))
+}
+
+func (s *BalancerTestSuite) TestIsTimeout() {
+	s.nodes[0].delay = 100 * time.Millisecond
+
+	client := &http.Client{Transport: &http.Transport{ResponseHeaderTimeout: 10 * time.Millisecond}}
+	b := newBalancer(s.config, client)
+	err := b.nodes[0].shell.Request("files/stat", "/ipfs/"+fileID).Exec(s.ctx, new(statResult))
+
+	s.True(isTimeout(err), err)
+	s.False(isTimeout(t.ErrInvalidResource))
+}
+
+// TestCallerTimeout tests whether nodes using up the deadline of the caller are ejected.
+func (s *BalancerTestSuite) TestCallerTimeout() {
+	s.nodes[0].delay = 100 * time.Millisecond
+	s.nodes[1].delay = 100 * time.Millisecond
+	s.config.EjectDuration = time.Minute
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Millisecond)
+	defer cancel()
+
+	b := newBalancer(s.config, http.DefaultClient)
+
+	var used *ipfs.Shell
+	err := b.do(ctx, nil, func(shell *ipfs.Shell) error {
+		used = shell
+		return shell.Request("files/stat", "/ipfs/"+fileID).Exec(ctx, new(statResult))
+	})
+
+	s.ErrorIs(err, context.DeadlineExceeded)
+
+	for _, n := range b.nodes {
+		s.Equal(n.shell != used, n.healthy(time.Now()), n.apiURL)
+	}
+}
+
+// TestCallerCancelNotReported tests whether calls cancelled by the caller do not count as timeouts.
+func (s *BalancerTestSuite) TestCallerCancelNotReported() {
+	s.nodes[0].delay = 100 * time.Millisecond
+	s.nodes[1].delay = 100 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	b := newBalancer(s.config, http.DefaultClient)
+	err := b.do(ctx, nil, func(shell *ipfs.Shell) error {
+		return shell.Request("files/stat", "/ipfs/"+fileID).Exec(ctx, new(statResult))
+	})
+
+	s.ErrorIs(err, context.Canceled)
+
+	for _, n := range b.nodes {
+		s.True(n.healthy(time.Now()))
+	}
+}
+
+func (s *BalancerTestSuite) TestLatencyWeighted() {
+	b := newBalancer(s.config, http.DefaultClient)
+	b.nodes[0].latency = time.Second
+	b.nodes[1].latency = time.Millisecond
+
+	picked := 0
+	for n := 0; n < 100; n++ {
+		if b.pick(resource(fileID)) == b.nodes[1] {
+			picked++
+		}
+	}
+
+	s.Greater(picked, 90)
+}
+
+func (s *BalancerTestSuite) TestConcurrencyLimit() {
+	s.config.MaxConcurrency = 1
+	b := newBalancer(s.config, http.DefaultClient)
+
+	// Occupy the only slot of the faster node.
+	b.nodes[1].latency = time.Millisecond
+	b.nodes[1].slots <- struct{}{}
+
+	for n := 0; n < 10; n++ {
+		s.Equal(b.nodes[0], b.pick(resource(fileID)))
+	}
+}
+
+func TestBalancerTestSuite(t *testing.T) {
+	suite.Run(t, new(BalancerTestSuite))
+}
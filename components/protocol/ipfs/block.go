@@ -0,0 +1,62 @@
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	ipfs "github.com/ipfs/go-ipfs-api"
+	files "github.com/ipfs/go-ipfs-files"
+	"github.com/multiformats/go-multihash"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type blockPutResult struct {
+	Key string
+}
+
+// PutBlock stores a block in all IPFS nodes, verifying that the nodes derive the same multihash. Crawlers might
+// request the blocks of an import from any node, also from other processes, hence they are stored on every node.
+// Ref: https://docs.ipfs.tech/reference/kubo/rpc/#api-v0-block-put
+func (i *IPFS) PutBlock(ctx context.Context, c cid.Cid, data []byte) error {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.PutBlock")
+	defer span.End()
+
+	prefix := c.Prefix()
+
+	err := i.nodes.all(ctx, func(shell *ipfs.Shell) error {
+		f := files.NewBytesFile(data)
+		d := files.NewSliceDirectory([]files.DirEntry{files.FileEntry("", f)})
+		result := new(blockPutResult)
+
+		err := shell.Request("block/put").
+			Option("cid-codec", t.CodecName(c)).
+			Option("mhtype", multihash.Codes[prefix.MhType]).
+			Option("mhlen", prefix.MhLength).
+			Body(files.NewMultiFileReader(d, true)).
+			Exec(ctx, result)
+		if err != nil {
+			return err
+		}
+
+		// The node might return another CID version, hence compare multihashes.
+		stored, err := cid.Decode(result.Key)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(stored.Hash(), c.Hash()) {
+			return fmt.Errorf("stored block %s does not match %s", stored, c)
+		}
+
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package ipfs
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/dankinder/httpmock"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type BlockTestSuite struct {
+	suite.Suite
+
+	ctx  context.Context
+	ipfs *IPFS
+
+	mockAPIHandler *httpmock.MockHandler
+	mockAPIServer  *httpmock.Server
+}
+
+func (s *BlockTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.mockAPIHandler = &httpmock.MockHandler{}
+	s.mockAPIServer = httpmock.NewServer(s.mockAPIHandler)
+
+	cfg := DefaultConfig()
+	cfg.APIURL = s.mockAPIServer.URL()
+
+	s.ipfs = New(cfg, http.DefaultClient, instr.New())
+}
+
+func (s *BlockTestSuite) TearDownTest() {
+	s.mockAPIServer.Close()
+}
+
+func (s *BlockTestSuite) expectPut(key string) {
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/block/put?cid-codec=raw&mhlen=32&mhtype=sha2-256", mock.Anything).
+		Return(httpmock.Response{
+			Body: []byte(`{"Key":"` + key + `","Size":5}`),
+		}).
+		Once()
+}
+
+func (s *BlockTestSuite) TestPutBlock() {
+	data := []byte("hello")
+	c, err := cid.NewPrefixV1(cid.Raw, 0x12).Sum(data)
+	s.Require().NoError(err)
+
+	s.expectPut(c.String())
+
+	err = s.ipfs.PutBlock(s.ctx, c, data)
+
+	s.NoError(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func (s *BlockTestSuite) TestPutBlockMismatch() {
+	data := []byte("hello")
+	c, err := cid.NewPrefixV1(cid.Raw, 0x12).Sum(data)
+	s.Require().NoError(err)
+
+	other, err := cid.NewPrefixV1(cid.Raw, 0x12).Sum([]byte("world"))
+	s.Require().NoError(err)
+
+	s.expectPut(other.String())
+
+	err = s.ipfs.PutBlock(s.ctx, c, data)
+
+	s.Error(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+}
+
+func TestBlockTestSuite(t *testing.T) {
+	suite.Run(t, new(BlockTestSuite))
+}
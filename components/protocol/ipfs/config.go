@@ -1,14 +1,28 @@
 package ipfs
 
 import (
+	"time"
+
 	"github.com/c2h5oh/datasize"
 )
 
+// Node specifies an additional IPFS node to balance requests over.
+type Node struct {
+	APIURL         string `yaml:"api_url"`                   // URL of the IPFS API endpoint of the node.
+	GatewayURL     string `yaml:"gateway_url,omitempty"`     // URL of the IPFS Gateway of the node; defaults to Config.GatewayURL.
+	MaxConcurrency int    `yaml:"max_concurrency,omitempty"` // Maximum concurrent requests; defaults to Config.MaxConcurrency.
+}
+
 // Config specifies the configuration for the IPFS protocol.
 type Config struct {
 	APIURL      string            // URL of an IPFS API endpoint (for Ls and Stat calls).
 	GatewayURL  string            // URL of an IPFS Gateway (to request content).
 	PartialSize datasize.ByteSize // Filesize of items which are being considered partials (chunks).
+
+	Nodes          []Node        // Additional nodes; requests are balanced over the node above and these.
+	MaxConcurrency int           // Maximum concurrent requests per node, 0 for unlimited.
+	EjectTimeouts  int           // Consecutive timeouts after which a node is ejected, 0 to never eject.
+	EjectDuration  time.Duration // Period during which ejected nodes receive no requests.
 }
 
 // DefaultConfig returns the default configuration for a Sniffer.
@@ -19,5 +33,8 @@ func DefaultConfig() *Config {
 		PartialSize: 262144,
 		// 256KB is the default chunker block size. Therefore, unreferenced files with exactly
 		// this size are very likely to be chunks of files (partials) rather than full files.
+
+		EjectTimeouts: 5,
+		EjectDuration: time.Minute,
 	}
 }
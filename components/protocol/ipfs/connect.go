@@ -0,0 +1,121 @@
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	ipfs "github.com/ipfs/go-ipfs-api"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// connectTimeout limits finding and connecting to providers, leaving time for the actual request.
+	connectTimeout = 10 * time.Second
+
+	// connectInterval is the minimal interval between connection attempts of a node to the same provider.
+	connectInterval = 5 * time.Minute
+
+	// peersSize is the number of providers for which the last connection attempt is remembered, per node.
+	peersSize = 10000
+
+	// finalPeer is the routing query event type with the addresses of a peer.
+	// Ref: https://github.com/libp2p/go-libp2p/blob/master/core/routing/query.go
+	finalPeer = 2
+)
+
+var errPeerNotFound = errors.New("peer not found")
+
+type findPeerResult struct {
+	Type      int
+	Responses []struct {
+		ID    string
+		Addrs []string
+	}
+}
+
+// findPeer returns the addresses of a peer, as /<multiaddr>/p2p/<peer> for swarm/connect.
+// Ref: https://docs.ipfs.tech/reference/kubo/rpc/#api-v0-routing-findpeer
+func findPeer(ctx context.Context, shell *ipfs.Shell, peer string) ([]string, error) {
+	resp, err := shell.Request("routing/findpeer", peer).Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// If err == nil, response might be nil and cannot be closed.
+	defer resp.Close()
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	dec := json.NewDecoder(resp.Output)
+
+	for {
+		var result findPeerResult
+
+		if err := dec.Decode(&result); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errPeerNotFound
+			}
+
+			return nil, err
+		}
+
+		if result.Type != finalPeer {
+			continue
+		}
+
+		for _, r := range result.Responses {
+			if r.ID != peer || len(r.Addrs) == 0 {
+				continue
+			}
+
+			addrs := make([]string, len(r.Addrs))
+			for i, a := range r.Addrs {
+				addrs[i] = a + "/p2p/" + peer
+			}
+
+			return addrs, nil
+		}
+	}
+}
+
+// shouldConnect returns whether the node should attempt to connect to a provider, recording the attempt.
+func (n *node) shouldConnect(peer string, now time.Time) bool {
+	if v, ok := n.peers.Get(peer); ok && now.Sub(v.(time.Time)) < connectInterval {
+		return false
+	}
+
+	n.peers.Add(peer, now)
+
+	return true
+}
+
+// connect connects the node to providers, so that rarely provided content can be fetched from them directly.
+// This is best-effort: errors are recorded on the span in ctx but otherwise ignored.
+// Ref: https://docs.ipfs.tech/reference/kubo/rpc/#api-v0-swarm-connect
+func (n *node) connect(ctx context.Context, providers []string) {
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	span := trace.SpanFromContext(ctx)
+	now := time.Now()
+
+	for _, p := range providers {
+		if !n.shouldConnect(p, now) {
+			continue
+		}
+
+		addrs, err := findPeer(ctx, n.shell, p)
+		if err == nil {
+			err = n.shell.Request("swarm/connect", addrs...).Exec(ctx, nil)
+		}
+
+		if err != nil {
+			span.RecordError(err)
+		}
+	}
+}
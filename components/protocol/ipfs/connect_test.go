@@ -0,0 +1,124 @@
+package ipfs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const (
+	providerID = "QmeTtFXm42Jb2todcKR538j6qHYxXt6suUzpF3rtT9FPSd"
+	unknownID  = "12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN"
+)
+
+type ConnectTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	server *httptest.Server
+	ipfs   *IPFS
+
+	mu       sync.Mutex
+	requests []*url.URL
+}
+
+func (s *ConnectTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.requests = nil
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	cfg := DefaultConfig()
+	cfg.APIURL = s.server.URL
+
+	s.ipfs = New(cfg, http.DefaultClient, instr.New())
+}
+
+func (s *ConnectTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ConnectTestSuite) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.URL.Path {
+	case "/api/v0/routing/findpeer":
+		if r.URL.Query().Get("arg") != providerID {
+			return
+		}
+
+		// Query events precede the final peer.
+		w.Write([]byte(`{"Extra":"","ID":"","Responses":null,"Type":0}` + "\n"))
+		w.Write([]byte(`{"Extra":"","ID":"","Responses":[{"Addrs":["/ip4/1.2.3.4/tcp/4001","/ip4/1.2.3.4/udp/4001/quic"],"ID":"` + providerID + `"}],"Type":2}` + "\n"))
+	case "/api/v0/swarm/connect":
+		w.Write([]byte(`{"Strings":["connect ` + providerID + ` success"]}`))
+	case "/api/v0/files/stat":
+		w.Write([]byte(`{"Size":5,"CumulativeSize":5,"Type":"file"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *ConnectTestSuite) paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths := make([]string, len(s.requests))
+	for i, u := range s.requests {
+		paths[i] = u.Path
+	}
+
+	return paths
+}
+
+func (s *ConnectTestSuite) stat(providers ...string) {
+	r := resource(fileID)
+	r.Providers = providers
+
+	s.Require().NoError(s.ipfs.Stat(s.ctx, r))
+	s.Equal(t.FileType, r.Type)
+}
+
+func (s *ConnectTestSuite) TestConnect() {
+	s.stat(providerID)
+
+	s.Equal([]string{"/api/v0/routing/findpeer", "/api/v0/swarm/connect", "/api/v0/files/stat"}, s.paths())
+	s.Equal([]string{
+		"/ip4/1.2.3.4/tcp/4001/p2p/" + providerID,
+		"/ip4/1.2.3.4/udp/4001/quic/p2p/" + providerID,
+	}, s.requests[1].Query()["arg"])
+
+	// Recently connected providers are skipped.
+	s.stat(providerID)
+
+	s.Len(s.paths(), 4)
+}
+
+func (s *ConnectTestSuite) TestNotFound() {
+	// Unknown providers don't fail requests.
+	s.stat(unknownID)
+
+	s.Equal([]string{"/api/v0/routing/findpeer", "/api/v0/files/stat"}, s.paths())
+}
+
+func (s *ConnectTestSuite) TestNoProviders() {
+	s.stat()
+
+	s.Equal([]string{"/api/v0/files/stat"}, s.paths())
+}
+
+func TestConnectTestSuite(t *testing.T) {
+	suite.Run(t, new(ConnectTestSuite))
+}
@@ -0,0 +1,68 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	ipfs "github.com/ipfs/go-ipfs-api"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// maxDAGSize is the maximum size of dag-json encoded documents; blocks are limited to 2MiB but binary
+// data is expanded by its encoding.
+const maxDAGSize = 8 * 1024 * 1024
+
+// GetDAG returns the dag-json encoding of an IPLD document.
+// Ref: https://docs.ipfs.tech/reference/kubo/rpc/#api-v0-dag-get
+func (i *IPFS) GetDAG(ctx context.Context, r *t.AnnotatedResource) ([]byte, error) {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.GetDAG")
+	defer span.End()
+
+	var data []byte
+
+	err := i.nodes.do(ctx, r, func(shell *ipfs.Shell) (err error) {
+		data, err = getDAG(ctx, shell, r)
+		return err
+	})
+
+	if err != nil && !errors.Is(err, t.ErrInvalidResource) {
+		span.RecordError(err)
+	}
+
+	return data, err
+}
+
+func getDAG(ctx context.Context, shell *ipfs.Shell, r *t.AnnotatedResource) ([]byte, error) {
+	resp, err := shell.Request("dag/get", absolutePath(r)).
+		Option("output-codec", "dag-json").
+		Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// If err == nil, response might be nil and cannot be closed.
+	defer resp.Close()
+
+	if err := resp.Error; err != nil {
+		if isInvalidResourceErr(err) {
+			return nil, fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+		}
+
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Output, maxDAGSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxDAGSize {
+		return nil, fmt.Errorf("%w: document larger than %d bytes", t.ErrInvalidResource, maxDAGSize)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,70 @@
+package ipfs
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type DAGTestSuite struct {
+	suite.Suite
+
+	ctx  context.Context
+	ipfs *IPFS
+
+	mockAPIHandler *httpmock.MockHandler
+	mockAPIServer  *httpmock.Server
+}
+
+func (s *DAGTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.mockAPIHandler = &httpmock.MockHandler{}
+	s.mockAPIServer = httpmock.NewServer(s.mockAPIHandler)
+
+	cfg := DefaultConfig()
+	cfg.APIURL = s.mockAPIServer.URL()
+
+	s.ipfs = New(cfg, http.DefaultClient, instr.New())
+}
+
+func (s *DAGTestSuite) TearDownTest() {
+	s.mockAPIServer.Close()
+}
+
+func (s *DAGTestSuite) TestGetDAG() {
+	const body = `{"name":"Token #1","image":{"/":"QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}}`
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "bafyreidmmwhoqp5x5ajeqjeu6psbnkdw6y7udcqlripv45wup3sbo4bvzm",
+		},
+	}
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/dag/get?arg=%2Fipfs%2Fbafyreidmmwhoqp5x5ajeqjeu6psbnkdw6y7udcqlripv45wup3sbo4bvzm&output-codec=dag-json", mock.Anything).
+		Return(httpmock.Response{
+			Header: http.Header{"Content-Type": []string{"text/plain"}},
+			Body:   []byte(body),
+		}).
+		Once()
+
+	data, err := s.ipfs.GetDAG(s.ctx, r)
+
+	s.NoError(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	s.Equal(body, string(data))
+}
+
+func TestDAGTestSuite(t *testing.T) {
+	suite.Run(t, new(DAGTestSuite))
+}
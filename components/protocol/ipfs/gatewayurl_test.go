@@ -78,6 +78,46 @@ func (s *GatewayURLTestSuite) TestGatewayURLUnnamedReference() {
 	s.Equal(url, gatewayURL+"/ipfs/QmcBLKyRHjbGeLnjnmj74FFJpGJDz4YxFqUDYqMU7Mny1p")
 }
 
+func (s *GatewayURLTestSuite) TestGatewayURLDomainReference() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmcBLKyRHjbGeLnjnmj74FFJpGJDz4YxFqUDYqMU7Mny1p",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.DNSLinkProtocol,
+				ID:       "example.com",
+			},
+			Name: "example.com",
+		},
+	}
+
+	url := s.ipfs.GatewayURL(r)
+
+	s.Equal(url, gatewayURL+"/ipfs/QmcBLKyRHjbGeLnjnmj74FFJpGJDz4YxFqUDYqMU7Mny1p")
+}
+
+func (s *GatewayURLTestSuite) TestGatewayURLIPLDReference() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "QmcBLKyRHjbGeLnjnmj74FFJpGJDz4YxFqUDYqMU7Mny1p",
+		},
+		Reference: t.Reference{
+			Parent: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       "bafyreidmmwhoqp5x5ajeqjeu6psbnkdw6y7udcqlripv45wup3sbo4bvzm",
+			},
+			Name: "properties/image",
+		},
+	}
+
+	url := s.ipfs.GatewayURL(r)
+
+	s.Equal(url, gatewayURL+"/ipfs/QmcBLKyRHjbGeLnjnmj74FFJpGJDz4YxFqUDYqMU7Mny1p")
+}
+
 func (s *GatewayURLTestSuite) TestEscapeURL() {
 	// Regression test:
 	// http://ipfs-tika:8081/ipfs/QmehSxmTPRCr85Xjgzjut6uWQihoTfqg9VVihJ892bmZCp/Killing_Yourself_to_Live:_85%_of_a_True_Story.html
@@ -105,3 +145,16 @@ func (s *GatewayURLTestSuite) TestEscapeURL() {
 func TestGatewayURLTestSuite(t *testing.T) {
 	suite.Run(t, new(GatewayURLTestSuite))
 }
+
+func (s *GatewayURLTestSuite) TestGatewayURLName() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPNSProtocol,
+			ID:       "k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8",
+		},
+	}
+
+	url := s.ipfs.GatewayURL(r)
+
+	s.Equal(url, gatewayURL+"/ipns/k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8")
+}
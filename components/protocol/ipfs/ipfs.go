@@ -3,9 +3,6 @@ package ipfs
 import (
 	"fmt"
 	"net/http"
-	"net/url"
-
-	ipfs "github.com/ipfs/go-ipfs-api"
 
 	"github.com/ipfs-search/ipfs-search/components/protocol"
 
@@ -14,42 +11,36 @@ import (
 )
 
 // IPFS implements the Protocol interface for the Interplanery Filesystem. It is concurrency-safe.
+// Requests are balanced over the configured nodes.
 type IPFS struct {
 	config *Config
 
-	gatewayURL *url.URL
-	shell      *ipfs.Shell
+	nodes *balancer
 
 	*instr.Instrumentation
 }
 
-// absolutePath returns the absolute (CID-only) path for a resource.
+// absolutePath returns the absolute (CID or name only) path for a resource, e.g. /ipfs/<cid> or /ipns/<name>.
+// DNSLink domains are resolved as names: /ipns/<domain>.
 func absolutePath(r *t.AnnotatedResource) string {
-	return fmt.Sprintf("/ipfs/%s", r.ID)
+	if r.Protocol == t.DNSLinkProtocol {
+		return fmt.Sprintf("/%s/%s", t.IPNSProtocol, r.ID)
+	}
+
+	return fmt.Sprintf("/%s/%s", r.Protocol, r.ID)
 }
 
 // New returns a new IPFS protocol.
 func New(config *Config, client *http.Client, instr *instr.Instrumentation) *IPFS {
-	// Initialize gatewayURL
-	gatewayURL, err := url.Parse(config.GatewayURL)
-	if err != nil {
-		panic(fmt.Sprintf("could not parse IPFS Gateway URL, error: %v", err))
-	}
-
-	if !gatewayURL.IsAbs() {
-		panic(fmt.Sprintf("gateway URL is not absolute: %s", gatewayURL))
-	}
-
-	// Create IPFS shell
-	shell := ipfs.NewShellWithClient(config.APIURL, client)
-
 	return &IPFS{
 		config,
-		gatewayURL,
-		shell,
+		newBalancer(config, client),
 		instr,
 	}
 }
 
-// Compile-time assurance that implementation satisfies interface.
-var _ protocol.Protocol = &IPFS{}
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ protocol.Protocol = &IPFS{}
+	_ protocol.Resolver = &IPFS{}
+)
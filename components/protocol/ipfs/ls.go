@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 
+	ipfs "github.com/ipfs/go-ipfs-api"
 	unixfs "github.com/ipfs/go-unixfs"
 	unixfs_pb "github.com/ipfs/go-unixfs/pb"
 
@@ -125,9 +126,21 @@ func (i *IPFS) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.Ann
 	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.Ls")
 	defer span.End()
 
+	err := i.nodes.do(ctx, r, func(shell *ipfs.Shell) error {
+		return ls(ctx, shell, r, out)
+	})
+
+	if err != nil && !errors.Is(err, t.ErrInvalidResource) {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func ls(ctx context.Context, shell *ipfs.Shell, r *t.AnnotatedResource, out chan<- *t.AnnotatedResource) error {
 	path := absolutePath(r)
 
-	resp, err := i.shell.Request("ls", path).
+	resp, err := shell.Request("ls", path).
 		Option("resolve-type", false).
 		Option("size", false).
 		Option("stream", true).
@@ -145,7 +158,6 @@ func (i *IPFS) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.Ann
 			return fmt.Errorf("%w: %v", t.ErrInvalidResource, resp.Error)
 		}
 
-		span.RecordError(err)
 		return err
 	}
 
@@ -166,9 +178,6 @@ func (i *IPFS) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.Ann
 				err = nil
 			}
 
-			if err != nil {
-				span.RecordError(err)
-			}
 			return err
 		}
 
@@ -186,6 +195,8 @@ func (i *IPFS) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.Ann
 				Type: typeFromPb(link.Type),
 				Size: link.Size,
 			},
+			// Providers of a directory are likely to provide its entries.
+			Providers: r.Providers,
 		}
 
 		select {
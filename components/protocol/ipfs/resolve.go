@@ -0,0 +1,113 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	ipfs "github.com/ipfs/go-ipfs-api"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+type resolveResult struct {
+	Path string
+}
+
+// Resolve returns the IPFS resource an IPNS name or DNSLink domain currently points to, following names
+// recursively. Paths into the resolved content (/ipfs/<cid>/sub/path) are resolved to the CID of the sub path.
+// Ref: https://docs.ipfs.tech/reference/kubo/rpc/#api-v0-name-resolve
+// Ref: https://docs.ipfs.tech/reference/kubo/rpc/#api-v0-resolve
+func (i *IPFS) Resolve(ctx context.Context, r *t.AnnotatedResource) (*t.Resource, error) {
+	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.Resolve")
+	defer span.End()
+
+	var cmd, arg string
+
+	switch r.Protocol {
+	case t.IPNSProtocol:
+		cmd, arg = "name/resolve", r.ID
+	case t.DNSLinkProtocol:
+		cmd, arg = "resolve", absolutePath(r)
+	default:
+		// Resolving immutable resources is a programming error.
+		panic(fmt.Sprintf("cannot resolve %v", r))
+	}
+
+	result := new(resolveResult)
+
+	err := i.nodes.do(ctx, r, func(shell *ipfs.Shell) error {
+		return shell.Request(cmd, arg).Option("recursive", true).Exec(ctx, result)
+	})
+
+	if err != nil {
+		if isInvalidResourceErr(err) {
+			err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+		}
+
+		span.RecordError(err)
+		return nil, err
+	}
+
+	id, err := cidFromPath(result.Path)
+
+	if errors.Is(err, errSubPath) {
+		// Name points into a directory; resolve the sub path.
+		id, err = i.resolvePath(ctx, r, result.Path)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return &t.Resource{Protocol: t.IPFSProtocol, ID: id}, nil
+}
+
+var errSubPath = errors.New("path has sub path")
+
+// cidFromPath returns the CID from an /ipfs/<cid> path, errSubPath for paths like /ipfs/<cid>/sub/path.
+func cidFromPath(path string) (string, error) {
+	const prefix = "/ipfs/"
+
+	id := strings.TrimPrefix(path, prefix)
+	if id == path || id == "" {
+		return "", fmt.Errorf("%w: unexpected path %s", t.ErrUnexpectedResponse, path)
+	}
+
+	if strings.Contains(id, "/") {
+		return "", errSubPath
+	}
+
+	return id, nil
+}
+
+type resolvePathResult struct {
+	Path string
+}
+
+// resolvePath returns the CID of an IPFS path r resolved to.
+// Ref: https://docs.ipfs.tech/reference/kubo/rpc/#api-v0-resolve
+func (i *IPFS) resolvePath(ctx context.Context, r *t.AnnotatedResource, path string) (string, error) {
+	result := new(resolvePathResult)
+
+	err := i.nodes.do(ctx, r, func(shell *ipfs.Shell) error {
+		return shell.Request("resolve", path).Exec(ctx, result)
+	})
+
+	if err != nil {
+		if isInvalidResourceErr(err) {
+			err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+		}
+
+		return "", err
+	}
+
+	id, err := cidFromPath(result.Path)
+	if errors.Is(err, errSubPath) {
+		err = fmt.Errorf("%w: unexpected path %s", t.ErrUnexpectedResponse, result.Path)
+	}
+
+	return id, err
+}
@@ -0,0 +1,135 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const testName = "k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8"
+
+type ResolveTestSuite struct {
+	suite.Suite
+
+	ctx  context.Context
+	ipfs *IPFS
+
+	mockAPIHandler *httpmock.MockHandler
+	mockAPIServer  *httpmock.Server
+	responseHeader http.Header
+}
+
+func (s *ResolveTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.mockAPIHandler = &httpmock.MockHandler{}
+	s.mockAPIServer = httpmock.NewServer(s.mockAPIHandler)
+	s.responseHeader = http.Header{
+		"Content-Type": []string{"application/json"},
+	}
+
+	cfg := DefaultConfig()
+	cfg.APIURL = s.mockAPIServer.URL()
+
+	s.ipfs = New(cfg, http.DefaultClient, instr.New())
+}
+
+func (s *ResolveTestSuite) TearDownTest() {
+	s.mockAPIServer.Close()
+}
+
+func (s *ResolveTestSuite) name() *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPNSProtocol,
+			ID:       testName,
+		},
+	}
+}
+
+func (s *ResolveTestSuite) expectResolve(path string) {
+	s.mockAPIHandler.
+		On("Handle", "POST", fmt.Sprintf("/api/v0/name/resolve?arg=%s&recursive=true", testName), mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(fmt.Sprintf(`{"Path":"%s"}`, path)),
+		}).
+		Once()
+}
+
+func (s *ResolveTestSuite) TestResolve() {
+	s.expectResolve("/ipfs/QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv")
+
+	target, err := s.ipfs.Resolve(s.ctx, s.name())
+
+	s.NoError(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	s.Equal(&t.Resource{
+		Protocol: t.IPFSProtocol,
+		ID:       "QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv",
+	}, target)
+}
+
+func (s *ResolveTestSuite) TestResolveSubPath() {
+	s.expectResolve("/ipfs/QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv/docs")
+
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/resolve?arg=%2Fipfs%2FQmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv%2Fdocs", mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"Path":"/ipfs/QmPZ9gcCEpqKTo6aq61g2nXGUhM4iCL3ewB6LDXZCtioEB"}`),
+		}).
+		Once()
+
+	target, err := s.ipfs.Resolve(s.ctx, s.name())
+
+	s.NoError(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	s.Equal("QmPZ9gcCEpqKTo6aq61g2nXGUhM4iCL3ewB6LDXZCtioEB", target.ID)
+}
+
+func (s *ResolveTestSuite) TestResolveUnexpectedPath() {
+	s.expectResolve("/ipns/example.com")
+
+	_, err := s.ipfs.Resolve(s.ctx, s.name())
+
+	s.ErrorIs(err, t.ErrUnexpectedResponse)
+}
+
+func (s *ResolveTestSuite) TestResolveDNSLink() {
+	s.mockAPIHandler.
+		On("Handle", "POST", "/api/v0/resolve?arg=%2Fipns%2Fexample.com&recursive=true", mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"Path":"/ipfs/QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv"}`),
+		}).
+		Once()
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.DNSLinkProtocol,
+			ID:       "example.com",
+		},
+	}
+
+	target, err := s.ipfs.Resolve(s.ctx, r)
+
+	s.NoError(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	s.Equal("QmS4ustL54uo8FzR9455qaxZwuMiUhyvMcX9Ba8nUH4uVv", target.ID)
+}
+
+func TestResolveTestSuite(t *testing.T) {
+	suite.Run(t, new(ResolveTestSuite))
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ipfs/go-cid"
+	ipfs "github.com/ipfs/go-ipfs-api"
+
 	t "github.com/ipfs-search/ipfs-search/types"
 )
 
@@ -32,20 +35,60 @@ func getSize(rType t.ResourceType, result *statResult) uint64 {
 	return result.CumulativeSize
 }
 
+type blockStatResult struct {
+	Size uint64
+}
+
+// statIPLD populates the Stat of an IPLD document, which files/stat doesn't understand.
+// Ref: https://docs.ipfs.tech/reference/kubo/rpc/#api-v0-block-stat
+func (i *IPFS) statIPLD(ctx context.Context, r *t.AnnotatedResource) error {
+	result := new(blockStatResult)
+
+	err := i.nodes.do(ctx, r, func(shell *ipfs.Shell) error {
+		return shell.Request("block/stat", r.ID).Exec(ctx, result)
+	})
+
+	if err != nil {
+		if isInvalidResourceErr(err) {
+			err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+		}
+
+		return err
+	}
+
+	r.Stat = t.Stat{
+		Type: t.IPLDType,
+		Size: result.Size,
+	}
+
+	return nil
+}
+
 // Stat returns a AnnotatedResource with Type and Size populated.
 // Ref: http://docs.ipfs.io.ipns.localhost:8080/reference/http/api/#api-v0-files-stat
 func (i *IPFS) Stat(ctx context.Context, r *t.AnnotatedResource) error {
 	ctx, span := i.Tracer.Start(ctx, "protocol.ipfs.Stat")
 	defer span.End()
 
+	if c, err := cid.Decode(r.ID); err == nil && t.IsIPLDDocument(c) {
+		if err := i.statIPLD(ctx, r); err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		return nil
+	}
+
 	const cmd = "files/stat"
 
 	path := absolutePath(r)
-	req := i.shell.Request(cmd, path)
-
 	result := new(statResult)
 
-	if err := req.Exec(ctx, result); err != nil {
+	err := i.nodes.do(ctx, r, func(shell *ipfs.Shell) error {
+		return shell.Request(cmd, path).Exec(ctx, result)
+	})
+
+	if err != nil {
 		if isInvalidResourceErr(err) {
 			err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
 		}
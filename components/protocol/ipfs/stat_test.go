@@ -312,6 +312,35 @@ func (s *StatTestSuite) TestNonInvalid500() {
 	s.False(errors.Is(err, t.ErrInvalidResource))
 }
 
+func (s *StatTestSuite) TestIPLD() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "bafyreidmmwhoqp5x5ajeqjeu6psbnkdw6y7udcqlripv45wup3sbo4bvzm",
+		},
+	}
+
+	rURL := fmt.Sprintf("/api/v0/block/stat?arg=%s", r.ID)
+
+	s.mockAPIHandler.
+		On("Handle", "POST", rURL, mock.Anything).
+		Return(httpmock.Response{
+			Header: s.responseHeader,
+			Body:   []byte(`{"Key":"bafyreidmmwhoqp5x5ajeqjeu6psbnkdw6y7udcqlripv45wup3sbo4bvzm","Size":1234}`),
+		}).
+		Once()
+
+	err := s.ipfs.Stat(s.ctx, r)
+
+	s.NoError(err)
+	s.mockAPIHandler.AssertExpectations(s.T())
+
+	s.Equal(r.Stat, t.Stat{
+		Type: t.IPLDType,
+		Size: 1234,
+	})
+}
+
 func TestStatTestSuite(t *testing.T) {
 	suite.Run(t, new(StatTestSuite))
 }
@@ -36,5 +36,20 @@ func (m *Mock) IsInvalidResourceErr(err error) bool {
 	return args.Bool(0)
 }
 
-// Compile-time assurance that implementation satisfies interface.
-var _ Protocol = &Mock{}
+// GetDAG mocks the corresponding method on the Protocol interface.
+func (m *Mock) GetDAG(ctx context.Context, r *t.AnnotatedResource) ([]byte, error) {
+	args := m.Called(ctx, r)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// Resolve mocks the corresponding method on the Resolver interface.
+func (m *Mock) Resolve(ctx context.Context, r *t.AnnotatedResource) (*t.Resource, error) {
+	args := m.Called(ctx, r)
+	return args.Get(0).(*t.Resource), args.Error(1)
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ Protocol = &Mock{}
+	_ Resolver = &Mock{}
+)
@@ -0,0 +1,63 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger"
+	"github.com/ipfs/go-cid"
+)
+
+// blocksPrefix is the mount point of blocks in datastores of IPFS nodes.
+const blocksPrefix = "/blocks/"
+
+// Badger is a read-only Blockstore for badger (v1) datastores, as used by go-ipfs' badgerds.
+// Ref: https://github.com/ipfs/go-ds-badger
+type Badger struct {
+	db *badger.DB
+}
+
+// OpenBadger opens the badger datastore in path, read-only. The datastore should not be in use.
+func OpenBadger(path string) (*Badger, error) {
+	opts := badger.DefaultOptions(path).
+		WithReadOnly(true).
+		WithLogger(nil)
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Badger{db}, nil
+}
+
+// Get returns the data of the block with CID c, or ErrNotFound.
+func (s *Badger) Get(_ context.Context, c cid.Cid) ([]byte, error) {
+	var data []byte
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		for _, key := range dsKeys(c) {
+			item, err := txn.Get([]byte(blocksPrefix + key))
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			data, err = item.ValueCopy(nil)
+			return err
+		}
+
+		return fmt.Errorf("%w: %s", ErrNotFound, c)
+	})
+
+	return data, err
+}
+
+// Close closes the datastore.
+func (s *Badger) Close() error {
+	return s.db.Close()
+}
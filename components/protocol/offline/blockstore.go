@@ -0,0 +1,104 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-base32"
+)
+
+// ErrNotFound is returned for blocks missing from a Blockstore.
+var ErrNotFound = errors.New("block not found")
+
+// Blockstore provides read access to blocks. It is concurrency-safe.
+type Blockstore interface {
+	// Get returns the data of the block with CID c, or ErrNotFound.
+	Get(ctx context.Context, c cid.Cid) ([]byte, error)
+	Close() error
+}
+
+// Open opens a CAR file, a flatfs or badger datastore, or the repository of an IPFS node using either, read-only.
+func Open(path string) (Blockstore, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fi.IsDir() {
+		return OpenCAR(path)
+	}
+
+	// Repositories of IPFS nodes keep blocks in blocks/ (flatfs) or badgerds/ (badger).
+	for _, dir := range []string{path, filepath.Join(path, "blocks"), filepath.Join(path, "badgerds")} {
+		if exists(filepath.Join(dir, shardingFile)) {
+			return OpenFlatFS(dir)
+		}
+
+		if exists(filepath.Join(dir, "MANIFEST")) {
+			return OpenBadger(dir)
+		}
+	}
+
+	return nil, fmt.Errorf("no flatfs or badger datastore in %s", path)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// dsKeys returns the datastore keys blocks are stored under, without leading slash: the base32 encoded
+// multihash (since go-ipfs 0.12) and, for older repositories, the base32 encoded CID.
+func dsKeys(c cid.Cid) []string {
+	keys := []string{base32.RawStdEncoding.EncodeToString(c.Hash())}
+
+	if c.Version() != 0 {
+		keys = append(keys, base32.RawStdEncoding.EncodeToString(c.Bytes()))
+	}
+
+	return keys
+}
+
+// Memory is a Blockstore keeping blocks in memory, e.g. for tests.
+type Memory struct {
+	mu     sync.RWMutex
+	blocks map[string][]byte // Keyed by multihash.
+}
+
+// NewMemory returns an empty Memory Blockstore.
+func NewMemory() *Memory {
+	return &Memory{
+		blocks: make(map[string][]byte),
+	}
+}
+
+// Put stores a block.
+func (m *Memory) Put(c cid.Cid, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.blocks[string(c.Hash())] = data
+}
+
+// Get returns the data of the block with CID c, or ErrNotFound.
+func (m *Memory) Get(_ context.Context, c cid.Cid) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.blocks[string(c.Hash())]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, c)
+	}
+
+	return data, nil
+}
+
+// Close is a no-op.
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,161 @@
+package offline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger"
+	"github.com/ipfs/go-cid"
+	merkledag "github.com/ipfs/go-merkledag"
+	"github.com/multiformats/go-base32"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/car"
+)
+
+type BlockstoreTestSuite struct {
+	suite.Suite
+
+	ctx     context.Context
+	dir     string
+	block   *merkledag.RawNode
+	missing cid.Cid
+}
+
+func (s *BlockstoreTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.dir = s.T().TempDir()
+	s.block = merkledag.NewRawNode([]byte("hello"))
+	s.missing = merkledag.NewRawNode([]byte("missing")).Cid()
+}
+
+func (s *BlockstoreTestSuite) key() string {
+	return base32.RawStdEncoding.EncodeToString(s.block.Cid().Hash())
+}
+
+func (s *BlockstoreTestSuite) assertBlockstore(bs Blockstore) {
+	defer bs.Close()
+
+	data, err := bs.Get(s.ctx, s.block.Cid())
+	s.NoError(err)
+	s.Equal(s.block.RawData(), data)
+
+	_, err = bs.Get(s.ctx, s.missing)
+	s.ErrorIs(err, ErrNotFound)
+}
+
+func (s *BlockstoreTestSuite) writeCAR() string {
+	path := filepath.Join(s.dir, "test.car")
+
+	f, err := os.Create(path)
+	s.Require().NoError(err)
+	defer f.Close()
+
+	w, err := car.NewWriter(f, []cid.Cid{s.block.Cid()})
+	s.Require().NoError(err)
+
+	// Precede by another block, so that data is not at the start.
+	other := merkledag.NewRawNode([]byte("other"))
+	s.Require().NoError(w.Put(other.Cid(), other.RawData()))
+	s.Require().NoError(w.Put(s.block.Cid(), s.block.RawData()))
+
+	return path
+}
+
+func (s *BlockstoreTestSuite) writeFlatFS(dir string) {
+	key := s.key()
+	shard := filepath.Join(dir, key[len(key)-3:len(key)-1])
+
+	s.Require().NoError(os.MkdirAll(shard, 0o755))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, shardingFile), []byte("/repo/flatfs/shard/v1/next-to-last/2\n"), 0o644))
+	s.Require().NoError(os.WriteFile(filepath.Join(shard, key+dataExtension), s.block.RawData(), 0o644))
+}
+
+func (s *BlockstoreTestSuite) writeBadger(dir string) {
+	s.Require().NoError(os.MkdirAll(dir, 0o755))
+
+	db, err := badger.Open(badger.DefaultOptions(dir).WithLogger(nil))
+	s.Require().NoError(err)
+
+	err = db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(blocksPrefix+s.key()), s.block.RawData())
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(db.Close())
+}
+
+func (s *BlockstoreTestSuite) TestCAR() {
+	bs, err := OpenCAR(s.writeCAR())
+	s.Require().NoError(err)
+
+	s.Equal([]cid.Cid{s.block.Cid()}, bs.Roots())
+	s.assertBlockstore(bs)
+}
+
+func (s *BlockstoreTestSuite) TestFlatFS() {
+	s.writeFlatFS(s.dir)
+
+	bs, err := OpenFlatFS(s.dir)
+	s.Require().NoError(err)
+
+	s.assertBlockstore(bs)
+}
+
+func (s *BlockstoreTestSuite) TestBadger() {
+	s.writeBadger(s.dir)
+
+	bs, err := OpenBadger(s.dir)
+	s.Require().NoError(err)
+
+	s.assertBlockstore(bs)
+}
+
+func (s *BlockstoreTestSuite) TestMemory() {
+	bs := NewMemory()
+	bs.Put(s.block.Cid(), s.block.RawData())
+
+	s.assertBlockstore(bs)
+}
+
+func (s *BlockstoreTestSuite) TestOpen() {
+	flatfsRepo := filepath.Join(s.dir, "flatfs")
+	s.writeFlatFS(filepath.Join(flatfsRepo, "blocks"))
+
+	badgerRepo := filepath.Join(s.dir, "badger")
+	s.writeBadger(filepath.Join(badgerRepo, "badgerds"))
+
+	for _, path := range []string{s.writeCAR(), flatfsRepo, badgerRepo} {
+		bs, err := Open(path)
+		s.Require().NoError(err, path)
+
+		s.assertBlockstore(bs)
+	}
+
+	_, err := Open(s.T().TempDir())
+	s.Error(err)
+}
+
+func (s *BlockstoreTestSuite) TestParseSharding() {
+	const key = "CIQA"
+
+	cases := map[string]string{
+		"/repo/flatfs/shard/v1/next-to-last/2": "IQ",
+		"/repo/flatfs/shard/v1/prefix/3":       "CIQ",
+		"/repo/flatfs/shard/v1/suffix/2":       "QA",
+	}
+
+	for spec, expected := range cases {
+		shard, err := parseSharding(spec)
+		s.Require().NoError(err, spec)
+		s.Equal(expected, shard(key), spec)
+	}
+
+	_, err := parseSharding("/repo/flatfs/shard/v1/unknown/2")
+	s.Error(err)
+}
+
+func TestBlockstoreTestSuite(t *testing.T) {
+	suite.Run(t, new(BlockstoreTestSuite))
+}
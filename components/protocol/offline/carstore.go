@@ -0,0 +1,93 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-search/ipfs-search/components/car"
+)
+
+type location struct {
+	offset int64
+	length int
+}
+
+// CAR is a Blockstore reading blocks from a CAR file. Blocks are verified and indexed when opening; only
+// their location is kept in memory.
+type CAR struct {
+	f     *os.File
+	roots []cid.Cid
+	index map[string]location // Keyed by multihash.
+}
+
+// OpenCAR opens and indexes a CARv1 or CARv2 file.
+func OpenCAR(path string) (*CAR, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := indexCAR(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+func indexCAR(f *os.File) (*CAR, error) {
+	r, err := car.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &CAR{
+		f:     f,
+		roots: r.Roots(),
+		index: make(map[string]location),
+	}
+
+	for {
+		b, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return s, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.index[string(b.Cid.Hash())] = location{r.Offset(), len(b.Data)}
+	}
+}
+
+// Roots returns the roots listed in the header of the archive.
+func (s *CAR) Roots() []cid.Cid {
+	return s.roots
+}
+
+// Get returns the data of the block with CID c, or ErrNotFound.
+func (s *CAR) Get(_ context.Context, c cid.Cid) ([]byte, error) {
+	l, ok := s.index[string(c.Hash())]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, c)
+	}
+
+	data := make([]byte, l.length)
+	if _, err := s.f.ReadAt(data, l.offset); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Close closes the file.
+func (s *CAR) Close() error {
+	return s.f.Close()
+}
@@ -0,0 +1,19 @@
+package offline
+
+import (
+	"github.com/c2h5oh/datasize"
+)
+
+// Config specifies the configuration for the offline protocol.
+type Config struct {
+	GatewayURL  string            // URL of the Gateway serving content from the Blockstore.
+	PartialSize datasize.ByteSize // Filesize of items which are being considered partials (chunks).
+}
+
+// DefaultConfig returns the default configuration for the offline protocol.
+func DefaultConfig() *Config {
+	return &Config{
+		GatewayURL:  "http://localhost:8082",
+		PartialSize: 262144,
+	}
+}
@@ -0,0 +1,95 @@
+package offline
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-search/ipfs-search/components/car"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// cidTag is the CBOR tag for CIDs in dag-cbor.
+const cidTag = 42
+
+var cborDecMode, _ = cbor.DecOptions{
+	DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+}.DecMode()
+
+// DAGJSON returns the dag-json encoding of an IPLD document block; dag-cbor documents are converted.
+// Other blocks are invalid resources.
+func DAGJSON(b *car.Block) ([]byte, error) {
+	switch b.Cid.Type() {
+	case t.DagJSON:
+		return b.Data, nil
+	case cid.DagCBOR:
+		data, err := cborToDAGJSON(b.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+		}
+
+		return data, nil
+	default:
+		return nil, fmt.Errorf("%w: %s is not an IPLD document", t.ErrInvalidResource, b.Cid)
+	}
+}
+
+// cborToDAGJSON converts a dag-cbor document to dag-json.
+// Ref: https://ipld.io/specs/codecs/dag-json/spec/
+func cborToDAGJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := cborDecMode.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	v, err := toDAGJSON(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map keys are sorted by encoding/json.
+	return json.Marshal(v)
+}
+
+// toDAGJSON replaces links and bytes by their dag-json representation, {"/": "<cid>"} and
+// {"/": {"bytes": "<base64>"}} respectively.
+func toDAGJSON(v interface{}) (interface{}, error) {
+	var err error
+
+	switch v := v.(type) {
+	case cbor.Tag:
+		b, ok := v.Content.([]byte)
+		if v.Number != cidTag || !ok || len(b) < 1 {
+			return nil, fmt.Errorf("unsupported tag %d", v.Number)
+		}
+
+		c, err := cid.Cast(b[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{"/": c.String()}, nil
+	case []byte:
+		return map[string]interface{}{
+			"/": map[string]interface{}{"bytes": base64.RawStdEncoding.EncodeToString(v)},
+		}, nil
+	case map[string]interface{}:
+		for k, e := range v {
+			if v[k], err = toDAGJSON(e); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			if v[i], err = toDAGJSON(e); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return v, nil
+}
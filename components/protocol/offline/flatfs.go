@@ -0,0 +1,97 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+)
+
+const (
+	shardingFile   = "SHARDING"
+	shardingPrefix = "/repo/flatfs/shard/v1/"
+	dataExtension  = ".data"
+)
+
+// FlatFS is a read-only Blockstore for flatfs datastores, with one file per block.
+// Ref: https://github.com/ipfs/go-ds-flatfs
+type FlatFS struct {
+	path  string
+	shard func(key string) string
+}
+
+// parseSharding returns the function mapping keys to their directory for a flatfs sharding specification,
+// e.g. /repo/flatfs/shard/v1/next-to-last/2.
+func parseSharding(spec string) (func(string) string, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(spec), shardingPrefix), "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid flatfs sharding: %s", spec)
+	}
+
+	n, err := strconv.Atoi(parts[1])
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid flatfs sharding: %s", spec)
+	}
+
+	padding := strings.Repeat("_", n+1)
+
+	switch parts[0] {
+	case "prefix":
+		return func(key string) string {
+			return (key + padding)[:n]
+		}, nil
+	case "suffix":
+		return func(key string) string {
+			k := padding + key
+			return k[len(k)-n:]
+		}, nil
+	case "next-to-last":
+		return func(key string) string {
+			k := padding + key
+			offset := len(k) - n - 1
+			return k[offset : offset+n]
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported flatfs sharding: %s", spec)
+	}
+}
+
+// OpenFlatFS opens the flatfs datastore in path.
+func OpenFlatFS(path string) (*FlatFS, error) {
+	spec, err := os.ReadFile(filepath.Join(path, shardingFile))
+	if err != nil {
+		return nil, err
+	}
+
+	shard, err := parseSharding(string(spec))
+	if err != nil {
+		return nil, err
+	}
+
+	return &FlatFS{path, shard}, nil
+}
+
+// Get returns the data of the block with CID c, or ErrNotFound.
+func (s *FlatFS) Get(_ context.Context, c cid.Cid) ([]byte, error) {
+	for _, key := range dsKeys(c) {
+		data, err := os.ReadFile(filepath.Join(s.path, s.shard(key), key+dataExtension))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		return data, err
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, c)
+}
+
+// Close is a no-op.
+func (s *FlatFS) Close() error {
+	return nil
+}
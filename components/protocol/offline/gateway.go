@@ -0,0 +1,201 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	merkledag "github.com/ipfs/go-merkledag"
+	unixfs "github.com/ipfs/go-unixfs"
+
+	"github.com/ipfs-search/ipfs-search/components/car"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// indexFile is served for directories.
+const indexFile = "index.html"
+
+var errNotFile = errors.New("not a file")
+
+// Gateway serves files from a Blockstore at /ipfs/<cid>[/<path>], like the path gateway of an IPFS node.
+// Directories are served through their index.html. Range requests are not supported.
+type Gateway struct {
+	bs Blockstore
+}
+
+// NewGateway returns a Gateway for bs.
+func NewGateway(bs Blockstore) *Gateway {
+	return &Gateway{bs}
+}
+
+// ServeHTTP serves GET and HEAD requests.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := req.Context()
+
+	c, n, name, err := g.resolve(ctx, req.URL.Path)
+	if err != nil {
+		g.error(w, err)
+		return
+	}
+
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatUint(n.Size, 10))
+	w.Header().Set("Etag", fmt.Sprintf(`"%s"`, c))
+
+	if req.Method == http.MethodHead {
+		return
+	}
+
+	// Errors after writing the header can't be reported; the response is truncated.
+	_ = g.writeFile(ctx, w, c)
+}
+
+func (g *Gateway) error(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, t.ErrInvalidResource), errors.Is(err, errNotFile):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (g *Gateway) node(ctx context.Context, c cid.Cid) (*car.Node, error) {
+	data, err := g.bs.Get(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(&car.Block{Cid: c, Data: data})
+}
+
+// resolve returns the CID, Node and name of the file at p, which is the index file for directories.
+func (g *Gateway) resolve(ctx context.Context, p string) (cid.Cid, *car.Node, string, error) {
+	if !strings.HasPrefix(p, "/ipfs/") {
+		return cid.Undef, nil, "", fmt.Errorf("%w: %s", ErrNotFound, p)
+	}
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(p, "/ipfs/"), "/"), "/")
+
+	c, err := cid.Decode(segments[0])
+	if err != nil {
+		return cid.Undef, nil, "", fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+	}
+
+	n, err := g.node(ctx, c)
+	if err != nil {
+		return cid.Undef, nil, "", err
+	}
+
+	name := ""
+
+	for _, s := range segments[1:] {
+		if n.Type != t.DirectoryType {
+			return cid.Undef, nil, "", fmt.Errorf("%w: %s", ErrNotFound, p)
+		}
+
+		if c, n, err = g.entry(ctx, n, s); err != nil {
+			return cid.Undef, nil, "", err
+		}
+
+		name = s
+	}
+
+	if n.Type == t.DirectoryType {
+		if c, n, err = g.entry(ctx, n, indexFile); err != nil {
+			return cid.Undef, nil, "", err
+		}
+
+		name = indexFile
+	}
+
+	if n.Type != t.FileType {
+		return cid.Undef, nil, "", fmt.Errorf("%w: %s", errNotFile, p)
+	}
+
+	return c, n, name, nil
+}
+
+// entry returns the CID and Node of the entry with name in directory n.
+func (g *Gateway) entry(ctx context.Context, n *car.Node, name string) (cid.Cid, *car.Node, error) {
+	c, err := g.lookup(ctx, n, name)
+	if err != nil {
+		return cid.Undef, nil, err
+	}
+
+	n, err = g.node(ctx, c)
+
+	return c, n, err
+}
+
+// lookup returns the CID of the entry with name in directory n, descending into sub-shards.
+func (g *Gateway) lookup(ctx context.Context, n *car.Node, name string) (cid.Cid, error) {
+	for _, l := range n.Links {
+		if l.Name == name {
+			return l.Cid, nil
+		}
+
+		if l.Name == "" {
+			shard, err := g.node(ctx, l.Cid)
+			if err != nil {
+				return cid.Undef, err
+			}
+
+			if c, err := g.lookup(ctx, shard, name); err == nil {
+				return c, nil
+			}
+		}
+	}
+
+	return cid.Undef, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// writeFile writes the content of the file with CID c to w, in order of its chunks.
+func (g *Gateway) writeFile(ctx context.Context, w http.ResponseWriter, c cid.Cid) error {
+	data, err := g.bs.Get(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	if c.Type() == cid.Raw {
+		_, err := w.Write(data)
+		return err
+	}
+
+	pn, err := merkledag.DecodeProtobuf(data)
+	if err != nil {
+		return err
+	}
+
+	fsn, err := unixfs.FSNodeFromBytes(pn.Data())
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(fsn.Data()); err != nil {
+		return err
+	}
+
+	for _, l := range pn.Links() {
+		if err := g.writeFile(ctx, w, l.Cid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package offline
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GatewayTestSuite struct {
+	suite.Suite
+
+	f      *fixture
+	server *httptest.Server
+}
+
+func (s *GatewayTestSuite) SetupTest() {
+	s.f = newFixture()
+	s.server = httptest.NewServer(NewGateway(s.f.bs))
+}
+
+func (s *GatewayTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *GatewayTestSuite) get(method, path string) (*http.Response, string) {
+	req, err := http.NewRequest(method, s.server.URL+path, nil)
+	s.Require().NoError(err)
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+
+	return resp, string(body)
+}
+
+func (s *GatewayTestSuite) TestFile() {
+	resp, body := s.get(http.MethodGet, "/ipfs/"+s.f.raw.Cid().String())
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal("hello", body)
+	s.Equal("5", resp.Header.Get("Content-Length"))
+}
+
+func (s *GatewayTestSuite) TestChunkedFile() {
+	resp, body := s.get(http.MethodGet, "/ipfs/"+s.f.chunked.Cid().String())
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal("hello world", body)
+}
+
+func (s *GatewayTestSuite) TestPath() {
+	resp, body := s.get(http.MethodGet, "/ipfs/"+s.f.root.Cid().String()+"/chunked.txt")
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal("hello world", body)
+	s.Equal("text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+}
+
+func (s *GatewayTestSuite) TestIndex() {
+	resp, body := s.get(http.MethodGet, "/ipfs/"+s.f.root.Cid().String()+"/site/")
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal("<html></html>", body)
+	s.Equal("text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+}
+
+func (s *GatewayTestSuite) TestHead() {
+	resp, body := s.get(http.MethodHead, "/ipfs/"+s.f.root.Cid().String()+"/hello.txt")
+
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal("5", resp.Header.Get("Content-Length"))
+	s.Empty(body)
+}
+
+func (s *GatewayTestSuite) TestNotFound() {
+	for _, path := range []string{
+		"/ipfs/" + s.f.root.Cid().String() + "/missing.txt",
+		"/ipfs/" + s.f.root.Cid().String() + "/nonexistent",
+		"/ipfs/" + s.f.root.Cid().String() + "/hello.txt/sub",
+		"/ipns/example.com",
+	} {
+		resp, _ := s.get(http.MethodGet, path)
+		s.Equal(http.StatusNotFound, resp.StatusCode, path)
+	}
+}
+
+func (s *GatewayTestSuite) TestDirectoryWithoutIndex() {
+	resp, _ := s.get(http.MethodGet, "/ipfs/"+s.f.root.Cid().String())
+
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+}
+
+func (s *GatewayTestSuite) TestInvalid() {
+	resp, _ := s.get(http.MethodGet, "/ipfs/invalid")
+
+	s.Equal(http.StatusBadRequest, resp.StatusCode)
+}
+
+func (s *GatewayTestSuite) TestMethodNotAllowed() {
+	resp, _ := s.get(http.MethodPost, "/ipfs/"+s.f.raw.Cid().String())
+
+	s.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestGatewayTestSuite(t *testing.T) {
+	suite.Run(t, new(GatewayTestSuite))
+}
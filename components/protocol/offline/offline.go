@@ -0,0 +1,224 @@
+// Package offline implements the Protocol interface on top of a local Blockstore, e.g. a CAR file or the
+// datastore of an IPFS node, without any network access. A Gateway serves content to extractors.
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-search/ipfs-search/components/car"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Offline implements the Protocol interface for content in a Blockstore. It is concurrency-safe.
+// Names can't be resolved offline.
+type Offline struct {
+	config *Config
+
+	gatewayURL *url.URL
+	bs         Blockstore
+
+	*instr.Instrumentation
+}
+
+// New returns a new Offline protocol.
+func New(config *Config, bs Blockstore, instr *instr.Instrumentation) *Offline {
+	gatewayURL, err := url.Parse(config.GatewayURL)
+	if err != nil {
+		panic(fmt.Sprintf("could not parse Gateway URL, error: %v", err))
+	}
+
+	if !gatewayURL.IsAbs() {
+		panic(fmt.Sprintf("gateway URL is not absolute: %s", gatewayURL))
+	}
+
+	return &Offline{
+		config,
+		gatewayURL,
+		bs,
+		instr,
+	}
+}
+
+// GatewayURL returns the URL to request a resource from the Gateway.
+func (o *Offline) GatewayURL(r *t.AnnotatedResource) string {
+	url, err := o.gatewayURL.Parse(protocol.GatewayPath(r))
+	if err != nil {
+		panic(fmt.Sprintf("error generating GatewayURL: %v", err))
+	}
+
+	return url.String()
+}
+
+// getBlock returns the block for a resource.
+func (o *Offline) getBlock(ctx context.Context, r *t.AnnotatedResource) (*car.Block, error) {
+	if r.Protocol != t.IPFSProtocol {
+		return nil, fmt.Errorf("unsupported protocol %s", r.Protocol)
+	}
+
+	c, err := cid.Decode(r.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+	}
+
+	return o.getCid(ctx, c)
+}
+
+func (o *Offline) getCid(ctx context.Context, c cid.Cid) (*car.Block, error) {
+	data, err := o.bs.Get(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &car.Block{Cid: c, Data: data}, nil
+}
+
+// decode returns the Node for a block; undecodable blocks are invalid resources.
+func decode(b *car.Block) (*car.Node, error) {
+	n, err := car.Decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+	}
+
+	return n, nil
+}
+
+// Stat populates the Type and Size of a resource from its block.
+func (o *Offline) Stat(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := o.Tracer.Start(ctx, "protocol.offline.Stat")
+	defer span.End()
+
+	b, err := o.getBlock(ctx, r)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	n, err := decode(b)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	r.Stat = t.Stat{
+		Type: n.Type,
+		Size: n.Size,
+	}
+
+	// Override type for *unreferenced* partials, based on size
+	if r.Type == t.FileType && r.Size == uint64(o.config.PartialSize) && r.Reference.Parent == nil {
+		r.Stat.Type = t.PartialType
+	}
+
+	return nil
+}
+
+// Ls writes the entries of a directory to out, with Type and Size populated for entries in the Blockstore.
+func (o *Offline) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.AnnotatedResource) error {
+	ctx, span := o.Tracer.Start(ctx, "protocol.offline.Ls")
+	defer span.End()
+
+	b, err := o.getBlock(ctx, r)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	n, err := decode(b)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if err := o.ls(ctx, r, n, out); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// ls lists the links of n, descending into sub-shards.
+func (o *Offline) ls(ctx context.Context, r *t.AnnotatedResource, n *car.Node, out chan<- *t.AnnotatedResource) error {
+	for _, l := range n.Links {
+		b, err := o.getCid(ctx, l.Cid)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		var child *car.Node
+		if b != nil {
+			if child, err = decode(b); err != nil {
+				return err
+			}
+		}
+
+		if l.Name == "" {
+			if child == nil {
+				return fmt.Errorf("shard %s: %w", l.Cid, ErrNotFound)
+			}
+
+			if err := o.ls(ctx, r, child, out); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		entry := &t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       l.Cid.String(),
+			},
+			Source: t.DirectorySource,
+			Reference: t.Reference{
+				Parent: r.Resource,
+				Name:   l.Name,
+			},
+		}
+
+		if child != nil {
+			entry.Stat = t.Stat{
+				Type: child.Type,
+				Size: child.Size,
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- entry:
+		}
+	}
+
+	return nil
+}
+
+// GetDAG returns the dag-json encoding of an IPLD document; dag-cbor documents are converted.
+func (o *Offline) GetDAG(ctx context.Context, r *t.AnnotatedResource) ([]byte, error) {
+	ctx, span := o.Tracer.Start(ctx, "protocol.offline.GetDAG")
+	defer span.End()
+
+	b, err := o.getBlock(ctx, r)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	data, err := DAGJSON(b)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return data, err
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var _ protocol.Protocol = &Offline{}
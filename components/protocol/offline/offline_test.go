@@ -0,0 +1,219 @@
+package offline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	format "github.com/ipfs/go-ipld-format"
+	merkledag "github.com/ipfs/go-merkledag"
+	unixfs "github.com/ipfs/go-unixfs"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// fixture is a directory with a raw file, a chunked file, a website and an entry missing from the blockstore.
+type fixture struct {
+	bs *Memory
+
+	raw, chunked, index, missing format.Node
+	site, root                   *merkledag.ProtoNode
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		bs:      NewMemory(),
+		raw:     merkledag.NewRawNode([]byte("hello")),
+		index:   merkledag.NewRawNode([]byte("<html></html>")),
+		missing: merkledag.NewRawNode([]byte("missing")),
+	}
+
+	chunk1 := merkledag.NewRawNode([]byte("hello "))
+	chunk2 := merkledag.NewRawNode([]byte("world"))
+
+	fsn := unixfs.NewFSNode(unixfs.TFile)
+	fsn.AddBlockSize(6)
+	fsn.AddBlockSize(5)
+
+	fsnData, err := fsn.GetBytes()
+	if err != nil {
+		panic(err)
+	}
+
+	chunked := merkledag.NodeWithData(fsnData)
+	f.mustLink(chunked, "", chunk1)
+	f.mustLink(chunked, "", chunk2)
+	f.chunked = chunked
+
+	f.site = merkledag.NodeWithData(unixfs.FolderPBData())
+	f.mustLink(f.site, "index.html", f.index)
+
+	f.root = merkledag.NodeWithData(unixfs.FolderPBData())
+	f.mustLink(f.root, "hello.txt", f.raw)
+	f.mustLink(f.root, "chunked.txt", f.chunked)
+	f.mustLink(f.root, "site", f.site)
+	f.mustLink(f.root, "missing.txt", f.missing)
+
+	for _, n := range []format.Node{f.raw, chunk1, chunk2, f.chunked, f.index, f.site, f.root} {
+		f.bs.Put(n.Cid(), n.RawData())
+	}
+
+	return f
+}
+
+func (f *fixture) mustLink(n *merkledag.ProtoNode, name string, child format.Node) {
+	if err := n.AddNodeLink(name, child); err != nil {
+		panic(err)
+	}
+}
+
+func resource(c cid.Cid) *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       c.String(),
+		},
+	}
+}
+
+type OfflineTestSuite struct {
+	suite.Suite
+
+	ctx     context.Context
+	f       *fixture
+	offline *Offline
+}
+
+func (s *OfflineTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.f = newFixture()
+	s.offline = New(DefaultConfig(), s.f.bs, instr.New())
+}
+
+func (s *OfflineTestSuite) TestStat() {
+	cases := []struct {
+		c    cid.Cid
+		stat t.Stat
+	}{
+		{s.f.raw.Cid(), t.Stat{Type: t.FileType, Size: 5}},
+		{s.f.chunked.Cid(), t.Stat{Type: t.FileType, Size: 11}},
+		{s.f.site.Cid(), t.Stat{Type: t.DirectoryType, Size: uint64(len(s.f.site.RawData())) + 13}},
+	}
+
+	for _, c := range cases {
+		r := resource(c.c)
+
+		s.NoError(s.offline.Stat(s.ctx, r))
+		s.Equal(c.stat, r.Stat)
+	}
+}
+
+func (s *OfflineTestSuite) TestStatPartial() {
+	partial := merkledag.NewRawNode(make([]byte, DefaultConfig().PartialSize))
+	s.f.bs.Put(partial.Cid(), partial.RawData())
+
+	r := resource(partial.Cid())
+
+	s.NoError(s.offline.Stat(s.ctx, r))
+	s.Equal(t.PartialType, r.Type)
+}
+
+func (s *OfflineTestSuite) TestStatMissing() {
+	err := s.offline.Stat(s.ctx, resource(s.f.missing.Cid()))
+
+	s.ErrorIs(err, ErrNotFound)
+}
+
+func (s *OfflineTestSuite) TestStatInvalid() {
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       "invalid",
+		},
+	}
+
+	s.ErrorIs(s.offline.Stat(s.ctx, r), t.ErrInvalidResource)
+}
+
+func (s *OfflineTestSuite) TestLs() {
+	r := resource(s.f.root.Cid())
+	out := make(chan *t.AnnotatedResource, 10)
+
+	s.Require().NoError(s.offline.Ls(s.ctx, r, out))
+	close(out)
+
+	entries := map[string]*t.AnnotatedResource{}
+	for e := range out {
+		s.Equal(t.DirectorySource, e.Source)
+		s.Equal(r.Resource, e.Reference.Parent)
+
+		entries[e.Reference.Name] = e
+	}
+
+	s.Len(entries, 4)
+
+	s.Equal(s.f.raw.Cid().String(), entries["hello.txt"].ID)
+	s.Equal(t.Stat{Type: t.FileType, Size: 5}, entries["hello.txt"].Stat)
+	s.Equal(t.Stat{Type: t.FileType, Size: 11}, entries["chunked.txt"].Stat)
+	s.Equal(t.DirectoryType, entries["site"].Type)
+
+	// Entries missing from the blockstore have an undefined type.
+	s.Equal(t.Stat{}, entries["missing.txt"].Stat)
+}
+
+func (s *OfflineTestSuite) TestGetDAGCBOR() {
+	// {"link": CID(raw), "bytes": h'0102', "list": [1, "a"]}
+	data := []byte{0xa3}
+	data = append(data, 0x64, 'l', 'i', 'n', 'k', 0xd8, 0x2a, 0x58, byte(len(s.f.raw.Cid().Bytes())+1), 0x00)
+	data = append(data, s.f.raw.Cid().Bytes()...)
+	data = append(data, 0x65, 'b', 'y', 't', 'e', 's', 0x42, 0x01, 0x02)
+	data = append(data, 0x64, 'l', 'i', 's', 't', 0x82, 0x01, 0x61, 'a')
+
+	c, err := cid.Prefix{Version: 1, Codec: cid.DagCBOR, MhType: 0x12, MhLength: -1}.Sum(data)
+	s.Require().NoError(err)
+	s.f.bs.Put(c, data)
+
+	json, err := s.offline.GetDAG(s.ctx, resource(c))
+
+	s.NoError(err)
+	s.JSONEq(`{
+		"bytes": {"/": {"bytes": "AQI"}},
+		"link": {"/": "`+s.f.raw.Cid().String()+`"},
+		"list": [1, "a"]
+	}`, string(json))
+}
+
+func (s *OfflineTestSuite) TestGetDAGJSON() {
+	data := []byte(`{"a":1}`)
+
+	c, err := cid.Prefix{Version: 1, Codec: t.DagJSON, MhType: 0x12, MhLength: -1}.Sum(data)
+	s.Require().NoError(err)
+	s.f.bs.Put(c, data)
+
+	json, err := s.offline.GetDAG(s.ctx, resource(c))
+
+	s.NoError(err)
+	s.Equal(data, json)
+}
+
+func (s *OfflineTestSuite) TestGetDAGUnixFS() {
+	_, err := s.offline.GetDAG(s.ctx, resource(s.f.root.Cid()))
+
+	s.ErrorIs(err, t.ErrInvalidResource)
+}
+
+func (s *OfflineTestSuite) TestGatewayURL() {
+	r := resource(s.f.raw.Cid())
+	r.Reference = t.Reference{
+		Parent: resource(s.f.root.Cid()).Resource,
+		Name:   "hello.txt",
+	}
+
+	s.Equal("http://localhost:8082/ipfs/"+s.f.root.Cid().String()+"/hello.txt", s.offline.GatewayURL(r))
+}
+
+func TestOfflineTestSuite(t *testing.T) {
+	suite.Run(t, new(OfflineTestSuite))
+}
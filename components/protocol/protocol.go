@@ -3,6 +3,7 @@ package protocol
 
 import (
 	"context"
+	"errors"
 
 	t "github.com/ipfs-search/ipfs-search/types"
 )
@@ -12,4 +13,16 @@ type Protocol interface {
 	GatewayURL(*t.AnnotatedResource) string
 	Stat(context.Context, *t.AnnotatedResource) error
 	Ls(context.Context, *t.AnnotatedResource, chan<- *t.AnnotatedResource) error
+
+	// GetDAG returns the dag-json encoding of an IPLD document, with links encoded as {"/": "<cid>"}.
+	GetDAG(context.Context, *t.AnnotatedResource) ([]byte, error)
+}
+
+// ErrNotResolver is returned when names need to be resolved by a Protocol not implementing Resolver.
+var ErrNotResolver = errors.New("protocol does not resolve names")
+
+// Resolver is implemented by protocols resolving mutable names, e.g. IPNS names, to the immutable resource
+// they currently point to.
+type Resolver interface {
+	Resolve(context.Context, *t.AnnotatedResource) (*t.Resource, error)
 }
@@ -0,0 +1,21 @@
+package trustless
+
+import (
+	"github.com/c2h5oh/datasize"
+)
+
+// Config specifies the configuration for the trustless gateway protocol.
+type Config struct {
+	URL         string            // URL of a trustless gateway (to request blocks and CAR archives).
+	GatewayURL  string            // URL of the Gateway serving verified content to extractors.
+	PartialSize datasize.ByteSize // Filesize of items which are being considered partials (chunks).
+}
+
+// DefaultConfig returns the default configuration for the trustless gateway protocol.
+func DefaultConfig() *Config {
+	return &Config{
+		URL:         "http://localhost:8080",
+		GatewayURL:  "http://localhost:8083",
+		PartialSize: 262144,
+	}
+}
@@ -0,0 +1,28 @@
+package trustless
+
+import (
+	"context"
+
+	"github.com/ipfs-search/ipfs-search/components/protocol/offline"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// GetDAG returns the dag-json encoding of an IPLD document; dag-cbor documents are converted.
+func (p *Trustless) GetDAG(ctx context.Context, r *t.AnnotatedResource) ([]byte, error) {
+	ctx, span := p.Tracer.Start(ctx, "protocol.trustless.GetDAG")
+	defer span.End()
+
+	b, err := p.getBlock(ctx, r)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	data, err := offline.DAGJSON(b)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return data, err
+}
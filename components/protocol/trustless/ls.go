@@ -0,0 +1,62 @@
+package trustless
+
+import (
+	"context"
+
+	"github.com/ipfs-search/ipfs-search/components/car"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// Ls writes the entries of a directory to out. The directory is requested as a single archive, including the
+// sub-shards of sharded directories. Entries have an undefined type, unless the gateway included their blocks.
+func (p *Trustless) Ls(ctx context.Context, r *t.AnnotatedResource, out chan<- *t.AnnotatedResource) error {
+	ctx, span := p.Tracer.Start(ctx, "protocol.trustless.Ls")
+	defer span.End()
+
+	c, err := getCid(r)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	dag, err := p.getDAG(ctx, c)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	err = dag.Ls(c, func(l car.Link) error {
+		entry := &t.AnnotatedResource{
+			Resource: &t.Resource{
+				Protocol: t.IPFSProtocol,
+				ID:       l.Cid.String(),
+			},
+			Source: t.DirectorySource,
+			Reference: t.Reference{
+				Parent: r.Resource,
+				Name:   l.Name,
+			},
+		}
+
+		if n := dag.Get(l.Cid); n != nil {
+			entry.Stat = t.Stat{
+				Type: n.Type,
+				Size: n.Size,
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- entry:
+			return nil
+		}
+	})
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
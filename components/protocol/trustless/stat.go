@@ -0,0 +1,72 @@
+package trustless
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-search/ipfs-search/components/car"
+
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// getCid returns the CID of a resource.
+func getCid(r *t.AnnotatedResource) (cid.Cid, error) {
+	if r.Protocol != t.IPFSProtocol {
+		return cid.Undef, fmt.Errorf("unsupported protocol %s", r.Protocol)
+	}
+
+	c, err := cid.Decode(r.ID)
+	if err != nil {
+		return cid.Undef, fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+	}
+
+	return c, nil
+}
+
+// getBlock returns the verified block for a resource.
+func (p *Trustless) getBlock(ctx context.Context, r *t.AnnotatedResource) (*car.Block, error) {
+	c, err := getCid(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := p.Get(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &car.Block{Cid: c, Data: data}, nil
+}
+
+// Stat populates the Type and Size of a resource from its root block.
+func (p *Trustless) Stat(ctx context.Context, r *t.AnnotatedResource) error {
+	ctx, span := p.Tracer.Start(ctx, "protocol.trustless.Stat")
+	defer span.End()
+
+	b, err := p.getBlock(ctx, r)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	n, err := car.Decode(b)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+		span.RecordError(err)
+		return err
+	}
+
+	r.Stat = t.Stat{
+		Type: n.Type,
+		Size: n.Size,
+	}
+
+	// Override type for *unreferenced* partials, based on size
+	if r.Type == t.FileType && r.Size == uint64(p.config.PartialSize) && r.Reference.Parent == nil {
+		r.Stat.Type = t.PartialType
+	}
+
+	return nil
+}
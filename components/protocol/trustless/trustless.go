@@ -0,0 +1,176 @@
+// Package trustless implements the Protocol interface using only the trustless gateway spec: blocks and CAR
+// archives are requested from any (public) gateway and verified locally, rather than trusting a Kubo RPC API.
+// Ref: https://specs.ipfs.tech/http-gateways/trustless-gateway/
+package trustless
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-search/ipfs-search/components/car"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/components/protocol/offline"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const (
+	rawContentType = "application/vnd.ipld.raw"
+	carContentType = "application/vnd.ipld.car"
+
+	// maxBlockSize is the maximum size of blocks exchanged by IPFS nodes.
+	maxBlockSize = 2 << 20
+
+	// maxCARSize limits archives for (sharded) directories.
+	maxCARSize = 64 << 20
+)
+
+// Trustless implements the Protocol interface for a trustless gateway. It is concurrency-safe.
+// It is also a Blockstore, so that verified content can be served to extractors by an offline.Gateway.
+// Names can't be resolved, as IPNS records are not verified.
+type Trustless struct {
+	config *Config
+
+	url        *url.URL
+	gatewayURL *url.URL
+	client     *http.Client
+
+	*instr.Instrumentation
+}
+
+func mustParseURL(rawURL string) *url.URL {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		panic(fmt.Sprintf("could not parse URL, error: %v", err))
+	}
+
+	if !u.IsAbs() {
+		panic(fmt.Sprintf("URL is not absolute: %s", u))
+	}
+
+	return u
+}
+
+// New returns a new Trustless protocol.
+func New(config *Config, client *http.Client, instr *instr.Instrumentation) *Trustless {
+	return &Trustless{
+		config,
+		mustParseURL(config.URL),
+		mustParseURL(config.GatewayURL),
+		client,
+		instr,
+	}
+}
+
+// GatewayURL returns the URL to request a resource from the Gateway.
+func (p *Trustless) GatewayURL(r *t.AnnotatedResource) string {
+	url, err := p.gatewayURL.Parse(protocol.GatewayPath(r))
+	if err != nil {
+		panic(fmt.Sprintf("error generating GatewayURL: %v", err))
+	}
+
+	return url.String()
+}
+
+// request performs a request for c, accepting the given content type, and returns the response body.
+func (p *Trustless) request(ctx context.Context, c cid.Cid, accept string, query url.Values) (io.ReadCloser, error) {
+	u, err := p.url.Parse("/ipfs/" + c.String())
+	if err != nil {
+		panic(fmt.Sprintf("error generating URL: %v", err))
+	}
+
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", accept)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := statusError(resp); err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", c, err)
+	}
+
+	return resp.Body, nil
+}
+
+// statusError returns the error for a non-200 response.
+func statusError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return offline.ErrNotFound
+	case http.StatusBadRequest, http.StatusGone, http.StatusUnavailableForLegalReasons:
+		// Invalid CIDs, unsupported codecs and blocked content.
+		return fmt.Errorf("%w: %s", t.ErrInvalidResource, resp.Status)
+	default:
+		return fmt.Errorf("%w: %s", t.ErrUnexpectedResponse, resp.Status)
+	}
+}
+
+// Get returns the verified block for c, implementing offline.Blockstore.
+func (p *Trustless) Get(ctx context.Context, c cid.Cid) ([]byte, error) {
+	body, err := p.request(ctx, c, rawContentType, url.Values{"format": {"raw"}})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxBlockSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxBlockSize {
+		return nil, fmt.Errorf("%w: block %s exceeds %d bytes", t.ErrInvalidResource, c, maxBlockSize)
+	}
+
+	// Gateways ignoring the format return deserialized content, failing verification as well.
+	actual, err := c.Prefix().Sum(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", t.ErrInvalidResource, err)
+	}
+
+	if !actual.Equals(c) {
+		return nil, fmt.Errorf("%w: %s", car.ErrHashMismatch, c)
+	}
+
+	return data, nil
+}
+
+// Close implements offline.Blockstore; it's a no-op.
+func (p *Trustless) Close() error {
+	return nil
+}
+
+// getDAG returns the verified archive of the entity c: all blocks of a file or of a (sharded) directory, but not
+// of its entries. Blocks in the archive are verified by car.Load.
+func (p *Trustless) getDAG(ctx context.Context, c cid.Cid) (*car.DAG, error) {
+	body, err := p.request(ctx, c, carContentType, url.Values{"format": {"car"}, "dag-scope": {"entity"}})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return car.Load(io.LimitReader(body, maxCARSize), nil)
+}
+
+// Compile-time assurance that implementation satisfies interfaces.
+var (
+	_ protocol.Protocol  = &Trustless{}
+	_ offline.Blockstore = &Trustless{}
+)
@@ -0,0 +1,287 @@
+package trustless
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	format "github.com/ipfs/go-ipld-format"
+	merkledag "github.com/ipfs/go-merkledag"
+	unixfs "github.com/ipfs/go-unixfs"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/car"
+	"github.com/ipfs-search/ipfs-search/components/protocol/offline"
+
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+func resource(c cid.Cid) *t.AnnotatedResource {
+	return &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       c.String(),
+		},
+	}
+}
+
+type TrustlessTestSuite struct {
+	suite.Suite
+
+	ctx       context.Context
+	server    *httptest.Server
+	trustless *Trustless
+
+	// Blocks served by the gateway and, for archives, the blocks included after the root.
+	blocks   map[string][]byte
+	entities map[string][]format.Node
+	status   int
+	requests []*http.Request
+}
+
+func (s *TrustlessTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.blocks = make(map[string][]byte)
+	s.entities = make(map[string][]format.Node)
+	s.status = http.StatusOK
+	s.requests = nil
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	cfg := DefaultConfig()
+	cfg.URL = s.server.URL
+
+	s.trustless = New(cfg, s.server.Client(), instr.New())
+}
+
+func (s *TrustlessTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *TrustlessTestSuite) serve(w http.ResponseWriter, r *http.Request) {
+	s.requests = append(s.requests, r)
+
+	if s.status != http.StatusOK {
+		w.WriteHeader(s.status)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/ipfs/")
+	data, ok := s.blocks[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "raw":
+		w.Write(data)
+	case "car":
+		c, err := cid.Decode(id)
+		s.Require().NoError(err)
+
+		cw, err := car.NewWriter(w, []cid.Cid{c})
+		s.Require().NoError(err)
+		s.Require().NoError(cw.Put(c, data))
+
+		for _, n := range s.entities[id] {
+			s.Require().NoError(cw.Put(n.Cid(), n.RawData()))
+		}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (s *TrustlessTestSuite) add(nodes ...format.Node) {
+	for _, n := range nodes {
+		s.blocks[n.Cid().String()] = n.RawData()
+	}
+}
+
+func (s *TrustlessTestSuite) mustLink(n *merkledag.ProtoNode, name string, child format.Node) {
+	s.Require().NoError(n.AddNodeLink(name, child))
+}
+
+func (s *TrustlessTestSuite) ls(c cid.Cid) map[string]*t.AnnotatedResource {
+	r := resource(c)
+	out := make(chan *t.AnnotatedResource, 10)
+
+	s.Require().NoError(s.trustless.Ls(s.ctx, r, out))
+	close(out)
+
+	entries := map[string]*t.AnnotatedResource{}
+	for e := range out {
+		s.Equal(t.DirectorySource, e.Source)
+		s.Equal(r.Resource, e.Reference.Parent)
+
+		entries[e.Reference.Name] = e
+	}
+
+	return entries
+}
+
+func (s *TrustlessTestSuite) TestStat() {
+	raw := merkledag.NewRawNode([]byte("hello"))
+	file := merkledag.NodeWithData(unixfs.FilePBData([]byte("hello world"), 11))
+
+	dir := merkledag.NodeWithData(unixfs.FolderPBData())
+	s.mustLink(dir, "hello.txt", raw)
+
+	s.add(raw, file, dir)
+
+	cases := []struct {
+		c    cid.Cid
+		stat t.Stat
+	}{
+		{raw.Cid(), t.Stat{Type: t.FileType, Size: 5}},
+		{file.Cid(), t.Stat{Type: t.FileType, Size: 11}},
+		{dir.Cid(), t.Stat{Type: t.DirectoryType, Size: uint64(len(dir.RawData())) + 5}},
+	}
+
+	for _, c := range cases {
+		r := resource(c.c)
+
+		s.NoError(s.trustless.Stat(s.ctx, r))
+		s.Equal(c.stat, r.Stat)
+	}
+
+	req := s.requests[0]
+	s.Equal("/ipfs/"+raw.Cid().String(), req.URL.Path)
+	s.Equal("raw", req.URL.Query().Get("format"))
+	s.Equal(rawContentType, req.Header.Get("Accept"))
+}
+
+func (s *TrustlessTestSuite) TestStatPartial() {
+	partial := merkledag.NewRawNode(make([]byte, DefaultConfig().PartialSize))
+	s.add(partial)
+
+	r := resource(partial.Cid())
+
+	s.NoError(s.trustless.Stat(s.ctx, r))
+	s.Equal(t.PartialType, r.Type)
+}
+
+func (s *TrustlessTestSuite) TestStatHashMismatch() {
+	raw := merkledag.NewRawNode([]byte("hello"))
+	s.blocks[raw.Cid().String()] = []byte("<html>deserialized</html>")
+
+	err := s.trustless.Stat(s.ctx, resource(raw.Cid()))
+
+	s.ErrorIs(err, car.ErrHashMismatch)
+}
+
+func (s *TrustlessTestSuite) TestStatErrors() {
+	raw := merkledag.NewRawNode([]byte("hello"))
+
+	cases := map[int]error{
+		http.StatusNotFound:                   offline.ErrNotFound,
+		http.StatusGone:                       t.ErrInvalidResource,
+		http.StatusUnavailableForLegalReasons: t.ErrInvalidResource,
+		http.StatusGatewayTimeout:             t.ErrUnexpectedResponse,
+	}
+
+	for status, expected := range cases {
+		s.status = status
+
+		err := s.trustless.Stat(s.ctx, resource(raw.Cid()))
+		s.ErrorIs(err, expected, status)
+	}
+}
+
+func (s *TrustlessTestSuite) TestLs() {
+	raw := merkledag.NewRawNode([]byte("hello"))
+	missing := merkledag.NewRawNode([]byte("missing"))
+
+	dir := merkledag.NodeWithData(unixfs.FolderPBData())
+	s.mustLink(dir, "hello.txt", raw)
+	s.mustLink(dir, "missing.txt", missing)
+
+	s.add(dir)
+	s.entities[dir.Cid().String()] = []format.Node{raw}
+
+	entries := s.ls(dir.Cid())
+
+	s.Len(entries, 2)
+	s.Equal(raw.Cid().String(), entries["hello.txt"].ID)
+	s.Equal(t.Stat{Type: t.FileType, Size: 5}, entries["hello.txt"].Stat)
+
+	// Entries not included in the archive have an undefined type.
+	s.Equal(missing.Cid().String(), entries["missing.txt"].ID)
+	s.Equal(t.Stat{}, entries["missing.txt"].Stat)
+
+	req := s.requests[0]
+	s.Equal("car", req.URL.Query().Get("format"))
+	s.Equal("entity", req.URL.Query().Get("dag-scope"))
+	s.Equal(carContentType, req.Header.Get("Accept"))
+}
+
+func (s *TrustlessTestSuite) TestLsShardedDirectory() {
+	const fanout, murmur3 = 256, 0x22
+
+	b := merkledag.NewRawNode([]byte("b"))
+	c := merkledag.NewRawNode([]byte("c"))
+
+	shardData, err := unixfs.HAMTShardData([]byte{1}, fanout, murmur3)
+	s.Require().NoError(err)
+
+	subShard := merkledag.NodeWithData(shardData)
+	s.mustLink(subShard, "00c.txt", c)
+
+	shard := merkledag.NodeWithData(shardData)
+	s.mustLink(shard, "0Ab.txt", b)
+	s.mustLink(shard, "1F", subShard)
+
+	s.add(shard)
+	s.entities[shard.Cid().String()] = []format.Node{subShard}
+
+	entries := s.ls(shard.Cid())
+
+	s.Len(entries, 2)
+	s.Equal(b.Cid().String(), entries["b.txt"].ID)
+	s.Equal(c.Cid().String(), entries["c.txt"].ID)
+
+	// Archives without sub-shards are incomplete.
+	delete(s.entities, shard.Cid().String())
+
+	err = s.trustless.Ls(s.ctx, resource(shard.Cid()), make(chan *t.AnnotatedResource, 10))
+	s.ErrorIs(err, car.ErrMissing)
+}
+
+func (s *TrustlessTestSuite) TestGetDAG() {
+	data := []byte(`{"a":1}`)
+
+	c, err := cid.Prefix{Version: 1, Codec: t.DagJSON, MhType: 0x12, MhLength: -1}.Sum(data)
+	s.Require().NoError(err)
+	s.blocks[c.String()] = data
+
+	json, err := s.trustless.GetDAG(s.ctx, resource(c))
+
+	s.NoError(err)
+	s.Equal(data, json)
+}
+
+func (s *TrustlessTestSuite) TestGatewayURL() {
+	raw := merkledag.NewRawNode([]byte("hello"))
+
+	s.Equal("http://localhost:8083/ipfs/"+raw.Cid().String(), s.trustless.GatewayURL(resource(raw.Cid())))
+}
+
+func (s *TrustlessTestSuite) TestGateway() {
+	raw := merkledag.NewRawNode([]byte("hello"))
+	s.add(raw)
+
+	// Verified content is served to extractors through an offline Gateway.
+	rec := httptest.NewRecorder()
+	offline.NewGateway(s.trustless).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ipfs/"+raw.Cid().String(), nil))
+
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal("hello", rec.Body.String())
+}
+
+func TestTrustlessTestSuite(t *testing.T) {
+	suite.Run(t, new(TrustlessTestSuite))
+}
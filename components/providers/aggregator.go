@@ -0,0 +1,207 @@
+// Package providers records which peers provide content: announcements sniffed from the DHT are aggregated per
+// resource and published to a queue, from which they are merged into an index of providers.
+package providers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// publishPriority is the queue priority of aggregated announcements.
+const publishPriority uint8 = 1
+
+// Announcements are the aggregated announcements of a resource by one or more peers.
+type Announcements struct {
+	*t.Resource
+	Providers []indexTypes.ProviderRecord
+}
+
+// Aggregator aggregates announcements per resource and peer, periodically publishing them to a queue. This
+// limits the messages for resources announced by many peers, or repeatedly by the same peer.
+//
+// Aggregated announcements are published in the background, so that announcements keep being received while
+// publishing. While publishing, announcements of resources which are not yet pending are dropped once
+// Config.MaxPending resources are pending. Pending announcements are kept in memory; they are lost on exit.
+type Aggregator struct {
+	cfg      *Config
+	queue    queue.Publisher
+	counters aggregatorCounters
+
+	mu      sync.Mutex
+	pending map[t.Resource]map[string]*indexTypes.ProviderRecord
+
+	*instr.Instrumentation
+}
+
+// NewAggregator returns a new Aggregator, publishing aggregated announcements to queue.
+func NewAggregator(queue queue.Publisher, cfg *Config, instr *instr.Instrumentation) *Aggregator {
+	if queue == nil {
+		panic("providers.NewAggregator queue cannot be nil.")
+	}
+
+	if cfg == nil {
+		panic("providers.NewAggregator Config cannot be nil.")
+	}
+
+	return &Aggregator{
+		cfg:             cfg,
+		queue:           queue,
+		pending:         make(map[t.Resource]map[string]*indexTypes.ProviderRecord),
+		Instrumentation: instr,
+	}
+}
+
+// Stats returns a snapshot of the counters.
+func (a *Aggregator) Stats() AggregatorStats {
+	return AggregatorStats{
+		Announcements: a.counters.announcements.Load(),
+		Published:     a.counters.published.Load(),
+		Failed:        a.counters.failed.Load(),
+		Dropped:       a.counters.dropped.Load(),
+	}
+}
+
+// add records an announcement and returns the number of pending resources. When limit resources are pending,
+// announcements of other resources are dropped; 0 means no limit.
+func (a *Aggregator) add(p *t.Provider, limit int) int {
+	a.counters.announcements.Add(1)
+
+	// Announcement dates are indexed without fractional seconds.
+	date := p.Date.UTC().Truncate(time.Second)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	peers, ok := a.pending[*p.Resource]
+	if !ok {
+		if limit > 0 && len(a.pending) >= limit {
+			a.counters.dropped.Add(1)
+			return len(a.pending)
+		}
+
+		peers = make(map[string]*indexTypes.ProviderRecord)
+		a.pending[*p.Resource] = peers
+	}
+
+	r, ok := peers[p.Provider]
+	if !ok {
+		peers[p.Provider] = &indexTypes.ProviderRecord{
+			Peer:           p.Provider,
+			FirstAnnounced: date,
+			LastAnnounced:  date,
+			Count:          1,
+		}
+
+		return len(a.pending)
+	}
+
+	if date.Before(r.FirstAnnounced) {
+		r.FirstAnnounced = date
+	}
+
+	if date.After(r.LastAnnounced) {
+		r.LastAnnounced = date
+	}
+
+	r.Count++
+
+	return len(a.pending)
+}
+
+// swap clears and returns pending announcements.
+func (a *Aggregator) swap() map[t.Resource]map[string]*indexTypes.ProviderRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pending := a.pending
+	a.pending = make(map[t.Resource]map[string]*indexTypes.ProviderRecord)
+
+	return pending
+}
+
+// flush publishes and clears pending announcements.
+func (a *Aggregator) flush(ctx context.Context) error {
+	return a.publish(ctx, a.swap())
+}
+
+// publish publishes announcements.
+func (a *Aggregator) publish(ctx context.Context, pending map[t.Resource]map[string]*indexTypes.ProviderRecord) error {
+	for resource, peers := range pending {
+		resource := resource
+
+		announcements := &Announcements{
+			Resource:  &resource,
+			Providers: make([]indexTypes.ProviderRecord, 0, len(peers)),
+		}
+
+		for _, r := range peers {
+			announcements.Providers = append(announcements.Providers, *r)
+		}
+
+		if err := a.queue.Publish(ctx, announcements, publishPriority); err != nil {
+			a.counters.failed.Add(1)
+			return err
+		}
+
+		a.counters.published.Add(1)
+	}
+
+	return nil
+}
+
+// Aggregate aggregates announcements from in until ctx is done, publishing them every Config.FlushInterval or
+// when Config.MaxPending resources are pending. At most one flush is in flight at a time. Publishing errors
+// are returned.
+func (a *Aggregator) Aggregate(ctx context.Context, in <-chan t.Provider) error {
+	ticker := time.NewTicker(a.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	// flushed receives the result of the flush in flight; nil when no flush is in flight.
+	var flushed chan error
+
+	flush := func() {
+		if flushed != nil {
+			return
+		}
+
+		flushed = make(chan error, 1)
+
+		go func(pending map[t.Resource]map[string]*indexTypes.ProviderRecord, done chan<- error) {
+			done <- a.publish(ctx, pending)
+		}(a.swap(), flushed)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if flushed != nil {
+				<-flushed
+			}
+
+			return ctx.Err()
+		case err := <-flushed:
+			flushed = nil
+
+			if err != nil {
+				return err
+			}
+		case <-ticker.C:
+			flush()
+		case p := <-in:
+			limit := 0
+			if flushed != nil {
+				limit = a.cfg.MaxPending
+			}
+
+			if a.add(&p, limit) >= a.cfg.MaxPending {
+				flush()
+			}
+		}
+	}
+}
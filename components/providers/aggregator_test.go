@@ -0,0 +1,193 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/queue"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+const (
+	testCID   = "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+	testPeer  = "QmeTtFXm42Jb2todcKR538j6qHYxXt6suUzpF3rtT9FPSd"
+	otherPeer = "QmNnooDu7bfjPFoTZYxMNLWUQJyrVwtbZg5gBMjTezGAJN"
+)
+
+type AggregatorTestSuite struct {
+	suite.Suite
+
+	ctx   context.Context
+	now   time.Time
+	queue *queue.Mock
+	cfg   *Config
+	a     *Aggregator
+}
+
+func (s *AggregatorTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.now = time.Now().Truncate(time.Second).UTC()
+	s.queue = &queue.Mock{}
+	s.cfg = DefaultConfig()
+	s.a = NewAggregator(s.queue, s.cfg, instr.New())
+}
+
+func resource(id string) *t.Resource {
+	return &t.Resource{
+		Protocol: t.IPFSProtocol,
+		ID:       id,
+	}
+}
+
+func announcement(peer string, date time.Time) t.Provider {
+	return t.Provider{
+		Resource: resource(testCID),
+		Date:     date,
+		Provider: peer,
+	}
+}
+
+func (s *AggregatorTestSuite) TestFlushAggregates() {
+	earlier := s.now.Add(-time.Minute)
+
+	for _, p := range []t.Provider{
+		announcement(testPeer, s.now),
+		announcement(testPeer, earlier),
+		announcement(otherPeer, s.now),
+	} {
+		p := p
+		s.a.add(&p, 0)
+	}
+
+	s.queue.On("Publish", mock.Anything, mock.MatchedBy(func(msg interface{}) bool {
+		a := msg.(*Announcements)
+		s.Equal(resource(testCID), a.Resource)
+		s.ElementsMatch([]indexTypes.ProviderRecord{
+			{Peer: testPeer, FirstAnnounced: earlier, LastAnnounced: s.now, Count: 2},
+			{Peer: otherPeer, FirstAnnounced: s.now, LastAnnounced: s.now, Count: 1},
+		}, a.Providers)
+		return true
+	}), publishPriority).Return(nil).Once()
+
+	s.NoError(s.a.flush(s.ctx))
+	s.queue.AssertExpectations(s.T())
+
+	// Flushed announcements are no longer pending.
+	s.NoError(s.a.flush(s.ctx))
+	s.queue.AssertNumberOfCalls(s.T(), "Publish", 1)
+
+	s.Equal(AggregatorStats{Announcements: 3, Published: 1}, s.a.Stats())
+}
+
+// TestFlushDateFormat tests whether announcement dates are published without fractional seconds, as required
+// by the date_time_no_millis format of the providers index.
+func (s *AggregatorTestSuite) TestFlushDateFormat() {
+	date := time.Date(2024, 1, 2, 3, 4, 5, 678901234, time.FixedZone("CET", 3600))
+	p := announcement(testPeer, date)
+	s.a.add(&p, 0)
+
+	s.queue.On("Publish", mock.Anything, mock.MatchedBy(func(msg interface{}) bool {
+		b, err := json.Marshal(msg.(*Announcements).Providers)
+		s.NoError(err)
+		s.JSONEq(`[{
+			"peer": "`+testPeer+`",
+			"first-announced": "2024-01-02T02:04:05Z",
+			"last-announced": "2024-01-02T02:04:05Z",
+			"count": 1
+		}]`, string(b))
+		return true
+	}), publishPriority).Return(nil).Once()
+
+	s.NoError(s.a.flush(s.ctx))
+	s.queue.AssertExpectations(s.T())
+}
+
+func (s *AggregatorTestSuite) TestFlushError() {
+	p := announcement(testPeer, s.now)
+	s.a.add(&p, 0)
+
+	err := errors.New("publish failed")
+	s.queue.On("Publish", mock.Anything, mock.Anything, publishPriority).Return(err).Once()
+
+	s.ErrorIs(s.a.flush(s.ctx), err)
+	s.Equal(int64(1), s.a.Stats().Failed)
+}
+
+// TestAggregateMaxPending tests whether announcements are published early when too many resources are pending.
+func (s *AggregatorTestSuite) TestAggregateMaxPending() {
+	s.cfg.FlushInterval = time.Hour
+	s.cfg.MaxPending = 1
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	s.queue.On("Publish", mock.Anything, mock.AnythingOfType("*providers.Announcements"), publishPriority).
+		Return(nil).
+		Run(func(mock.Arguments) { cancel() }).
+		Once()
+
+	in := make(chan t.Provider, 1)
+	in <- announcement(testPeer, s.now)
+
+	s.ErrorIs(s.a.Aggregate(ctx, in), context.Canceled)
+	s.queue.AssertExpectations(s.T())
+}
+
+// TestAggregateWhilePublishing tests whether announcements are received while publishing, dropping those of
+// resources which are not yet pending once too many are.
+func (s *AggregatorTestSuite) TestAggregateWhilePublishing() {
+	s.cfg.FlushInterval = time.Hour
+	s.cfg.MaxPending = 1
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	publishing := make(chan struct{}, 3)
+	release := make(chan struct{})
+
+	s.queue.On("Publish", mock.Anything, mock.AnythingOfType("*providers.Announcements"), publishPriority).
+		Return(nil).
+		Run(func(mock.Arguments) {
+			select {
+			case publishing <- struct{}{}:
+			default:
+			}
+			<-release
+		})
+
+	in := make(chan t.Provider)
+	errc := make(chan error)
+
+	go func() { errc <- s.a.Aggregate(ctx, in) }()
+
+	in <- announcement(testPeer, s.now)
+	<-publishing
+
+	// Not blocked by the publishing flush.
+	for _, id := range []string{"bafkqaaa", "bafkqaab"} {
+		p := announcement(testPeer, s.now)
+		p.Resource = resource(id)
+		in <- p
+	}
+
+	close(release)
+	cancel()
+
+	s.ErrorIs(<-errc, context.Canceled)
+
+	stats := s.a.Stats()
+	s.Equal(int64(3), stats.Announcements)
+	s.Equal(int64(1), stats.Dropped)
+}
+
+func TestAggregatorTestSuite(t *testing.T) {
+	suite.Run(t, new(AggregatorTestSuite))
+}
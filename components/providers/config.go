@@ -0,0 +1,25 @@
+package providers
+
+import (
+	"time"
+)
+
+// Config configures aggregating and indexing of provider announcements.
+type Config struct {
+	FlushInterval time.Duration // Publish aggregated announcements this often.
+	MaxPending    int           // Publish early when announcements for this many resources are pending; drop others while publishing.
+	Retention     time.Duration // Drop peers which have not announced content for this long.
+	MaxProviders  int           // Keep at most this many, most recently announcing, peers per resource.
+	SweepInterval time.Duration // Delete documents of which all peers expired this often, 0 to only expire on announcements.
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		FlushInterval: time.Minute,
+		MaxPending:    100000,
+		Retention:     30 * 24 * time.Hour,
+		MaxProviders:  100,
+		SweepInterval: time.Hour,
+	}
+}
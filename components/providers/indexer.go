@@ -0,0 +1,230 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+// lockStripes is the number of locks serializing merges of documents in indexes without atomic merges.
+const lockStripes = 256
+
+// lastAnnouncedField is the field holding the last announcement of a document.
+const lastAnnouncedField = "last-announced"
+
+// Indexer merges aggregated announcements into an index of providers, keyed by CID.
+type Indexer struct {
+	cfg      *Config
+	index    index.Index
+	counters indexerCounters
+	locks    [lockStripes]sync.Mutex
+
+	*instr.Instrumentation
+}
+
+// NewIndexer returns a new Indexer, writing to idx.
+func NewIndexer(idx index.Index, cfg *Config, instr *instr.Instrumentation) *Indexer {
+	if idx == nil {
+		panic("providers.NewIndexer index cannot be nil.")
+	}
+
+	if cfg == nil {
+		panic("providers.NewIndexer Config cannot be nil.")
+	}
+
+	return &Indexer{
+		cfg:             cfg,
+		index:           idx,
+		Instrumentation: instr,
+	}
+}
+
+// Stats returns a snapshot of the counters.
+func (i *Indexer) Stats() IndexerStats {
+	return IndexerStats{
+		Indexed: i.counters.indexed.Load(),
+		Expired: i.counters.expired.Load(),
+		Deleted: i.counters.deleted.Load(),
+		Swept:   i.counters.swept.Load(),
+	}
+}
+
+// Index merges announcements into the document of their resource. Peers which haven't announced within
+// Config.Retention, or beyond the Config.MaxProviders most recently announcing peers, are dropped; documents
+// without remaining peers are deleted.
+//
+// Indexes implementing index.ProvidersMerger merge atomically. Otherwise, the document is read, merged and
+// written while holding a lock for the resource; this only prevents lost updates within this process.
+func (i *Indexer) Index(ctx context.Context, a *Announcements) error {
+	ctx, span := i.Tracer.Start(ctx, "providers.Index")
+	defer span.End()
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	err := index.MergeProviders(ctx, i.index, a.ID, a.Providers, now.Add(-i.cfg.Retention), i.cfg.MaxProviders)
+	if errors.Is(err, index.ErrNotSupported) {
+		err = i.mergeStored(ctx, a, now)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	i.counters.indexed.Add(1)
+
+	return nil
+}
+
+// lock returns the lock for merging the document with id.
+func (i *Indexer) lock(id string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+
+	return &i.locks[h.Sum32()%lockStripes]
+}
+
+// mergeStored merges announcements into the stored document of their resource.
+func (i *Indexer) mergeStored(ctx context.Context, a *Announcements, now time.Time) error {
+	l := i.lock(a.ID)
+	l.Lock()
+	defer l.Unlock()
+
+	doc := new(indexTypes.Providers)
+
+	found, err := i.index.Get(ctx, a.ID, doc)
+	if err != nil {
+		return err
+	}
+
+	before := len(doc.Providers) + len(a.Providers)
+	i.merge(doc, a.Providers, now)
+	i.counters.expired.Add(int64(before - len(doc.Providers)))
+
+	switch {
+	case len(doc.Providers) == 0 && found:
+		i.counters.deleted.Add(1)
+		return i.index.Delete(ctx, a.ID)
+	case len(doc.Providers) == 0:
+		// All announcements already expired.
+		return nil
+	case found:
+		return i.index.Update(ctx, a.ID, doc)
+	default:
+		return i.index.Index(ctx, a.ID, doc)
+	}
+}
+
+// Work deletes documents of which all peers exceeded Config.Retention every Config.SweepInterval, until ctx is
+// done; expired peers of other documents are dropped when their resource is announced again. Returns
+// index.ErrNotSupported right away when the index cannot delete documents by date.
+func (i *Indexer) Work(ctx context.Context) error {
+	if _, ok := i.index.(index.Expirer); !ok {
+		return index.ErrNotSupported
+	}
+
+	if i.cfg.SweepInterval == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ticker := time.NewTicker(i.cfg.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := i.sweep(ctx); err != nil {
+				log.Printf("Error deleting expired providers from %s: %s", i.index, err)
+			}
+		}
+	}
+}
+
+// sweep deletes documents of which all peers exceeded Config.Retention.
+func (i *Indexer) sweep(ctx context.Context) error {
+	ctx, span := i.Tracer.Start(ctx, "providers.sweep")
+	defer span.End()
+
+	cutoff := time.Now().UTC().Truncate(time.Second).Add(-i.cfg.Retention)
+
+	deleted, err := index.DeleteBefore(ctx, i.index, lastAnnouncedField, cutoff)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	i.counters.swept.Add(deleted)
+
+	return nil
+}
+
+// merge adds records to doc, applying retention and updating totals.
+func (i *Indexer) merge(doc *indexTypes.Providers, records []indexTypes.ProviderRecord, now time.Time) {
+	peers := make(map[string]*indexTypes.ProviderRecord, len(doc.Providers)+len(records))
+
+	for _, r := range append(doc.Providers, records...) {
+		r := r
+
+		existing, ok := peers[r.Peer]
+		if !ok {
+			peers[r.Peer] = &r
+			continue
+		}
+
+		if r.FirstAnnounced.Before(existing.FirstAnnounced) {
+			existing.FirstAnnounced = r.FirstAnnounced
+		}
+
+		if r.LastAnnounced.After(existing.LastAnnounced) {
+			existing.LastAnnounced = r.LastAnnounced
+		}
+
+		existing.Count += r.Count
+	}
+
+	for _, r := range records {
+		if r.LastAnnounced.After(doc.LastAnnounced) {
+			doc.LastAnnounced = r.LastAnnounced
+		}
+	}
+
+	cutoff := now.Add(-i.cfg.Retention)
+	doc.Providers = doc.Providers[:0]
+
+	for _, r := range peers {
+		if r.LastAnnounced.After(cutoff) {
+			doc.Providers = append(doc.Providers, *r)
+		}
+	}
+
+	sort.Slice(doc.Providers, func(a, b int) bool {
+		return doc.Providers[a].LastAnnounced.After(doc.Providers[b].LastAnnounced)
+	})
+
+	if len(doc.Providers) > i.cfg.MaxProviders {
+		doc.Providers = doc.Providers[:i.cfg.MaxProviders]
+	}
+
+	doc.ProviderCount = len(doc.Providers)
+	doc.Count = 0
+	doc.FirstAnnounced = time.Time{}
+
+	for _, r := range doc.Providers {
+		doc.Count += r.Count
+
+		if doc.FirstAnnounced.IsZero() || r.FirstAnnounced.Before(doc.FirstAnnounced) {
+			doc.FirstAnnounced = r.FirstAnnounced
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package providers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/instr"
+)
+
+type IndexerTestSuite struct {
+	suite.Suite
+
+	ctx   context.Context
+	now   time.Time
+	index *index.Mock
+	cfg   *Config
+	i     *Indexer
+}
+
+// isIndexDate returns whether t serializes as date_time_no_millis: UTC without fractional seconds.
+func isIndexDate(t time.Time) bool {
+	return t.Location() == time.UTC && t.Equal(t.Truncate(time.Second))
+}
+
+func (s *IndexerTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.now = time.Now().Truncate(time.Second).UTC()
+	s.index = &index.Mock{}
+	s.cfg = DefaultConfig()
+	s.i = NewIndexer(s.index, s.cfg, instr.New())
+}
+
+// expectGet makes Get return doc, or not found when doc is nil.
+func (s *IndexerTestSuite) expectGet(doc *indexTypes.Providers) {
+	s.index.On("Get", mock.Anything, testCID, mock.AnythingOfType("*types.Providers"), []string(nil)).
+		Run(func(args mock.Arguments) {
+			if doc != nil {
+				*args.Get(2).(*indexTypes.Providers) = *doc
+			}
+		}).
+		Return(doc != nil, nil).
+		Once()
+}
+
+func (s *IndexerTestSuite) announcements(records ...indexTypes.ProviderRecord) *Announcements {
+	return &Announcements{
+		Resource:  resource(testCID),
+		Providers: records,
+	}
+}
+
+func (s *IndexerTestSuite) TestIndexNew() {
+	s.expectGet(nil)
+
+	record := indexTypes.ProviderRecord{Peer: testPeer, FirstAnnounced: s.now, LastAnnounced: s.now, Count: 2}
+
+	s.index.On("Index", mock.Anything, testCID, &indexTypes.Providers{
+		Providers:      []indexTypes.ProviderRecord{record},
+		ProviderCount:  1,
+		Count:          2,
+		FirstAnnounced: s.now,
+		LastAnnounced:  s.now,
+	}).Return(nil).Once()
+
+	s.NoError(s.i.Index(s.ctx, s.announcements(record)))
+	s.index.AssertExpectations(s.T())
+}
+
+func (s *IndexerTestSuite) TestIndexMerge() {
+	first := s.now.Add(-time.Hour)
+
+	s.expectGet(&indexTypes.Providers{
+		Providers: []indexTypes.ProviderRecord{
+			{Peer: testPeer, FirstAnnounced: first, LastAnnounced: first, Count: 3},
+		},
+		ProviderCount:  1,
+		Count:          3,
+		FirstAnnounced: first,
+		LastAnnounced:  first,
+	})
+
+	s.index.On("Update", mock.Anything, testCID, &indexTypes.Providers{
+		Providers: []indexTypes.ProviderRecord{
+			{Peer: otherPeer, FirstAnnounced: s.now, LastAnnounced: s.now, Count: 1},
+			{Peer: testPeer, FirstAnnounced: first, LastAnnounced: s.now.Add(-time.Second), Count: 4},
+		},
+		ProviderCount:  2,
+		Count:          5,
+		FirstAnnounced: first,
+		LastAnnounced:  s.now,
+	}).Return(nil).Once()
+
+	s.NoError(s.i.Index(s.ctx, s.announcements(
+		indexTypes.ProviderRecord{Peer: testPeer, FirstAnnounced: s.now.Add(-time.Second), LastAnnounced: s.now.Add(-time.Second), Count: 1},
+		indexTypes.ProviderRecord{Peer: otherPeer, FirstAnnounced: s.now, LastAnnounced: s.now, Count: 1},
+	)))
+	s.index.AssertExpectations(s.T())
+}
+
+// TestIndexMaxProviders tests whether only the most recently announcing peers are kept.
+func (s *IndexerTestSuite) TestIndexMaxProviders() {
+	s.cfg.MaxProviders = 1
+	s.expectGet(nil)
+
+	recent := indexTypes.ProviderRecord{Peer: otherPeer, FirstAnnounced: s.now, LastAnnounced: s.now, Count: 1}
+
+	s.index.On("Index", mock.Anything, testCID, &indexTypes.Providers{
+		Providers:      []indexTypes.ProviderRecord{recent},
+		ProviderCount:  1,
+		Count:          1,
+		FirstAnnounced: s.now,
+		LastAnnounced:  s.now,
+	}).Return(nil).Once()
+
+	s.NoError(s.i.Index(s.ctx, s.announcements(
+		indexTypes.ProviderRecord{Peer: testPeer, FirstAnnounced: s.now.Add(-time.Minute), LastAnnounced: s.now.Add(-time.Minute), Count: 1},
+		recent,
+	)))
+	s.index.AssertExpectations(s.T())
+	s.Equal(int64(1), s.i.Stats().Expired)
+}
+
+// TestIndexRetention tests whether documents are deleted when all of their peers exceeded retention.
+func (s *IndexerTestSuite) TestIndexRetention() {
+	expired := s.now.Add(-2 * s.cfg.Retention)
+
+	s.expectGet(&indexTypes.Providers{
+		Providers: []indexTypes.ProviderRecord{
+			{Peer: testPeer, FirstAnnounced: expired, LastAnnounced: expired, Count: 1},
+		},
+	})
+
+	s.index.On("Delete", mock.Anything, testCID).Return(nil).Once()
+
+	s.NoError(s.i.Index(s.ctx, s.announcements(
+		indexTypes.ProviderRecord{Peer: otherPeer, FirstAnnounced: expired, LastAnnounced: expired, Count: 1},
+	)))
+	s.index.AssertExpectations(s.T())
+
+	s.Equal(IndexerStats{Indexed: 1, Expired: 2, Deleted: 1}, s.i.Stats())
+}
+
+// TestIndexMergeProviders tests whether announcements are merged atomically by indexes supporting it.
+func (s *IndexerTestSuite) TestIndexMergeProviders() {
+	idx := &index.ProvidersMock{}
+	i := NewIndexer(idx, s.cfg, instr.New())
+
+	record := indexTypes.ProviderRecord{Peer: testPeer, FirstAnnounced: s.now, LastAnnounced: s.now, Count: 2}
+	cutoff := mock.MatchedBy(func(t time.Time) bool {
+		return isIndexDate(t) && !t.After(time.Now().Add(-s.cfg.Retention)) &&
+			t.After(s.now.Add(-s.cfg.Retention-time.Minute))
+	})
+
+	idx.On("MergeProviders", mock.Anything, testCID, []indexTypes.ProviderRecord{record}, cutoff, s.cfg.MaxProviders).
+		Return(nil).Once()
+
+	s.NoError(i.Index(s.ctx, s.announcements(record)))
+	idx.AssertExpectations(s.T())
+
+	s.Equal(IndexerStats{Indexed: 1}, i.Stats())
+}
+
+// TestSweep tests whether documents of which all peers exceeded retention are deleted.
+func (s *IndexerTestSuite) TestSweep() {
+	idx := &index.ProvidersMock{}
+	i := NewIndexer(idx, s.cfg, instr.New())
+
+	cutoff := mock.MatchedBy(func(t time.Time) bool {
+		return isIndexDate(t) && !t.After(time.Now().Add(-s.cfg.Retention)) &&
+			t.After(s.now.Add(-s.cfg.Retention-time.Minute))
+	})
+
+	idx.On("DeleteBefore", mock.Anything, "last-announced", cutoff).Return(int64(3), nil).Once()
+
+	s.NoError(i.sweep(s.ctx))
+	idx.AssertExpectations(s.T())
+
+	s.Equal(int64(3), i.Stats().Swept)
+}
+
+// TestWorkNotSupported tests whether Work returns right away for indexes which cannot delete by date.
+func (s *IndexerTestSuite) TestWorkNotSupported() {
+	s.ErrorIs(s.i.Work(s.ctx), index.ErrNotSupported)
+}
+
+func TestIndexerTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexerTestSuite))
+}
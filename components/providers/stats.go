@@ -0,0 +1,37 @@
+package providers
+
+import (
+	"sync/atomic"
+)
+
+// aggregatorCounters tracks aggregating of announcements.
+type aggregatorCounters struct {
+	announcements atomic.Int64
+	published     atomic.Int64
+	failed        atomic.Int64
+	dropped       atomic.Int64
+}
+
+// AggregatorStats is a snapshot of the counters of an Aggregator.
+type AggregatorStats struct {
+	Announcements int64 // Announcements received.
+	Published     int64 // Aggregated announcements of resources published.
+	Failed        int64 // Aggregated announcements which could not be published.
+	Dropped       int64 // Announcements dropped as too many resources were pending while publishing.
+}
+
+// indexerCounters tracks indexing of announcements.
+type indexerCounters struct {
+	indexed atomic.Int64
+	expired atomic.Int64
+	deleted atomic.Int64
+	swept   atomic.Int64
+}
+
+// IndexerStats is a snapshot of the counters of an Indexer.
+type IndexerStats struct {
+	Indexed int64 // Aggregated announcements merged into the index.
+	Expired int64 // Peers dropped for exceeding retention or the maximum number of providers; not counted for atomic merges.
+	Deleted int64 // Documents deleted as all of their peers expired; not counted for atomic merges.
+	Swept   int64 // Documents deleted by periodic sweeps, as all of their peers expired.
+}
@@ -0,0 +1,27 @@
+package site
+
+import (
+	"time"
+)
+
+// Config configures site indexing.
+type Config struct {
+	Interval       time.Duration // Index queued sites after this time.
+	MaxPending     int           // Drop new sites when this many are pending.
+	MaxPages       int           // Fetch at most this many HTML pages per site.
+	MaxPageSize    uint64        // Skip pages larger than this many bytes.
+	RequestTimeout time.Duration // Timeout for fetching a page.
+	MaxAttempts    int           // Give up on sites with pages not yet indexed after this many attempts.
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Interval:       time.Minute,
+		MaxPending:     10000,
+		MaxPages:       256,
+		MaxPageSize:    1024 * 1024,
+		RequestTimeout: 30 * time.Second,
+		MaxAttempts:    5,
+	}
+}
@@ -0,0 +1,357 @@
+// Package site indexes static websites: the titles of their HTML pages, links between pages and
+// the PageRank of pages within the site.
+package site
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+const debug bool = false
+
+// ClassType is the type of directory classes which are indexed as sites.
+const ClassType = "website"
+
+type pendingSite struct {
+	links    indexTypes.Links
+	attempts int
+}
+
+// Indexer indexes sites in the background. Add() queues a site directory with its links; Work()
+// periodically fetches the HTML pages in the root of queued sites and updates the Site of their
+// documents in the files index.
+//
+// As pages are generally crawled after their directory, sites with pages which are not yet in the
+// files index are retried in the next interval, up to MaxAttempts; after that, only pages which
+// have been indexed are updated. Pending sites are kept in memory; they are lost on exit.
+type Indexer struct {
+	cfg      *Config
+	files    index.Index
+	getter   utils.HTTPBodyGetter
+	protocol protocol.Protocol
+	counters counters
+
+	mu      sync.Mutex
+	pending map[string]*pendingSite
+
+	*instr.Instrumentation
+}
+
+// New returns a new Indexer, fetching pages through the gateway of protocol and writing to files.
+// Sites are only indexed when Work() is running.
+func New(files index.Index, getter utils.HTTPBodyGetter, protocol protocol.Protocol, cfg *Config, instr *instr.Instrumentation) *Indexer {
+	if files == nil {
+		panic("site.New files cannot be nil.")
+	}
+
+	if cfg == nil {
+		panic("site.New Config cannot be nil.")
+	}
+
+	return &Indexer{
+		cfg:             cfg,
+		files:           files,
+		getter:          getter,
+		protocol:        protocol,
+		pending:         make(map[string]*pendingSite),
+		Instrumentation: instr,
+	}
+}
+
+// String returns a name for the indexer, for convenient logging.
+func (i *Indexer) String() string {
+	return fmt.Sprintf("sites in '%s'", i.files)
+}
+
+// Stats returns a snapshot of the counters.
+func (i *Indexer) Stats() Stats {
+	return Stats{
+		Enqueued: i.counters.enqueued.Load(),
+		Dropped:  i.counters.dropped.Load(),
+		Indexed:  i.counters.indexed.Load(),
+		Retried:  i.counters.retried.Load(),
+		Failed:   i.counters.failed.Load(),
+	}
+}
+
+// Add queues the site in directory dir with links for indexing.
+func (i *Indexer) Add(dir string, links indexTypes.Links) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.pending[dir]; ok {
+		return
+	}
+
+	if len(i.pending) >= i.cfg.MaxPending {
+		i.counters.dropped.Add(1)
+		return
+	}
+
+	i.pending[dir] = &pendingSite{links: links}
+	i.counters.enqueued.Add(1)
+}
+
+// requeue queues a pending site again, unless it has been added since.
+func (i *Indexer) requeue(dir string, p *pendingSite) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.pending[dir]; !ok {
+		i.pending[dir] = p
+	}
+}
+
+// Work indexes pending sites every interval, until the context is closed.
+func (i *Indexer) Work(ctx context.Context) error {
+	ticker := time.NewTicker(i.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			i.indexPending(ctx)
+		}
+	}
+}
+
+// Flush indexes pending sites right away, e.g. before shutting down.
+func (i *Indexer) Flush(ctx context.Context) {
+	i.indexPending(ctx)
+}
+
+// indexPending indexes all pending sites.
+func (i *Indexer) indexPending(ctx context.Context) {
+	i.mu.Lock()
+	pending := i.pending
+	i.pending = make(map[string]*pendingSite, len(pending))
+	i.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	ctx, span := i.Tracer.Start(ctx, "site.indexPending")
+	defer span.End()
+
+	for dir, p := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+
+		pages := i.pages(p.links)
+
+		indexed, err := i.indexed(ctx, pages)
+		if err != nil {
+			i.counters.failed.Add(1)
+			log.Printf("site %s: error getting pages of %s: %s", i, dir, err)
+			continue
+		}
+
+		p.attempts++
+
+		if len(indexed) < len(pages) && p.attempts < i.cfg.MaxAttempts {
+			i.counters.retried.Add(1)
+			i.requeue(dir, p)
+			continue
+		}
+
+		if err := i.index(ctx, dir, p.links, pages, indexed); err != nil {
+			i.counters.failed.Add(1)
+			log.Printf("site %s: error indexing %s: %s", i, dir, err)
+		}
+	}
+
+	if debug {
+		log.Printf("site %s: indexed %d sites, %+v", i, len(pending), i.Stats())
+	}
+}
+
+// isPage returns whether l is an HTML page.
+func isPage(l *indexTypes.Link) bool {
+	if l.Type != indexTypes.FileLinkType {
+		return false
+	}
+
+	switch strings.ToLower(path.Ext(l.Name)) {
+	case ".html", ".htm":
+		return true
+	default:
+		return false
+	}
+}
+
+// pages returns the HTML pages in links, up to MaxPages and MaxPageSize.
+func (i *Indexer) pages(links indexTypes.Links) indexTypes.Links {
+	var pages indexTypes.Links
+
+	for j := range links {
+		if isPage(&links[j]) && links[j].Size <= i.cfg.MaxPageSize {
+			pages = append(pages, links[j])
+		}
+	}
+
+	// Prefer index pages and otherwise keep a stable selection.
+	sort.SliceStable(pages, func(a, b int) bool {
+		return isIndexPage(pages[a].Name) && !isIndexPage(pages[b].Name)
+	})
+
+	if len(pages) > i.cfg.MaxPages {
+		pages = pages[:i.cfg.MaxPages]
+	}
+
+	return pages
+}
+
+func isIndexPage(name string) bool {
+	for _, n := range indexPages {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+// indexed returns the CIDs of pages which are in the files index.
+func (i *Indexer) indexed(ctx context.Context, pages indexTypes.Links) (map[string]bool, error) {
+	indexed := make(map[string]bool, len(pages))
+
+	for _, p := range pages {
+		found, err := i.files.Get(ctx, p.Hash, &indexTypes.Update{}, "last-seen")
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			indexed[p.Hash] = true
+		}
+	}
+
+	return indexed, nil
+}
+
+// fetch returns the title and the hrefs of links in page.
+func (i *Indexer) fetch(ctx context.Context, page indexTypes.Link) (string, []string, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.cfg.RequestTimeout)
+	defer cancel()
+
+	r := &t.AnnotatedResource{
+		Resource: &t.Resource{
+			Protocol: t.IPFSProtocol,
+			ID:       page.Hash,
+		},
+	}
+
+	body, err := i.getter.GetBody(ctx, i.protocol.GatewayURL(r), 200)
+	if err != nil {
+		return "", nil, err
+	}
+	defer body.Close()
+
+	return parsePage(io.LimitReader(body, int64(i.cfg.MaxPageSize)))
+}
+
+// Index fetches the pages of the site in directory dir with links and updates the Site of pages which
+// are in the files index.
+func (i *Indexer) Index(ctx context.Context, dir string, links indexTypes.Links) error {
+	pages := i.pages(links)
+
+	indexed, err := i.indexed(ctx, pages)
+	if err != nil {
+		return err
+	}
+
+	return i.index(ctx, dir, links, pages, indexed)
+}
+
+func (i *Indexer) index(ctx context.Context, dir string, links, pages indexTypes.Links, indexed map[string]bool) error {
+	ctx, span := i.Tracer.Start(ctx, "site.index")
+	defer span.End()
+
+	if len(indexed) == 0 {
+		return nil
+	}
+
+	byName := make(map[string]indexTypes.Link, len(links))
+	for _, l := range links {
+		byName[l.Name] = l
+	}
+
+	pageIdx := make(map[string]int, len(pages))
+	for j, p := range pages {
+		pageIdx[p.Hash] = j
+	}
+
+	var (
+		sites = make([]indexTypes.SitePage, len(pages))
+		edges = make([][]int, len(pages))
+	)
+
+	for j, p := range pages {
+		sites[j].Root = dir
+
+		title, hrefs, err := i.fetch(ctx, p)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			// Keep the page in the site, without outgoing links.
+			log.Printf("site %s: error fetching %s in %s: %s", i, p.Name, dir, err)
+			continue
+		}
+
+		sites[j].Title = title
+
+		seen := map[string]bool{p.Hash: true}
+
+		for _, href := range hrefs {
+			target, ok := resolve(href, byName)
+			if !ok || seen[target] {
+				continue
+			}
+
+			seen[target] = true
+			sites[j].Links = append(sites[j].Links, target)
+
+			if k, ok := pageIdx[target]; ok {
+				edges[j] = append(edges[j], k)
+			}
+		}
+	}
+
+	for j, rank := range pageRank(len(pages), edges) {
+		sites[j].Rank = rank
+	}
+
+	for j, p := range pages {
+		if !indexed[p.Hash] {
+			continue
+		}
+
+		if err := i.files.Update(ctx, p.Hash, &indexTypes.Update{Site: &sites[j]}); err != nil {
+			return err
+		}
+	}
+
+	i.counters.indexed.Add(1)
+
+	return nil
+}
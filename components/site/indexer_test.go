@@ -0,0 +1,176 @@
+package site
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/ipfs-search/ipfs-search/components/index"
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+	"github.com/ipfs-search/ipfs-search/utils"
+)
+
+const testDir = "QmSite"
+
+var testPages = map[string]string{
+	"QmIndex": `<html><head><title>Home</title></head><body><a href="about.html">About</a><a href="img/logo.png">Logo</a></body></html>`,
+	"QmAbout": `<html><head><title>About</title></head><body><a href="/">Home</a><a href="https://example.com/">Elsewhere</a></body></html>`,
+}
+
+var testLinks = indexTypes.Links{
+	{Hash: "QmAbout", Name: "about.html", Size: 100, Type: indexTypes.FileLinkType},
+	{Hash: "QmImg", Name: "img", Type: indexTypes.DirectoryLinkType},
+	{Hash: "QmIndex", Name: "index.html", Size: 100, Type: indexTypes.FileLinkType},
+	{Hash: "QmData", Name: "data.json", Size: 100, Type: indexTypes.FileLinkType},
+}
+
+type IndexerTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+
+	files    *index.Mock
+	protocol *protocol.Mock
+	server   *httptest.Server
+
+	cfg *Config
+	i   *Indexer
+}
+
+func (s *IndexerTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := testPages[strings.TrimPrefix(r.URL.Path, "/ipfs/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(page))
+	}))
+
+	s.protocol = &protocol.Mock{}
+	for id := range testPages {
+		id := id
+
+		s.protocol.
+			On("GatewayURL", mock.MatchedBy(func(r *t.AnnotatedResource) bool { return r.ID == id })).
+			Return(s.server.URL + "/ipfs/" + id)
+	}
+
+	s.files = &index.Mock{}
+	s.cfg = DefaultConfig()
+
+	i := instr.New()
+	s.i = New(s.files, utils.NewHTTPBodyGetter(http.DefaultClient, i), s.protocol, s.cfg, i)
+}
+
+func (s *IndexerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *IndexerTestSuite) expectIndexed(id string, found bool) {
+	s.files.
+		On("Get", mock.Anything, id, mock.Anything, []string{"last-seen"}).
+		Return(found, nil)
+}
+
+// expectSite expects an update of the site of page and returns a pointer to it.
+func (s *IndexerTestSuite) expectSite(id string) *indexTypes.SitePage {
+	site := new(indexTypes.SitePage)
+
+	s.files.
+		On("Update", mock.Anything, id, mock.AnythingOfType("*types.Update")).
+		Run(func(args mock.Arguments) {
+			*site = *args.Get(2).(*indexTypes.Update).Site
+		}).
+		Return(nil).
+		Once()
+
+	return site
+}
+
+func (s *IndexerTestSuite) TestIndex() {
+	s.expectIndexed("QmIndex", true)
+	s.expectIndexed("QmAbout", true)
+
+	index := s.expectSite("QmIndex")
+	about := s.expectSite("QmAbout")
+
+	s.NoError(s.i.Index(s.ctx, testDir, testLinks))
+	s.files.AssertExpectations(s.T())
+
+	s.Equal(testDir, index.Root)
+	s.Equal("Home", index.Title)
+	s.Equal([]string{"QmAbout", "QmImg"}, index.Links)
+
+	s.Equal(testDir, about.Root)
+	s.Equal("About", about.Title)
+	s.Equal([]string{"QmIndex"}, about.Links)
+
+	s.InDelta(1.0, index.Rank+about.Rank, 1e-6)
+}
+
+func (s *IndexerTestSuite) TestIndexOnlyIndexedPages() {
+	s.expectIndexed("QmIndex", true)
+	s.expectIndexed("QmAbout", false)
+
+	index := s.expectSite("QmIndex")
+
+	s.NoError(s.i.Index(s.ctx, testDir, testLinks))
+	s.files.AssertExpectations(s.T())
+	s.files.AssertNotCalled(s.T(), "Update", mock.Anything, "QmAbout", mock.Anything)
+
+	// Links are resolved nonetheless.
+	s.Equal([]string{"QmAbout", "QmImg"}, index.Links)
+}
+
+func (s *IndexerTestSuite) TestRetryPending() {
+	s.cfg.MaxAttempts = 2
+
+	s.expectIndexed("QmIndex", true)
+	s.expectIndexed("QmAbout", false)
+
+	s.i.Add(testDir, testLinks)
+
+	// First attempt; not all pages indexed.
+	s.i.indexPending(s.ctx)
+	s.files.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+	s.Equal(int64(1), s.i.Stats().Retried)
+
+	// Last attempt; indexed pages are updated.
+	s.expectSite("QmIndex")
+	s.i.indexPending(s.ctx)
+	s.files.AssertExpectations(s.T())
+
+	s.Equal(Stats{Enqueued: 1, Retried: 1, Indexed: 1}, s.i.Stats())
+}
+
+func (s *IndexerTestSuite) TestAddMaxPending() {
+	s.cfg.MaxPending = 1
+
+	s.i.Add("QmA", testLinks)
+	s.i.Add("QmA", testLinks)
+	s.i.Add("QmB", testLinks)
+
+	s.Equal(Stats{Enqueued: 1, Dropped: 1}, s.i.Stats())
+}
+
+func (s *IndexerTestSuite) TestPagesMaxPages() {
+	s.cfg.MaxPages = 1
+
+	s.Equal(indexTypes.Links{testLinks[2]}, s.i.pages(testLinks))
+}
+
+func TestIndexerTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexerTestSuite))
+}
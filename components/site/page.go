@@ -0,0 +1,58 @@
+package site
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxTitleLength limits stored titles; longer ones are most likely not titles.
+const maxTitleLength = 512
+
+// parsePage returns the title and the href attributes of links in an HTML page.
+func parsePage(r io.Reader) (title string, hrefs []string, err error) {
+	z := html.NewTokenizer(r)
+
+	inTitle := false
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return strings.TrimSpace(title), hrefs, nil
+			}
+
+			return "", nil, z.Err()
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+
+			switch atom.Lookup(name) {
+			case atom.Title:
+				// Only use the first title; svg elements have titles too.
+				inTitle = title == ""
+			case atom.A, atom.Area:
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = z.TagAttr()
+
+					if string(key) == "href" {
+						hrefs = append(hrefs, string(val))
+					}
+				}
+			}
+
+		case html.EndTagToken:
+			if name, _ := z.TagName(); atom.Lookup(name) == atom.Title {
+				inTitle = false
+			}
+
+		case html.TextToken:
+			if inTitle && len(title) < maxTitleLength {
+				title += string(z.Text())
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package site
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+func TestParsePage(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html><head><title> About us </title></head>
+<body>
+<svg><title>icon</title></svg>
+<a href="index.html">Home</a>
+<a href="https://example.com/">External</a>
+<map><area href="/img/"></map>
+<a>No href</a>
+</body></html>`
+
+	title, hrefs, err := parsePage(strings.NewReader(page))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "About us", title)
+	assert.Equal(t, []string{"index.html", "https://example.com/", "/img/"}, hrefs)
+}
+
+func TestResolve(t *testing.T) {
+	links := map[string]indexTypes.Link{
+		"index.html": {Hash: "QmIndex", Name: "index.html", Type: indexTypes.FileLinkType},
+		"a b.html":   {Hash: "QmAB", Name: "a b.html", Type: indexTypes.FileLinkType},
+		"img":        {Hash: "QmImg", Name: "img", Type: indexTypes.DirectoryLinkType},
+	}
+
+	for href, expected := range map[string]string{
+		"index.html":              "QmIndex",
+		"./index.html#top":        "QmIndex",
+		"/index.html?q=1":         "QmIndex",
+		"/":                       "QmIndex",
+		"./":                      "QmIndex",
+		"a%20b.html":              "QmAB",
+		"img/logo.png":            "QmImg",
+		"/img/":                   "QmImg",
+		"#top":                    "",
+		"missing.html":            "",
+		"../index.html":           "",
+		"https://example.com/":    "",
+		"//example.com/img":       "",
+		"mailto:info@example.com": "",
+	} {
+		hash, ok := resolve(href, links)
+		assert.Equal(t, expected != "", ok, href)
+		assert.Equal(t, expected, hash, href)
+	}
+}
+
+func TestPageRank(t *testing.T) {
+	assert.Nil(t, pageRank(0, nil))
+
+	// 1 and 2 link to 0, 0 links to 1.
+	rank := pageRank(3, [][]int{{1}, {0}, {0}})
+
+	assert.InDelta(t, 1.0, rank[0]+rank[1]+rank[2], 1e-6)
+	assert.Greater(t, rank[0], rank[1])
+	assert.Greater(t, rank[1], rank[2])
+
+	// Without links, all pages are equal.
+	rank = pageRank(2, make([][]int, 2))
+	assert.InDelta(t, 0.5, rank[0], 1e-6)
+	assert.InDelta(t, 0.5, rank[1], 1e-6)
+}
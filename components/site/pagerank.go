@@ -0,0 +1,62 @@
+package site
+
+import (
+	"math"
+)
+
+const (
+	damping       = 0.85
+	maxIterations = 50
+	tolerance     = 1e-6
+)
+
+// pageRank returns the PageRank of n pages, with outgoing edges by page, summing to 1.
+// Rank of pages without outgoing edges is distributed over all pages.
+func pageRank(n int, edges [][]int) []float64 {
+	if n == 0 {
+		return nil
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+
+	next := make([]float64, n)
+
+	for iter := 0; iter < maxIterations; iter++ {
+		dangling := 0.0
+
+		for i := range next {
+			next[i] = 0
+		}
+
+		for i, out := range edges {
+			if len(out) == 0 {
+				dangling += rank[i]
+				continue
+			}
+
+			share := rank[i] / float64(len(out))
+			for _, j := range out {
+				next[j] += share
+			}
+		}
+
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		delta := 0.0
+
+		for i := range next {
+			next[i] = base + damping*next[i]
+			delta += math.Abs(next[i] - rank[i])
+		}
+
+		rank, next = next, rank
+
+		if delta < tolerance {
+			break
+		}
+	}
+
+	return rank
+}
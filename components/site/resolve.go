@@ -0,0 +1,48 @@
+package site
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+)
+
+// indexPages are served for directories, in order of preference.
+var indexPages = []string{"index.html", "index.htm"}
+
+// resolve returns the CID of the entry of the site directory with links that href, in a page in the
+// root of the directory, points to. Links to subdirectories resolve to the subdirectory.
+// External links, and links to entries which are not in links, are not resolved.
+func resolve(href string, links map[string]indexTypes.Link) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Opaque != "" {
+		return "", false
+	}
+
+	if u.Path == "" {
+		// Fragment or query only; the page itself.
+		return "", false
+	}
+
+	// Root-relative links are resolved against the site directory.
+	p := path.Clean(strings.TrimPrefix(u.Path, "/"))
+
+	if p == "." {
+		for _, name := range indexPages {
+			if l, ok := links[name]; ok {
+				return l.Hash, true
+			}
+		}
+
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(p, "/")
+
+	if l, ok := links[name]; ok {
+		return l.Hash, true
+	}
+
+	return "", false
+}
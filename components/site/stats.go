@@ -0,0 +1,23 @@
+package site
+
+import (
+	"sync/atomic"
+)
+
+// counters tracks site indexing.
+type counters struct {
+	enqueued atomic.Int64
+	dropped  atomic.Int64
+	indexed  atomic.Int64
+	retried  atomic.Int64
+	failed   atomic.Int64
+}
+
+// Stats is a snapshot of the counters of an Indexer.
+type Stats struct {
+	Enqueued int64 // Sites queued, when not already pending.
+	Dropped  int64 // Sites not queued as too many were pending.
+	Indexed  int64 // Sites for which pages were updated.
+	Retried  int64 // Sites queued again as not all pages were indexed yet.
+	Failed   int64 // Sites for which fetching or writing failed, or which were given up on.
+}
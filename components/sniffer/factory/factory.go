@@ -41,7 +41,7 @@ func getInstr(cfg *instr.Config) (*instr.Instrumentation, func(context.Context),
 }
 
 // getQueue 使用重试拨号器初始化 AMQP 发布者工厂。
-func getQueue(ctx context.Context, cfg *amqp.Config, i *instr.Instrumentation) amqp.PublisherFactory {
+func getQueue(ctx context.Context, cfg *amqp.Config, name string, i *instr.Instrumentation) amqp.PublisherFactory {
 	// 用于连接的重试拨号器
 	dialer := &utils.RetryingDialer{
 		Dialer: net.Dialer{
@@ -58,7 +58,7 @@ func getQueue(ctx context.Context, cfg *amqp.Config, i *instr.Instrumentation) a
 	return amqp.PublisherFactory{
 		Config:          cfg,
 		AMQPConfig:      samqpConfig,
-		Queue:           "hashes", // 设置队列名称。
+		Queue:           name, // 设置队列名称。
 		Instrumentation: i,
 	}
 }
@@ -84,7 +84,7 @@ func Start(ctx context.Context, ds datastore.Batching) (context.Context, datasto
 	// 创建一个可以被 sniffer 取消的上下文，以便从 sniffer goroutine 传播失败。
 	ctx, cancel := context.WithCancel(ctx)
 
-	q := getQueue(ctx, cfg.AMQPConfig(), i)
+	q := getQueue(ctx, cfg.AMQPConfig(), cfg.Queues.Hashes.Name, i)
 
 	s, err := getSniffer(cfg.SnifferConfig(), ds, q, i)
 	if err != nil {
@@ -92,6 +92,11 @@ func Start(ctx context.Context, ds datastore.Batching) (context.Context, datasto
 		return nil, nil, err
 	}
 
+	// 可选地记录提供者公告。
+	if cfg.Providers.FlushInterval > 0 {
+		s.RecordProviders(getQueue(ctx, cfg.AMQPConfig(), cfg.Queues.Providers.Name, i), cfg.ProvidersConfig())
+	}
+
 	// 使用批处理数据存储。
 	ds = s.Batching()
 
@@ -56,9 +56,9 @@ func (h *Handler) HandleFunc(ctx context.Context, e eventsource.EvtProviderPut)
 			Protocol: t.IPFSProtocol, // 固定协议为IPFS
 			ID:       e.CID.String(), // 转换CID为字符串
 		},
-		Date:        time.Now(),         // 记录处理时间
-		Provider:    e.PeerID.String(),  // 转换PeerID为字符串
-		SpanContext: span.SpanContext(), // 保存当前Span上下文
+		Date:        time.Now().UTC().Truncate(time.Second), // 记录处理时间，截断到秒级兼容ES格式
+		Provider:    e.PeerID.String(),                      // 转换PeerID为字符串
+		SpanContext: span.SpanContext(),                     // 保存当前Span上下文
 	}
 
 	// 非阻塞式写入通道（带上下文监听）
@@ -15,7 +15,7 @@ var (
 	errUnsupportedCodec    = errors.New("unsupported codec")
 )
 
-// CidFilter filters out invalid CID's or those which are not Raw or DagProtobuf.
+// CidFilter filters out invalid CID's or those which are not Raw, DagProtobuf or IPLD documents.
 type CidFilter struct{}
 
 // NewCidFilter returns a pointer to a new CidFilter.
@@ -36,10 +36,13 @@ func (f *CidFilter) Filter(p t.Provider) (bool, error) {
 		return false, fmt.Errorf("%w: %s decoding CID %v", errDecodingCID, err, p)
 	}
 
-	switch cidType := c.Type(); cidType {
-	case cid.Raw, cid.DagProtobuf:
+	switch cidType := c.Type(); {
+	case cidType == cid.Raw, cidType == cid.DagProtobuf:
 		// (Potential) files and directories
 		return true, nil
+	case t.IsIPLDDocument(c):
+		// dag-cbor and dag-json documents
+		return true, nil
 	default:
 		// Can't handle other types (for now)
 		return false, fmt.Errorf("%w: %s for %v", errUnsupportedCodec, cid.CodecToStr[cidType], p)
@@ -70,6 +70,38 @@ func TestCid1Protobuf(t *testing.T) {
 	assert.True(result)
 }
 
+func TestCid1DagCBOR(t *testing.T) {
+	assert := assert.New(t)
+
+	r := &types.Resource{
+		Protocol: types.IPFSProtocol,
+		ID:       "bafyreidmmwhoqp5x5ajeqjeu6psbnkdw6y7udcqlripv45wup3sbo4bvzm",
+	}
+
+	p := makeProvider(r)
+
+	result, err := filter.Filter(*p)
+
+	assert.Empty(err)
+	assert.True(result)
+}
+
+func TestCid1DagJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	r := &types.Resource{
+		Protocol: types.IPFSProtocol,
+		ID:       "baguqeerajqk7i6x6p6ax7vkz4ew5xqtw6sjqywbc6icjbcgw6zqfx3d45jla",
+	}
+
+	p := makeProvider(r)
+
+	result, err := filter.Filter(*p)
+
+	assert.Empty(err)
+	assert.True(result)
+}
+
 func TestUnsupported(t *testing.T) {
 	assert := assert.New(t)
 
@@ -49,11 +49,10 @@ func (q *Queuer) iterate(ctx context.Context) error {
 			), trace.WithSpanKind(trace.SpanKindProducer))
 			defer span.End()
 
-			// TODO: Queue provider here, not AnnotatedResource.
-
 			r := t.AnnotatedResource{
-				Resource: p.Resource,
-				Source:   t.SnifferSource,
+				Resource:  p.Resource,
+				Source:    t.SnifferSource,
+				Providers: []string{p.Provider},
 			}
 
 			// Add with highest priority (9), as this is supposed to be available
@@ -27,8 +27,9 @@ func (s *QueuerTestSuite) SetupTest() {
 	s.q.Test(s.T())
 	s.p = t.MockProvider()
 	s.r = &t.AnnotatedResource{
-		Resource: s.p.Resource,
-		Source:   t.SnifferSource,
+		Resource:  s.p.Resource,
+		Source:    t.SnifferSource,
+		Providers: []string{s.p.Provider},
 	}
 }
 
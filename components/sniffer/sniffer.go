@@ -10,6 +10,8 @@ IPFS DHT 数据存储 (datastore.Batching)
        ↓ 订阅事件
 subscribe() → 写入 sniffed 通道
        ↓ 从 sniffed 读取
+tee() → 复制到 announced 通道 → aggregate() → 发布到提供者队列（可选）
+       ↓ 写入 unfiltered 通道
 filter() → 写入 filtered 通道
        ↓ 从 filtered 读取
 queue() → 发布到消息队列
@@ -28,6 +30,7 @@ import (
 	"github.com/ipfs/go-datastore"  // IPFS数据存储接口
 	"github.com/libp2p/go-eventbus" // 事件总线
 
+	"github.com/ipfs-search/ipfs-search/components/providers"                       // 提供者记录
 	"github.com/ipfs-search/ipfs-search/components/queue"                           // 队列组件
 	"github.com/ipfs-search/ipfs-search/components/sniffer/eventsource"             // 事件源
 	"github.com/ipfs-search/ipfs-search/components/sniffer/handler"                 // 事件处理器
@@ -46,6 +49,9 @@ type Sniffer struct {
 	es  eventsource.EventSource // 事件源
 	pub queue.PublisherFactory  // 消息队列工厂
 
+	providersPub queue.PublisherFactory // 提供者记录队列工厂，为 nil 时不记录
+	providersCfg *providers.Config      // 提供者记录配置
+
 	*instr.Instrumentation // 监控组件
 }
 
@@ -68,6 +74,12 @@ func New(cfg *Config, ds datastore.Batching, pub queue.PublisherFactory, i *inst
 	return &s, nil
 }
 
+// RecordProviders 使 Sniffer 在过滤之前聚合所有提供者公告，并定期发布到 pub 创建的队列。
+func (s *Sniffer) RecordProviders(pub queue.PublisherFactory, cfg *providers.Config) {
+	s.providersPub = pub
+	s.providersCfg = cfg
+}
+
 // Batching 返回一个被嗅探钩子包装的数据存储。
 func (s *Sniffer) Batching() datastore.Batching {
 	return s.es.Batching() // 返回带有嗅探钩子的数据存储
@@ -123,6 +135,36 @@ func (s *Sniffer) queue(ctx context.Context, c <-chan t.Provider) error {
 	return err
 }
 
+// tee 将事件同时写入 out 和 announced 通道
+func (s *Sniffer) tee(ctx context.Context, in <-chan t.Provider, out, announced chan<- t.Provider) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case p := <-in:
+			for _, c := range []chan<- t.Provider{out, announced} {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case c <- p:
+				}
+			}
+		}
+	}
+}
+
+// aggregate 聚合提供者公告并发布到提供者队列
+func (s *Sniffer) aggregate(ctx context.Context, c <-chan t.Provider) error {
+	publisher, err := s.providersPub.NewPublisher(ctx) // 创建队列发布者
+	if err != nil {
+		return err
+	}
+
+	a := providers.NewAggregator(publisher, s.providersCfg, s.Instrumentation)
+
+	return a.Aggregate(ctx, c)
+}
+
 // iterate 使用错误处理组并发运行订阅、过滤和入队列流程
 func (s *Sniffer) iterate(ctx context.Context, sniffed, filtered chan t.Provider) error {
 	// ctx, span := s.Tracer.Start(ctx, "sniffer.iterate")
@@ -131,9 +173,20 @@ func (s *Sniffer) iterate(ctx context.Context, sniffed, filtered chan t.Provider
 	// Create error group and context
 	errg, ctx := errgroup.WithContext(ctx) // 创建错误处理组
 	// 并发执行三个核心流程
-	errg.Go(func() error { return s.subscribe(ctx, sniffed) })        // 数据源
-	errg.Go(func() error { return s.filter(ctx, sniffed, filtered) }) // 中间处理
-	errg.Go(func() error { return s.queue(ctx, filtered) })           // 最终输出
+	errg.Go(func() error { return s.subscribe(ctx, sniffed) }) // 数据源
+
+	unfiltered := sniffed
+	if s.providersPub != nil {
+		// 在过滤之前复制所有公告，以记录每次公告
+		unfiltered = make(chan t.Provider, s.cfg.BufferSize)
+		announced := make(chan t.Provider, s.cfg.BufferSize)
+
+		errg.Go(func() error { return s.tee(ctx, sniffed, unfiltered, announced) })
+		errg.Go(func() error { return s.aggregate(ctx, announced) })
+	}
+
+	errg.Go(func() error { return s.filter(ctx, unfiltered, filtered) }) // 中间处理
+	errg.Go(func() error { return s.queue(ctx, filtered) })              // 最终输出
 
 	// Wait until all contexts are closed, then return *first* error
 	err := errg.Wait() // 等待所有协程完成
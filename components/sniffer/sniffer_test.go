@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
+	ipfsProviders "github.com/ipfs-search/ipfs-search/components/providers"
 	"github.com/ipfs-search/ipfs-search/components/queue"
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
@@ -172,6 +173,71 @@ func (s *SnifferTestSuite) TestHandleToPublish() {
 	qMock.AssertExpectations(s.T())
 }
 
+// TestRecordProviders tests whether announcements are published to the providers queue.
+func (s *SnifferTestSuite) TestRecordProviders() {
+	cidStr := "QmSKboVigcD3AY4kLsob117KJcMHvMUu6vNFqk1PQzYUpp"
+	provStr := "QmeTtFXm42Jb2todcKR538j6qHYxXt6suUzpF3rtT9FPSd"
+
+	key, err := makeKey(cidStr, provStr)
+	s.NoError(err)
+
+	now := time.Now()
+	value := timeToVal(now)
+
+	// Create sniffer, recording providers
+	cfg := DefaultConfig()
+	sniffy, e := New(cfg, s.ds, s.f, instr.New())
+	s.NoError(e)
+
+	providersCfg := ipfsProviders.DefaultConfig()
+	providersCfg.FlushInterval = 10 * time.Millisecond
+
+	pf := &queue.MockFactory{}
+	pf.Test(s.T())
+	sniffy.RecordProviders(pf, providersCfg)
+
+	wrappedDs := sniffy.Batching()
+
+	// Setup Mock Queues
+	qMock := &queue.Mock{}
+	qMock.On("Publish", mock.Anything, mock.AnythingOfType("*types.AnnotatedResource"), uint8(9)).Return(nil)
+	s.f.On("NewPublisher", mock.AnythingOfType("*context.cancelCtx")).Return(qMock, nil)
+
+	pMock := &queue.Mock{}
+	pMock.On("Publish", mock.Anything, mock.MatchedBy(func(msg interface{}) bool {
+		a := msg.(*ipfsProviders.Announcements)
+		s.Equal(cidStr, a.ID)
+		s.Len(a.Providers, 1)
+		s.Equal(provStr, a.Providers[0].Peer)
+		s.Equal(uint64(1), a.Providers[0].Count)
+		s.WithinDuration(now, a.Providers[0].LastAnnounced, time.Second)
+		return true
+	}), uint8(1)).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			s.cancel()
+		})
+	pf.On("NewPublisher", mock.AnythingOfType("*context.cancelCtx")).Return(pMock, nil)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		err := sniffy.Sniff(s.ctx)
+		s.Contains(err.Error(), "context canceled")
+		wg.Done()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	s.NoError(wrappedDs.Put(key, value))
+
+	wg.Wait()
+
+	pf.AssertExpectations(s.T())
+	pMock.AssertExpectations(s.T())
+}
+
 // // TestLogToPublish tests the full chain from a log to a publish
 // func (s *SnifferTestSuite) TestLogToPublish() {
 // 	// Create queue and channels to retreive published messages and priorities
@@ -29,9 +29,22 @@ func (p *Pool) getConsumeChans(ctx context.Context) (*consumeChans, error) {
 	}
 
 	// Note: Manually adjust order here!
-	return &consumeChans{
+	c := &consumeChans{
 		Files:       chans[0],
 		Directories: chans[1],
 		Hashes:      chans[2],
-	}, nil
+	}
+
+	if p.providers != nil {
+		q, err := p.getProvidersQueue(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.Providers, err = q.Consume(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
 }
@@ -2,9 +2,17 @@ package pool
 
 import (
 	"context"
+	"errors"
 	"log"
 
+	"github.com/ipfs-search/ipfs-search/components/classifier"
 	"github.com/ipfs-search/ipfs-search/components/crawler"
+	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/names"
+	"github.com/ipfs-search/ipfs-search/components/protocol"
+	"github.com/ipfs-search/ipfs-search/components/providers"
+	"github.com/ipfs-search/ipfs-search/components/site"
+	t "github.com/ipfs-search/ipfs-search/types"
 )
 
 func (p *Pool) getCrawler(ctx context.Context) (*crawler.Crawler, error) {
@@ -24,9 +32,109 @@ func (p *Pool) getCrawler(ctx context.Context) (*crawler.Crawler, error) {
 		return nil, err
 	}
 
-	protocol := p.getProtocol()
+	protocol, err := p.getProtocol(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	extractors := p.getExtractors(protocol)
 	config := p.config.CrawlerConfig()
 
-	return crawler.New(config, indexes, queues, protocol, extractors, p.Instrumentation), nil
+	classifier, err := classifier.New(p.config.ClassifierConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	var sites *site.Indexer
+	if p.config.Indexes.Sites > 0 {
+		sites = p.getSiteIndexer(ctx, indexes, protocol)
+	}
+
+	p.indexes = indexes
+	p.names = p.getNameRefresher(ctx, queues)
+
+	if p.config.Providers.FlushInterval > 0 && indexes.Providers != nil {
+		p.providers = p.getProvidersIndexer(ctx, indexes)
+	}
+
+	return crawler.New(config, indexes, queues, protocol, extractors, &crawler.Components{
+		Classifier: classifier,
+		Sites:      sites,
+		Names:      p.names,
+	}, p.Instrumentation), nil
+}
+
+// getNameRefresher returns a refresher queueing IPNS names for resolving again, running until ctx is done.
+// Counters are logged on exit.
+func (p *Pool) getNameRefresher(ctx context.Context, queues *crawler.Queues) *names.Refresher {
+	r := names.New(queues.Hashes, names.DefaultConfig(), p.Instrumentation)
+
+	p.goBackground(func() {
+		r.Work(ctx)
+		log.Printf("Name refresher: %+v", r.Stats())
+	})
+
+	return r
+}
+
+// loadNames schedules the names and domains stored in the indexes for refreshing, so that names resolved before a
+// restart are refreshed without being rediscovered.
+func (p *Pool) loadNames(ctx context.Context) {
+	interval := p.config.Crawler.NameRefreshInterval
+
+	for _, n := range []struct {
+		idx      index.Index
+		protocol t.Protocol
+	}{
+		{p.indexes.Names, t.IPNSProtocol},
+		{p.indexes.Domains, t.DNSLinkProtocol},
+	} {
+		if n.idx == nil {
+			continue
+		}
+
+		loaded, err := p.names.Load(ctx, n.idx, n.protocol, interval)
+
+		switch {
+		case errors.Is(err, index.ErrNotSupported):
+			log.Printf("Index %s cannot be scanned, names are refreshed when rediscovered.", n.idx)
+		case err != nil:
+			log.Printf("Error scheduling names from %s: %s", n.idx, err)
+		default:
+			log.Printf("Scheduled %d names from %s for refreshing.", loaded, n.idx)
+		}
+	}
+}
+
+// getSiteIndexer returns an indexer for pages of websites, running until ctx is done. Counters are logged on exit.
+func (p *Pool) getSiteIndexer(ctx context.Context, indexes *crawler.Indexes, protocol protocol.Protocol) *site.Indexer {
+	cfg := site.DefaultConfig()
+	cfg.Interval = p.config.Indexes.Sites
+
+	s := site.New(indexes.Files, p.getExtractorGetter(), protocol, cfg, p.Instrumentation)
+	p.onFlush(s.Flush)
+
+	p.goBackground(func() {
+		s.Work(ctx)
+		log.Printf("Site indexer %s: %+v", s, s.Stats())
+	})
+
+	return s
+}
+
+// getProvidersIndexer returns an indexer for provider announcements, periodically deleting expired documents until
+// ctx is done. Counters are logged on exit.
+func (p *Pool) getProvidersIndexer(ctx context.Context, indexes *crawler.Indexes) *providers.Indexer {
+	i := providers.NewIndexer(indexes.Providers, p.config.ProvidersConfig(), p.Instrumentation)
+
+	go func() {
+		if err := i.Work(ctx); errors.Is(err, index.ErrNotSupported) {
+			log.Printf("Providers index %s cannot delete expired documents, they expire when announced again.", indexes.Providers)
+			<-ctx.Done()
+		}
+
+		log.Printf("Providers indexer: %+v", i.Stats())
+	}()
+
+	return i
 }
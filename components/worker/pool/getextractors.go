@@ -10,11 +10,16 @@ import (
 	"github.com/ipfs-search/ipfs-search/utils"
 )
 
-func (p *Pool) getExtractors(protocol protocol.Protocol) []extractor.Extractor {
-	// Limited extractor connections (as resources are generally known to be available by now)
+// getExtractorGetter returns a getter for resources which are generally known to be available, with limited connections.
+func (p *Pool) getExtractorGetter() utils.HTTPBodyGetter {
 	extractorTransport := utils.GetHTTPTransport(p.dialer.DialContext, p.config.Workers.MaxExtractorConns)
 
-	getter := utils.NewHTTPBodyGetter(&http.Client{Transport: extractorTransport}, p.Instrumentation)
+	return utils.NewHTTPBodyGetter(&http.Client{Transport: extractorTransport}, p.Instrumentation)
+}
+
+func (p *Pool) getExtractors(protocol protocol.Protocol) []extractor.Extractor {
+	// Limited extractor connections (as resources are generally known to be available by now)
+	getter := p.getExtractorGetter()
 
 	tikaExtractor := tika.New(p.config.TikaConfig(), getter, protocol, p.Instrumentation)
 	nsfwExtractor := nsfw.New(p.config.NSFWConfig(), getter, p.Instrumentation)
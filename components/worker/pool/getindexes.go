@@ -2,19 +2,35 @@ package pool
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"reflect"
 	"time"
 
+	"github.com/blevesearch/bleve/v2/mapping"
+
 	"github.com/ipfs-search/ipfs-search/components/crawler"
+	"github.com/ipfs-search/ipfs-search/components/graph"
+	graphSQLite "github.com/ipfs-search/ipfs-search/components/graph/sqlite"
 	"github.com/ipfs-search/ipfs-search/components/index"
+	"github.com/ipfs-search/ipfs-search/components/index/attribution"
+	"github.com/ipfs-search/ipfs-search/components/index/bleve"
 	"github.com/ipfs-search/ipfs-search/components/index/cache"
+	"github.com/ipfs-search/ipfs-search/components/index/coalesce"
+	"github.com/ipfs-search/ipfs-search/components/index/meilisearch"
+	"github.com/ipfs-search/ipfs-search/components/index/multi"
 	"github.com/ipfs-search/ipfs-search/components/index/opensearch"
 	"github.com/ipfs-search/ipfs-search/components/index/redis"
+	"github.com/ipfs-search/ipfs-search/components/index/sqlite"
 	indexTypes "github.com/ipfs-search/ipfs-search/components/index/types"
+	"github.com/ipfs-search/ipfs-search/config"
 	"github.com/ipfs-search/ipfs-search/utils"
 )
 
+// checkpointTTL is the expiry of directory listing checkpoints, so those of directories which are never retried
+// do not accumulate.
+const checkpointTTL = 7 * 24 * time.Hour
+
 func osWorkLoop(ctx context.Context, workFunc func(context.Context) error) {
 	for {
 		// Keep starting worker unless context is done.
@@ -31,15 +47,31 @@ func osWorkLoop(ctx context.Context, workFunc func(context.Context) error) {
 	}
 }
 
-func (w *Pool) getOpenSearchClient() (*opensearch.Client, error) {
+// getOpenSearchClient returns a client for the configured cluster, or for the mirror cluster.
+func (w *Pool) getOpenSearchClient(mirror bool) (*opensearch.Client, error) {
+	cluster := config.OpenSearchCluster{
+		URL:      w.config.OpenSearch.URL,
+		Username: w.config.OpenSearch.Username,
+		Password: w.config.OpenSearch.Password,
+	}
+
+	if mirror {
+		cluster = w.config.OpenSearch.Mirror
+	}
+
 	config := &opensearch.ClientConfig{
-		URL:       w.config.OpenSearch.URL,
+		URL:       cluster.URL,
+		Username:  cluster.Username,
+		Password:  cluster.Password,
 		Transport: utils.GetHTTPTransport(w.dialer.DialContext, 100),
 		Debug:     false,
 
+		SyncWrites: w.config.OpenSearch.SyncWrites,
+
 		BulkIndexerWorkers:      w.config.OpenSearch.BulkIndexerWorkers,
 		BulkIndexerFlushBytes:   int(w.config.OpenSearch.BulkIndexerFlushBytes),
 		BulkIndexerFlushTimeout: w.config.OpenSearch.BulkIndexerFlushTimeout,
+		SyncFlushTimeout:        w.config.OpenSearch.SyncFlushTimeout,
 		BulkGetterBatchSize:     w.config.OpenSearch.BulkGetterBatchSize,
 		BulkGetterBatchTimeout:  w.config.OpenSearch.BulkGetterBatchTimeout,
 	}
@@ -66,6 +98,15 @@ func getCachingFields() []string {
 	return cachingFields
 }
 
+// referenceLimits returns the limits of references stored in documents, enforced by indexes appending references
+// atomically.
+func (w *Pool) referenceLimits() indexTypes.ReferenceLimits {
+	return indexTypes.ReferenceLimits{
+		Max:      w.config.Crawler.MaxReferences,
+		MaxNames: w.config.Crawler.MaxReferenceNames,
+	}
+}
+
 func getOsIndex(c *opensearch.Client, name string) index.Index {
 	return opensearch.New(
 		c,
@@ -74,7 +115,351 @@ func getOsIndex(c *opensearch.Client, name string) index.Index {
 }
 
 func (w *Pool) getIndexes(ctx context.Context) (*crawler.Indexes, error) {
-	os, err := w.getOpenSearchClient()
+	indexes, err := w.getBackendIndexes(ctx, w.config.Indexes.Backend)
+	if err != nil {
+		return nil, err
+	}
+
+	if mirror := w.config.Indexes.Mirror; mirror != "" {
+		mirrorIndexes, err := w.getMirrorIndexes(ctx, mirror)
+		if err != nil {
+			return nil, err
+		}
+
+		indexes = w.getMultiIndexes(ctx, indexes, mirrorIndexes)
+	}
+
+	if w.config.Indexes.CoalesceWindow > 0 {
+		indexes = w.getCoalescingIndexes(ctx, indexes)
+	}
+
+	if w.config.Indexes.Attribution > 0 {
+		indexes.Files = w.getAttributingIndex(ctx, indexes)
+	}
+
+	if w.config.Graph.Path != "" {
+		if indexes.Graph, err = w.getGraph(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return indexes, nil
+}
+
+// getMirrorIndexes returns indexes for the mirror backend. An OpenSearch mirror writes to its own cluster, when
+// configured, without Redis caching: the cache keys would collide with those of an OpenSearch backend, and documents
+// read from the mirror as a fallback are copied from it in full.
+func (w *Pool) getMirrorIndexes(ctx context.Context, mirror string) (*crawler.Indexes, error) {
+	ownCluster := mirror == config.OpenSearchBackend && w.config.OpenSearch.Mirror.URL != ""
+
+	if ownCluster && w.config.OpenSearch.Mirror.URL == w.config.OpenSearch.URL {
+		return nil, fmt.Errorf("opensearch mirror cannot be the same cluster: %s", w.config.OpenSearch.URL)
+	}
+
+	if mirror == w.config.Indexes.Backend && !ownCluster {
+		return nil, fmt.Errorf("index mirror cannot be the same as backend: %s", mirror)
+	}
+
+	if mirror == config.OpenSearchBackend {
+		return w.getOpenSearchMirrorIndexes(ctx)
+	}
+
+	return w.getBackendIndexes(ctx, mirror)
+}
+
+// getGraph returns the edge store, closed when ctx is done.
+func (w *Pool) getGraph(ctx context.Context) (graph.Store, error) {
+	store, err := graphSQLite.New(ctx, w.config.GraphConfig(), w.Instrumentation)
+	if err != nil {
+		return nil, err
+	}
+
+	w.goBackground(func() {
+		<-ctx.Done()
+		store.Close()
+	})
+
+	return store, nil
+}
+
+// getAttributingIndex returns the files index, resolving full paths of files with new references from the
+// references of files and directories. Counters are logged on exit.
+func (w *Pool) getAttributingIndex(ctx context.Context, indexes *crawler.Indexes) index.Index {
+	cfg := attribution.DefaultConfig()
+	cfg.Interval = w.config.Indexes.Attribution
+
+	parents := attribution.ReferenceParents{indexes.Files, indexes.Directories}
+	a := attribution.New(indexes.Files, parents, cfg, w.Instrumentation)
+	w.onFlush(a.Flush)
+
+	w.goBackground(func() {
+		a.Work(ctx)
+		log.Printf("Index %s: %+v", a, a.Stats())
+	})
+
+	return a
+}
+
+// getCoalescingIndexes returns indexes merging updates to the same document within the configured window.
+// Write amplification counters are logged on exit.
+func (w *Pool) getCoalescingIndexes(ctx context.Context, backing *crawler.Indexes) *crawler.Indexes {
+	cfg := coalesce.DefaultConfig()
+	cfg.Window = w.config.Indexes.CoalesceWindow
+
+	coalescing := []*coalesce.Index{
+		coalesce.New(backing.Files, cfg, w.Instrumentation),
+		coalesce.New(backing.Directories, cfg, w.Instrumentation),
+		coalesce.New(backing.Invalids, cfg, w.Instrumentation),
+		coalesce.New(backing.Partials, cfg, w.Instrumentation),
+		coalesce.New(backing.References, cfg, w.Instrumentation),
+		coalesce.New(backing.Pages, cfg, w.Instrumentation),
+		coalesce.New(backing.Names, cfg, w.Instrumentation),
+		coalesce.New(backing.Domains, cfg, w.Instrumentation),
+		coalesce.New(backing.IPLD, cfg, w.Instrumentation),
+	}
+
+	for _, c := range coalescing {
+		c := c
+
+		w.onFlush(c.Flush)
+		w.goBackground(func() {
+			c.Work(ctx)
+			log.Printf("Index %s: %+v, saved %d writes", c, c.Stats(), c.Stats().Saved())
+		})
+	}
+
+	return &crawler.Indexes{
+		Files:       coalescing[0],
+		Directories: coalescing[1],
+		Invalids:    coalescing[2],
+		Partials:    coalescing[3],
+		References:  coalescing[4],
+		Pages:       coalescing[5],
+		Names:       coalescing[6],
+		Domains:     coalescing[7],
+		IPLD:        coalescing[8],
+		Checkpoints: backing.Checkpoints,
+		Providers:   backing.Providers,
+	}
+}
+
+// getMultiIndexes returns indexes writing to both primary and mirror indexes, reading from the primary.
+// Errors from the mirror are logged and counted, counters are logged on exit.
+func (w *Pool) getMultiIndexes(ctx context.Context, primary, mirror *crawler.Indexes) *crawler.Indexes {
+	newMulti := func(p, m index.Index) *multi.Index {
+		return multi.New(p, []multi.Target{{
+			Index:    m,
+			Policy:   multi.IgnoreErrors,
+			Fallback: w.config.Indexes.MirrorFallback,
+		}}, w.Instrumentation)
+	}
+
+	multis := []*multi.Index{
+		newMulti(primary.Files, mirror.Files),
+		newMulti(primary.Directories, mirror.Directories),
+		newMulti(primary.Invalids, mirror.Invalids),
+		newMulti(primary.Partials, mirror.Partials),
+		newMulti(primary.References, mirror.References),
+		newMulti(primary.Pages, mirror.Pages),
+		newMulti(primary.Names, mirror.Names),
+		newMulti(primary.Domains, mirror.Domains),
+		newMulti(primary.IPLD, mirror.IPLD),
+		newMulti(primary.Providers, mirror.Providers),
+	}
+
+	w.goBackground(func() {
+		<-ctx.Done()
+
+		for _, m := range multis {
+			log.Printf("Index %s: %+v", m, m.Stats())
+		}
+	})
+
+	return &crawler.Indexes{
+		Files:       multis[0],
+		Directories: multis[1],
+		Invalids:    multis[2],
+		Partials:    multis[3],
+		References:  multis[4],
+		Pages:       multis[5],
+		Names:       multis[6],
+		Domains:     multis[7],
+		IPLD:        multis[8],
+		Providers:   multis[9],
+		Checkpoints: primary.Checkpoints,
+	}
+}
+
+// getBackendIndexes returns indexes for the given backend.
+func (w *Pool) getBackendIndexes(ctx context.Context, backend string) (*crawler.Indexes, error) {
+	switch backend {
+	case config.OpenSearchBackend:
+		return w.getOpenSearchIndexes(ctx)
+	case config.BleveBackend:
+		return w.getBleveIndexes(ctx)
+	case config.SQLiteBackend:
+		return w.getSQLiteIndexes(ctx)
+	case config.MeilisearchBackend:
+		return w.getMeilisearchIndexes(ctx)
+	default:
+		return nil, fmt.Errorf("unknown index backend: %s", backend)
+	}
+}
+
+// getBleveIndexes returns local, embedded indexes; no OpenSearch or Redis required.
+func (w *Pool) getBleveIndexes(ctx context.Context) (*crawler.Indexes, error) {
+	client, err := bleve.NewClient(w.config.BleveConfig(), w.Instrumentation)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := w.config.Indexes
+	indexes := &crawler.Indexes{}
+
+	for _, i := range []struct {
+		dst     *index.Index
+		name    string
+		mapping func() mapping.IndexMapping
+	}{
+		{&indexes.Files, cfg.Files.Name, bleve.FilesMapping},
+		{&indexes.Directories, cfg.Directories.Name, bleve.DirectoriesMapping},
+		{&indexes.Invalids, cfg.Invalids.Name, bleve.InvalidsMapping},
+		{&indexes.Partials, cfg.Partials.Name, bleve.PartialsMapping},
+		{&indexes.References, cfg.References.Name, bleve.ReferencesMapping},
+		{&indexes.Pages, cfg.Pages.Name, bleve.PagesMapping},
+		{&indexes.Names, cfg.Names.Name, bleve.NamesMapping},
+		{&indexes.Domains, cfg.Domains.Name, bleve.NamesMapping},
+		{&indexes.IPLD, cfg.IPLD.Name, bleve.IPLDMapping},
+		{&indexes.Providers, cfg.Providers.Name, bleve.ProvidersMapping},
+	} {
+		idx, err := client.NewIndex(i.name, i.mapping())
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+
+		*i.dst = idx
+	}
+
+	w.goBackground(func() {
+		<-ctx.Done()
+		client.Close()
+	})
+
+	return indexes, nil
+}
+
+// getSQLiteIndexes returns indexes stored in a single SQLite database; no OpenSearch or Redis required.
+func (w *Pool) getSQLiteIndexes(ctx context.Context) (*crawler.Indexes, error) {
+	client, err := sqlite.NewClient(w.config.SQLiteConfig(), w.Instrumentation)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := w.config.Indexes
+	indexes := &crawler.Indexes{}
+
+	for _, i := range []struct {
+		dst  *index.Index
+		name string
+	}{
+		{&indexes.Files, cfg.Files.Name},
+		{&indexes.Directories, cfg.Directories.Name},
+		{&indexes.Invalids, cfg.Invalids.Name},
+		{&indexes.Partials, cfg.Partials.Name},
+		{&indexes.References, cfg.References.Name},
+		{&indexes.Pages, cfg.Pages.Name},
+		{&indexes.Names, cfg.Names.Name},
+		{&indexes.Domains, cfg.Domains.Name},
+		{&indexes.IPLD, cfg.IPLD.Name},
+		{&indexes.Providers, cfg.Providers.Name},
+	} {
+		idx, err := client.NewIndex(ctx, i.name)
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+
+		*i.dst = idx
+	}
+
+	w.goBackground(func() {
+		<-ctx.Done()
+		client.Close()
+	})
+
+	return indexes, nil
+}
+
+// getMeilisearchIndexes returns indexes stored in Meilisearch; writes and gets are batched by the client.
+func (w *Pool) getMeilisearchIndexes(ctx context.Context) (*crawler.Indexes, error) {
+	cfg := w.config.MeilisearchConfig()
+	cfg.Transport = utils.GetHTTPTransport(w.dialer.DialContext, 100)
+
+	client, err := meilisearch.NewClient(cfg, w.Instrumentation)
+	if err != nil {
+		return nil, err
+	}
+
+	indexCfg := w.config.Indexes
+	indexes := &crawler.Indexes{}
+
+	for _, i := range []struct {
+		dst      *index.Index
+		name     string
+		settings func() *meilisearch.Settings
+	}{
+		{&indexes.Files, indexCfg.Files.Name, meilisearch.FilesSettings},
+		{&indexes.Directories, indexCfg.Directories.Name, meilisearch.DirectoriesSettings},
+		{&indexes.Invalids, indexCfg.Invalids.Name, meilisearch.InvalidsSettings},
+		{&indexes.Partials, indexCfg.Partials.Name, meilisearch.PartialsSettings},
+		{&indexes.References, indexCfg.References.Name, meilisearch.ReferencesSettings},
+		{&indexes.Pages, indexCfg.Pages.Name, meilisearch.PagesSettings},
+		{&indexes.Names, indexCfg.Names.Name, meilisearch.NamesSettings},
+		{&indexes.Domains, indexCfg.Domains.Name, meilisearch.NamesSettings},
+		{&indexes.IPLD, indexCfg.IPLD.Name, meilisearch.IPLDSettings},
+		{&indexes.Providers, indexCfg.Providers.Name, meilisearch.ProvidersSettings},
+	} {
+		if err := client.CreateIndex(ctx, i.name, i.settings()); err != nil {
+			return nil, err
+		}
+
+		*i.dst = client.NewIndex(i.name)
+	}
+
+	w.goBackground(func() { osWorkLoop(ctx, client.Work) })
+
+	return indexes, nil
+}
+
+// getOpenSearchMirrorIndexes returns uncached indexes in the mirror cluster, or else in the configured cluster.
+func (w *Pool) getOpenSearchMirrorIndexes(ctx context.Context) (*crawler.Indexes, error) {
+	os, err := w.getOpenSearchClient(w.config.OpenSearch.Mirror.URL != "")
+	if err != nil {
+		return nil, err
+	}
+
+	w.goBackground(func() { osWorkLoop(ctx, os.Work) })
+
+	cfg := w.config.Indexes
+	limits := w.referenceLimits()
+
+	return &crawler.Indexes{
+		Files:       os.NewReferencedIndex(cfg.Files.Name, limits),
+		Directories: os.NewReferencedIndex(cfg.Directories.Name, limits),
+		Invalids:    os.NewIndex(cfg.Invalids.Name),
+		Partials:    os.NewIndex(cfg.Partials.Name),
+		IPLD:        os.NewReferencedIndex(cfg.IPLD.Name, limits),
+		References:  os.NewIndex(cfg.References.Name),
+		Pages:       os.NewIndex(cfg.Pages.Name),
+		Names:       os.NewIndex(cfg.Names.Name),
+		Domains:     os.NewIndex(cfg.Domains.Name),
+		Providers:   os.NewIndex(cfg.Providers.Name),
+	}, nil
+}
+
+func (w *Pool) getOpenSearchIndexes(ctx context.Context) (*crawler.Indexes, error) {
+	os, err := w.getOpenSearchClient(false)
 	if err != nil {
 		return nil, err
 	}
@@ -84,29 +469,30 @@ func (w *Pool) getIndexes(ctx context.Context) (*crawler.Indexes, error) {
 		return nil, err
 	}
 
-	go osWorkLoop(ctx, os.Work)
+	w.goBackground(func() { osWorkLoop(ctx, os.Work) })
 
 	if err := redis.Start(ctx); err != nil {
 		return nil, err
 	}
 
-	go func() {
+	w.goBackground(func() {
 		<-ctx.Done()
 		redis.Close(ctx)
-	}()
+	})
 
 	cfg := w.config.Indexes
+	limits := w.referenceLimits()
 
 	return &crawler.Indexes{
 		Files: cache.New(
-			os.NewIndex(cfg.Files.Name),
-			redis.NewIndex(cfg.Files.Name, cfg.Files.Prefix, false),
+			os.NewReferencedIndex(cfg.Files.Name, limits),
+			redis.NewReferencedIndex(cfg.Files.Name, cfg.Files.Prefix, limits),
 			indexTypes.Update{},
 			w.Instrumentation,
 		),
 		Directories: cache.New(
-			os.NewIndex(cfg.Directories.Name),
-			redis.NewIndex(cfg.Directories.Name, cfg.Directories.Prefix, false),
+			os.NewReferencedIndex(cfg.Directories.Name, limits),
+			redis.NewReferencedIndex(cfg.Directories.Name, cfg.Directories.Prefix, limits),
 			indexTypes.Update{},
 			w.Instrumentation,
 		),
@@ -122,5 +508,17 @@ func (w *Pool) getIndexes(ctx context.Context) (*crawler.Indexes, error) {
 			struct{}{},
 			w.Instrumentation,
 		),
+		IPLD: cache.New(
+			os.NewReferencedIndex(cfg.IPLD.Name, limits),
+			redis.NewReferencedIndex(cfg.IPLD.Name, cfg.IPLD.Prefix, limits),
+			indexTypes.Update{},
+			w.Instrumentation,
+		),
+		References:  os.NewIndex(cfg.References.Name),
+		Pages:       os.NewIndex(cfg.Pages.Name),
+		Names:       os.NewIndex(cfg.Names.Name),
+		Domains:     os.NewIndex(cfg.Domains.Name),
+		Providers:   os.NewIndex(cfg.Providers.Name),
+		Checkpoints: redis.NewExpiringIndex(cfg.Checkpoints.Name, cfg.Checkpoints.Prefix, checkpointTTL),
 	}, nil
 }
@@ -1,16 +1,96 @@
 package pool
 
 import (
+	"context"
+	"errors"
+	"log"
+	"net"
 	"net/http"
 
+	"github.com/ipfs-search/ipfs-search/components/dnslink"
 	"github.com/ipfs-search/ipfs-search/components/protocol"
 	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs"
+	"github.com/ipfs-search/ipfs-search/components/protocol/offline"
+	"github.com/ipfs-search/ipfs-search/components/protocol/trustless"
 	"github.com/ipfs-search/ipfs-search/utils"
 )
 
-func (p *Pool) getProtocol() protocol.Protocol {
+// getProtocol returns the IPFS protocol or, when a blockstore is configured, the offline protocol and, when a
+// trustless gateway is configured, the trustless protocol; DNSLink domains are resolved through the configured DNS
+// server, when set, and through IPFS otherwise.
+func (p *Pool) getProtocol(ctx context.Context) (protocol.Protocol, error) {
+	var protocol protocol.Protocol
+
 	ipfsTransport := utils.GetHTTPTransport(p.dialer.DialContext, p.config.Workers.MaxIPFSConns)
 	ipfsClient := &http.Client{Transport: ipfsTransport}
 
-	return ipfs.New(p.config.IPFSConfig(), ipfsClient, p.Instrumentation)
+	switch {
+	case p.config.Offline.Blockstore != "":
+		var err error
+		if protocol, err = p.getOfflineProtocol(ctx); err != nil {
+			return nil, err
+		}
+	case p.config.Trustless.URL != "":
+		t := trustless.New(p.config.TrustlessConfig(), ipfsClient, p.Instrumentation)
+
+		log.Printf("Using trustless gateway %s.", p.config.Trustless.URL)
+		if err := serveGateway(ctx, p.config.Trustless.GatewayAddr, t); err != nil {
+			return nil, err
+		}
+
+		protocol = t
+	default:
+		protocol = ipfs.New(p.config.IPFSConfig(), ipfsClient, p.Instrumentation)
+	}
+
+	if cfg := p.config.DNSLinkConfig(); cfg.Server != "" {
+		return dnslink.New(cfg).Wrap(protocol), nil
+	}
+
+	return protocol, nil
+}
+
+// getOfflineProtocol opens the configured blockstore and returns the offline protocol for it, serving content
+// through a gateway until ctx is done.
+func (p *Pool) getOfflineProtocol(ctx context.Context) (protocol.Protocol, error) {
+	cfg := p.config.Offline
+
+	log.Printf("Opening blockstore %s.", cfg.Blockstore)
+	bs, err := offline.Open(cfg.Blockstore)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := serveGateway(ctx, cfg.GatewayAddr, bs); err != nil {
+		return nil, err
+	}
+
+	return offline.New(p.config.OfflineConfig(), bs, p.Instrumentation), nil
+}
+
+// serveGateway serves the content of bs on addr until ctx is done, after which bs is closed.
+func serveGateway(ctx context.Context, addr string, bs offline.Blockstore) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		bs.Close()
+		return err
+	}
+
+	srv := &http.Server{Handler: offline.NewGateway(bs)}
+
+	go func() {
+		log.Printf("Serving gateway on %s.", l.Addr())
+
+		if err := srv.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Error serving gateway: %s", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+		bs.Close()
+	}()
+
+	return nil
 }
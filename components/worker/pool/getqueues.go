@@ -7,16 +7,21 @@ import (
 	samqp "github.com/rabbitmq/amqp091-go"
 
 	"github.com/ipfs-search/ipfs-search/components/crawler"
+	"github.com/ipfs-search/ipfs-search/components/queue"
 	"github.com/ipfs-search/ipfs-search/components/queue/amqp"
 )
 
-func (p *Pool) getQueues(ctx context.Context) (*crawler.Queues, error) {
+func (p *Pool) getAMQPConnection(ctx context.Context) (*amqp.Connection, error) {
 	amqpConfig := &samqp.Config{
 		Dial: p.dialer.Dial,
 	}
 
 	log.Println("Connecting to AMQP.")
-	amqpConnection, err := amqp.NewConnection(ctx, p.config.AMQPConfig(), amqpConfig, p.Instrumentation)
+	return amqp.NewConnection(ctx, p.config.AMQPConfig(), amqpConfig, p.Instrumentation)
+}
+
+func (p *Pool) getQueues(ctx context.Context) (*crawler.Queues, error) {
+	amqpConnection, err := p.getAMQPConnection(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -43,3 +48,18 @@ func (p *Pool) getQueues(ctx context.Context) (*crawler.Queues, error) {
 		Hashes:      hq,
 	}, nil
 }
+
+// getProvidersQueue returns the queue of aggregated provider announcements.
+func (p *Pool) getProvidersQueue(ctx context.Context) (queue.Queue, error) {
+	amqpConnection, err := p.getAMQPConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := amqpConnection.NewChannelQueue(ctx, p.config.Queues.Providers.Name, p.config.Workers.ProviderWorkers)
+	if err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"sync"
 	"time"
 
 	samqp "github.com/rabbitmq/amqp091-go"
 
 	"github.com/ipfs-search/ipfs-search/components/crawler"
+	"github.com/ipfs-search/ipfs-search/components/names"
+	"github.com/ipfs-search/ipfs-search/components/providers"
 	"github.com/ipfs-search/ipfs-search/components/worker"
 	"github.com/ipfs-search/ipfs-search/config"
 	"github.com/ipfs-search/ipfs-search/instr"
@@ -21,6 +24,7 @@ type consumeChans struct {
 	Files       <-chan samqp.Delivery
 	Directories <-chan samqp.Delivery
 	Hashes      <-chan samqp.Delivery
+	Providers   <-chan samqp.Delivery // 可选，仅在记录提供者时消费
 }
 
 // Pool 表示一个池的集合。
@@ -28,6 +32,13 @@ type Pool struct {
 	config  *config.Config
 	dialer  *utils.RetryingDialer
 	crawler *crawler.Crawler
+	indexes *crawler.Indexes
+
+	providers *providers.Indexer // 可选的提供者公告索引器
+	names     *names.Refresher   // 名称刷新器
+
+	flushers   []func(context.Context) // 后台组件的缓冲刷新函数，按索引由下至上的顺序注册
+	background sync.WaitGroup          // 退出时仍会写入的后台组件
 
 	*consumeChans
 	*instr.Instrumentation
@@ -55,13 +66,56 @@ func (p *Pool) Start(ctx context.Context) {
 	p.startWorkers(ctx, p.consumeChans.Files, p.config.Workers.FileWorkers, "files")
 	p.startWorkers(ctx, p.consumeChans.Hashes, p.config.Workers.HashWorkers, "hashes")
 	p.startWorkers(ctx, p.consumeChans.Directories, p.config.Workers.DirectoryWorkers, "directories")
+
+	if p.providers != nil {
+		p.startProvidersWorkers(ctx, p.consumeChans.Providers, p.config.Workers.ProviderWorkers)
+	}
+
+	// 重新安排重启前已安排刷新的名称
+	p.goBackground(func() {
+		p.loadNames(ctx)
+	})
 }
 
-// init 初始化 Pool 对象
-func (p *Pool) init(ctx context.Context) error {
-	var err error
+// startProvidersWorkers 启动指定数量的 worker 来索引提供者公告
+func (p *Pool) startProvidersWorkers(ctx context.Context, deliveries <-chan samqp.Delivery, workers int) {
+	log.Printf("Starting %d workers for providers", workers)
+
+	for i := 0; i < workers; i++ {
+		name := fmt.Sprintf("providers-%d", i)
+		worker := worker.NewProviders(name, p.providers, p.Instrumentation)
+		go worker.Start(ctx, deliveries)
+	}
+}
+
+// onFlush 注册后台组件的缓冲刷新函数；组件写入先注册的组件，因此刷新时按注册的相反顺序进行。
+func (p *Pool) onFlush(flush func(context.Context)) {
+	p.flushers = append(p.flushers, flush)
+}
+
+// goBackground 在后台运行 f，关闭时等待其返回。
+func (p *Pool) goBackground(f func()) {
+	p.background.Add(1)
+
+	go func() {
+		defer p.background.Done()
+		f()
+	}()
+}
+
+// shutdown 由上至下刷新后台组件的缓冲，随后调用 cancel 停止后台组件，并等待其写入剩余的更新后退出。
+func (p *Pool) shutdown(ctx context.Context, cancel context.CancelFunc) {
+	for i := len(p.flushers) - 1; i >= 0; i-- {
+		p.flushers[i](ctx)
+	}
 
-	p.dialer = &utils.RetryingDialer{
+	cancel()
+	p.background.Wait()
+}
+
+// newDialer 返回带重试的拨号器
+func newDialer(ctx context.Context) *utils.RetryingDialer {
+	return &utils.RetryingDialer{
 		Dialer: net.Dialer{
 			Timeout:   30 * time.Second,
 			KeepAlive: 30 * time.Second,
@@ -69,6 +123,13 @@ func (p *Pool) init(ctx context.Context) error {
 		},
 		Context: ctx,
 	}
+}
+
+// init 初始化 Pool 对象
+func (p *Pool) init(ctx context.Context) error {
+	var err error
+
+	p.dialer = newDialer(ctx)
 
 	log.Println("Initializing crawler.")
 	if p.crawler, err = p.getCrawler(ctx); err != nil {
@@ -102,3 +163,28 @@ func New(ctx context.Context, c *config.Config, i *instr.Instrumentation) (*Pool
 
 	return p, err
 }
+
+// NewCrawler 返回与工作池配置相同、但不消费队列的 Crawler，用于直接爬取资源（如 import-car）。
+// 后台组件（索引缓冲、名称刷新等）运行至 ctx 结束，或至调用返回的 close 函数为止；close 刷新所有缓冲的更新，
+// 并在后台组件退出后返回。
+func NewCrawler(ctx context.Context, c *config.Config, i *instr.Instrumentation) (*crawler.Crawler, func(context.Context), error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	p := &Pool{
+		config:          c,
+		dialer:          newDialer(ctx),
+		Instrumentation: i,
+	}
+
+	crawler, err := p.getCrawler(ctx)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	close := func(flushCtx context.Context) {
+		p.shutdown(flushCtx, cancel)
+	}
+
+	return crawler, close, nil
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	samqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ipfs-search/ipfs-search/components/providers"
+	"github.com/ipfs-search/ipfs-search/instr"
+	t "github.com/ipfs-search/ipfs-search/types"
+)
+
+// ProvidersWorker indexes aggregated provider announcements from a queue.
+type ProvidersWorker struct {
+	name    string
+	indexer *providers.Indexer
+
+	*instr.Instrumentation
+}
+
+// NewProviders returns a new worker for provider announcements.
+func NewProviders(name string, indexer *providers.Indexer, i *instr.Instrumentation) *ProvidersWorker {
+	return &ProvidersWorker{
+		name, indexer, i,
+	}
+}
+
+// Start indexing deliveries, synchronously.
+func (w *ProvidersWorker) Start(ctx context.Context, deliveries <-chan samqp.Delivery) {
+	ctx, span := w.Tracer.Start(ctx, "crawler.pool.startProvidersWorker")
+	defer span.End()
+
+	consume(ctx, span, deliveries, w.indexDelivery)
+}
+
+func (w *ProvidersWorker) indexDelivery(ctx context.Context, d samqp.Delivery) error {
+	ctx, span := w.Tracer.Start(ctx, "crawler.pool.indexDelivery", trace.WithNewRoot())
+	defer span.End()
+
+	a := &providers.Announcements{
+		Resource: &t.Resource{},
+	}
+
+	if err := json.Unmarshal(d.Body, a); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if !a.IsValid() {
+		err := fmt.Errorf("Invalid resource: %v", a.Resource)
+		span.RecordError(err)
+		return err
+	}
+
+	err := w.indexer.Index(ctx, a)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
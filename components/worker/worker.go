@@ -3,6 +3,7 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 
@@ -10,6 +11,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/ipfs-search/ipfs-search/components/crawler"
+	"github.com/ipfs-search/ipfs-search/components/index"
 	"github.com/ipfs-search/ipfs-search/instr"
 	t "github.com/ipfs-search/ipfs-search/types"
 )
@@ -34,6 +36,11 @@ func (w *Worker) Start(ctx context.Context, deliveries <-chan samqp.Delivery) {
 	ctx, span := w.Tracer.Start(ctx, "crawler.pool.startWorker")
 	defer span.End()
 
+	consume(ctx, span, deliveries, w.crawlDelivery)
+}
+
+// consume handles deliveries until ctx is done, acking them on success and rejecting them otherwise.
+func consume(ctx context.Context, span trace.Span, deliveries <-chan samqp.Delivery, handle func(context.Context, samqp.Delivery) error) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -43,9 +50,9 @@ func (w *Worker) Start(ctx context.Context, deliveries <-chan samqp.Delivery) {
 				// This is a fatal error; it should never happen - crash the program!
 				panic("unexpected channel close")
 			}
-			if err := w.crawlDelivery(ctx, d); err != nil {
-				// By default, do not retry.
-				shouldRetry := false
+			if err := handle(ctx, d); err != nil {
+				// Retry temporary errors (e.g. failed synchronous index writes), once.
+				shouldRetry := errors.Is(err, index.ErrTemporary) && !d.Redelivered
 
 				span.RecordError(err)
 
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/index/bleve"
+)
+
+// Bleve 结构体保存了嵌入式 Bleve 索引的配置。
+type Bleve struct {
+	Path string `yaml:"path" env:"BLEVE_PATH" optional:"true"` // 存放 Bleve 索引的目录，从 YAML 文件或环境变量读取；为空时使用内存索引。
+}
+
+// BleveConfig 方法从中央配置中返回组件特定的配置。
+func (c *Config) BleveConfig() *bleve.ClientConfig {
+	cfg := bleve.ClientConfig(c.Bleve)
+	return &cfg
+}
+
+// BleveDefaults 函数返回组件配置的默认值，基于组件特定的配置。
+func BleveDefaults() Bleve {
+	return Bleve(*bleve.DefaultClientConfig())
+}
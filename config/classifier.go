@@ -0,0 +1,21 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/classifier"
+)
+
+// Classifier 结构体保存了目录分类器的配置。
+type Classifier struct {
+	Rules string `yaml:"rules" env:"CLASSIFIER_RULES" optional:"true"` // 分类规则 YAML 文件路径；为空时使用内置规则。
+}
+
+// ClassifierConfig 方法从中央配置中返回组件特定的配置。
+func (c *Config) ClassifierConfig() *classifier.Config {
+	cfg := classifier.Config(c.Classifier)
+	return &cfg
+}
+
+// ClassifierDefaults 函数返回组件配置的默认值，基于组件特定的配置。
+func ClassifierDefaults() Classifier {
+	return Classifier(*classifier.DefaultConfig())
+}
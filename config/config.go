@@ -33,19 +33,28 @@ import (
 
 // Config 聚合所有组件配置的顶级结构
 type Config struct {
-	IPFS       `yaml:"ipfs"`       // IPFS节点配置
-	OpenSearch `yaml:"opensearch"` // OpenSearch配置
-	Redis      `yaml:"redis"`      // Redis配置
-	AMQP       `yaml:"amqp"`       // RabbitMQ配置
-	Tika       `yaml:"tika"`       // Tika文本解析服务配置
-	NSFW       `yaml:"nsfw"`       // NSFW内容检测配置
-
-	Instr   `yaml:"instrumentation"` // 监控指标配置
-	Crawler `yaml:"crawler"`         // 爬虫组件配置
-	Sniffer `yaml:"sniffer"`         // 嗅探器配置
-	Indexes `yaml:"indexes"`         // 索引定义
-	Queues  `yaml:"queues"`          // 消息队列定义
-	Workers `yaml:"workers"`         // 工作线程池配置
+	IPFS        `yaml:"ipfs"`        // IPFS节点配置
+	Offline     `yaml:"offline"`     // 离线协议配置
+	Trustless   `yaml:"trustless"`   // 无信任网关协议配置
+	OpenSearch  `yaml:"opensearch"`  // OpenSearch配置
+	Redis       `yaml:"redis"`       // Redis配置
+	Bleve       `yaml:"bleve"`       // 嵌入式Bleve索引配置
+	SQLite      `yaml:"sqlite"`      // SQLite索引配置
+	Meilisearch `yaml:"meilisearch"` // Meilisearch索引配置
+	Graph       `yaml:"graph"`       // 父子边存储配置
+	AMQP        `yaml:"amqp"`        // RabbitMQ配置
+	Tika        `yaml:"tika"`        // Tika文本解析服务配置
+	NSFW        `yaml:"nsfw"`        // NSFW内容检测配置
+
+	Instr      `yaml:"instrumentation"` // 监控指标配置
+	Crawler    `yaml:"crawler"`         // 爬虫组件配置
+	Classifier `yaml:"classifier"`      // 目录分类器配置
+	DNSLink    `yaml:"dnslink"`         // DNSLink 域名解析配置
+	Sniffer    `yaml:"sniffer"`         // 嗅探器配置
+	Providers  `yaml:"providers"`       // 提供者记录配置
+	Indexes    `yaml:"indexes"`         // 索引定义
+	Queues     `yaml:"queues"`          // 消息队列定义
+	Workers    `yaml:"workers"`         // 工作线程池配置
 }
 
 // 将Config序列化为YAML字符串（调试用）
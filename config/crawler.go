@@ -7,11 +7,18 @@ import (
 
 // Crawler contains configuration for a Crawler.
 type Crawler struct {
-	DirEntryBufferSize uint          `yaml:"direntry_buffer_size"` // 处理目录条目通道的缓冲区大小。
-	MinUpdateAge       time.Duration `yaml:"min_update_age"`       // 项目更新的最小时间间隔。
-	StatTimeout        time.Duration `yaml:"stat_timeout"`         // Stat() 调用的超时时间。
-	DirEntryTimeout    time.Duration `yaml:"direntry_timeout"`     // 目录条目之间的超时时间。
-	MaxDirSize         uint          `yaml:"max_dirsize"`          // 目录条目的最大数量。
+	DirEntryBufferSize    uint          `yaml:"direntry_buffer_size"`                    // 处理目录条目通道的缓冲区大小。
+	MinUpdateAge          time.Duration `yaml:"min_update_age"`                          // 项目更新的最小时间间隔。
+	StatTimeout           time.Duration `yaml:"stat_timeout"`                            // Stat() 调用的超时时间。
+	DirEntryTimeout       time.Duration `yaml:"direntry_timeout"`                        // 目录条目之间的超时时间。
+	MaxDirSize            uint          `yaml:"max_dirsize"`                             // 目录条目的最大数量，超出时分页索引。
+	DirPageSize           uint          `yaml:"dir_page_size"`                           // 大目录每页的条目数量。
+	DirCheckpointInterval uint          `yaml:"dir_checkpoint_interval" optional:"true"` // 每处理此数量的目录条目保存一次列表进度，为 0 时仅在出错时保存。
+	MaxReferences         uint          `yaml:"max_references" optional:"true"`          // 存储的最大引用数量（0 表示不限制），超出时仅计数，所有引用存入引用索引。
+	MaxReferenceNames     uint          `yaml:"max_reference_names"`                     // 引用超出上限时，采样保存的不同名称的最大数量。
+	NameRefreshInterval   time.Duration `yaml:"name_refresh_interval"`                   // 经过此时间后重新解析 IPNS 名称。
+	MaxNameHistory        uint          `yaml:"max_name_history"`                        // IPNS 名称保存的历史目标的最大数量。
+	MaxDAGLinks           uint          `yaml:"max_dag_links" optional:"true"`           // 每个 IPLD 文档索引并入队的最大链接数量（0 表示不限制）。
 }
 
 // CrawlerConfig 方法从中央配置中返回组件特定的配置。
@@ -4,14 +4,23 @@ package config
 func Default() *Config {
 	return &Config{
 		IPFSDefaults(),
+		OfflineDefaults(),
+		TrustlessDefaults(),
 		OpenSearchDefaults(),
 		RedisDefaults(),
+		BleveDefaults(),
+		SQLiteDefaults(),
+		MeilisearchDefaults(),
+		GraphDefaults(),
 		AMQPDefaults(),
 		TikaDefaults(),
 		NSFWDefaults(),
 		InstrDefaults(),
 		CrawlerDefaults(),
+		ClassifierDefaults(),
+		DNSLinkDefaults(),
 		SnifferDefaults(),
+		ProvidersDefaults(),
 		IndexesDefaults(),
 		QueuesDefaults(),
 		WorkersDefaults(),
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/dnslink"
+)
+
+// DNSLink 结构体保存了 DNSLink 域名解析的配置。
+type DNSLink struct {
+	Server  string        `yaml:"server" env:"DNSLINK_SERVER" optional:"true"` // 查询 TXT 记录的 DNS 服务器（host:port）；为空时通过 IPFS API 解析域名。
+	Timeout time.Duration `yaml:"timeout"`                                     // DNS 查询的超时时间。
+}
+
+// DNSLinkConfig 方法从中央配置中返回组件特定的配置。
+func (c *Config) DNSLinkConfig() *dnslink.Config {
+	cfg := dnslink.Config(c.DNSLink)
+	return &cfg
+}
+
+// DNSLinkDefaults 函数返回组件配置的默认值，基于组件特定的配置。
+func DNSLinkDefaults() DNSLink {
+	return DNSLink(*dnslink.DefaultConfig())
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/graph/sqlite"
+)
+
+// Graph 结构体保存了边存储的配置。
+type Graph struct {
+	Path string `yaml:"path" env:"GRAPH_PATH" optional:"true"` // 边存储 SQLite 数据库文件路径；为空时不存储边。
+}
+
+// GraphConfig 方法从中央配置中返回组件特定的配置。
+func (c *Config) GraphConfig() *sqlite.Config {
+	cfg := sqlite.Config(c.Graph)
+	return &cfg
+}
+
+// GraphDefaults 函数返回组件配置的默认值，基于组件特定的配置。
+func GraphDefaults() Graph {
+	return Graph(*sqlite.DefaultConfig())
+}
@@ -1,5 +1,17 @@
 package config
 
+import (
+	"time"
+)
+
+// 可选的索引后端。
+const (
+	OpenSearchBackend  = "opensearch"  // OpenSearch，以 Redis 作为缓存。
+	BleveBackend       = "bleve"       // 嵌入式 Bleve 索引，无需外部服务。
+	SQLiteBackend      = "sqlite"      // SQLite 数据库，带 FTS5 全文搜索。
+	MeilisearchBackend = "meilisearch" // Meilisearch 服务，写入和读取均批量进行。
+)
+
 // Index 结构体表示单个索引的配置。
 type Index struct {
 	Name   string // 索引的名称。
@@ -8,15 +20,29 @@ type Index struct {
 
 // Indexes 结构体表示我们正在使用的各种索引。
 type Indexes struct {
-	Files       Index `yaml:"files"`       // 文件索引的配置。
-	Directories Index `yaml:"directories"` // 目录索引的配置。
-	Invalids    Index `yaml:"invalids"`    // 无效条目索引的配置。
-	Partials    Index `yaml:"partials"`    // 部分条目索引的配置。
+	Backend        string        `yaml:"backend" env:"INDEX_BACKEND"`                                 // 使用的索引后端，opensearch、bleve、sqlite 或 meilisearch。
+	Mirror         string        `yaml:"mirror" env:"INDEX_MIRROR" optional:"true"`                   // 可选，同时写入的第二个索引后端，其错误只记录不返回。
+	MirrorFallback bool          `yaml:"mirror_fallback" optional:"true"`                             // 在主后端未找到文档时从镜像后端读取。
+	CoalesceWindow time.Duration `yaml:"coalesce_window" env:"INDEX_COALESCE_WINDOW" optional:"true"` // 可选，在此时间内合并对同一文档的更新；为 0 时不合并。
+	Attribution    time.Duration `yaml:"attribution" env:"INDEX_ATTRIBUTION" optional:"true"`         // 可选，每隔此时间为新增引用的文件解析完整路径和根目录；为 0 时不解析。
+	Sites          time.Duration `yaml:"sites" env:"INDEX_SITES" optional:"true"`                     // 可选，每隔此时间索引被分类为网站的目录中的页面标题、链接和 PageRank；为 0 时不索引。
+	Files          Index         `yaml:"files"`                                                       // 文件索引的配置。
+	Directories    Index         `yaml:"directories"`                                                 // 目录索引的配置。
+	Invalids       Index         `yaml:"invalids"`                                                    // 无效条目索引的配置。
+	Partials       Index         `yaml:"partials"`                                                    // 部分条目索引的配置。
+	References     Index         `yaml:"references"`                                                  // 超出上限的引用边索引的配置。
+	Pages          Index         `yaml:"pages"`                                                       // 大目录链接分页索引的配置。
+	Checkpoints    Index         `yaml:"checkpoints"`                                                 // 目录列举进度检查点的配置，仅存于 Redis。
+	IPLD           Index         `yaml:"ipld"`                                                        // IPLD 文档索引的配置。
+	Names          Index         `yaml:"names"`                                                       // IPNS 名称索引的配置。
+	Domains        Index         `yaml:"domains"`                                                     // DNSLink 域名索引的配置。
+	Providers      Index         `yaml:"providers"`                                                   // 提供者记录索引的配置。
 }
 
 // IndexesDefaults 函数返回默认的索引配置。
 func IndexesDefaults() Indexes {
 	return Indexes{
+		Backend: OpenSearchBackend, // 默认使用 OpenSearch。
 		Files: Index{
 			Name:   "ipfs_files", // 文件索引的默认名称。
 			Prefix: "f",          // 文件索引的默认前缀。
@@ -33,5 +59,33 @@ func IndexesDefaults() Indexes {
 			Name:   "ipfs_partials", // 部分条目索引的默认名称。
 			Prefix: "p",             // 部分条目索引的默认前缀。
 		},
+		References: Index{
+			Name:   "ipfs_references", // 引用边索引的默认名称。
+			Prefix: "r",               // 引用边索引的默认前缀。
+		},
+		Pages: Index{
+			Name:   "ipfs_directory_pages", // 目录分页索引的默认名称。
+			Prefix: "g",                    // 目录分页索引的默认前缀。
+		},
+		Checkpoints: Index{
+			Name:   "ipfs_checkpoints", // 检查点索引的默认名称。
+			Prefix: "l",                // 检查点索引的默认前缀。
+		},
+		IPLD: Index{
+			Name:   "ipfs_ipld", // IPLD 文档索引的默认名称。
+			Prefix: "b",         // IPLD 文档索引的默认前缀。
+		},
+		Names: Index{
+			Name:   "ipfs_names", // 名称索引的默认名称。
+			Prefix: "n",          // 名称索引的默认前缀。
+		},
+		Domains: Index{
+			Name:   "ipfs_domains", // 域名索引的默认名称。
+			Prefix: "m",            // 域名索引的默认前缀。
+		},
+		Providers: Index{
+			Name:   "ipfs_providers", // 提供者索引的默认名称。
+			Prefix: "v",              // 提供者索引的默认前缀。
+		},
 	}
 }
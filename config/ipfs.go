@@ -1,21 +1,31 @@
 package config
 
 import (
+	"time" // 节点剔除时长
+
 	"github.com/c2h5oh/datasize"                                  // 处理数据大小单位解析（如 "100MB" → uint64）
 	"github.com/ipfs-search/ipfs-search/components/protocol/ipfs" // 组件内部IPFS协议实现 tocheck: 默认配置来源
 )
 
-// IPFS 结构体定义协议层配置（API、网关、分块大小）
+// IPFS 结构体定义协议层配置（API、网关、分块大小、多节点负载均衡）
 // 示例配置
 // ipfs:
 //
 //	api_url: "http://10.0.0.2:5001"     # 自定义远程IPFS节点
 //	gateway_url: "https://ipfs.io"      # 使用公共网关
 //	partial_size: "2MB"                 # 仅下载前2MB内容
+//	nodes:                              # 额外节点
+//	  - api_url: "http://10.0.0.3:5001"
+//	    gateway_url: "http://10.0.0.3:8080"
 type IPFS struct {
 	APIURL      string            `yaml:"api_url" env:"IPFS_API_URL"`         // IPFS API地址（默认：localhost:5001） 用途：连接本地或远程IPFS节点的API端点（如 http://127.0.0.1:5001）。
 	GatewayURL  string            `yaml:"gateway_url" env:"IPFS_GATEWAY_URL"` // 网关地址（默认：localhost:8080）用途：访问IPFS网关的URL，用于内容检索（如 http://localhost:8080/ipfs/）。
 	PartialSize datasize.ByteSize `yaml:"partial_size"`                       // 部分内容下载大小限制（如仅下载文件头） 用途：定义从IPFS下载时的部分内容大小限制（例如仅下载前1MB用于元数据解析），避免大文件全量下载。
+
+	Nodes          []ipfs.Node   `yaml:"nodes" optional:"true"`           // 额外的 IPFS 节点；请求在上述节点与这些节点之间按延迟加权负载均衡。
+	MaxConcurrency int           `yaml:"max_concurrency" optional:"true"` // 每个节点的最大并发请求数，0 表示不限制。
+	EjectTimeouts  int           `yaml:"eject_timeouts" optional:"true"`  // 连续超时达到该次数后剔除节点，0 表示从不剔除。
+	EjectDuration  time.Duration `yaml:"eject_duration"`                  // 被剔除节点不接收请求的时长。
 }
 
 // IPFSConfig 将全局Config中的IPFS配置转换为组件所需的ipfs.Config类型
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/index/meilisearch"
+)
+
+// Meilisearch 结构体保存了 Meilisearch 索引的配置。
+type Meilisearch struct {
+	URL                     string        `yaml:"url" env:"MEILISEARCH_URL"`                         // Meilisearch 的 URL 地址，从 YAML 文件或环境变量读取。
+	APIKey                  string        `yaml:"api_key" env:"MEILISEARCH_API_KEY" optional:"true"` // 可选的 API 密钥。
+	BulkWriterBatchSize     int           `yaml:"bulk_writer_batch_size"`                            // 在达到这个数量的写操作后刷新。
+	BulkWriterFlushInterval time.Duration `yaml:"bulk_writer_flush_interval"`                        // 在达到这个时间后刷新写操作。
+	BulkGetterBatchSize     int           `yaml:"bulk_getter_batch_size"`                            // 批量获取操作的最大批次大小。
+	BulkGetterBatchTimeout  time.Duration `yaml:"bulk_getter_batch_timeout"`                         // 在达到这个时间后执行批量获取。
+	MetadataAttributes      []string      `yaml:"metadata_attributes"`                               // 作为独立属性存储的元数据字段，其余元数据被丢弃。
+}
+
+// MeilisearchConfig 方法从中央配置中返回组件特定的配置。
+func (c *Config) MeilisearchConfig() *meilisearch.ClientConfig {
+	return &meilisearch.ClientConfig{
+		URL:                     c.Meilisearch.URL,
+		APIKey:                  c.Meilisearch.APIKey,
+		BulkWriterBatchSize:     c.Meilisearch.BulkWriterBatchSize,
+		BulkWriterFlushInterval: c.Meilisearch.BulkWriterFlushInterval,
+		BulkGetterBatchSize:     c.Meilisearch.BulkGetterBatchSize,
+		BulkGetterBatchTimeout:  c.Meilisearch.BulkGetterBatchTimeout,
+		MetadataAttributes:      c.Meilisearch.MetadataAttributes,
+	}
+}
+
+// MeilisearchDefaults 函数返回组件配置的默认值，基于组件特定的配置。
+func MeilisearchDefaults() Meilisearch {
+	cfg := meilisearch.DefaultClientConfig()
+
+	return Meilisearch{
+		URL:                     cfg.URL,
+		APIKey:                  cfg.APIKey,
+		BulkWriterBatchSize:     cfg.BulkWriterBatchSize,
+		BulkWriterFlushInterval: cfg.BulkWriterFlushInterval,
+		BulkGetterBatchSize:     cfg.BulkGetterBatchSize,
+		BulkGetterBatchTimeout:  cfg.BulkGetterBatchTimeout,
+		MetadataAttributes:      cfg.MetadataAttributes,
+	}
+}
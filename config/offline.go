@@ -0,0 +1,30 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/protocol/offline"
+)
+
+// Offline 结构体保存了离线协议的配置：从本地区块存储（CAR 文件、flatfs 或 badger 数据存储）读取内容，而不是通过 IPFS 节点。
+type Offline struct {
+	Blockstore  string `yaml:"blockstore" env:"OFFLINE_BLOCKSTORE" optional:"true"` // CAR 文件、flatfs/badger 数据存储或 IPFS 仓库的路径；为空时使用 IPFS 节点。
+	GatewayAddr string `yaml:"gateway_addr"`                                        // 为提取器提供内容的网关监听地址。
+	GatewayURL  string `yaml:"gateway_url"`                                         // 提取器（如 Tika）访问网关的 URL。
+}
+
+// OfflineConfig 方法从中央配置中返回组件特定的配置；分块大小与 IPFS 协议相同。
+func (c *Config) OfflineConfig() *offline.Config {
+	return &offline.Config{
+		GatewayURL:  c.Offline.GatewayURL,
+		PartialSize: c.IPFS.PartialSize,
+	}
+}
+
+// OfflineDefaults 函数返回离线协议配置的默认值。
+func OfflineDefaults() Offline {
+	cfg := offline.DefaultConfig()
+
+	return Offline{
+		GatewayAddr: "localhost:8082",
+		GatewayURL:  cfg.GatewayURL,
+	}
+}
@@ -9,12 +9,24 @@ import (
 
 // OpenSearch 结构体保存了 OpenSearch 的配置。
 type OpenSearch struct {
-	URL                     string            `yaml:"url" env:"OPENSEARCH_URL"`  // OpenSearch 的 URL 地址，从 YAML 文件或环境变量读取。
-	BulkIndexerWorkers      int               `yaml:"bulk_indexer_workers"`      // 用于索引器的工作线程数量。
-	BulkIndexerFlushBytes   datasize.ByteSize `yaml:"bulk_flush_bytes"`          // 在达到这个字节数后刷新索引缓冲区。
-	BulkIndexerFlushTimeout time.Duration     `yaml:"bulk_flush_timeout"`        // 在达到这个时间后刷新索引缓冲区。
-	BulkGetterBatchSize     int               `yaml:"bulk_getter_batch_size"`    // 批量获取操作的最大批次大小。
-	BulkGetterBatchTimeout  time.Duration     `yaml:"bulk_getter_batch_timeout"` // 在达到这个时间后执行批量操作。
+	URL                     string            `yaml:"url" env:"OPENSEARCH_URL"`                                 // OpenSearch 的 URL 地址，从 YAML 文件或环境变量读取。
+	Username                string            `yaml:"username" env:"OPENSEARCH_USERNAME" optional:"true"`       // 可选，基本认证的用户名。
+	Password                string            `yaml:"password" env:"OPENSEARCH_PASSWORD" optional:"true"`       // 可选，基本认证的密码。
+	Mirror                  OpenSearchCluster `yaml:"mirror" optional:"true"`                                   // 可选，镜像后端为 opensearch 时写入的第二个集群；URL 为空时使用上述集群。
+	SyncWrites              bool              `yaml:"sync_writes" env:"OPENSEARCH_SYNC_WRITES" optional:"true"` // 写入阻塞直到结果已知，失败的投递将被拒绝或重试。
+	BulkIndexerWorkers      int               `yaml:"bulk_indexer_workers"`                                     // 用于索引器的工作线程数量。
+	BulkIndexerFlushBytes   datasize.ByteSize `yaml:"bulk_flush_bytes"`                                         // 在达到这个字节数后刷新索引缓冲区。
+	BulkIndexerFlushTimeout time.Duration     `yaml:"bulk_flush_timeout"`                                       // 在达到这个时间后刷新索引缓冲区。
+	SyncFlushTimeout        time.Duration     `yaml:"sync_flush_timeout"`                                       // 同步写入时，在达到这个时间后刷新索引缓冲区（取两者中较短者）。
+	BulkGetterBatchSize     int               `yaml:"bulk_getter_batch_size"`                                   // 批量获取操作的最大批次大小。
+	BulkGetterBatchTimeout  time.Duration     `yaml:"bulk_getter_batch_timeout"`                                // 在达到这个时间后执行批量操作。
+}
+
+// OpenSearchCluster 结构体保存了连接另一个 OpenSearch 集群的地址和凭据。
+type OpenSearchCluster struct {
+	URL      string `yaml:"url" env:"OPENSEARCH_MIRROR_URL" optional:"true"`           // 集群的 URL 地址。
+	Username string `yaml:"username" env:"OPENSEARCH_MIRROR_USERNAME" optional:"true"` // 可选，基本认证的用户名。
+	Password string `yaml:"password" env:"OPENSEARCH_MIRROR_PASSWORD" optional:"true"` // 可选，基本认证的密码。
 }
 
 // OpenSearchDefaults 函数返回 OpenSearch 的默认配置。
@@ -23,6 +35,7 @@ func OpenSearchDefaults() OpenSearch {
 		URL:                     "http://localhost:9200", // 默认的 OpenSearch URL 地址。
 		BulkIndexerWorkers:      runtime.NumCPU(),        // 默认的索引器工作线程数量为系统的 CPU 核心数。
 		BulkIndexerFlushTimeout: 5 * time.Minute,         // 默认的索引缓冲区刷新时间为 5 分钟。
+		SyncFlushTimeout:        time.Second,             // 同步写入时默认每秒刷新索引缓冲区。
 		BulkIndexerFlushBytes:   5e+6,                    // 默认的索引缓冲区刷新字节数为 5MB。
 		BulkGetterBatchSize:     48,                      // 默认的批量获取操作的最大批次大小为 48。
 		BulkGetterBatchTimeout:  150 * time.Millisecond,  // 默认的批量获取操作的最大等待时间为 150 毫秒。
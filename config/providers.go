@@ -0,0 +1,27 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ipfs-search/ipfs-search/components/providers"
+)
+
+// Providers 结构体保存了提供者记录的配置：嗅探器聚合 DHT 中的提供者公告并发布到提供者队列，爬虫将其合并到提供者索引。
+type Providers struct {
+	FlushInterval time.Duration `yaml:"flush_interval" env:"PROVIDERS_FLUSH_INTERVAL" optional:"true"` // 发布聚合公告的间隔；为 0 时不记录提供者。
+	MaxPending    int           `yaml:"max_pending"`                                                   // 待发布资源达到此数量时提前发布；发布期间丢弃其他资源的公告。
+	Retention     time.Duration `yaml:"retention" env:"PROVIDERS_RETENTION"`                           // 丢弃在此时间内未公告内容的节点。
+	MaxProviders  int           `yaml:"max_providers" env:"PROVIDERS_MAX_PROVIDERS"`                   // 每个资源最多保留的（最近公告的）节点数量。
+	SweepInterval time.Duration `yaml:"sweep_interval" optional:"true"`                                // 定期删除所有节点均已过期的文档的间隔；为 0 时仅在再次公告时过期。
+}
+
+// ProvidersConfig 方法从中央配置中返回组件特定的配置。
+func (c *Config) ProvidersConfig() *providers.Config {
+	cfg := providers.Config(c.Providers)
+	return &cfg
+}
+
+// ProvidersDefaults 函数返回组件配置的默认值，基于组件特定的配置。
+func ProvidersDefaults() Providers {
+	return Providers(*providers.DefaultConfig())
+}
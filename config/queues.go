@@ -10,6 +10,7 @@ type Queues struct {
 	Files       Queue `yaml:"files"`       // 已知是文件的资源队列。
 	Directories Queue `yaml:"directories"` // 已知是目录的资源队列。
 	Hashes      Queue `yaml:"hashes"`      // 类型未知的资源队列。
+	Providers   Queue `yaml:"providers"`   // 聚合的提供者公告队列。
 }
 
 // QueuesDefaults 函数返回默认的队列配置。
@@ -24,5 +25,8 @@ func QueuesDefaults() Queues {
 		Hashes: Queue{
 			Name: "hashes", // 类型未知资源队列的默认名称。
 		},
+		Providers: Queue{
+			Name: "providers", // 提供者公告队列的默认名称。
+		},
 	}
 }
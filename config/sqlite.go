@@ -0,0 +1,21 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/index/sqlite"
+)
+
+// SQLite 结构体保存了 SQLite 索引的配置。
+type SQLite struct {
+	Path string `yaml:"path" env:"SQLITE_PATH" optional:"true"` // SQLite 数据库文件路径，从 YAML 文件或环境变量读取；为空时使用内存数据库。
+}
+
+// SQLiteConfig 方法从中央配置中返回组件特定的配置。
+func (c *Config) SQLiteConfig() *sqlite.ClientConfig {
+	cfg := sqlite.ClientConfig(c.SQLite)
+	return &cfg
+}
+
+// SQLiteDefaults 函数返回组件配置的默认值，基于组件特定的配置。
+func SQLiteDefaults() SQLite {
+	return SQLite(*sqlite.DefaultClientConfig())
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/ipfs-search/ipfs-search/components/protocol/trustless"
+)
+
+// Trustless 结构体保存了无信任网关协议的配置：只通过无信任网关规范请求区块和 CAR 文件并在本地校验，而不依赖 Kubo RPC API。
+type Trustless struct {
+	URL         string `yaml:"url" env:"TRUSTLESS_URL" optional:"true"` // 无信任网关的 URL；为空时使用 IPFS 节点。
+	GatewayAddr string `yaml:"gateway_addr"`                            // 为提取器提供已校验内容的网关监听地址。
+	GatewayURL  string `yaml:"gateway_url"`                             // 提取器（如 Tika）访问网关的 URL。
+}
+
+// TrustlessConfig 方法从中央配置中返回组件特定的配置；分块大小与 IPFS 协议相同。
+func (c *Config) TrustlessConfig() *trustless.Config {
+	return &trustless.Config{
+		URL:         c.Trustless.URL,
+		GatewayURL:  c.Trustless.GatewayURL,
+		PartialSize: c.IPFS.PartialSize,
+	}
+}
+
+// TrustlessDefaults 函数返回无信任网关协议配置的默认值。
+func TrustlessDefaults() Trustless {
+	cfg := trustless.DefaultConfig()
+
+	return Trustless{
+		GatewayAddr: "localhost:8083",
+		GatewayURL:  cfg.GatewayURL,
+	}
+}
@@ -3,9 +3,11 @@ package config
 import (
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 // findZeroElements returns a slice of all (nested) struct fields with a zero value.
+// Fields tagged `optional:"true"` may be zero.
 func findZeroElements(s interface{}) []string {
 	var output []string
 
@@ -14,7 +16,12 @@ func findZeroElements(s interface{}) []string {
 	// Iterate over fields
 	for i := 0; i < v.NumField(); i++ {
 		f := v.Field(i)
-		name := v.Type().Field(i).Tag.Get("yaml")
+		field := v.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+
+		if field.Tag.Get("optional") == "true" {
+			continue
+		}
 
 		switch f.Kind() {
 		case reflect.Struct:
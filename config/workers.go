@@ -9,6 +9,7 @@ type Workers struct {
 	HashWorkers       int `yaml:"hash_workers" env:"HASH_WORKERS"`                           // 哈希计算工人的数量。
 	FileWorkers       int `yaml:"file_workers" env:"FILE_WORKERS"`                           // 文件处理工人的数量。
 	DirectoryWorkers  int `yaml:"directory_workers" env:"DIRECTORY_WORKERS"`                 // 目录处理工人的数量。
+	ProviderWorkers   int `yaml:"provider_workers" env:"PROVIDER_WORKERS"`                   // 提供者公告索引工人的数量。
 	MaxIPFSConns      int `yaml:"ipfs_max_connections" env:"IPFS_MAX_CONNECTIONS"`           // 最大 IPFS 连接数。
 	MaxExtractorConns int `yaml:"extractor_max_connections" env:"EXTRACTOR_MAX_CONNECTIONS"` // 最大提取器连接数。
 }
@@ -19,6 +20,7 @@ func WorkersDefaults() Workers {
 		HashWorkers:       70,   // 哈希计算工人的默认数量。
 		FileWorkers:       120,  // 文件处理工人的默认数量。
 		DirectoryWorkers:  70,   // 目录处理工人的默认数量。
+		ProviderWorkers:   10,   // 提供者公告索引工人的默认数量。
 		MaxIPFSConns:      1000, // 最大 IPFS 连接数的默认值。
 		MaxExtractorConns: 100,  // 最大提取器连接数的默认值。
 	}
@@ -0,0 +1,92 @@
+#!/bin/sh
+
+# Migrates NSFW classifications from the misspelled `nfsw` field to `nsfw`.
+# Note: for now, this is a pseudo-script, to be run against the files index (alias ipfs_files).
+# The crawler reads both field names, so it can keep running during the migration.
+
+# Add the `nsfw` field to the existing (strict) mapping, so new documents can be written.
+PUT /ipfs_files/_mapping
+{
+    "properties": {
+        "nsfw": {
+            "properties": {
+                "classification": {
+                    "properties": {
+                        "drawing": {
+                            "type": "float"
+                        },
+                        "hentai": {
+                            "type": "float"
+                        },
+                        "neutral": {
+                            "type": "float"
+                        },
+                        "porn": {
+                            "type": "float"
+                        },
+                        "sexy": {
+                            "type": "float"
+                        }
+                    }
+                },
+                "modelCid": {
+                    "type": "text",
+                    "fields": {
+                        "keyword": {
+                            "type": "keyword",
+                            "ignore_above": 256
+                        }
+                    }
+                },
+                "nsfwServerVersion": {
+                    "type": "text",
+                    "fields": {
+                        "keyword": {
+                            "type": "keyword",
+                            "ignore_above": 256
+                        }
+                    }
+                },
+                "nsfwjsVersion": {
+                    "type": "text",
+                    "fields": {
+                        "keyword": {
+                            "type": "keyword",
+                            "ignore_above": 256
+                        }
+                    }
+                }
+            }
+        }
+    }
+}
+
+# Rewrite old documents in place.
+POST /ipfs_files/_update_by_query?conflicts=proceed&slices=auto&wait_for_completion=false
+{
+    "query": {
+        "exists": {
+            "field": "nfsw"
+        }
+    },
+    "script": {
+        "lang": "painless",
+        "source": "def legacy = ctx._source.remove('nfsw'); if (!ctx._source.containsKey('nsfw')) { ctx._source.nsfw = legacy; }"
+    }
+}
+
+# Alternatively, when reindexing into a new index created from files.json (which no longer maps `nfsw`),
+# rename the field as part of the reindex.
+POST /_reindex?wait_for_completion=false
+{
+    "source": {
+        "index": "ipfs_files_v<old>"
+    },
+    "dest": {
+        "index": "ipfs_files_v<new>"
+    },
+    "script": {
+        "lang": "painless",
+        "source": "if (ctx._source.containsKey('nfsw')) { def legacy = ctx._source.remove('nfsw'); if (!ctx._source.containsKey('nsfw')) { ctx._source.nsfw = legacy; } }"
+    }
+}
@@ -12,6 +12,7 @@ import (
 	"syscall"
 
 	"github.com/ipfs-search/ipfs-search/commands"
+	"github.com/ipfs-search/ipfs-search/components/graph"
 	"github.com/ipfs-search/ipfs-search/config"
 	"gopkg.in/urfave/cli.v1" // CLI框架
 )
@@ -33,10 +34,32 @@ func main() {
 	// 定义所有支持的CLI命令
 	app.Commands = []cli.Command{
 		{
-			Name:    "add",                         // 添加哈希命令
-			Aliases: []string{"a"},                 // 别名
-			Usage:   "add `HASH` to crawler queue", // 用法提示
-			Action:  add,                           // 执行函数（下方定义的add函数）
+			Name:    "add",                                                           // 添加哈希命令
+			Aliases: []string{"a"},                                                   // 别名
+			Usage:   "add `HASH`, /ipfs/HASH, /ipns/NAME or DOMAIN to crawler queue", // 用法提示
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "provider",
+					Usage: "peer `ID` known to provide the content (repeatable)",
+				},
+			},
+			Action: add, // 执行函数（下方定义的add函数）
+		},
+		{
+			Name:      "import-car", // 导入 CAR 文件命令
+			Usage:     "import content from CAR `FILE`, queueing its roots",
+			ArgsUsage: "FILE",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "crawl",
+					Usage: "crawl roots directly instead of queueing them",
+				},
+				cli.BoolFlag{
+					Name:  "push",
+					Usage: "push blocks to the IPFS nodes",
+				},
+			},
+			Action: importCAR,
 		},
 		{
 			Name:    "crawl", // 启动爬虫命令
@@ -44,6 +67,34 @@ func main() {
 			Usage:   "start crawler",
 			Action:  crawl, // 执行函数（下方定义的crawl函数）
 		},
+		{
+			Name:    "graph", // 父子边查询命令组
+			Aliases: []string{"g"},
+			Usage:   "query parent to child edges",
+			Subcommands: []cli.Command{
+				{
+					Name:      "parents", // 祖先
+					Usage:     "list ancestors of `CID`",
+					ArgsUsage: "CID",
+					Flags:     graphFlags,
+					Action:    graphWalk(graph.Ancestors),
+				},
+				{
+					Name:      "children", // 后代
+					Usage:     "list descendants of `CID`",
+					ArgsUsage: "CID",
+					Flags:     graphFlags,
+					Action:    graphWalk(graph.Descendants),
+				},
+				{
+					Name:      "paths", // 完整路径
+					Usage:     "list full paths to `CID`",
+					ArgsUsage: "CID",
+					Flags:     graphFlags,
+					Action:    graphPaths,
+				},
+			},
+		},
 		{
 			Name:    "config", // 配置管理命令组
 			Aliases: []string{},
@@ -161,7 +212,31 @@ func add(c *cli.Context) error {
 
 	fmt.Printf("正在添加哈希 '%s' 到队列\n", hash)
 
-	err = commands.AddHash(ctx, cfg, hash)
+	err = commands.AddHash(ctx, cfg, hash, c.StringSlice("provider"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
+// import-car命令的具体实现
+func importCAR(c *cli.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	onSigTerm(cancel)
+
+	if c.NArg() != 1 {
+		return cli.NewExitError("请提供一个 CAR 文件参数", 1)
+	}
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	err = commands.ImportCAR(ctx, cfg, c.Args().Get(0), c.Bool("crawl"), c.Bool("push"), os.Stdout)
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
@@ -218,3 +293,57 @@ func crawl(c *cli.Context) error {
 
 	return nil
 }
+
+// graph 子命令的通用标志
+var graphFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "depth, d",
+		Value: 3,
+		Usage: "follow at most `N` levels",
+	},
+	cli.IntFlag{
+		Name:  "limit, l",
+		Value: 100,
+		Usage: "query at most `N` edges per CID",
+	},
+}
+
+// graph parents/children 子命令的具体实现
+func graphWalk(dir graph.Direction) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		if c.NArg() != 1 {
+			return cli.NewExitError("请提供一个 CID 参数", 1)
+		}
+
+		cfg, err := getConfig(c)
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+
+		err = commands.WalkGraph(context.Background(), cfg, c.Args().Get(0), dir, c.Int("depth"), c.Int("limit"), os.Stdout)
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+
+		return nil
+	}
+}
+
+// graph paths 子命令的具体实现
+func graphPaths(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return cli.NewExitError("请提供一个 CID 参数", 1)
+	}
+
+	cfg, err := getConfig(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	err = commands.GraphPaths(context.Background(), cfg, c.Args().Get(0), c.Int("depth"), c.Int("limit"), os.Stdout)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
@@ -10,6 +10,7 @@ type AnnotatedResource struct {
 	Source    SourceType `json:",omitempty"`
 	Reference `json:",omitempty"`
 	Stat      `json:",omitempty"`
+	Providers []string `json:",omitempty"` // 已知提供该资源的节点的 peer ID，用于在获取前直接连接。
 }
 
 // String 方法返回第一个引用的名称或 URI。
@@ -0,0 +1,33 @@
+package types
+
+import (
+	"github.com/ipfs/go-cid"
+)
+
+// DagJSON is the multicodec of dag-json, which is missing from go-cid's codec table.
+const DagJSON uint64 = 0x0129
+
+// IsIPLDDocument returns whether content with CID c is a structured IPLD document, as opposed to UnixFS
+// files and directories.
+func IsIPLDDocument(c cid.Cid) bool {
+	switch c.Type() {
+	case cid.DagCBOR, DagJSON:
+		return true
+	default:
+		return false
+	}
+}
+
+// CodecName returns the multicodec name of c; go-cid uses legacy names for some codecs.
+func CodecName(c cid.Cid) string {
+	switch c.Type() {
+	case DagJSON:
+		return "dag-json"
+	case cid.DagCBOR:
+		return "dag-cbor"
+	case cid.DagProtobuf:
+		return "dag-pb"
+	default:
+		return cid.CodecToStr[c.Type()]
+	}
+}
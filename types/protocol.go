@@ -1,20 +1,34 @@
 package types
 
+import (
+	"fmt"
+)
+
 // Protocol is an enum specifying the protocol.
 type Protocol uint8
 
 const (
 	// InvalidProtocol (default) value signifies an invalid protocol.
 	InvalidProtocol Protocol = iota
-	// IPFSProtocol (currently only supported protocol)
+	// IPFSProtocol represents immutable content, identified by CID.
 	IPFSProtocol
+	// IPNSProtocol represents mutable names, resolving to IPFS content.
+	IPNSProtocol
+	// DNSLinkProtocol represents domain names, resolving to IPFS content through DNSLink TXT records.
+	DNSLinkProtocol
 )
 
 func (p Protocol) String() string {
 	switch p {
+	case InvalidProtocol:
+		return "invalid"
 	case IPFSProtocol:
 		return "ipfs"
+	case IPNSProtocol:
+		return "ipns"
+	case DNSLinkProtocol:
+		return "dnslink"
 	default:
-		panic("Invalid value for Protocol.")
+		return fmt.Sprintf("unknown(%d)", uint8(p))
 	}
 }
@@ -14,6 +14,8 @@ const (
 	DirectoryType
 	// PartialType represents *unreferenced* partial items.
 	PartialType
+	// IPLDType is a structured IPLD document, e.g. dag-cbor or dag-json.
+	IPLDType
 )
 
 func (t ResourceType) String() string {
@@ -28,6 +30,8 @@ func (t ResourceType) String() string {
 		return "directory"
 	case PartialType:
 		return "partial"
+	case IPLDType:
+		return "ipld"
 	default:
 		panic("Invalid value for ResourceType.")
 	}